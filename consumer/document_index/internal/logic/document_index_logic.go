@@ -3,25 +3,27 @@ package logic
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"gozero-rag/internal/concurrentx"
 	"os"
 	"path/filepath"
 	"time"
 
 	"gozero-rag/consumer/document_index/internal/svc"
+	"gozero-rag/internal/indexctl"
 	"gozero-rag/internal/model/chunk"
 	"gozero-rag/internal/model/knowledge_base"
 	"gozero-rag/internal/model/knowledge_document"
 	"gozero-rag/internal/model/local_message"
 	"gozero-rag/internal/mq"
+	"gozero-rag/internal/rag_core/constant"
 	"gozero-rag/internal/rag_core/metric"
 	"gozero-rag/internal/rag_core/parser"
+	"gozero-rag/internal/rag_core/qa"
 	"gozero-rag/internal/rag_core/types"
 	"gozero-rag/internal/slicex"
 	"gozero-rag/internal/tools/llmx"
 
-	"github.com/cespare/xxhash/v2"
 	"github.com/cloudwego/eino-ext/components/embedding/openai"
 	"github.com/cloudwego/eino/components/embedding"
 	"github.com/cloudwego/eino/schema"
@@ -34,11 +36,12 @@ import (
 // ========================================
 
 const (
-	defaultEmbeddingDim = 1024
-	tokenEstimateRatio  = 4 // 每 4 个字符约等于 1 个 token
+	tokenEstimateRatio = 4 // 每 4 个字符约等于 1 个 token
 	// 并发批量生成向量
 	batchSize = 10 // 每批10个文本
 	workers   = 4  // 4个并发worker
+	// 向量生成或写入 ES 失败后的最大执行次数, 不超过本地消息表的补偿重试次数
+	maxIndexAttempts = 3
 )
 
 // ========================================
@@ -77,6 +80,9 @@ func (l *DocumentIndexLogic) Consume(_ context.Context, key, val string) (err er
 
 	// 使用本地消息表包装业务逻辑，实现分布式一致性
 	return l.svcCtx.LocalMsgExecutor.Execute(l.ctx, local_message.TaskTypeDocumentIndex, msg.KnowledgeDocumentIndexMsg, func(ctx context.Context) error {
+		if msg.TaskId != "" {
+			return l.processSubTask(ctx, msg)
+		}
 		return l.processDocument(ctx, msg)
 	})
 }
@@ -96,18 +102,24 @@ func (m *indexMessage) isValid() bool {
 
 // indexContext 索引过程中的上下文数据
 type indexContext struct {
-	msg       *indexMessage
-	doc       *knowledge_document.KnowledgeDocument
-	kb        *knowledge_base.KnowledgeBase
-	config    *parser.ParserConfigGeneral
-	embedder  embedding.Embedder
-	qaEnabled bool
-	qaConfig  *qaModelConfig
-	startTime time.Time
-}
-
-// qaModelConfig QA 模型配置
-type qaModelConfig struct {
+	msg        *indexMessage
+	doc        *knowledge_document.KnowledgeDocument
+	kb         *knowledge_base.KnowledgeBase
+	parserId   parser.ParserId // 生效的解析模板, 文档未指定时使用知识库的
+	config     *parser.ParserConfigGeneral
+	template   parser.ParserConfig // 解析模板完整配置 (包含模板专属字段), 文档配置叠加在知识库配置之上
+	embedder   embedding.Embedder
+	qaEnabled  bool
+	qaConfig   *llmModelConfig
+	chunkLlm   *llmModelConfig         // agentic 分片使用的模型, 未配置时分片回退到 structure
+	existing   map[string]*chunk.Chunk // 文档已有分片, 增量索引时复用向量
+	checkpoint map[string][]float64    // 暂停或失败前已完成的 embedding 批次, 恢复时复用
+	progress   *progressReporter
+	startTime  time.Time
+}
+
+// llmModelConfig LLM 模型配置
+type llmModelConfig struct {
 	apiKey    string
 	baseUrl   string
 	modelName string
@@ -134,13 +146,21 @@ func (l *DocumentIndexLogic) processDocument(ctx context.Context, msg *indexMess
 	if err := l.loadDocument(ctx, ic); err != nil {
 		return err
 	}
+	if ic.doc == nil {
+		return nil
+	}
+	ic.progress = newProgressReporter(l, msg)
 
 	// Step 2: 下载文件到临时目录
+	ic.progress.report(ctx, indexctl.StepDownload, 0, 0, "正在下载文件")
 	tempFilePath, cleanup, err := l.downloadToTemp(ctx, ic.doc)
 	if err != nil {
 		return l.failTask(ctx, ic, fmt.Sprintf("文件下载失败: %v", err))
 	}
 	defer cleanup()
+	if err := l.checkSignal(ctx, ic); err != nil {
+		return l.interruptTask(ctx, ic, err, nil)
+	}
 
 	// Step 3: 加载知识库和模型配置
 	if err := l.loadKnowledgeBaseConfig(ctx, ic); err != nil {
@@ -151,44 +171,89 @@ func (l *DocumentIndexLogic) processDocument(ctx context.Context, msg *indexMess
 	if err := l.createEmbedder(ctx, ic); err != nil {
 		return l.failTask(ctx, ic, fmt.Sprintf("创建Embedder失败: %v", err))
 	}
+	if err := l.checkSignal(ctx, ic); err != nil {
+		return l.interruptTask(ctx, ic, err, nil)
+	}
 
-	// Step 5: 更新状态为索引中
-	l.updateRunStatus(ctx, ic.msg.DocumentId, knowledge_document.RunStateRunning, "正在索引...")
+	// Step 5: 更新状态为索引中, 读取文档后被取消或暂停时不覆盖
+	ok, err := l.svcCtx.KnowledgeDocumentModel.CompareAndSetRunStatus(ctx, ic.msg.DocumentId, knowledge_document.RunStatePending, knowledge_document.RunStateRunning, "正在索引...")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		logx.Infof("[DocIndex] DocumentId=%s 状态已变更，跳过处理", ic.msg.DocumentId)
+		return nil
+	}
 
-	// Step 6: 解析文档并切片
-	chunks, err := l.parseDocument(ctx, ic, tempFilePath)
+	// Step 6: 解析文档并切片, 新一轮索引重新计算 agentic 分片的 LLM 调用预算
+	if err := l.svcCtx.LlmBudget.Reset(ctx, ic.msg.DocumentId); err != nil {
+		return l.failTask(ctx, ic, fmt.Sprintf("重置 LLM 调用预算失败: %v", err))
+	}
+	ic.progress.report(ctx, indexctl.StepParse, 0, 0, "正在解析文档")
+	sections, err := l.loadSections(ctx, ic, tempFilePath)
 	if err != nil {
 		return l.failTask(ctx, ic, fmt.Sprintf("文档解析失败: %v", err))
 	}
 
-	// Step 7: 生成向量并构建 ES Chunk
-	saveChunks, totalTokenNum, err := l.buildChunksWithEmbedding(ctx, ic, chunks)
+	// 大文档按章节范围拆分为子任务, 切片、QA 及向量生成由多个消费者并行执行, 由最后完成的子任务汇总
+	if contentSize(sections) > subTaskContentThreshold {
+		return l.dispatchSubTasks(ctx, ic, sections)
+	}
+
+	chunks, err := l.splitSections(ctx, ic, sections)
 	if err != nil {
+		return l.failTask(ctx, ic, fmt.Sprintf("文档切片失败: %v", err))
+	}
+	ic.progress.report(ctx, indexctl.StepSplit, 0, 0, fmt.Sprintf("切片完成, 共 %d 个分片", len(chunks)))
+
+	chunks, err = l.generateQA(ctx, ic, chunks)
+	if err != nil {
+		return l.failTask(ctx, ic, fmt.Sprintf("生成 QA 失败: %v", err))
+	}
+	if err := l.checkSignal(ctx, ic); err != nil {
+		return l.interruptTask(ctx, ic, err, nil)
+	}
+
+	// Step 7: 生成向量并构建 ES Chunk, 复用未变化分片的向量
+	if err := l.loadExistingChunks(ctx, ic); err != nil {
 		return l.failTask(ctx, ic, err.Error())
 	}
+	saveChunks, totalTokenNum, err := l.buildChunksWithEmbedding(ctx, ic, chunks)
+	if isInterrupted(err) {
+		return l.interruptTask(ctx, ic, err, nil)
+	}
+	if err != nil {
+		return l.retryTask(ctx, ic, err.Error())
+	}
+	// QA 向量生成失败不阻断主流程, 这里再次检查, 避免暂停后写入缺少 QA 的分片集合
+	if err := l.checkSignal(ctx, ic); err != nil {
+		return l.interruptTask(ctx, ic, err, nil)
+	}
+	saveChunks = keepReviewedQa(ic.existing, saveChunks)
+	diff := diffChunks(ic.existing, saveChunks)
 
-	// Step 8: 写入 ES
+	// Step 8: 写入 ES, 删除已不存在的旧分片
+	ic.progress.report(ctx, indexctl.StepWrite, 0, 0, fmt.Sprintf("正在写入 %d 个分片", len(saveChunks)))
 	if err := l.svcCtx.ChunkModel.Put(ctx, saveChunks); err != nil {
-		return l.failTask(ctx, ic, fmt.Sprintf("写入ES失败: %v", err))
+		return l.retryTask(ctx, ic, fmt.Sprintf("写入ES失败: %v", err))
+	}
+	// 写入后收到取消信号则回滚本次新增的分片; 暂停信号此时已无意义, 继续完成索引
+	if err := l.checkSignal(ctx, ic); errors.Is(err, indexctl.ErrCanceled) {
+		return l.interruptTask(ctx, ic, err, diff.addedIds)
+	}
+	if err := l.svcCtx.ChunkModel.DeleteByIds(ctx, diff.staleIds); err != nil {
+		return l.failTask(ctx, ic, fmt.Sprintf("删除旧分片失败: %v", err))
 	}
 
 	// Step 9: 更新 MySQL 状态
-	if err := l.finalizeDocument(ctx, ic, len(chunks), totalTokenNum, saveChunks); err != nil {
+	if err := l.finalizeDocument(ctx, ic, len(chunks), totalTokenNum, diff); err != nil {
 		return err
 	}
 
 	// Step 10: 如果开启了rag生成，发送task到消息队列
-	if ic.config.GraphRag.EnableGraph {
-		_ = l.svcCtx.MqPusherClient.PublishGraphGenerateMsg(l.ctx, &mq.GraphGenerateMsg{
-			DocumentId:             ic.msg.DocumentId,
-			KnowledgeBaseId:        ic.msg.KnowledgeBaseId,
-			TenantId:               ic.msg.TenantId,
-			LlmId:                  ic.config.GraphRag.GraphLlmId,
-			EntityTypes:            ic.config.GraphRag.EntityTypes,
-			EnableEntityResolution: ic.config.GraphRag.EnableEntityResolution,
-			EnableCommunity:        ic.config.GraphRag.EnableCommunity,
-		})
-	}
+	l.publishGraphTask(ctx, ic)
+
+	ic.progress.finish(ctx, knowledge_document.RunStateSuccess, diff.String())
 
 	// 记录成功指标
 	l.recordSuccessMetrics(ic, len(saveChunks), len(chunks))
@@ -255,25 +320,49 @@ func (l *DocumentIndexLogic) loadKnowledgeBaseConfig(ctx context.Context, ic *in
 	}
 	ic.kb = kb
 
-	// 解析 Parser 配置
-	if kb.ParserId != parser.ParserIdGeneral {
-		return fmt.Errorf("尚不支持该解析类型: %s", kb.ParserId)
+	// 解析 Parser 配置: 文档级配置覆盖知识库默认配置
+	ic.parserId = kb.ParserId
+	if ic.doc.ParserId != "" {
+		ic.parserId = ic.doc.ParserId
 	}
-
-	var config parser.ParserConfigGeneral
-	if err := json.Unmarshal([]byte(kb.ParserConfig.String), &config); err != nil {
+	template, err := parser.ResolveParserConfig(ic.parserId, kb.ParserConfig.String, ic.doc.ParserConfig)
+	if err != nil {
 		return fmt.Errorf("解析配置无效: %w", err)
 	}
-	ic.config = &config
+	ic.template = template
+	ic.config = template.General()
 
 	// 加载 QA 模型配置（可选）
 	l.loadQAConfig(ctx, ic)
 
+	// 加载分片模型配置（可选）
+	l.loadChunkLlmConfig(ctx, ic)
+
 	return nil
 }
 
+func (l *DocumentIndexLogic) loadChunkLlmConfig(ctx context.Context, ic *indexContext) {
+	if ic.config.ChunkMethod != parser.ChunkMethodAgentic {
+		return
+	}
+
+	modelName, factory := llmx.GetModelNameFactory(ic.config.ChunkLlmId)
+	chatModel, err := l.svcCtx.TenantLlmModel.FindOneByTenantIdLlmFactoryLlmName(ctx, ic.kb.TenantId, factory, modelName)
+	if err != nil {
+		logx.Infof("[DocIndex] 分片模型 %s 未配置或获取失败，agentic 分片将回退到 structure", ic.config.ChunkLlmId)
+		return
+	}
+
+	ic.chunkLlm = &llmModelConfig{
+		apiKey:    chatModel.ApiKey.String,
+		baseUrl:   chatModel.ApiBase.String,
+		modelName: modelName,
+	}
+}
+
 func (l *DocumentIndexLogic) loadQAConfig(ctx context.Context, ic *indexContext) {
-	if ic.config.QaNum <= 0 {
+	// 问答模板的 chunk 本身就是问答对, 无需再生成
+	if ic.config.QaNum <= 0 || ic.parserId == parser.ParserIdQa {
 		return
 	}
 
@@ -285,7 +374,7 @@ func (l *DocumentIndexLogic) loadQAConfig(ctx context.Context, ic *indexContext)
 	}
 
 	ic.qaEnabled = true
-	ic.qaConfig = &qaModelConfig{
+	ic.qaConfig = &llmModelConfig{
 		apiKey:    qaModel.ApiKey.String,
 		baseUrl:   qaModel.ApiBase.String,
 		modelName: modelName,
@@ -303,7 +392,7 @@ func (l *DocumentIndexLogic) createEmbedder(ctx context.Context, ic *indexContex
 		return fmt.Errorf("Embedding 模型未找到: %w", err)
 	}
 
-	embDim := defaultEmbeddingDim
+	embDim := chunk.DefaultEmbeddingDims
 	embedder, err := openai.NewEmbedder(ctx, &openai.EmbeddingConfig{
 		APIKey:     llmModel.ApiKey.String,
 		BaseURL:    llmModel.ApiBase.String,
@@ -322,21 +411,46 @@ func (l *DocumentIndexLogic) createEmbedder(ctx context.Context, ic *indexContex
 // Step 5: 文档解析
 // ========================================
 
-func (l *DocumentIndexLogic) parseDocument(ctx context.Context, ic *indexContext, filePath string) ([]*schema.Document, error) {
+// loadSections 加载并预清洗文档, 返回切片前的章节
+func (l *DocumentIndexLogic) loadSections(ctx context.Context, ic *indexContext, filePath string) ([]*schema.Document, error) {
 	req := &types.ProcessRequest{
-		URI: filePath,
-		IndexConfig: types.ProcessConfig{
-			KnowledgeName:  ic.doc.DocName.String,
-			EnableQACheck:  ic.qaEnabled,
-			Separators:     ic.config.Separator,
-			ChunkOverlap:   ic.config.ChunkOverlapTokenNum,
-			MaxChunkLength: ic.config.ChunkTokenNum,
-			QaNum:          ic.config.QaNum,
-			LlmConfig:      l.buildLlmConfig(ic),
-		},
+		URI:         filePath,
+		IndexConfig: l.buildProcessConfig(ctx, ic),
+	}
+	return l.svcCtx.DocProcessService.Load(ctx, req)
+}
+
+// splitSections 对章节切片, QA 生成由 generateQA 单独执行, 大文档拆分后在子任务中并行切片及生成
+// agentic 分片的 LLM 调用预算按文档计数, 各子任务共享
+func (l *DocumentIndexLogic) splitSections(ctx context.Context, ic *indexContext, sections []*schema.Document) ([]*schema.Document, error) {
+	indexConfig := l.buildProcessConfig(ctx, ic)
+	indexConfig.EnableQACheck = false
+	indexConfig.AgenticLlmBudget = l.svcCtx.LlmBudget.Document(ic.msg.DocumentId)
+	return l.svcCtx.DocProcessService.Split(ctx, indexConfig, sections)
+}
+
+// generateQA 对切片评分并生成 QA, 未开启 QA 时仅评分
+func (l *DocumentIndexLogic) generateQA(ctx context.Context, ic *indexContext, chunks []*schema.Document) ([]*schema.Document, error) {
+	if len(chunks) == 0 {
+		return chunks, nil
 	}
+	if !ic.qaEnabled {
+		qa.NewQaChecker().Score(chunks)
+		return chunks, nil
+	}
+	indexConfig := l.buildProcessConfig(ctx, ic)
+	return qa.NewQaChecker().Check(context.WithValue(ctx, constant.CtxKeyIndexConfig, indexConfig), chunks)
+}
 
-	return l.svcCtx.DocProcessService.Invoke(ctx, req)
+func (l *DocumentIndexLogic) buildProcessConfig(ctx context.Context, ic *indexContext) types.ProcessConfig {
+	indexConfig := parser.NewProcessConfig(ic.parserId, ic.template)
+	indexConfig.KnowledgeName = ic.doc.DocName.String
+	indexConfig.EnableQACheck = ic.qaEnabled
+	indexConfig.LlmConfig = l.buildLlmConfig(ic)
+	indexConfig.QaProgress = func(done, total int) {
+		ic.progress.report(ctx, indexctl.StepQa, done, total, fmt.Sprintf("生成 QA %d/%d", done, total))
+	}
+	return indexConfig
 }
 
 func (l *DocumentIndexLogic) buildLlmConfig(ic *indexContext) types.ProcessLlmConfig {
@@ -355,6 +469,12 @@ func (l *DocumentIndexLogic) buildLlmConfig(ic *indexContext) types.ProcessLlmCo
 		config.QaModelName = ic.qaConfig.modelName
 	}
 
+	if ic.chunkLlm != nil {
+		config.ChatKey = ic.chunkLlm.apiKey
+		config.ChatBaseUrl = ic.chunkLlm.baseUrl
+		config.ChatModelName = ic.chunkLlm.modelName
+	}
+
 	return config
 }
 
@@ -393,6 +513,9 @@ func (l *DocumentIndexLogic) buildChunksWithEmbedding(ctx context.Context, ic *i
 	}
 
 	saveChunks := contentChunks
+	if parentChunks := l.buildParentChunks(ic, chunks, contentChunks); len(parentChunks) > 0 {
+		saveChunks = append(saveChunks, parentChunks...)
+	}
 	if len(qaChunks) > 0 {
 		saveChunks = append(saveChunks, qaChunks...)
 	}
@@ -401,37 +524,27 @@ func (l *DocumentIndexLogic) buildChunksWithEmbedding(ctx context.Context, ic *i
 }
 
 func (l *DocumentIndexLogic) buildContentChunks(ctx context.Context, ic *indexContext, chunks []*schema.Document) ([]*chunk.Chunk, int64, error) {
-
-	// 批量生成向量
-	vectors, err := concurrentx.ParallelProcessOrdered(ctx, chunks, concurrentx.ParallelProcessConfig{
-		BatchSize: batchSize,
-		Workers:   workers,
-		Timeout:   60 * time.Second,
-	}, func(ctx context.Context, batch []*schema.Document) ([][]float64, error) {
-		contents := slicex.Into(batch, func(d *schema.Document) string {
-			return d.Content
-		})
-		return ic.embedder.EmbedStrings(ctx, contents)
+	chunkIds := slicex.Into(chunks, func(doc *schema.Document) string {
+		return l.contentChunkId(ic, doc)
 	})
+
+	// 批量生成向量, 未变化的分片复用已有向量
+	vectors, err := l.embedWithReuse(ctx, ic, chunkIds, slicex.Into(chunks, embedText))
 	if err != nil {
 		return nil, 0, fmt.Errorf("生成 Embedding 失败: %w", err)
 	}
 
-	if len(vectors) != len(chunks) {
-		return nil, 0, fmt.Errorf("embedding 数量(%d)与 Chunk 数量(%d)不一致", len(vectors), len(chunks))
-	}
-
 	saveChunks := make([]*chunk.Chunk, 0, len(chunks))
 	var totalTokenNum int64
 
 	now := float64(time.Now().Unix())
 
 	for i, doc := range chunks {
-		chunkId := l.generateChunkId("chunk", doc.Content, ic.msg.DocumentId)
+		chunkId := chunkIds[i]
 		tokenNum := int64(len(doc.Content) / tokenEstimateRatio)
 		totalTokenNum += tokenNum
 
-		saveChunks = append(saveChunks, &chunk.Chunk{
+		c := &chunk.Chunk{
 			Id:            chunkId,
 			DocId:         ic.msg.DocumentId,
 			KbIds:         []string{ic.msg.KnowledgeBaseId},
@@ -440,27 +553,117 @@ func (l *DocumentIndexLogic) buildContentChunks(ctx context.Context, ic *indexCo
 			DocName:       ic.doc.DocName.String,
 			CreateTime:    now,
 			Available:     1,
-		})
+		}
+
+		// 表格模式: 记录工作表和行号, 便于按工作表过滤
+		if sheet, ok := doc.MetaData[constant.MetaSheetName].(string); ok {
+			c.SheetName = sheet
+		}
+		if rows, ok := doc.MetaData[constant.MetaRowIndex].([]int); ok {
+			c.RowNum = rows
+		}
+		// 问答模板: 问题同时写入 question_keywords, 便于关键词召回
+		if question, ok := doc.MetaData[constant.MetaQuestion].(string); ok {
+			c.QuestionKw = []string{question}
+		}
+		applyQuality(c, doc, ic.config.MinQualityScore)
+
+		saveChunks = append(saveChunks, c)
 	}
 
 	return saveChunks, totalTokenNum, nil
 }
 
+// applyQuality 写入质量评分, 低于知识库阈值的分片默认禁用
+// 已存在分片的启用状态在 diffChunks 中保留, 阈值只影响新增分片
+func applyQuality(c *chunk.Chunk, doc *schema.Document, minScore float64) {
+	score, ok := doc.MetaData[constant.MetaQualityScore].(float64)
+	if !ok {
+		return
+	}
+	c.QualityScore = &score
+	if details, ok := doc.MetaData[constant.MetaQualityDetails].(*types.ChunkQualityScore); ok {
+		c.QualityIssues = details.Issues
+		c.QualitySuggestions = details.Suggestions
+	}
+	if minScore > 0 && score < minScore {
+		c.Available = 0
+	}
+}
+
+// buildParentChunks 父子分片: 按子块记录的父块生成父块 (不生成向量), 并回填子块的 ParentId
+// contentChunks 与 docs 一一对应
+func (l *DocumentIndexLogic) buildParentChunks(ic *indexContext, docs []*schema.Document, contentChunks []*chunk.Chunk) []*chunk.Chunk {
+	now := float64(time.Now().Unix())
+	parents := make(map[string]*chunk.Chunk)
+	var parentChunks []*chunk.Chunk
+
+	for i, doc := range docs {
+		parentContent, ok := doc.MetaData[constant.MetaParentContent].(string)
+		if !ok {
+			continue
+		}
+		parentKey, _ := doc.MetaData[constant.MetaParentId].(string)
+
+		parent, ok := parents[parentKey]
+		if !ok {
+			parent = &chunk.Chunk{
+				Id:         l.generateChunkId("parent", parentContent, ic.msg.DocumentId),
+				DocId:      ic.msg.DocumentId,
+				KbIds:      []string{ic.msg.KnowledgeBaseId},
+				Content:    parentContent,
+				DocName:    ic.doc.DocName.String,
+				CreateTime: now,
+				Available:  1,
+				ChunkType:  chunk.ChunkTypeParent,
+				SheetName:  contentChunks[i].SheetName,
+			}
+			parents[parentKey] = parent
+			parentChunks = append(parentChunks, parent)
+		}
+		contentChunks[i].ParentId = parent.Id
+	}
+
+	return parentChunks
+}
+
+// embedText 生成向量使用的文本, 问答模板的 chunk 使用问题生成向量, 与 QA chunk 保持一致
+func embedText(doc *schema.Document) string {
+	if question, ok := doc.MetaData[constant.MetaQuestion].(string); ok && question != "" {
+		return question
+	}
+	return doc.Content
+}
+
+// contentChunkId 内容分片 id, 问答模板的分片使用 qa 前缀
+func (l *DocumentIndexLogic) contentChunkId(ic *indexContext, doc *schema.Document) string {
+	prefix := "chunk"
+	if _, ok := doc.MetaData[constant.MetaQuestion].(string); ok {
+		prefix = "qa"
+	}
+	return l.generateChunkId(prefix, doc.Content, ic.msg.DocumentId)
+}
+
+// buildQAChunks 为生成的问答对构建 QA 分片, 记录来源分片 id, 检索命中后返回来源分片
 func (l *DocumentIndexLogic) buildQAChunks(ctx context.Context, ic *indexContext, docs []*schema.Document) ([]*chunk.Chunk, error) {
 	// Step 1: 收集所有 QA 对
 	type qaWithMeta struct {
-		qa       types.QAItem
-		question string
+		qa        types.QAItem
+		question  string
+		sourceId  string
+		sheetName string
 	}
 	var allQAs []qaWithMeta
 
 	for _, doc := range docs {
-		qaPairs, ok := doc.MetaData["qa_pairs"].([]types.QAItem)
+		qaPairs, ok := doc.MetaData[constant.MetaQaPairs].([]types.QAItem)
 		if !ok || len(qaPairs) == 0 {
 			continue
 		}
+		sourceId := l.contentChunkId(ic, doc)
+		sheetName, _ := doc.MetaData[constant.MetaSheetName].(string)
 		for _, qa := range qaPairs {
-			allQAs = append(allQAs, qaWithMeta{qa: qa, question: qa.Question})
+			allQAs = append(allQAs, qaWithMeta{qa: qa, question: qa.Question, sourceId: sourceId, sheetName: sheetName})
 		}
 	}
 
@@ -473,14 +676,12 @@ func (l *DocumentIndexLogic) buildQAChunks(ctx context.Context, ic *indexContext
 		return q.question
 	})
 
-	// Step 3: 一次性并发批量生成所有 QA 向量
-	qaVectors, err := concurrentx.ParallelProcessOrdered(ctx, questions, concurrentx.ParallelProcessConfig{
-		BatchSize: batchSize,
-		Workers:   workers,
-		Timeout:   60 * time.Second,
-	}, func(ctx context.Context, batch []string) ([][]float64, error) {
-		return ic.embedder.EmbedStrings(ctx, batch)
+	qaIds := slicex.Into(allQAs, func(q qaWithMeta) string {
+		return l.generateChunkId("qa", q.qa.Question+q.qa.Answer, ic.msg.DocumentId)
 	})
+
+	// Step 3: 一次性并发批量生成所有 QA 向量, 未变化的 QA 复用已有向量
+	qaVectors, err := l.embedWithReuse(ctx, ic, qaIds, questions)
 	if err != nil {
 		return nil, fmt.Errorf("QA 向量生成失败: %w", err)
 	}
@@ -495,8 +696,8 @@ func (l *DocumentIndexLogic) buildQAChunks(ctx context.Context, ic *indexContext
 
 	for i, qaMeta := range allQAs {
 		qa := qaMeta.qa
-		qaId := l.generateChunkId("qa", qa.Question+qa.Answer, ic.msg.DocumentId)
-		qaContent := fmt.Sprintf("Question: %s\nAnswer: %s", qa.Question, qa.Answer)
+		qaId := qaIds[i]
+		qaContent := chunk.QaContent(qa.Question, qa.Answer)
 
 		qaChunks = append(qaChunks, &chunk.Chunk{
 			Id:            qaId,
@@ -507,6 +708,12 @@ func (l *DocumentIndexLogic) buildQAChunks(ctx context.Context, ic *indexContext
 			DocName:       ic.doc.DocName.String,
 			CreateTime:    now,
 			Available:     1,
+			SheetName:     qaMeta.sheetName,
+			ChunkType:     chunk.ChunkTypeQa,
+			SourceId:      qaMeta.sourceId,
+			Question:      qa.Question,
+			Answer:        qa.Answer,
+			QaStatus:      chunk.QaStatusPending,
 		})
 	}
 
@@ -517,21 +724,55 @@ func (l *DocumentIndexLogic) buildQAChunks(ctx context.Context, ic *indexContext
 // Step 7: 完成索引
 // ========================================
 
-func (l *DocumentIndexLogic) finalizeDocument(ctx context.Context, ic *indexContext, chunkCount int, totalTokenNum int64, saveChunks []*chunk.Chunk) error {
-	err := l.svcCtx.KnowledgeDocumentModel.UpdateStatusWithChunkCount(ctx, ic.msg.DocumentId, knowledge_document.RunStateSuccess, int64(chunkCount), totalTokenNum)
+func (l *DocumentIndexLogic) finalizeDocument(ctx context.Context, ic *indexContext, chunkCount int, totalTokenNum int64, diff *chunkDiff) error {
+	err := l.svcCtx.KnowledgeDocumentModel.UpdateIndexResult(ctx, ic.msg.DocumentId, knowledge_document.RunStateSuccess, int64(chunkCount), totalTokenNum, diff.stat())
 	if err != nil {
-		logx.Errorf("[DocIndex] MySQL 更新失败，回滚 ES 数据: %v", err)
-		_ = l.svcCtx.ChunkModel.DeleteByDocId(ctx, ic.msg.KnowledgeBaseId, ic.msg.DocumentId)
+		// 只回滚本次新增的分片, 复用的分片仍属于文档的有效数据
+		logx.Errorf("[DocIndex] MySQL 更新失败，回滚本次新增的 ES 分片: %v", err)
+		if delErr := l.svcCtx.ChunkModel.DeleteByIds(ctx, diff.addedIds); delErr != nil {
+			logx.Errorf("[DocIndex] DocumentId=%s 回滚新增分片失败: %v", ic.msg.DocumentId, delErr)
+		}
 		return l.failTask(ctx, ic, fmt.Sprintf("更新数据库失败: %v", err))
 	}
+	l.updateRunStatus(ctx, ic.msg.DocumentId, knowledge_document.RunStateSuccess, diff.String())
+	l.clearControlState(ctx, ic)
+	logx.Infof("[DocIndex] DocumentId=%s %s", ic.msg.DocumentId, diff)
 
+	l.recordEffectiveConfig(ctx, ic)
 	return nil
 }
 
+// recordEffectiveConfig 记录本次索引实际生效的配置, 便于排查
+// 写入独立的 effective_config 字段, parser_config 保留用户的文档级覆盖配置, 后续知识库默认配置的修改仍会生效
+func (l *DocumentIndexLogic) recordEffectiveConfig(ctx context.Context, ic *indexContext) {
+	if effective, err := json.Marshal(ic.template); err == nil {
+		if err := l.svcCtx.KnowledgeDocumentModel.UpdateEffectiveConfig(ctx, ic.msg.DocumentId, string(effective)); err != nil {
+			logx.Errorf("[DocIndex] DocumentId=%s 记录生效配置失败: %v", ic.msg.DocumentId, err)
+		}
+	}
+}
+
 // ========================================
 // 辅助函数
 // ========================================
 
+// publishGraphTask 开启知识图谱时投递图谱提取任务
+func (l *DocumentIndexLogic) publishGraphTask(ctx context.Context, ic *indexContext) {
+	if !ic.config.GraphRag.EnableGraph {
+		return
+	}
+	ic.progress.report(ctx, indexctl.StepGraph, 0, 0, "正在投递知识图谱任务")
+	_ = l.svcCtx.MqPusherClient.PublishGraphGenerateMsg(l.ctx, &mq.GraphGenerateMsg{
+		DocumentId:             ic.msg.DocumentId,
+		KnowledgeBaseId:        ic.msg.KnowledgeBaseId,
+		TenantId:               ic.msg.TenantId,
+		LlmId:                  ic.config.GraphRag.GraphLlmId,
+		EntityTypes:            ic.config.GraphRag.EntityTypes,
+		EnableEntityResolution: ic.config.GraphRag.EnableEntityResolution,
+		EnableCommunity:        ic.config.GraphRag.EnableCommunity,
+	})
+}
+
 func (l *DocumentIndexLogic) parseMessage(val string) (*indexMessage, error) {
 	var msg mq.KnowledgeDocumentIndexMsg
 	if err := json.Unmarshal([]byte(val), &msg); err != nil {
@@ -541,9 +782,7 @@ func (l *DocumentIndexLogic) parseMessage(val string) (*indexMessage, error) {
 }
 
 func (l *DocumentIndexLogic) generateChunkId(prefix, content, docId string) string {
-	hashStr := fmt.Sprintf("%s-%s", content, docId)
-	hash := xxhash.Sum64String(hashStr)
-	return fmt.Sprintf("%s-%x", prefix, hash)
+	return chunk.GenerateId(prefix, content, docId)
 }
 
 func (l *DocumentIndexLogic) updateRunStatus(ctx context.Context, docId, status, msg string) {
@@ -553,6 +792,12 @@ func (l *DocumentIndexLogic) updateRunStatus(ctx context.Context, docId, status,
 func (l *DocumentIndexLogic) failTask(ctx context.Context, ic *indexContext, reason string) error {
 	logx.Errorf("[DocIndex] DocumentId=%s 失败: %s", ic.msg.DocumentId, reason)
 	l.updateRunStatus(ctx, ic.msg.DocumentId, knowledge_document.RunStateFailed, reason)
+	// 最终失败不再自动重试, 删除检查点避免暂存向量在 Redis 中滞留至过期
+	_ = l.svcCtx.IndexSignal.Clear(ctx, ic.msg.DocumentId)
+	if err := l.svcCtx.IndexCheckpoint.Clear(ctx, ic.msg.DocumentId); err != nil {
+		logx.Errorf("[DocIndex] DocumentId=%s 清理检查点失败: %v", ic.msg.DocumentId, err)
+	}
+	ic.progress.finish(ctx, knowledge_document.RunStateFailed, reason)
 
 	// 记录失败指标
 	metric.IndexingErrors.WithLabelValues(ic.msg.KnowledgeBaseId, "process_error").Inc()
@@ -565,6 +810,7 @@ func (l *DocumentIndexLogic) recoverFromPanic(ic *indexContext, err *error) {
 	if r := recover(); r != nil {
 		logx.Errorf("[DocIndex] DocumentId=%s panic recovered: %v", ic.msg.DocumentId, r)
 		l.updateRunStatus(l.ctx, ic.msg.DocumentId, knowledge_document.RunStateFailed, fmt.Sprintf("panic: %v", r))
+		ic.progress.finish(l.ctx, knowledge_document.RunStateFailed, fmt.Sprintf("panic: %v", r))
 		*err = nil
 	}
 }
@@ -0,0 +1,54 @@
+package logic
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gozero-rag/internal/model/chunk"
+	"gozero-rag/internal/rag_core/constant"
+	"gozero-rag/internal/rag_core/types"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyQuality(t *testing.T) {
+	doc := &schema.Document{MetaData: map[string]any{
+		constant.MetaQualityScore: 45.0,
+		constant.MetaQualityDetails: &types.ChunkQualityScore{
+			TotalScore:  45,
+			Issues:      []string{types.IssueChunkTooShort},
+			Suggestions: []string{"合并相邻分片"},
+		},
+	}}
+
+	c := &chunk.Chunk{Available: 1}
+	applyQuality(c, doc, 0)
+	require.NotNil(t, c.QualityScore)
+	assert.Equal(t, 45.0, *c.QualityScore)
+	assert.Equal(t, []string{types.IssueChunkTooShort}, c.QualityIssues)
+	assert.Equal(t, []string{"合并相邻分片"}, c.QualitySuggestions)
+	assert.Equal(t, 1, c.Available)
+
+	// 低于阈值默认禁用
+	c = &chunk.Chunk{Available: 1}
+	applyQuality(c, doc, 60)
+	assert.Equal(t, 0, c.Available)
+
+	// 未评分的分片保持原样
+	c = &chunk.Chunk{Available: 1}
+	applyQuality(c, &schema.Document{}, 60)
+	assert.Equal(t, 1, c.Available)
+	assert.Nil(t, c.QualityScore)
+
+	// 0 分同样写入, 不能被当作未评分
+	c = &chunk.Chunk{Available: 1}
+	applyQuality(c, &schema.Document{MetaData: map[string]any{constant.MetaQualityScore: 0.0}}, 60)
+	require.NotNil(t, c.QualityScore)
+	assert.Zero(t, *c.QualityScore)
+	assert.Equal(t, 0, c.Available)
+	data, err := json.Marshal(c)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"quality_score_flt":0`)
+}
@@ -0,0 +1,203 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gozero-rag/internal/concurrentx"
+	"gozero-rag/internal/model/chunk"
+	"gozero-rag/internal/model/knowledge_document"
+	"gozero-rag/internal/rag_core/constant"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// ========================================
+// 增量索引
+// chunk id 由 xxhash(content + docId) 生成, 内容不变则 id 不变:
+// 1. 复用已有分片的向量, 只为新分片生成向量
+// 2. 写入新分片集合后删除已不存在的旧分片
+// ========================================
+
+// chunkDiff 本次分片集合与已有分片的差异
+type chunkDiff struct {
+	added     int
+	removed   int
+	unchanged int
+	addedIds  []string // 本次新写入的分片, 取消时删除
+	staleIds  []string // 需要删除的旧分片
+}
+
+func (d *chunkDiff) String() string {
+	return fmt.Sprintf("索引完成: 新增 %d, 删除 %d, 未变 %d", d.added, d.removed, d.unchanged)
+}
+
+// stat 返回持久化到文档记录的增量统计
+func (d *chunkDiff) stat() knowledge_document.ChunkDiff {
+	return knowledge_document.ChunkDiff{Added: int64(d.added), Removed: int64(d.removed), Reused: int64(d.unchanged)}
+}
+
+// loadExistingChunks 加载文档已有的分片 (含向量), 首次索引时为空
+func (l *DocumentIndexLogic) loadExistingChunks(ctx context.Context, ic *indexContext) error {
+	chunks, err := l.svcCtx.ChunkModel.ListAllByDocId(ctx, ic.msg.KnowledgeBaseId, ic.msg.DocumentId, true)
+	if err != nil {
+		return fmt.Errorf("查询已有分片失败: %w", err)
+	}
+	l.setExistingChunks(ctx, ic, chunks)
+	return nil
+}
+
+// loadPartExistingChunks 子任务只加载本范围切片对应的已有分片 (含向量): 内容分片、父块及以内容分片为来源的 QA 分片
+func (l *DocumentIndexLogic) loadPartExistingChunks(ctx context.Context, ic *indexContext, docs []*schema.Document) error {
+	chunks, err := l.svcCtx.ChunkModel.ListRelatedByIds(ctx, ic.msg.KnowledgeBaseId, ic.msg.DocumentId, l.relatedChunkIds(ic, docs), true)
+	if err != nil {
+		return fmt.Errorf("查询已有分片失败: %w", err)
+	}
+	l.setExistingChunks(ctx, ic, chunks)
+	return nil
+}
+
+// relatedChunkIds 切片对应的内容分片及父块 id, id 生成规则与 buildChunksWithEmbedding 一致
+func (l *DocumentIndexLogic) relatedChunkIds(ic *indexContext, docs []*schema.Document) []string {
+	seen := make(map[string]bool, len(docs))
+	ids := make([]string, 0, len(docs))
+	add := func(id string) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	for _, doc := range docs {
+		add(l.contentChunkId(ic, doc))
+		if parentContent, ok := doc.MetaData[constant.MetaParentContent].(string); ok {
+			add(l.generateChunkId("parent", parentContent, ic.msg.DocumentId))
+		}
+	}
+	return ids
+}
+
+func (l *DocumentIndexLogic) setExistingChunks(ctx context.Context, ic *indexContext, chunks []*chunk.Chunk) {
+	ic.existing = make(map[string]*chunk.Chunk, len(chunks))
+	for _, c := range chunks {
+		ic.existing[c.Id] = c
+	}
+	if len(chunks) > 0 {
+		logx.Infof("[DocIndex] DocumentId=%s 已有 %d 个分片, 执行增量索引", ic.msg.DocumentId, len(chunks))
+	}
+
+	l.loadCheckpoint(ctx, ic)
+}
+
+// embedWithReuse 按分片 id 复用已有向量及检查点中的向量, 仅为新分片生成向量, 返回结果与 ids 一一对应
+// 每个批次生成前检查取消/暂停信号, 生成后写入检查点
+func (l *DocumentIndexLogic) embedWithReuse(ctx context.Context, ic *indexContext, ids []string, texts []string) ([][]float64, error) {
+	vectors := make([][]float64, len(ids))
+	var missing []int
+	for i, id := range ids {
+		if old, ok := ic.existing[id]; ok && len(old.ContentVector) > 0 {
+			vectors[i] = old.ContentVector
+			continue
+		}
+		if vec, ok := ic.checkpoint[id]; ok {
+			vectors[i] = vec
+			continue
+		}
+		missing = append(missing, i)
+	}
+
+	if len(missing) == 0 {
+		return vectors, nil
+	}
+
+	ic.progress.addEmbedBatches((len(missing) + batchSize - 1) / batchSize)
+	embedded, err := concurrentx.ParallelProcessOrdered(ctx, missing, concurrentx.ParallelProcessConfig{
+		BatchSize: batchSize,
+		Workers:   workers,
+		Timeout:   60 * time.Second,
+	}, func(ctx context.Context, batch []int) ([][]float64, error) {
+		if err := l.checkSignal(ctx, ic); err != nil {
+			return nil, err
+		}
+
+		batchIds := make([]string, len(batch))
+		batchTexts := make([]string, len(batch))
+		for j, i := range batch {
+			batchIds[j] = ids[i]
+			batchTexts[j] = texts[i]
+		}
+		embedded, err := ic.embedder.EmbedStrings(ctx, batchTexts)
+		if err != nil {
+			return nil, err
+		}
+		l.saveCheckpoint(ctx, ic, batchIds, embedded)
+		ic.progress.embedBatchDone(ctx)
+		return embedded, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(embedded) != len(missing) {
+		return nil, fmt.Errorf("embedding 数量(%d)与待生成数量(%d)不一致", len(embedded), len(missing))
+	}
+
+	for j, i := range missing {
+		vectors[i] = embedded[j]
+	}
+
+	logx.Infof("[DocIndex] DocumentId=%s 复用向量 %d 个, 新生成 %d 个", ic.msg.DocumentId, len(ids)-len(missing), len(missing))
+	return vectors, nil
+}
+
+// keepReviewedQa 保留已审核的 QA 分片: 重新生成的同 id 分片替换为已审核版本, 来源分片仍存在的已审核分片追加到分片集合
+func keepReviewedQa(existing map[string]*chunk.Chunk, saveChunks []*chunk.Chunk) []*chunk.Chunk {
+	saved := make(map[string]bool, len(saveChunks))
+	for i, c := range saveChunks {
+		if old, ok := existing[c.Id]; ok && old.QaStatus == chunk.QaStatusApproved {
+			saveChunks[i] = old
+		}
+		saved[c.Id] = true
+	}
+
+	for _, old := range existing {
+		if old.ChunkType != chunk.ChunkTypeQa || old.QaStatus != chunk.QaStatusApproved || saved[old.Id] {
+			continue
+		}
+		if saved[old.SourceId] {
+			saveChunks = append(saveChunks, old)
+			saved[old.Id] = true
+		}
+	}
+	return saveChunks
+}
+
+// diffChunks 对比新旧分片集合, 未变化的分片保留用户设置的启用状态
+func diffChunks(existing map[string]*chunk.Chunk, saveChunks []*chunk.Chunk) *chunkDiff {
+	diff := &chunkDiff{}
+	seen := make(map[string]bool, len(saveChunks))
+
+	for _, c := range saveChunks {
+		if seen[c.Id] {
+			continue
+		}
+		seen[c.Id] = true
+
+		if old, ok := existing[c.Id]; ok {
+			c.Available = old.Available
+			diff.unchanged++
+		} else {
+			diff.added++
+			diff.addedIds = append(diff.addedIds, c.Id)
+		}
+	}
+
+	for id := range existing {
+		if !seen[id] {
+			diff.staleIds = append(diff.staleIds, id)
+		}
+	}
+	diff.removed = len(diff.staleIds)
+
+	return diff
+}
@@ -0,0 +1,66 @@
+package logic
+
+import (
+	"testing"
+
+	"gozero-rag/internal/model/chunk"
+	"gozero-rag/internal/model/knowledge_document"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffChunks(t *testing.T) {
+	existing := map[string]*chunk.Chunk{
+		"chunk-a": {Id: "chunk-a", Available: 0}, // 用户已禁用
+		"chunk-b": {Id: "chunk-b", Available: 1},
+		"chunk-c": {Id: "chunk-c", Available: 1},
+	}
+	saveChunks := []*chunk.Chunk{
+		{Id: "chunk-a", Available: 1},
+		{Id: "chunk-b", Available: 1},
+		{Id: "chunk-d", Available: 1},
+		{Id: "chunk-d", Available: 1},
+	}
+
+	diff := diffChunks(existing, saveChunks)
+
+	assert.Equal(t, 1, diff.added)
+	assert.Equal(t, 1, diff.removed)
+	assert.Equal(t, 2, diff.unchanged)
+	assert.Equal(t, []string{"chunk-c"}, diff.staleIds)
+	assert.Equal(t, 0, saveChunks[0].Available)
+	assert.Equal(t, knowledge_document.ChunkDiff{Added: 1, Removed: 1, Reused: 2}, diff.stat())
+}
+
+func TestDiffChunks_FirstIndex(t *testing.T) {
+	diff := diffChunks(nil, []*chunk.Chunk{{Id: "chunk-a"}, {Id: "chunk-b"}})
+
+	assert.Equal(t, 2, diff.added)
+	assert.Equal(t, 0, diff.removed)
+	assert.Empty(t, diff.staleIds)
+}
+
+func TestKeepReviewedQa(t *testing.T) {
+	existing := map[string]*chunk.Chunk{
+		"chunk-a": {Id: "chunk-a"},
+		"qa-1":    {Id: "qa-1", ChunkType: chunk.ChunkTypeQa, SourceId: "chunk-a", Question: "人工修改", QaStatus: chunk.QaStatusApproved},
+		"qa-2":    {Id: "qa-2", ChunkType: chunk.ChunkTypeQa, SourceId: "chunk-a", QaStatus: chunk.QaStatusApproved},
+		"qa-3":    {Id: "qa-3", ChunkType: chunk.ChunkTypeQa, SourceId: "chunk-b", QaStatus: chunk.QaStatusApproved},
+		"qa-4":    {Id: "qa-4", ChunkType: chunk.ChunkTypeQa, SourceId: "chunk-a", QaStatus: chunk.QaStatusPending},
+	}
+	saveChunks := []*chunk.Chunk{
+		{Id: "chunk-a"},
+		{Id: "qa-1", ChunkType: chunk.ChunkTypeQa, SourceId: "chunk-a", Question: "自动生成", QaStatus: chunk.QaStatusPending},
+	}
+
+	result := keepReviewedQa(existing, saveChunks)
+
+	ids := make([]string, 0, len(result))
+	for _, c := range result {
+		ids = append(ids, c.Id)
+	}
+	// qa-3 的来源分片已不存在, qa-4 未审核, 均不保留
+	assert.Equal(t, []string{"chunk-a", "qa-1", "qa-2"}, ids)
+	assert.Equal(t, "人工修改", result[1].Question)
+	assert.Equal(t, chunk.QaStatusApproved, result[1].QaStatus)
+}
@@ -0,0 +1,100 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gozero-rag/internal/indexctl"
+	"gozero-rag/internal/model/knowledge_document"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// ========================================
+// 取消 / 暂停 / 恢复
+// 1. 流水线步骤之间及每个 embedding 批次前检查 Redis 中的控制信号
+// 2. 每完成一个 embedding 批次写入检查点, 暂停恢复或失败重试后跳过已生成向量的分片
+// 3. 取消时删除本次写入的分片和检查点; 暂停和等待重试时保留检查点, 最终失败时删除
+// ========================================
+
+// checkSignal 检查是否收到取消或暂停信号
+func (l *DocumentIndexLogic) checkSignal(ctx context.Context, ic *indexContext) error {
+	return l.svcCtx.IndexSignal.Check(ctx, ic.msg.DocumentId)
+}
+
+func isInterrupted(err error) bool {
+	return errors.Is(err, indexctl.ErrCanceled) || errors.Is(err, indexctl.ErrPaused)
+}
+
+// interruptTask 响应控制信号, written 为本次已写入 ES 的新分片, 取消时删除
+func (l *DocumentIndexLogic) interruptTask(ctx context.Context, ic *indexContext, err error, written []string) error {
+	docId := ic.msg.DocumentId
+
+	if errors.Is(err, indexctl.ErrPaused) {
+		logx.Infof("[DocIndex] DocumentId=%s 已暂停", docId)
+		l.updateRunStatus(ctx, docId, knowledge_document.RunStatePaused, "已暂停, 恢复后跳过已完成的向量批次")
+		ic.progress.finish(ctx, knowledge_document.RunStatePaused, "已暂停")
+		_ = l.svcCtx.IndexSignal.Clear(ctx, docId)
+		return nil
+	}
+
+	logx.Infof("[DocIndex] DocumentId=%s 已取消, 清理本次写入的分片 %d 个", docId, len(written))
+	if err := l.svcCtx.ChunkModel.DeleteByIds(ctx, written); err != nil {
+		logx.Errorf("[DocIndex] DocumentId=%s 清理分片失败: %v", docId, err)
+	}
+	if err := l.svcCtx.IndexCheckpoint.Clear(ctx, docId); err != nil {
+		logx.Errorf("[DocIndex] DocumentId=%s 清理检查点失败: %v", docId, err)
+	}
+	l.updateRunStatus(ctx, docId, knowledge_document.RunStateCanceled, "已取消")
+	ic.progress.finish(ctx, knowledge_document.RunStateCanceled, "已取消")
+	_ = l.svcCtx.IndexSignal.Clear(ctx, docId)
+	return nil
+}
+
+// loadCheckpoint 加载暂停或失败前已完成的 embedding 批次
+func (l *DocumentIndexLogic) loadCheckpoint(ctx context.Context, ic *indexContext) {
+	vectors, err := l.svcCtx.IndexCheckpoint.Load(ctx, ic.msg.DocumentId, ic.kb.EmbdId)
+	if err != nil {
+		logx.Errorf("[DocIndex] DocumentId=%s 读取检查点失败, 重新生成向量: %v", ic.msg.DocumentId, err)
+		return
+	}
+	ic.checkpoint = vectors
+	if len(vectors) > 0 {
+		logx.Infof("[DocIndex] DocumentId=%s 从检查点恢复向量 %d 个", ic.msg.DocumentId, len(vectors))
+	}
+}
+
+// saveCheckpoint 记录一个批次的向量, 写入失败只影响恢复时的复用
+func (l *DocumentIndexLogic) saveCheckpoint(ctx context.Context, ic *indexContext, ids []string, vectors [][]float64) {
+	batch := make(map[string][]float64, len(ids))
+	for i, id := range ids {
+		if i < len(vectors) {
+			batch[id] = vectors[i]
+		}
+	}
+	if err := l.svcCtx.IndexCheckpoint.Save(ctx, ic.msg.DocumentId, ic.kb.EmbdId, batch); err != nil {
+		logx.Errorf("[DocIndex] DocumentId=%s 写入检查点失败: %v", ic.msg.DocumentId, err)
+	}
+}
+
+// clearControlState 索引成功后清理控制信号和检查点, 暂存的向量此时已随分片写入 ES
+func (l *DocumentIndexLogic) clearControlState(ctx context.Context, ic *indexContext) {
+	_ = l.svcCtx.IndexSignal.Clear(ctx, ic.msg.DocumentId)
+	_ = l.svcCtx.IndexCheckpoint.Clear(ctx, ic.msg.DocumentId)
+}
+
+// retryTask 向量生成或写入 ES 失败时保留检查点, 文档重置为待解析并返回错误, 由本地消息表补偿重试
+// 重试时跳过已暂存向量的分片, 超过 maxIndexAttempts 次后文档置为失败
+func (l *DocumentIndexLogic) retryTask(ctx context.Context, ic *indexContext, reason string) error {
+	docId := ic.msg.DocumentId
+	attempts, err := l.svcCtx.IndexCheckpoint.IncrAttempts(ctx, docId)
+	if err != nil || attempts >= maxIndexAttempts {
+		return l.failTask(ctx, ic, reason)
+	}
+
+	logx.Errorf("[DocIndex] DocumentId=%s 第 %d 次失败, 等待重试: %s", docId, attempts, reason)
+	msg := fmt.Sprintf("第 %d 次失败, 等待重试: %s", attempts, reason)
+	l.updateRunStatus(ctx, docId, knowledge_document.RunStatePending, msg)
+	return fmt.Errorf("文档 %s 索引失败: %s", docId, reason)
+}
@@ -0,0 +1,124 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"gozero-rag/internal/indexctl"
+	"gozero-rag/internal/model/knowledge_document"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// ========================================
+// 索引进度上报
+// 每个步骤发布到 Redis pub/sub 供 SSE 推送, 同时写入文档的 progress / progress_msg 列
+// embedding 等高频步骤按时间间隔节流写库
+// ========================================
+
+const progressPersistInterval = time.Second
+
+type progressReporter struct {
+	l    *DocumentIndexLogic
+	msg  *indexMessage
+	mu   sync.Mutex
+	step indexctl.Step
+	last time.Time // 上次写库时间
+
+	progress   float64
+	embedDone  int
+	embedTotal int
+}
+
+func newProgressReporter(l *DocumentIndexLogic, msg *indexMessage) *progressReporter {
+	return &progressReporter{l: l, msg: msg}
+}
+
+// report 上报步骤进度, current / total 为步骤内完成数量, 无数量时传 0
+func (r *progressReporter) report(ctx context.Context, step indexctl.Step, current, total int, message string) {
+	if r == nil {
+		return
+	}
+	progress := indexctl.StepProgress(step, current, total)
+
+	r.mu.Lock()
+	now := time.Now()
+	persist := step != r.step || current >= total || now.Sub(r.last) >= progressPersistInterval
+	r.step = step
+	r.progress = progress
+	if persist {
+		r.last = now
+	}
+	r.mu.Unlock()
+
+	if persist {
+		if err := r.l.svcCtx.KnowledgeDocumentModel.UpdateProgress(ctx, r.msg.DocumentId, progress, message); err != nil {
+			logx.Errorf("[DocIndex] DocumentId=%s 更新进度失败: %v", r.msg.DocumentId, err)
+		}
+	}
+	r.publish(ctx, &indexctl.ProgressEvent{
+		Step:      step,
+		Progress:  progress,
+		Current:   current,
+		Total:     total,
+		Message:   message,
+		RunStatus: knowledge_document.RunStateRunning,
+	})
+}
+
+// addEmbedBatches 登记待生成的 embedding 批次, 内容分片与 QA 分片并行生成, 合并统计
+func (r *progressReporter) addEmbedBatches(n int) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.embedTotal += n
+	r.mu.Unlock()
+}
+
+// embedBatchDone 完成一个 embedding 批次
+func (r *progressReporter) embedBatchDone(ctx context.Context) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.embedDone++
+	done, total := r.embedDone, r.embedTotal
+	r.mu.Unlock()
+
+	r.report(ctx, indexctl.StepEmbedding, done, total, fmt.Sprintf("生成向量 %d/%d 批", done, total))
+}
+
+// finish 发布索引结束事件, 成功时进度写为 1, 其余状态的进度信息由 updateRunStatus 写入
+func (r *progressReporter) finish(ctx context.Context, status, message string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	progress := r.progress
+	r.mu.Unlock()
+
+	if status == knowledge_document.RunStateSuccess {
+		progress = 1
+		if err := r.l.svcCtx.KnowledgeDocumentModel.UpdateProgress(ctx, r.msg.DocumentId, progress, message); err != nil {
+			logx.Errorf("[DocIndex] DocumentId=%s 更新进度失败: %v", r.msg.DocumentId, err)
+		}
+	}
+	r.publish(ctx, &indexctl.ProgressEvent{
+		Step:      indexctl.StepFinish,
+		Progress:  progress,
+		Message:   message,
+		RunStatus: status,
+	})
+}
+
+func (r *progressReporter) publish(ctx context.Context, ev *indexctl.ProgressEvent) {
+	ev.DocumentId = r.msg.DocumentId
+	ev.KnowledgeBaseId = r.msg.KnowledgeBaseId
+	ev.Time = time.Now().UnixMilli()
+	if err := r.l.svcCtx.IndexProgress.Publish(ctx, ev); err != nil {
+		logx.Errorf("[DocIndex] DocumentId=%s 发布进度失败: %v", r.msg.DocumentId, err)
+	}
+}
@@ -0,0 +1,497 @@
+package logic
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gozero-rag/internal/indexctl"
+	"gozero-rag/internal/model/chunk"
+	"gozero-rag/internal/model/knowledge_document"
+	"gozero-rag/internal/model/task"
+	"gozero-rag/internal/mq"
+	"gozero-rag/internal/rag_core/constant"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/google/uuid"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// ========================================
+// 大文档拆分
+// 加载后内容超过 subTaskContentThreshold 的文档由 processDocument 按章节范围拆分为子任务:
+// 1. 加载与预清洗只执行一次, 章节按段落边界切分后分组, 每组暂存到 OSS, 在 task 表登记一条 parse 任务, 并投递带 TaskId 的索引消息
+// 2. 多个消费者并行对各自范围切片、生成 QA、向量并写入 ES, 在 task 表记录写入的分片 id, 累加切片统计
+// 3. 最后完成的子任务负责汇总: 删除已不存在的旧分片, 文档置为成功
+// 4. 子任务失败由本地消息表补偿重试, 只重跑失败的范围, 超过 maxSubTaskRetry 次后文档置为失败
+// ========================================
+
+const (
+	subTaskContentThreshold = 512 << 10 // 加载后内容超过该字节数的文档拆分为子任务
+	subTaskSectionSize      = 128 << 10 // 每个子任务的内容字节数
+	maxSubTaskRetry         = 3         // 子任务最大执行次数
+)
+
+// contentSize 章节内容总字节数
+func contentSize(sections []*schema.Document) int {
+	n := 0
+	for _, s := range sections {
+		n += len(s.Content)
+	}
+	return n
+}
+
+// cutSections 将超过 size 的章节在段落边界处切开, 切开的片段继承原章节的元数据
+func cutSections(sections []*schema.Document, size int) []*schema.Document {
+	var result []*schema.Document
+	for _, s := range sections {
+		content := s.Content
+		for len(content) > size {
+			cut := paragraphCut(content, size)
+			result = append(result, &schema.Document{ID: s.ID, Content: content[:cut], MetaData: maps.Clone(s.MetaData)})
+			content = content[cut:]
+		}
+		if content != "" || s.Content == "" {
+			result = append(result, &schema.Document{ID: s.ID, Content: content, MetaData: maps.Clone(s.MetaData)})
+		}
+	}
+	return result
+}
+
+// paragraphCut 返回 size 之内最后一个段落边界, 没有段落时按换行切分, 仍没有时在字符边界切分
+func paragraphCut(content string, size int) int {
+	for _, sep := range []string{"\n\n", "\n"} {
+		if i := strings.LastIndex(content[:size], sep); i > 0 {
+			return i + len(sep)
+		}
+	}
+	cut := size
+	for cut > 0 && !utf8.RuneStart(content[cut]) {
+		cut--
+	}
+	if cut == 0 {
+		return size
+	}
+	return cut
+}
+
+// splitRanges 按内容大小划分子任务的章节范围 [from, to), 每个范围至少包含一个章节
+func splitRanges(sections []*schema.Document, size int) [][2]int {
+	var ranges [][2]int
+	for from := 0; from < len(sections); {
+		to, n := from, 0
+		for to < len(sections) && (to == from || n+len(sections[to].Content) <= size) {
+			n += len(sections[to].Content)
+			to++
+		}
+		ranges = append(ranges, [2]int{from, to})
+		from = to
+	}
+	return ranges
+}
+
+func encodeStagedSections(sections []*schema.Document) ([]byte, error) {
+	return json.Marshal(sections)
+}
+
+// decodeStagedSections 解析暂存的章节, JSON 反序列化后数字变为 float64、切片变为 []any, 这里还原切片步骤依赖的类型
+func decodeStagedSections(data []byte) ([]*schema.Document, error) {
+	var sections []*schema.Document
+	if err := json.Unmarshal(data, &sections); err != nil {
+		return nil, err
+	}
+	for _, s := range sections {
+		if s.MetaData == nil {
+			s.MetaData = make(map[string]any)
+		}
+		switch row := s.MetaData[constant.MetaRowIndex].(type) {
+		case float64:
+			s.MetaData[constant.MetaRowIndex] = int(row)
+		case []any:
+			ints := make([]int, 0, len(row))
+			for _, r := range row {
+				if f, ok := r.(float64); ok {
+					ints = append(ints, int(f))
+				}
+			}
+			s.MetaData[constant.MetaRowIndex] = ints
+		}
+		for _, key := range []string{constant.MetaTableHeaders, constant.MetaRowValues} {
+			if values, ok := s.MetaData[key].([]any); ok {
+				strs := make([]string, 0, len(values))
+				for _, v := range values {
+					str, _ := v.(string)
+					strs = append(strs, str)
+				}
+				s.MetaData[key] = strs
+			}
+		}
+	}
+	return sections, nil
+}
+
+// partDigest 子任务摘要: 解析配置 + 章节内容
+func partDigest(template []byte, sections []*schema.Document) string {
+	h := sha256.New()
+	h.Write(template)
+	for _, c := range sections {
+		h.Write([]byte(c.Content))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// staleChunkIds 汇总各子任务写入的分片, 返回保留的分片及不在其中的已有分片
+func staleChunkIds(tasks []*task.Task, existing []*chunk.Chunk) (map[string]bool, []string) {
+	kept := make(map[string]bool)
+	for _, t := range tasks {
+		for _, id := range strings.Split(t.ChunkIds.String, ",") {
+			if id != "" {
+				kept[id] = true
+			}
+		}
+	}
+	var staleIds []string
+	for _, c := range existing {
+		if !kept[c.Id] {
+			staleIds = append(staleIds, c.Id)
+		}
+	}
+	return kept, staleIds
+}
+
+func stagingKey(msg *indexMessage, taskId string) string {
+	return fmt.Sprintf("tenant_%s/kb_%s/subtask/%s/%s.json", msg.TenantId, msg.KnowledgeBaseId, msg.DocumentId, taskId)
+}
+
+// ========================================
+// 协调者: 拆分并投递子任务
+// ========================================
+
+func (l *DocumentIndexLogic) dispatchSubTasks(ctx context.Context, ic *indexContext, sections []*schema.Document) error {
+	// 清理上一轮索引遗留的子任务
+	if err := l.cleanupSubTasks(ctx, ic.msg); err != nil {
+		return l.failTask(ctx, ic, fmt.Sprintf("清理旧子任务失败: %v", err))
+	}
+
+	template, _ := json.Marshal(ic.template)
+	sections = cutSections(sections, subTaskSectionSize)
+	ranges := splitRanges(sections, subTaskSectionSize)
+	tasks := make([]*task.Task, 0, len(ranges))
+	now := time.Now()
+	for _, r := range ranges {
+		part := sections[r[0]:r[1]]
+		data, err := encodeStagedSections(part)
+		if err != nil {
+			return l.failTask(ctx, ic, fmt.Sprintf("序列化子任务章节失败: %v", err))
+		}
+
+		t := &task.Task{
+			Id:          uuid.NewString(),
+			DocId:       ic.msg.DocumentId,
+			TaskType:    task.TaskTypeParse,
+			FromPage:    int64(r[0]),
+			ToPage:      int64(r[1]),
+			Status:      task.StatusPending,
+			Digest:      partDigest(template, part),
+			CreatedTime: now.UnixMilli(),
+			UpdatedTime: now.UnixMilli(),
+			CreatedDate: now,
+			UpdatedDate: now,
+		}
+		_, err = l.svcCtx.OssClient.PutObject(ctx, l.svcCtx.Config.Oss.BucketName, stagingKey(ic.msg, t.Id), bytes.NewReader(data), int64(len(data)), "application/json")
+		if err != nil {
+			return l.failTask(ctx, ic, fmt.Sprintf("暂存子任务章节失败: %v", err))
+		}
+		if _, err := l.svcCtx.TaskModel.Insert(ctx, t); err != nil {
+			return l.failTask(ctx, ic, fmt.Sprintf("创建子任务失败: %v", err))
+		}
+		tasks = append(tasks, t)
+	}
+
+	// 切片统计及增量统计清零后由子任务累加, 文档保持 indexing 直到全部子任务完成
+	if err := l.svcCtx.KnowledgeDocumentModel.UpdateIndexResult(ctx, ic.msg.DocumentId, knowledge_document.RunStateRunning, 0, 0, knowledge_document.ChunkDiff{}); err != nil {
+		return l.failTask(ctx, ic, fmt.Sprintf("更新数据库失败: %v", err))
+	}
+
+	for _, t := range tasks {
+		err := l.svcCtx.IndexPusher.PublishDocumentIndex(ctx, &mq.KnowledgeDocumentIndexMsg{
+			UserId:          ic.msg.UserId,
+			TenantId:        ic.msg.TenantId,
+			KnowledgeBaseId: ic.msg.KnowledgeBaseId,
+			DocumentId:      ic.msg.DocumentId,
+			TaskId:          t.Id,
+		})
+		if err != nil {
+			return l.failTask(ctx, ic, fmt.Sprintf("投递子任务失败: %v", err))
+		}
+	}
+
+	logx.Infof("[DocIndex] DocumentId=%s 共 %d 个章节, 拆分为 %d 个子任务", ic.msg.DocumentId, len(sections), len(tasks))
+	ic.progress.report(ctx, indexctl.StepSplit, 0, 0, fmt.Sprintf("共 %d 个章节, 已拆分为 %d 个子任务", len(sections), len(tasks)))
+	return nil
+}
+
+// cleanupSubTasks 删除文档的子任务及暂存切片
+func (l *DocumentIndexLogic) cleanupSubTasks(ctx context.Context, msg *indexMessage) error {
+	tasks, err := l.svcCtx.TaskModel.FindByDocIdTaskType(ctx, msg.DocumentId, task.TaskTypeParse)
+	if err != nil {
+		return err
+	}
+	if len(tasks) == 0 {
+		return nil
+	}
+	l.removeStagedSections(ctx, msg, tasks)
+	return l.svcCtx.TaskModel.DeleteByDocIdTaskType(ctx, msg.DocumentId, task.TaskTypeParse)
+}
+
+func (l *DocumentIndexLogic) removeStagedSections(ctx context.Context, msg *indexMessage, tasks []*task.Task) {
+	for _, t := range tasks {
+		if err := l.svcCtx.OssClient.RemoveObject(ctx, l.svcCtx.Config.Oss.BucketName, stagingKey(msg, t.Id)); err != nil {
+			logx.Errorf("[DocIndex] DocumentId=%s 删除暂存切片失败: task=%s, err=%v", msg.DocumentId, t.Id, err)
+		}
+	}
+}
+
+// ========================================
+// 子任务
+// ========================================
+
+// processSubTask 对一个子任务范围内的章节切片并写入
+func (l *DocumentIndexLogic) processSubTask(ctx context.Context, msg *indexMessage) (err error) {
+	ic := &indexContext{
+		msg:       msg,
+		startTime: time.Now(),
+	}
+
+	defer l.recoverFromPanic(ic, &err)
+
+	t, err := l.svcCtx.TaskModel.FindOne(ctx, msg.TaskId)
+	if err != nil {
+		if err == task.ErrNotFound {
+			return nil // 已被新一轮索引替换
+		}
+		return err
+	}
+
+	doc, err := l.svcCtx.KnowledgeDocumentModel.FindOne(ctx, msg.DocumentId)
+	if err != nil {
+		if err == knowledge_document.ErrNotFound {
+			return nil
+		}
+		return err
+	}
+	if doc.RunStatus != knowledge_document.RunStateRunning {
+		if t.Status != task.StatusSuccess {
+			return l.updateSubTask(ctx, t, task.StatusCanceled, fmt.Sprintf("文档状态为 %s, 跳过", doc.RunStatus))
+		}
+		return nil
+	}
+	ic.doc = doc
+
+	if err := l.loadKnowledgeBaseConfig(ctx, ic); err != nil {
+		return l.failSubTask(ctx, ic, t, err.Error())
+	}
+
+	// 重复投递的已完成子任务只需尝试汇总
+	if t.Status == task.StatusSuccess {
+		return l.completeSubTasks(ctx, ic)
+	}
+	if err := l.updateSubTask(ctx, t, task.StatusRunning, ""); err != nil {
+		return err
+	}
+
+	sections, err := l.loadStagedSections(ctx, ic, t)
+	if err != nil {
+		return l.failSubTask(ctx, ic, t, fmt.Sprintf("读取暂存章节失败: %v", err))
+	}
+	chunks, err := l.splitSections(ctx, ic, sections)
+	if err != nil {
+		return l.failSubTask(ctx, ic, t, fmt.Sprintf("文档切片失败: %v", err))
+	}
+	if err := l.createEmbedder(ctx, ic); err != nil {
+		return l.failSubTask(ctx, ic, t, fmt.Sprintf("创建Embedder失败: %v", err))
+	}
+	chunks, err = l.generateQA(ctx, ic, chunks)
+	if err != nil {
+		return l.failSubTask(ctx, ic, t, fmt.Sprintf("生成 QA 失败: %v", err))
+	}
+	if err := l.checkSignal(ctx, ic); err != nil {
+		return l.interruptSubTask(ctx, ic, t, err)
+	}
+
+	if err := l.loadPartExistingChunks(ctx, ic, chunks); err != nil {
+		return l.failSubTask(ctx, ic, t, err.Error())
+	}
+	saveChunks, totalTokenNum, err := l.buildChunksWithEmbedding(ctx, ic, chunks)
+	if isInterrupted(err) {
+		return l.interruptSubTask(ctx, ic, t, err)
+	}
+	if err != nil {
+		return l.failSubTask(ctx, ic, t, err.Error())
+	}
+	if err := l.checkSignal(ctx, ic); err != nil {
+		return l.interruptSubTask(ctx, ic, t, err)
+	}
+
+	// 保留已审核的 QA 及未变化分片的启用状态, 旧分片由汇总时统一删除
+	saveChunks = keepReviewedQa(ic.existing, saveChunks)
+	diff := diffChunks(ic.existing, saveChunks)
+	if err := l.svcCtx.ChunkModel.Put(ctx, saveChunks); err != nil {
+		return l.failSubTask(ctx, ic, t, fmt.Sprintf("写入ES失败: %v", err))
+	}
+
+	ids := make([]string, 0, len(saveChunks))
+	for _, c := range saveChunks {
+		ids = append(ids, c.Id)
+	}
+	t.ChunkIds = sql.NullString{String: strings.Join(ids, ","), Valid: true}
+	t.Progress = 1
+	t.ProcessDuration = time.Since(ic.startTime).Seconds()
+	if err := l.updateSubTask(ctx, t, task.StatusSuccess, fmt.Sprintf("切片 %d, 写入 %d 个分片", len(chunks), len(saveChunks))); err != nil {
+		return err
+	}
+	// 各子任务累加切片数及新增/复用数, 删除数由汇总时统计
+	stat := diff.stat()
+	stat.Removed = 0
+	if err := l.svcCtx.KnowledgeDocumentModel.IncrIndexResult(ctx, ic.msg.DocumentId, int64(len(chunks)), totalTokenNum, stat); err != nil {
+		logx.Errorf("[DocIndex] DocumentId=%s 更新切片统计失败: %v", ic.msg.DocumentId, err)
+	}
+
+	return l.completeSubTasks(ctx, ic)
+}
+
+func (l *DocumentIndexLogic) loadStagedSections(ctx context.Context, ic *indexContext, t *task.Task) ([]*schema.Document, error) {
+	tempFile, err := os.CreateTemp("", fmt.Sprintf("rag_subtask_%s_*.json", t.Id))
+	if err != nil {
+		return nil, err
+	}
+	tempFile.Close()
+	defer os.Remove(tempFile.Name())
+
+	if err := l.svcCtx.OssClient.FGetObject(ctx, l.svcCtx.Config.Oss.BucketName, stagingKey(ic.msg, t.Id), tempFile.Name()); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(tempFile.Name())
+	if err != nil {
+		return nil, err
+	}
+	return decodeStagedSections(data)
+}
+
+// updateSubTask 更新子任务状态, 更新失败时返回错误交给本地消息表补偿重试
+func (l *DocumentIndexLogic) updateSubTask(ctx context.Context, t *task.Task, status, msg string) error {
+	now := time.Now()
+	t.Status = status
+	if msg != "" {
+		t.ProgressMsg = sql.NullString{String: msg, Valid: true}
+	}
+	t.UpdatedTime = now.UnixMilli()
+	t.UpdatedDate = now
+	if err := l.svcCtx.TaskModel.Update(ctx, t); err != nil {
+		return fmt.Errorf("更新子任务 %s 状态为 %s 失败: %w", t.Id, status, err)
+	}
+	return nil
+}
+
+// failSubTask 子任务失败, 未超过重试次数时返回错误交给本地消息表补偿重试, 只重跑该范围
+func (l *DocumentIndexLogic) failSubTask(ctx context.Context, ic *indexContext, t *task.Task, reason string) error {
+	t.RetryCount++
+	t.FailReason = sql.NullString{String: reason, Valid: true}
+
+	if t.RetryCount < maxSubTaskRetry {
+		if err := l.updateSubTask(ctx, t, task.StatusFail, fmt.Sprintf("第 %d 次执行失败, 等待重试", t.RetryCount)); err != nil {
+			logx.Errorf("[DocIndex] DocumentId=%s %v", ic.msg.DocumentId, err)
+		}
+		logx.Errorf("[DocIndex] DocumentId=%s 子任务 [%d, %d) 失败, 等待重试: %s", ic.msg.DocumentId, t.FromPage, t.ToPage, reason)
+		return fmt.Errorf("子任务 %s 失败: %s", t.Id, reason)
+	}
+
+	if err := l.updateSubTask(ctx, t, task.StatusFail, "超过最大重试次数"); err != nil {
+		logx.Errorf("[DocIndex] DocumentId=%s %v", ic.msg.DocumentId, err)
+	}
+	return l.failTask(ctx, ic, fmt.Sprintf("子任务 [%d, %d) 执行 %d 次后失败: %s", t.FromPage, t.ToPage, t.RetryCount, reason))
+}
+
+// interruptSubTask 子任务收到取消/暂停信号, 文档状态由 interruptTask 更新, 其余子任务读取到非 indexing 状态后跳过
+// 取消时已完成子任务写入的分片保留, 下次索引成功时按旧分片清理
+func (l *DocumentIndexLogic) interruptSubTask(ctx context.Context, ic *indexContext, t *task.Task, err error) error {
+	status := task.StatusPaused
+	if errors.Is(err, indexctl.ErrCanceled) {
+		status = task.StatusCanceled
+	}
+	if err := l.updateSubTask(ctx, t, status, ""); err != nil {
+		logx.Errorf("[DocIndex] DocumentId=%s %v", ic.msg.DocumentId, err)
+	}
+	return l.interruptTask(ctx, ic, err, nil)
+}
+
+// completeSubTasks 全部子任务完成后汇总, 通过状态 CAS 保证只汇总一次
+func (l *DocumentIndexLogic) completeSubTasks(ctx context.Context, ic *indexContext) error {
+	docId := ic.msg.DocumentId
+	tasks, err := l.svcCtx.TaskModel.FindByDocIdTaskType(ctx, docId, task.TaskTypeParse)
+	if err != nil {
+		return err
+	}
+
+	done := 0
+	for _, t := range tasks {
+		if t.Status == task.StatusSuccess {
+			done++
+		}
+	}
+	ic.progress = newProgressReporter(l, ic.msg)
+	if done < len(tasks) {
+		ic.progress.report(ctx, indexctl.StepEmbedding, done, len(tasks), fmt.Sprintf("子任务 %d/%d 已完成", done, len(tasks)))
+		return nil
+	}
+
+	// 先删除本轮索引未写入的旧分片, 失败时返回错误交给本地消息表重试, 清理完成后才标记成功
+	// 重复投递时各子任务按相同的分片集合清理, 结果一致
+	existing, err := l.svcCtx.ChunkModel.ListAllByDocId(ctx, ic.msg.KnowledgeBaseId, docId, false)
+	if err != nil {
+		return fmt.Errorf("查询旧分片失败: %w", err)
+	}
+	kept, staleIds := staleChunkIds(tasks, existing)
+	if err := l.svcCtx.ChunkModel.DeleteByIds(ctx, staleIds); err != nil {
+		return fmt.Errorf("删除旧分片失败: %w", err)
+	}
+
+	ok, err := l.svcCtx.KnowledgeDocumentModel.CompareAndSetRunStatus(ctx, docId, knowledge_document.RunStateRunning, knowledge_document.RunStateSuccess, "正在汇总子任务结果")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+	if err := l.svcCtx.KnowledgeDocumentModel.IncrIndexResult(ctx, docId, 0, 0, knowledge_document.ChunkDiff{Removed: int64(len(staleIds))}); err != nil {
+		logx.Errorf("[DocIndex] DocumentId=%s 更新分片增量统计失败: %v", docId, err)
+	}
+
+	msg := fmt.Sprintf("索引完成: %d 个子任务, 分片 %d, 删除 %d", len(tasks), len(kept), len(staleIds))
+	l.updateRunStatus(ctx, docId, knowledge_document.RunStateSuccess, msg)
+	logx.Infof("[DocIndex] DocumentId=%s %s", docId, msg)
+
+	l.clearControlState(ctx, ic)
+	l.removeStagedSections(ctx, ic.msg, tasks)
+	l.recordEffectiveConfig(ctx, ic)
+	l.publishGraphTask(ctx, ic)
+	ic.progress.finish(ctx, knowledge_document.RunStateSuccess, msg)
+
+	// 切片数由各子任务累加, 重新读取后记录指标
+	if doc, err := l.svcCtx.KnowledgeDocumentModel.FindOne(ctx, docId); err == nil {
+		ic.doc = doc
+	}
+	l.recordSuccessMetrics(ic, len(kept), int(ic.doc.ChunkNum))
+	return nil
+}
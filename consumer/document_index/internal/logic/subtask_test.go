@@ -0,0 +1,300 @@
+package logic
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	"gozero-rag/consumer/document_index/internal/svc"
+	"gozero-rag/internal/indexctl"
+	"gozero-rag/internal/model/chunk"
+	"gozero-rag/internal/model/knowledge_document"
+	"gozero-rag/internal/model/task"
+	"gozero-rag/internal/mq"
+	"gozero-rag/internal/rag_core/constant"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+)
+
+func TestSplitRanges(t *testing.T) {
+	sections := []*schema.Document{
+		{Content: "aaaa"}, {Content: "bb"}, {Content: "cc"}, {Content: "dddddd"}, {Content: "e"},
+	}
+
+	// 按内容大小分组, 超过 size 的章节单独成组
+	assert.Equal(t, [][2]int{{0, 1}, {1, 3}, {3, 4}, {4, 5}}, splitRanges(sections, 4))
+	assert.Equal(t, [][2]int{{0, 5}}, splitRanges(sections, 100))
+	assert.Empty(t, splitRanges(nil, 4))
+}
+
+func TestCutSections(t *testing.T) {
+	sections := []*schema.Document{
+		{ID: "a", Content: "第一段内容\n\n第二段内容\n\n第三段", MetaData: map[string]any{constant.MetaSheetName: "s1"}},
+		{ID: "b", Content: "short"},
+	}
+
+	cut := cutSections(sections, 20)
+	require.Len(t, cut, 4)
+	assert.Equal(t, "第一段内容\n\n", cut[0].Content)
+	assert.Equal(t, "第二段内容\n\n", cut[1].Content)
+	assert.Equal(t, "第三段", cut[2].Content)
+	assert.Equal(t, "short", cut[3].Content)
+	// 片段继承元数据, 但不共享同一个 map
+	assert.Equal(t, "s1", cut[1].MetaData[constant.MetaSheetName])
+	cut[1].MetaData[constant.MetaSheetName] = "changed"
+	assert.Equal(t, "s1", cut[0].MetaData[constant.MetaSheetName])
+
+	var joined strings.Builder
+	for _, c := range cut[:3] {
+		joined.WriteString(c.Content)
+	}
+	assert.Equal(t, sections[0].Content, joined.String())
+}
+
+func TestParagraphCut(t *testing.T) {
+	assert.Equal(t, 4, paragraphCut("abc\ndefgh", 6))
+	// 没有换行时在字符边界切分, 不切断多字节字符
+	assert.Equal(t, 3, paragraphCut("中文内容", 4))
+}
+
+func TestStagedSections_RoundTrip(t *testing.T) {
+	sections := []*schema.Document{
+		{ID: "a", Content: "hello", MetaData: map[string]any{
+			constant.MetaRowIndex:     3,
+			constant.MetaTableHeaders: []string{"name", "age"},
+			constant.MetaRowValues:    []string{"tom", "18"},
+		}},
+		{ID: "b", Content: "world", MetaData: map[string]any{constant.MetaRowIndex: []int{1, 2}}},
+		{ID: "c", Content: "!"},
+	}
+
+	data, err := encodeStagedSections(sections)
+	require.NoError(t, err)
+
+	decoded, err := decodeStagedSections(data)
+	require.NoError(t, err)
+	require.Len(t, decoded, 3)
+	assert.Equal(t, "hello", decoded[0].Content)
+	assert.Equal(t, 3, decoded[0].MetaData[constant.MetaRowIndex])
+	assert.Equal(t, []string{"name", "age"}, decoded[0].MetaData[constant.MetaTableHeaders])
+	assert.Equal(t, []string{"tom", "18"}, decoded[0].MetaData[constant.MetaRowValues])
+	assert.Equal(t, []int{1, 2}, decoded[1].MetaData[constant.MetaRowIndex])
+	assert.NotNil(t, decoded[2].MetaData)
+}
+
+func TestPartDigest(t *testing.T) {
+	a := []*schema.Document{{Content: "x"}, {Content: "y"}}
+	b := []*schema.Document{{Content: "xy"}}
+
+	assert.Equal(t, partDigest([]byte("t"), a), partDigest([]byte("t"), a))
+	assert.NotEqual(t, partDigest([]byte("t"), a), partDigest([]byte("t"), b))
+	assert.NotEqual(t, partDigest([]byte("t"), a), partDigest([]byte("u"), a))
+}
+
+func TestStaleChunkIds(t *testing.T) {
+	tasks := []*task.Task{
+		{Id: "t1", ChunkIds: sql.NullString{String: "c1,c2", Valid: true}},
+		{Id: "t2", ChunkIds: sql.NullString{String: "c3", Valid: true}},
+		{Id: "t3"}, // 范围内没有分片
+	}
+	existing := []*chunk.Chunk{{Id: "c1"}, {Id: "old1"}, {Id: "c3"}, {Id: "old2"}}
+
+	kept, staleIds := staleChunkIds(tasks, existing)
+	assert.Equal(t, map[string]bool{"c1": true, "c2": true, "c3": true}, kept)
+	assert.Equal(t, []string{"old1", "old2"}, staleIds)
+
+	kept, staleIds = staleChunkIds(tasks, nil)
+	assert.Len(t, kept, 3)
+	assert.Empty(t, staleIds)
+}
+
+// ========================================
+// 子任务状态
+// ========================================
+
+type fakeTaskModel struct {
+	task.TaskModel
+	tasks     map[string]*task.Task
+	updateErr error
+}
+
+func (m *fakeTaskModel) FindOne(_ context.Context, id string) (*task.Task, error) {
+	t, ok := m.tasks[id]
+	if !ok {
+		return nil, task.ErrNotFound
+	}
+	cp := *t
+	return &cp, nil
+}
+
+func (m *fakeTaskModel) Update(_ context.Context, data *task.Task) error {
+	if m.updateErr != nil {
+		return m.updateErr
+	}
+	cp := *data
+	m.tasks[data.Id] = &cp
+	return nil
+}
+
+func (m *fakeTaskModel) FindByDocIdTaskType(_ context.Context, docId, taskType string) ([]*task.Task, error) {
+	var result []*task.Task
+	for _, t := range m.tasks {
+		if t.DocId == docId {
+			cp := *t
+			result = append(result, &cp)
+		}
+	}
+	return result, nil
+}
+
+type fakeChunkModel struct {
+	chunk.ChunkModel
+	chunks    []*chunk.Chunk
+	listErr   error
+	deleteErr error
+}
+
+func (m *fakeChunkModel) ListAllByDocId(context.Context, string, string, bool) ([]*chunk.Chunk, error) {
+	return m.chunks, m.listErr
+}
+
+func (m *fakeChunkModel) DeleteByIds(_ context.Context, ids []string) error {
+	if m.deleteErr != nil {
+		return m.deleteErr
+	}
+	deleted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		deleted[id] = true
+	}
+	kept := m.chunks[:0]
+	for _, c := range m.chunks {
+		if !deleted[c.Id] {
+			kept = append(kept, c)
+		}
+	}
+	m.chunks = kept
+	return nil
+}
+
+type fakeDocumentModel struct {
+	knowledge_document.KnowledgeDocumentModel
+	doc *knowledge_document.KnowledgeDocument
+}
+
+func (m *fakeDocumentModel) FindOne(_ context.Context, id string) (*knowledge_document.KnowledgeDocument, error) {
+	if m.doc == nil || m.doc.Id != id {
+		return nil, knowledge_document.ErrNotFound
+	}
+	cp := *m.doc
+	return &cp, nil
+}
+
+func (m *fakeDocumentModel) CompareAndSetRunStatus(_ context.Context, id, expected, status, msg string) (bool, error) {
+	if m.doc.RunStatus != expected {
+		return false, nil
+	}
+	return true, m.UpdateRunStatus(context.Background(), id, status, msg)
+}
+
+func (m *fakeDocumentModel) UpdateRunStatus(_ context.Context, id, status, msg string) error {
+	m.doc.RunStatus = status
+	m.doc.ProgressMsg = sql.NullString{String: msg, Valid: true}
+	return nil
+}
+
+func newSubTaskLogic(tasks ...*task.Task) (*DocumentIndexLogic, *fakeTaskModel, *fakeDocumentModel) {
+	taskModel := &fakeTaskModel{tasks: make(map[string]*task.Task)}
+	for _, t := range tasks {
+		taskModel.tasks[t.Id] = t
+	}
+	docModel := &fakeDocumentModel{doc: &knowledge_document.KnowledgeDocument{Id: "doc1", RunStatus: knowledge_document.RunStateRunning}}
+	// 未启动的 Redis, 清理信号及检查点失败只记录日志
+	rds := redis.New("127.0.0.1:1")
+	svcCtx := &svc.ServiceContext{
+		TaskModel:              taskModel,
+		KnowledgeDocumentModel: docModel,
+		IndexSignal:            indexctl.NewSignalStore(rds),
+		IndexCheckpoint:        indexctl.NewCheckpointStore(rds),
+	}
+	return NewDocumentIndexLogic(svcCtx, context.Background()), taskModel, docModel
+}
+
+func TestFailSubTask_RetryOnlyFailedPart(t *testing.T) {
+	l, taskModel, docModel := newSubTaskLogic(
+		&task.Task{Id: "t1", DocId: "doc1", Status: task.StatusRunning, FromPage: 0, ToPage: 2},
+		&task.Task{Id: "t2", DocId: "doc1", Status: task.StatusSuccess, FromPage: 2, ToPage: 4},
+	)
+	ic := &indexContext{msg: &indexMessage{&mq.KnowledgeDocumentIndexMsg{DocumentId: "doc1", TaskId: "t1"}}}
+
+	for attempt := 1; attempt < maxSubTaskRetry; attempt++ {
+		cur, err := taskModel.FindOne(context.Background(), "t1")
+		require.NoError(t, err)
+
+		// 返回错误交给本地消息表重试, 文档保持 indexing
+		err = l.failSubTask(context.Background(), ic, cur, "embedding timeout")
+		require.Error(t, err)
+		assert.Equal(t, task.StatusFail, taskModel.tasks["t1"].Status)
+		assert.Equal(t, int64(attempt), taskModel.tasks["t1"].RetryCount)
+		assert.Equal(t, "embedding timeout", taskModel.tasks["t1"].FailReason.String)
+		assert.Equal(t, knowledge_document.RunStateRunning, docModel.doc.RunStatus)
+	}
+	// 其他范围不受影响
+	assert.Equal(t, task.StatusSuccess, taskModel.tasks["t2"].Status)
+	assert.Zero(t, taskModel.tasks["t2"].RetryCount)
+
+	// 超过最大重试次数后文档置为失败, 不再重试
+	cur, err := taskModel.FindOne(context.Background(), "t1")
+	require.NoError(t, err)
+	require.NoError(t, l.failSubTask(context.Background(), ic, cur, "embedding timeout"))
+	assert.Equal(t, int64(maxSubTaskRetry), taskModel.tasks["t1"].RetryCount)
+	assert.Equal(t, knowledge_document.RunStateFailed, docModel.doc.RunStatus)
+	assert.Contains(t, docModel.doc.ProgressMsg.String, "[0, 2)")
+}
+
+func TestUpdateSubTask_ReturnsError(t *testing.T) {
+	l, taskModel, _ := newSubTaskLogic(&task.Task{Id: "t1", DocId: "doc1", Status: task.StatusPending})
+	taskModel.updateErr = errors.New("db down")
+
+	err := l.updateSubTask(context.Background(), &task.Task{Id: "t1"}, task.StatusRunning, "")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, taskModel.updateErr)
+}
+
+func TestProcessSubTask_DocNotRunning(t *testing.T) {
+	l, taskModel, docModel := newSubTaskLogic(&task.Task{Id: "t1", DocId: "doc1", Status: task.StatusPending})
+	docModel.doc.RunStatus = knowledge_document.RunStateCanceled
+	msg := &indexMessage{&mq.KnowledgeDocumentIndexMsg{DocumentId: "doc1", TaskId: "t1"}}
+
+	require.NoError(t, l.processSubTask(context.Background(), msg))
+	assert.Equal(t, task.StatusCanceled, taskModel.tasks["t1"].Status)
+
+	// 状态写入失败时返回错误, 由本地消息表重试
+	taskModel.tasks["t1"].Status = task.StatusPending
+	taskModel.updateErr = errors.New("db down")
+	assert.Error(t, l.processSubTask(context.Background(), msg))
+}
+
+func TestCompleteSubTasks_CleanupFailureKeepsRunning(t *testing.T) {
+	l, _, docModel := newSubTaskLogic(
+		&task.Task{Id: "t1", DocId: "doc1", Status: task.StatusSuccess, ChunkIds: sql.NullString{String: "c1", Valid: true}},
+	)
+	chunkModel := &fakeChunkModel{chunks: []*chunk.Chunk{{Id: "c1"}, {Id: "old1"}}}
+	l.svcCtx.ChunkModel = chunkModel
+	ic := &indexContext{msg: &indexMessage{&mq.KnowledgeDocumentIndexMsg{KnowledgeBaseId: "kb1", DocumentId: "doc1", TaskId: "t1"}}}
+
+	// 查询或删除旧分片失败时返回错误交给本地消息表重试, 文档保持 indexing, 旧分片不丢失
+	chunkModel.listErr = errors.New("es down")
+	require.Error(t, l.completeSubTasks(context.Background(), ic))
+	assert.Equal(t, knowledge_document.RunStateRunning, docModel.doc.RunStatus)
+
+	chunkModel.listErr = nil
+	chunkModel.deleteErr = errors.New("es down")
+	require.Error(t, l.completeSubTasks(context.Background(), ic))
+	assert.Equal(t, knowledge_document.RunStateRunning, docModel.doc.RunStatus)
+	assert.Len(t, chunkModel.chunks, 2)
+}
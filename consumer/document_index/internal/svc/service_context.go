@@ -6,14 +6,17 @@ import (
 
 	"github.com/zeromicro/go-queue/kq"
 	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/stores/redis"
 	"github.com/zeromicro/go-zero/core/stores/sqlx"
 
 	"gozero-rag/consumer/document_index/internal/config"
+	"gozero-rag/internal/indexctl"
 	"gozero-rag/internal/model/chunk"
 	"gozero-rag/internal/model/knowledge"
 	"gozero-rag/internal/model/knowledge_base"
 	"gozero-rag/internal/model/knowledge_document"
 	"gozero-rag/internal/model/local_message"
+	"gozero-rag/internal/model/task"
 	"gozero-rag/internal/model/tenant_llm"
 	"gozero-rag/internal/model/user_api"
 	"gozero-rag/internal/oss"
@@ -26,24 +29,39 @@ type ServiceContext struct {
 	SqlConn        sqlx.SqlConn
 	OssClient      oss.Client
 	MqPusherClient mq.Mq
+	IndexPusher    mq.Mq // 投递大文档拆分后的子任务
 
 	VectorClient                vectorstore.Client
 	KnowledgeBaseModel          knowledge_base.KnowledgeBaseModel
 	KnowledgeDocumentModel      knowledge_document.KnowledgeDocumentModel
 	KnowledgeDocumentChunkModel knowledge.KnowledgeDocumentChunkModel
 
-	ChunkModel   *chunk.EsChunkModel
+	ChunkModel   chunk.ChunkModel
 	UserApiModel user_api.UserApiModel
 
 	DocProcessService *doc_processor.ProcessorService
 
 	TenantLlmModel   tenant_llm.TenantLlmModel
+	TaskModel        task.TaskModel
 	LocalMsgExecutor *local_message.Executor
+
+	RedisClient     *redis.Redis
+	IndexSignal     *indexctl.SignalStore       // 取消/暂停信号
+	IndexCheckpoint *indexctl.CheckpointStore   // embedding 检查点
+	IndexProgress   *indexctl.ProgressPublisher // 索引进度
+	LlmBudget       *indexctl.LlmBudgetStore    // agentic 分片按文档共享的 LLM 调用预算
 }
 
 func NewServiceContext(c config.Config) *ServiceContext {
 	sqlConn := sqlx.NewMysql(c.Mysql.DataSource)
 
+	rdb := redis.MustNewRedis(redis.RedisConf{
+		Host: c.Cache[0].Host,
+		Type: c.Cache[0].Type,
+		User: c.Cache[0].User,
+		Pass: c.Cache[0].Pass,
+	})
+
 	// Init OSS Client
 	ossClient, err := oss.NewClient(c.Oss)
 	if err != nil {
@@ -75,6 +93,7 @@ func NewServiceContext(c config.Config) *ServiceContext {
 		SqlConn:        sqlConn,
 		OssClient:      ossClient,
 		MqPusherClient: mq.NewKafka(kq.NewPusher(c.KqPusherConf.Brokers, c.KqPusherConf.Topic), c.KqPusherConf.Topic),
+		IndexPusher:    mq.NewKafka(kq.NewPusher(c.KqConsumerConf.Brokers, mq.TopicDocumentIndex), mq.TopicDocumentIndex),
 
 		// VectorClient:                vectorClient,
 		KnowledgeBaseModel:          knowledge_base.NewKnowledgeBaseModel(sqlConn, c.Cache),
@@ -86,6 +105,13 @@ func NewServiceContext(c config.Config) *ServiceContext {
 		DocProcessService: docProcessService,
 
 		TenantLlmModel:   tenant_llm.NewTenantLlmModel(sqlConn, c.Cache),
+		TaskModel:        task.NewTaskModel(sqlConn, c.Cache),
 		LocalMsgExecutor: local_message.NewExecutor(local_message.NewLocalMessageModel(sqlConn)),
+
+		RedisClient:     rdb,
+		IndexSignal:     indexctl.NewSignalStore(rdb),
+		IndexCheckpoint: indexctl.NewCheckpointStore(rdb),
+		IndexProgress:   indexctl.NewProgressPublisher(rdb),
+		LlmBudget:       indexctl.NewLlmBudgetStore(rdb),
 	}
 }
@@ -0,0 +1,246 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+	"golang.org/x/sync/errgroup"
+
+	"gozero-rag/consumer/graph_extract/internal/svc"
+	"gozero-rag/internal/graphrag/community"
+	"gozero-rag/internal/graphrag/types"
+	"gozero-rag/internal/model/graph"
+	"gozero-rag/internal/model/knowledge_base"
+	"gozero-rag/internal/rag_core/parser"
+)
+
+const (
+	maxCommunityEdges      = 50000            // 社区发现读取的关系上限
+	reportConcurrency      = 4                // 社区报告生成并发数
+	reportEmbedBatchSize   = 16               // 社区报告向量化批大小
+	communityLockExpireSec = 1800             // 社区构建锁过期时间
+	communityScanInterval  = 10 * time.Second // 扫描到期重建任务的间隔
+	communityScanBatchSize = 10               // 每次扫描执行的重建任务数
+)
+
+// communityLockKey 同一知识库同时只允许一个社区构建任务
+func communityLockKey(kbId string) string {
+	return fmt.Sprintf("graphrag:community:lock:%s", kbId)
+}
+
+// CommunityScheduler 定时执行到期的社区报告重建
+// 文档图谱变化时只登记重建 (community.ScheduleRebuild), 同一知识库连续导入的文档合并为一次重建
+type CommunityScheduler struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	logic  *GraphExtractLogic
+	done   chan struct{}
+}
+
+func NewCommunityScheduler(ctx context.Context, svcCtx *svc.ServiceContext) *CommunityScheduler {
+	return &CommunityScheduler{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+		logic:  NewGraphExtractLogic(ctx, svcCtx),
+		done:   make(chan struct{}),
+	}
+}
+
+func (s *CommunityScheduler) Start() {
+	ticker := time.NewTicker(communityScanInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.runDue(s.ctx)
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *CommunityScheduler) Stop() {
+	close(s.done)
+}
+
+// runDue 执行已到期的重建任务
+func (s *CommunityScheduler) runDue(ctx context.Context) {
+	pairs, err := s.svcCtx.RedisClient.ZrangebyscoreWithScoresAndLimitCtx(ctx, community.PendingRebuildKey, 0, time.Now().UnixMilli(), 0, communityScanBatchSize)
+	if err != nil {
+		logx.Errorf("query pending community rebuild failed: %v", err)
+		return
+	}
+	for _, pair := range pairs {
+		s.logic.rebuildCommunities(ctx, pair.Key, pair.Score)
+	}
+}
+
+// rebuildCommunities 对知识库图谱做社区发现并更新社区报告, 成员未变化的社区沿用已有报告
+// 社区报告是附加能力, 失败只记录日志并延后重试, 不影响图谱抽取结果
+// 未获取到锁时保留登记, 由下一次扫描执行, 避免丢失构建期间的图谱变化
+func (l *GraphExtractLogic) rebuildCommunities(ctx context.Context, kbId string, score int64) {
+	lock := redis.NewRedisLock(l.svcCtx.RedisClient, communityLockKey(kbId))
+	lock.SetExpire(communityLockExpireSec)
+	ok, err := lock.AcquireCtx(ctx)
+	if err != nil {
+		logx.Errorf("acquire community lock failed: kb=%s, err=%v", kbId, err)
+		return
+	}
+	if !ok {
+		logx.Infof("知识库 %s 正在构建社区报告, 稍后重试", kbId)
+		return
+	}
+	defer func() {
+		// 按加锁时的随机值释放, 锁过期后已被其他实例获取时不会误删
+		if _, err := lock.ReleaseCtx(context.Background()); err != nil {
+			logx.Errorf("release community lock failed: kb=%s, err=%v", kbId, err)
+		}
+	}()
+
+	if err := l.buildCommunities(ctx, kbId); err != nil {
+		logx.Errorf("build community reports failed: kb=%s, err=%v", kbId, err)
+		// 延后重试, 沿用上次登记的模型
+		if err := community.ScheduleRebuild(ctx, l.svcCtx.RedisClient, kbId, ""); err != nil {
+			logx.Errorf("reschedule community rebuild failed: kb=%s, err=%v", kbId, err)
+		}
+		return
+	}
+	if _, err := community.FinishRebuild(ctx, l.svcCtx.RedisClient, kbId, score); err != nil {
+		logx.Errorf("finish community rebuild failed: kb=%s, err=%v", kbId, err)
+	}
+}
+
+func (l *GraphExtractLogic) buildCommunities(ctx context.Context, kbId string) error {
+	kb, err := l.svcCtx.KnowledgeBaseModel.FindOne(ctx, kbId)
+	if err == knowledge_base.ErrNotFound {
+		return nil // 知识库已删除
+	}
+	if err != nil {
+		return err
+	}
+
+	entities, relations, err := l.svcCtx.NebulaGraphModel.GetGraph(ctx, kbId, maxCommunityEdges)
+	if err != nil {
+		return fmt.Errorf("load graph: %w", err)
+	}
+	entityMap := make(map[string]types.Entity, len(entities))
+	for _, e := range entities {
+		entityMap[e.Name] = e
+	}
+
+	var communities []community.Community
+	ids := make([]string, 0)
+	for _, c := range community.Detect(relations) {
+		if len(c.Entities) >= community.MinSize {
+			communities = append(communities, c)
+			ids = append(ids, community.ReportId(kbId, c.Entities))
+		}
+	}
+	logx.Infof("知识库 %s 社区发现完成: 实体 %d, 关系 %d, 社区 %d", kbId, len(entities), len(relations), len(communities))
+
+	existing, err := l.svcCtx.CommunityModel.FindByIds(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("load existing reports: %w", err)
+	}
+	reports, pending := community.ReuseReports(kbId, communities, existing)
+
+	if len(pending) > 0 {
+		llmId, err := l.communityLlmId(ctx, kb)
+		if err != nil {
+			return err
+		}
+		llm, embedder, err := l.newModels(ctx, kb.TenantId, kb.EmbdId, llmId)
+		if err != nil {
+			return err
+		}
+		generated := l.generateReports(ctx, kbId, llm, pending, entityMap, relations)
+		if len(generated) == 0 {
+			// 全部生成失败时保留旧报告
+			return fmt.Errorf("all %d community reports failed", len(pending))
+		}
+		if err := embedReports(ctx, embedder, generated); err != nil {
+			return fmt.Errorf("embed reports: %w", err)
+		}
+		reports = append(reports, generated...)
+	}
+
+	if err := l.svcCtx.CommunityModel.Replace(ctx, kbId, reports); err != nil {
+		return fmt.Errorf("save reports: %w", err)
+	}
+	logx.Infof("知识库 %s 社区报告已更新: %d/%d, 沿用 %d", kbId, len(reports), len(communities), len(communities)-len(pending))
+	return nil
+}
+
+// communityLlmId 报告模型: 优先使用最近一次登记重建的模型, 否则使用知识库图谱配置
+func (l *GraphExtractLogic) communityLlmId(ctx context.Context, kb *knowledge_base.KnowledgeBase) (string, error) {
+	llmId, err := l.svcCtx.RedisClient.HgetCtx(ctx, community.RebuildLlmKey, kb.Id)
+	if err != nil && err != redis.Nil {
+		return "", err
+	}
+	if llmId != "" {
+		return llmId, nil
+	}
+	template, err := parser.ResolveParserConfig(kb.ParserId, kb.ParserConfig.String)
+	if err != nil {
+		return "", err
+	}
+	if g := template.General().GraphRag; g != nil && g.GraphLlmId != "" {
+		return g.GraphLlmId, nil
+	}
+	return "", fmt.Errorf("知识库 %s 未配置图谱模型", kb.Id)
+}
+
+func (l *GraphExtractLogic) generateReports(ctx context.Context, kbId string, llm model.BaseChatModel, communities []community.Community,
+	entities map[string]types.Entity, relations []types.Relation) []*graph.CommunityReport {
+	reporter := community.NewReporter(llm)
+
+	var (
+		mu      sync.Mutex
+		reports = make([]*graph.CommunityReport, 0, len(communities))
+	)
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(reportConcurrency)
+	for _, c := range communities {
+		g.Go(func() error {
+			report, err := reporter.Generate(gCtx, kbId, c, entities, relations)
+			if err != nil {
+				logx.Errorf("generate community report failed: kb=%s, community=%d, err=%v", kbId, c.Id, err)
+				return nil
+			}
+			mu.Lock()
+			reports = append(reports, report)
+			mu.Unlock()
+			return nil
+		})
+	}
+	_ = g.Wait()
+	return reports
+}
+
+// embedReports 为报告全文生成向量, 供检索使用
+func embedReports(ctx context.Context, embedder embedding.Embedder, reports []*graph.CommunityReport) error {
+	for start := 0; start < len(reports); start += reportEmbedBatchSize {
+		batch := reports[start:min(start+reportEmbedBatchSize, len(reports))]
+		texts := make([]string, 0, len(batch))
+		for _, r := range batch {
+			texts = append(texts, r.Content)
+		}
+		vectors, err := embedder.EmbedStrings(ctx, texts)
+		if err != nil {
+			return err
+		}
+		if len(vectors) != len(batch) {
+			return fmt.Errorf("向量数量不一致: %d != %d", len(vectors), len(batch))
+		}
+		for i, r := range batch {
+			r.Embedding = vectors[i]
+		}
+	}
+	return nil
+}
@@ -12,5 +12,6 @@ import (
 func Consumers(ctx context.Context, svcCtx *svc.ServiceContext) []service.Service {
 	return []service.Service{
 		kq.MustNewQueue(svcCtx.Config.KqConsumerConf, NewGraphExtractLogic(ctx, svcCtx)),
+		NewCommunityScheduler(ctx, svcCtx),
 	}
 }
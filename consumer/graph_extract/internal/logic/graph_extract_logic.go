@@ -2,20 +2,23 @@ package logic
 
 import (
 	"context"
-	"crypto/md5"
-	"encoding/hex"
 	"encoding/json"
-	"time"
 
 	openaiemb "github.com/cloudwego/eino-ext/components/embedding/openai"
 	"github.com/cloudwego/eino-ext/components/model/openai"
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/cloudwego/eino/components/model"
 	"github.com/zeromicro/go-zero/core/logx"
 
 	"gozero-rag/consumer/graph_extract/internal/svc"
+	"gozero-rag/internal/graphrag/community"
+	"gozero-rag/internal/graphrag/resolver"
 	"gozero-rag/internal/graphrag/types"
-	"gozero-rag/internal/model/graph"
+	"gozero-rag/internal/model/chunk"
+	"gozero-rag/internal/model/knowledge_base"
 	"gozero-rag/internal/model/local_message"
 	"gozero-rag/internal/mq"
+	"gozero-rag/internal/rag_core/parser"
 	"gozero-rag/internal/tools/llmx"
 )
 
@@ -48,6 +51,10 @@ func (l *GraphExtractLogic) Consume(ctx context.Context, key, value string) erro
 
 // processGraphExtract 图谱提取核心逻辑
 func (l *GraphExtractLogic) processGraphExtract(ctx context.Context, msg *mq.GraphGenerateMsg) error {
+	if len(msg.RemoveDocumentIds) > 0 {
+		return l.removeDocuments(ctx, msg)
+	}
+
 	// get embedding config
 	kb, err := l.svcCtx.KnowledgeBaseModel.FindOne(ctx, msg.KnowledgeBaseId)
 	if err != nil {
@@ -55,58 +62,46 @@ func (l *GraphExtractLogic) processGraphExtract(ctx context.Context, msg *mq.Gra
 		return err
 	}
 
-	embModelName, embFactory := llmx.GetModelNameFactory(kb.EmbdId)
-	// 1. Get LLM Config
-	llmModelName, factory := llmx.GetModelNameFactory(msg.LlmId)
-
-	tenantLlm, err := l.svcCtx.TenantLlmModel.FindByTenantFactoryName(ctx, msg.TenantId, factory, llmModelName)
-	if err != nil {
-		logx.Errorf("find tenant llm failed: %v", err)
-		return err
-	}
-
-	tenantEmb, err := l.svcCtx.TenantLlmModel.FindByTenantFactoryName(ctx, msg.TenantId, embFactory, embModelName)
-	if err != nil {
-		logx.Errorf("find tenant embedding failed: %v", err)
-		return err
-	}
-
-	embDim := 1024 // 后期做成可配置的
-	embedder, err := openaiemb.NewEmbedder(ctx, &openaiemb.EmbeddingConfig{
-		APIKey:     tenantEmb.ApiKey.String,
-		BaseURL:    tenantEmb.ApiBase.String,
-		Model:      tenantEmb.LlmName,
-		Dimensions: &embDim,
-	})
-	if err != nil {
-		return err
-	}
-	// 2. Initialize Chat Model
-	llm, err := openai.NewChatModel(ctx, &openai.ChatModelConfig{
-		APIKey:  tenantLlm.ApiKey.String,
-		BaseURL: tenantLlm.ApiBase.String,
-		Model:   tenantLlm.LlmName,
-	})
+	llm, embedder, err := l.newModels(ctx, msg.TenantId, kb.EmbdId, msg.LlmId)
 	if err != nil {
-		logx.Errorf("init chat model failed: %v", err)
 		return err
 	}
 
-	// 3. Get Chunks
-	chunkResult, err := l.svcCtx.ChunkModel.ListByDocId(ctx, msg.DocumentId, "", 1, 1000)
+	// 3. Get Chunks (大文档可能超过单页上限, 需全部读取; 父块和 QA 分片不参与抽取)
+	allChunks, err := l.svcCtx.ChunkModel.ListAllByDocId(ctx, msg.KnowledgeBaseId, msg.DocumentId, false)
 	if err != nil {
 		logx.Errorf("list chunks failed: %v", err)
 		return err
 	}
-	if chunkResult.Total == 0 {
+	chunks := make([]*chunk.Chunk, 0, len(allChunks))
+	for _, c := range allChunks {
+		if c.ChunkType != chunk.ChunkTypeParent && c.ChunkType != chunk.ChunkTypeQa {
+			chunks = append(chunks, c)
+		}
+	}
+	if len(chunks) == 0 {
 		logx.Info("no chunks found for document")
+		// 文档已无分片 (如重新解析后为空), 移除其历史图谱贡献
+		if err := l.svcCtx.GraphStore.RemoveDocuments(ctx, msg.KnowledgeBaseId, []string{msg.DocumentId}); err != nil {
+			logx.Errorf("remove graph contribution failed: %v", err)
+			return err
+		}
+		if msg.EnableCommunity {
+			if err := community.ScheduleRebuild(ctx, l.svcCtx.RedisClient, msg.KnowledgeBaseId, msg.LlmId); err != nil {
+				logx.Errorf("schedule community rebuild failed: kb=%s, err=%v", msg.KnowledgeBaseId, err)
+			}
+		}
 		return nil
 	}
+	chunkIds := make([]string, 0, len(chunks))
+	for _, c := range chunks {
+		chunkIds = append(chunkIds, c.Id)
+	}
 
 	// 4. Extract Graph
 	logx.Infof("开始提取知识图谱, doc_id: %s", msg.DocumentId)
 
-	extractResult, err := l.svcCtx.GraphExtractor.ParallelExtract(ctx, chunkResult.Chunks, llm, 10, 8)
+	extractResult, err := l.svcCtx.GraphExtractor.ParallelExtract(ctx, chunks, llm, msg.EntityTypes, 10, 8)
 	if err != nil {
 		logx.Errorf("extract graph failed: %v", err)
 		return err
@@ -127,110 +122,109 @@ func (l *GraphExtractLogic) processGraphExtract(ctx context.Context, msg *mq.Gra
 		}
 	}
 
-	// 5. Save to NebulaGraph
-	if err := l.saveToNebula(ctx, extractResult, msg.KnowledgeBaseId); err != nil {
-		logx.Errorf("save graph to nebula failed: %v", err)
-		return err
+	// 4.3 实体归一化: 与知识库已有实体合并重复实体
+	var aliases map[string]string
+	if msg.EnableEntityResolution && len(extractResult.Entities) > 0 {
+		resolution, err := resolver.NewEntityResolver(l.svcCtx.GraphModel, llm).Resolve(ctx, msg.KnowledgeBaseId, extractResult)
+		if err != nil {
+			// 归一化失败不阻塞流程, 按未归一化结果写入
+			logx.Errorf("resolve entities failed: %v", err)
+		} else {
+			extractResult = l.svcCtx.GraphExtractor.Merge([]*types.GraphExtractionResult{resolution.Result})
+			aliases = resolution.Aliases
+			logx.Infof("doc [%s] 实体归一化完成, 合并别名数: %d", msg.DocumentId, len(aliases))
+		}
 	}
 
-	// 6. Transform to ES Documents (with embeddings)
-	esDocs := l.transformToEsDocs(extractResult, msg.KnowledgeBaseId)
+	// 5. 合并别名实体, 改写其他文档贡献中的别名
+	if err := l.svcCtx.GraphStore.MergeAliases(ctx, msg.KnowledgeBaseId, aliases); err != nil {
+		// 合并失败不影响本次抽取结果
+		logx.Errorf("merge alias entities failed: %v", err)
+	}
 
-	// 7. Save to ES
-	if err := l.svcCtx.GraphModel.Put(ctx, esDocs); err != nil {
-		logx.Errorf("save graph to es failed: %v", err)
-		// 不阻塞流程
+	// 6. 替换文档的图谱贡献, 同步写入 NebulaGraph 和 ES (重新索引时先扣减旧贡献)
+	if err := l.svcCtx.GraphStore.ApplyDocument(ctx, msg.KnowledgeBaseId, msg.DocumentId, chunkIds, extractResult); err != nil {
+		logx.Errorf("save graph failed: %v", err)
+		return err
+	}
+
+	// 7. 登记社区报告重建, 由 CommunityScheduler 合并同一知识库的多次变化后执行
+	if msg.EnableCommunity {
+		if err := community.ScheduleRebuild(ctx, l.svcCtx.RedisClient, msg.KnowledgeBaseId, msg.LlmId); err != nil {
+			logx.Errorf("schedule community rebuild failed: kb=%s, err=%v", msg.KnowledgeBaseId, err)
+		}
 	}
 
 	logx.Infof("graph extraction completed for doc: %s", msg.DocumentId)
 	return nil
 }
 
-func (l *GraphExtractLogic) transformToEsDocs(result *types.GraphExtractionResult, kbId string) []*graph.EsGraphDocument {
-	docs := make([]*graph.EsGraphDocument, 0, len(result.Entities)+len(result.Relations))
-
-	// Entities
-	for _, entity := range result.Entities {
-		id := l.genEntityId(kbId, entity.Name)
-		doc := &graph.EsGraphDocument{
-			Id:          id, // Use deterministic ID for upsert
-			KbId:        kbId,
-			GraphType:   "entity",
-			EntityName:  entity.Name,
-			Description: entity.Description,
-			Weight:      1.0, // Default weight for entity
-			SourceIds:   entity.SourceId,
-			Embedding:   entity.Embedding, // 向量嵌入
-			UpdatedAt:   l.nowStr(),
+// removeDocuments 文档删除后移除其图谱贡献, 失败时返回错误由本地消息表重试
+// 知识库开启社区报告时登记重建, 模型沿用上次登记或知识库配置的图谱模型
+func (l *GraphExtractLogic) removeDocuments(ctx context.Context, msg *mq.GraphGenerateMsg) error {
+	if err := l.svcCtx.GraphStore.RemoveDocuments(ctx, msg.KnowledgeBaseId, msg.RemoveDocumentIds); err != nil {
+		logx.Errorf("remove graph contribution failed: kb=%s, docs=%v, err=%v", msg.KnowledgeBaseId, msg.RemoveDocumentIds, err)
+		return err
+	}
+
+	kb, err := l.svcCtx.KnowledgeBaseModel.FindOne(ctx, msg.KnowledgeBaseId)
+	if err != nil {
+		if err == knowledge_base.ErrNotFound {
+			// 知识库已删除, 图谱随知识库一并删除
+			return nil
 		}
-		// Content backup (不含 embedding，避免重复存储)
-		entityBackup := types.Entity{
-			Name:        entity.Name,
-			Type:        entity.Type,
-			Description: entity.Description,
-			SourceId:    entity.SourceId,
+		return err
+	}
+	template, err := parser.ResolveParserConfig(kb.ParserId, kb.ParserConfig.String)
+	if err != nil {
+		logx.Errorf("resolve parser config failed: kb=%s, err=%v", kb.Id, err)
+		return nil
+	}
+	if g := template.General().GraphRag; g != nil && g.EnableCommunity {
+		if err := community.ScheduleRebuild(ctx, l.svcCtx.RedisClient, kb.Id, ""); err != nil {
+			logx.Errorf("schedule community rebuild failed: kb=%s, err=%v", kb.Id, err)
 		}
-		contentBytes, _ := json.Marshal(entityBackup)
-		doc.ContentWithWeight = string(contentBytes)
-		docs = append(docs, doc)
-	}
-
-	// 只存实体
-	//// Relations
-	//for _, rel := range result.Relations {
-	//	id := l.genRelationId(kbId, rel.SrcId, rel.DstId)
-	//	doc := &graph.EsGraphDocument{
-	//		Id:          id,
-	//		KbId:        kbId,
-	//		GraphType:   "relation",
-	//		SrcName:     rel.SrcId,
-	//		DstName:     rel.DstId,
-	//		Description: rel.Description,
-	//		Weight:      rel.Weight,
-	//		SourceIds:   rel.SourceId,
-	//		UpdatedAt:   l.nowStr(),
-	//	}
-	//	contentBytes, _ := json.Marshal(rel)
-	//	doc.ContentWithWeight = string(contentBytes)
-	//	docs = append(docs, doc)
-	//}
-
-	return docs
-}
-
-func (l *GraphExtractLogic) genEntityId(kbId, name string) string {
-	// Prefix: entity_
-	hash := md5.Sum([]byte(kbId + name))
-	return "entity_" + hex.EncodeToString(hash[:])
-}
-
-func (l *GraphExtractLogic) genRelationId(kbId, src, dst string) string {
-	// Prefix: relation_
-	hash := md5.Sum([]byte(kbId + src + dst))
-	return "relation_" + hex.EncodeToString(hash[:])
+	}
+	return nil
 }
 
-func (l *GraphExtractLogic) nowStr() string {
-	return time.Now().Format(time.RFC3339)
-}
+// newModels 按租户配置创建图谱抽取使用的对话模型及 Embedder
+func (l *GraphExtractLogic) newModels(ctx context.Context, tenantId, embdId, llmId string) (model.ToolCallingChatModel, embedding.Embedder, error) {
+	embModelName, embFactory := llmx.GetModelNameFactory(embdId)
+	// 1. Get LLM Config
+	llmModelName, factory := llmx.GetModelNameFactory(llmId)
 
-// saveToNebula 将图谱数据存储到 NebulaGraph
-// 只调用 Model 层接口，不写 nGQL 语句
-func (l *GraphExtractLogic) saveToNebula(ctx context.Context, result *types.GraphExtractionResult, kbId string) error {
-	// 1. 确保 Space 和 Schema 存在
-	if err := l.svcCtx.NebulaGraphModel.EnsureSpaceAndSchema(ctx, kbId); err != nil {
-		return err
+	tenantLlm, err := l.svcCtx.TenantLlmModel.FindByTenantFactoryName(ctx, tenantId, factory, llmModelName)
+	if err != nil {
+		logx.Errorf("find tenant llm failed: %v", err)
+		return nil, nil, err
 	}
 
-	// 2. 批量写入实体
-	if err := l.svcCtx.NebulaGraphModel.BatchUpsertEntities(ctx, kbId, result.Entities); err != nil {
-		return err
+	tenantEmb, err := l.svcCtx.TenantLlmModel.FindByTenantFactoryName(ctx, tenantId, embFactory, embModelName)
+	if err != nil {
+		logx.Errorf("find tenant embedding failed: %v", err)
+		return nil, nil, err
 	}
 
-	// 3. 批量写入关系
-	if err := l.svcCtx.NebulaGraphModel.BatchInsertRelations(ctx, kbId, result.Relations); err != nil {
-		return err
+	embDim := 1024 // 后期做成可配置的
+	embedder, err := openaiemb.NewEmbedder(ctx, &openaiemb.EmbeddingConfig{
+		APIKey:     tenantEmb.ApiKey.String,
+		BaseURL:    tenantEmb.ApiBase.String,
+		Model:      tenantEmb.LlmName,
+		Dimensions: &embDim,
+	})
+	if err != nil {
+		return nil, nil, err
 	}
-
-	return nil
+	// 2. Initialize Chat Model
+	llm, err := openai.NewChatModel(ctx, &openai.ChatModelConfig{
+		APIKey:  tenantLlm.ApiKey.String,
+		BaseURL: tenantLlm.ApiBase.String,
+		Model:   tenantLlm.LlmName,
+	})
+	if err != nil {
+		logx.Errorf("init chat model failed: %v", err)
+		return nil, nil, err
+	}
+	return llm, embedder, nil
 }
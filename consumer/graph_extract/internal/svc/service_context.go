@@ -4,10 +4,12 @@ import (
 	"context"
 
 	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/stores/redis"
 	"github.com/zeromicro/go-zero/core/stores/sqlx"
 
 	"gozero-rag/consumer/graph_extract/internal/config"
 	"gozero-rag/internal/graphrag/extractor"
+	"gozero-rag/internal/graphrag/store"
 	"gozero-rag/internal/model/chunk"
 	"gozero-rag/internal/model/graph"
 	"gozero-rag/internal/model/knowledge_base"
@@ -22,6 +24,9 @@ type ServiceContext struct {
 	ChunkModel         chunk.ChunkModel
 	GraphModel         graph.GraphModel       // ES 图数据存储
 	NebulaGraphModel   graph.NebulaGraphModel // Nebula 图数据存储
+	CommunityModel     graph.CommunityModel   // ES 社区报告存储
+	GraphStore         *store.GraphStore      // 按文档贡献维护 Nebula 与 ES 图谱数据
+	RedisClient        *redis.Redis
 	GraphExtractor     *extractor.GraphExtractor
 	LocalMsgExecutor   *local_message.Executor
 }
@@ -50,6 +55,18 @@ func NewServiceContext(c config.Config) *ServiceContext {
 		panic(err)
 	}
 
+	communityModel, err := graph.NewEsCommunityModel(c.ElasticSearch.Addresses, c.ElasticSearch.Username, c.ElasticSearch.Password)
+	if err != nil {
+		logx.Errorf("NewEsCommunityModel failed: %v", err)
+		panic(err)
+	}
+
+	contributionModel, err := graph.NewEsContributionModel(c.ElasticSearch.Addresses, c.ElasticSearch.Username, c.ElasticSearch.Password)
+	if err != nil {
+		logx.Errorf("NewEsContributionModel failed: %v", err)
+		panic(err)
+	}
+
 	// Init Graph Extractor (Singleton)
 	graphExtractor, err := extractor.NewGraphExtractor(context.Background())
 	if err != nil {
@@ -57,6 +74,13 @@ func NewServiceContext(c config.Config) *ServiceContext {
 		panic(err)
 	}
 
+	redisClient := redis.MustNewRedis(redis.RedisConf{
+		Host: c.Cache[0].Host,
+		Type: c.Cache[0].Type,
+		User: c.Cache[0].User,
+		Pass: c.Cache[0].Pass,
+	})
+
 	return &ServiceContext{
 		Config:             c,
 		TenantLlmModel:     tenant_llm.NewTenantLlmModel(sqlConn, c.Cache),
@@ -64,6 +88,9 @@ func NewServiceContext(c config.Config) *ServiceContext {
 		ChunkModel:         chunkModel,
 		GraphModel:         graphModel,
 		NebulaGraphModel:   nebulaGraphModel,
+		CommunityModel:     communityModel,
+		GraphStore:         store.NewGraphStore(redisClient, contributionModel, graphModel, nebulaGraphModel),
+		RedisClient:        redisClient,
 		GraphExtractor:     graphExtractor,
 		LocalMsgExecutor:   local_message.NewExecutor(local_message.NewLocalMessageModel(sqlConn)),
 	}
@@ -0,0 +1,107 @@
+package community
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gozero-rag/internal/graphrag/types"
+	"gozero-rag/internal/model/graph"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetect_TwoCliques(t *testing.T) {
+	// 两个三角形之间只有一条弱连接
+	relations := []types.Relation{
+		{SrcId: "A", DstId: "B", Weight: 5},
+		{SrcId: "B", DstId: "C", Weight: 5},
+		{SrcId: "A", DstId: "C", Weight: 5},
+		{SrcId: "X", DstId: "Y", Weight: 5},
+		{SrcId: "Y", DstId: "Z", Weight: 5},
+		{SrcId: "X", DstId: "Z", Weight: 5},
+		{SrcId: "C", DstId: "X", Weight: 1},
+	}
+
+	communities := Detect(relations)
+	require.Len(t, communities, 2)
+	assert.Equal(t, []string{"A", "B", "C"}, communities[0].Entities)
+	assert.Equal(t, []string{"X", "Y", "Z"}, communities[1].Entities)
+	assert.Equal(t, 0, communities[0].Id)
+	assert.Equal(t, 1, communities[1].Id)
+}
+
+func TestDetect_Empty(t *testing.T) {
+	assert.Empty(t, Detect(nil))
+}
+
+type fakeLlm struct {
+	answer string
+	prompt string
+}
+
+func (f *fakeLlm) Generate(_ context.Context, msgs []*schema.Message, _ ...model.Option) (*schema.Message, error) {
+	f.prompt = msgs[0].Content
+	return schema.AssistantMessage(f.answer, nil), nil
+}
+
+func (f *fakeLlm) Stream(context.Context, []*schema.Message, ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	return nil, nil
+}
+
+func TestReporter_Generate(t *testing.T) {
+	llm := &fakeLlm{answer: "```json\n" + `{"title": "字节跳动与抖音", "summary": "摘要", "rating": 6.5, "rating_explanation": "影响较大",
+		"findings": [{"summary": "母公司", "explanation": "字节跳动拥有抖音"}]}` + "\n```"}
+	c := Community{Id: 3, Entities: []string{"字节跳动", "抖音"}}
+	entities := map[string]types.Entity{
+		"字节跳动": {Name: "字节跳动", Description: "互联网公司"},
+		"抖音":   {Name: "抖音", Description: "短视频平台"},
+	}
+	relations := []types.Relation{
+		{SrcId: "字节跳动", DstId: "抖音", Description: "拥有"},
+		{SrcId: "字节跳动", DstId: "火山引擎", Description: "社区外关系"},
+	}
+
+	report, err := NewReporter(llm).Generate(context.Background(), "kb-1", c, entities, relations)
+	require.NoError(t, err)
+
+	assert.Contains(t, llm.prompt, "0,字节跳动,互联网公司")
+	assert.Contains(t, llm.prompt, "0,字节跳动,抖音,拥有")
+	assert.NotContains(t, llm.prompt, "社区外关系")
+	assert.Equal(t, "字节跳动与抖音", report.Title)
+	assert.Equal(t, 6.5, report.Rating)
+	assert.Equal(t, 3, report.CommunityId)
+	assert.Equal(t, ReportId("kb-1", []string{"抖音", "字节跳动"}), report.Id)
+	assert.True(t, strings.HasPrefix(report.Content, "字节跳动与抖音\n摘要"))
+}
+
+func TestParseReport_Invalid(t *testing.T) {
+	_, err := parseReport("无法生成报告")
+	assert.Error(t, err)
+}
+
+func TestReuseReports(t *testing.T) {
+	existing := []*graph.CommunityReport{
+		{Id: ReportId("kb1", []string{"B", "A"}), CommunityId: 7, Title: "AB", Embedding: []float64{0.1}},
+		{Id: ReportId("kb1", []string{"X", "Y"}), CommunityId: 8, Title: "XY"}, // 缺少向量, 需要重新生成
+	}
+	communities := []Community{
+		{Id: 0, Entities: []string{"A", "B"}},
+		{Id: 1, Entities: []string{"A", "B", "C"}}, // 成员变化
+		{Id: 2, Entities: []string{"X", "Y"}},
+	}
+
+	reused, pending := ReuseReports("kb1", communities, existing)
+	require.Len(t, reused, 1)
+	assert.Equal(t, "AB", reused[0].Title)
+	assert.Equal(t, 0, reused[0].CommunityId)
+	assert.Equal(t, []float64{0.1}, reused[0].Embedding)
+	assert.Equal(t, 7, existing[0].CommunityId, "不修改已有报告")
+
+	require.Len(t, pending, 2)
+	assert.Equal(t, 1, pending[0].Id)
+	assert.Equal(t, 2, pending[1].Id)
+}
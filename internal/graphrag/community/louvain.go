@@ -0,0 +1,219 @@
+package community
+
+import (
+	"sort"
+
+	"gozero-rag/internal/graphrag/types"
+)
+
+// maxLocalMovingRounds 单层局部移动的最大轮数, 防止在平局时来回震荡
+const maxLocalMovingRounds = 20
+
+// Community 社区: 社区内实体之间的连接比与外部更紧密
+type Community struct {
+	Id       int
+	Entities []string
+}
+
+// louvainGraph 带权无向图, adj[i][i] 为社区内部权重的两倍, 使度数计算统一为按行求和
+type louvainGraph struct {
+	adj    []map[int]float64
+	degree []float64
+	total  float64 // 所有节点度数之和 (2m)
+}
+
+func newLouvainGraph(n int) *louvainGraph {
+	g := &louvainGraph{
+		adj:    make([]map[int]float64, n),
+		degree: make([]float64, n),
+	}
+	for i := range g.adj {
+		g.adj[i] = make(map[int]float64)
+	}
+	return g
+}
+
+func (g *louvainGraph) addEdge(i, j int, w float64) {
+	if i == j {
+		g.adj[i][i] += 2 * w
+		g.degree[i] += 2 * w
+		g.total += 2 * w
+		return
+	}
+	g.adj[i][j] += w
+	g.adj[j][i] += w
+	g.degree[i] += w
+	g.degree[j] += w
+	g.total += 2 * w
+}
+
+// Detect 使用 Louvain 算法对关系构成的图做社区划分
+// 关系按无向边处理, 权重 <= 0 时按 1 计算; 返回的社区按规模从大到小编号
+func Detect(relations []types.Relation) []Community {
+	names := nodeNames(relations)
+	if len(names) == 0 {
+		return nil
+	}
+	index := make(map[string]int, len(names))
+	for i, name := range names {
+		index[name] = i
+	}
+
+	g := newLouvainGraph(len(names))
+	for _, r := range relations {
+		if r.SrcId == "" || r.DstId == "" {
+			continue
+		}
+		w := r.Weight
+		if w <= 0 {
+			w = 1
+		}
+		g.addEdge(index[r.SrcId], index[r.DstId], w)
+	}
+
+	// membership[原始节点] = 当前层节点
+	membership := make([]int, len(names))
+	for i := range membership {
+		membership[i] = i
+	}
+
+	for {
+		partition, moved := localMoving(g)
+		if !moved {
+			break
+		}
+		var n int
+		partition, n = renumber(partition)
+		for i := range membership {
+			membership[i] = partition[membership[i]]
+		}
+		g = aggregate(g, partition, n)
+	}
+
+	groups := make(map[int][]string)
+	for i, c := range membership {
+		groups[c] = append(groups[c], names[i])
+	}
+	communities := make([]Community, 0, len(groups))
+	for _, entities := range groups {
+		sort.Strings(entities)
+		communities = append(communities, Community{Entities: entities})
+	}
+	sort.Slice(communities, func(i, j int) bool {
+		if len(communities[i].Entities) != len(communities[j].Entities) {
+			return len(communities[i].Entities) > len(communities[j].Entities)
+		}
+		return communities[i].Entities[0] < communities[j].Entities[0]
+	})
+	for i := range communities {
+		communities[i].Id = i
+	}
+	return communities
+}
+
+// localMoving Louvain 第一阶段: 依次把节点移动到模块度增益最大的相邻社区, 直到没有节点移动
+func localMoving(g *louvainGraph) ([]int, bool) {
+	n := len(g.adj)
+	partition := make([]int, n)
+	tot := make([]float64, n) // 每个社区的度数之和
+	for i := 0; i < n; i++ {
+		partition[i] = i
+		tot[i] = g.degree[i]
+	}
+	if g.total == 0 {
+		return partition, false
+	}
+
+	moved := false
+	for round := 0; round < maxLocalMovingRounds; round++ {
+		improved := false
+		for i := 0; i < n; i++ {
+			current := partition[i]
+
+			// 节点与各相邻社区之间的权重
+			weights := make(map[int]float64)
+			for j, w := range g.adj[i] {
+				if j != i {
+					weights[partition[j]] += w
+				}
+			}
+
+			tot[current] -= g.degree[i]
+			best, bestGain := current, weights[current]-tot[current]*g.degree[i]/g.total
+			for _, c := range sortedCommunities(weights) {
+				gain := weights[c] - tot[c]*g.degree[i]/g.total
+				if gain > bestGain+1e-12 {
+					best, bestGain = c, gain
+				}
+			}
+			tot[best] += g.degree[i]
+
+			if best != current {
+				partition[i] = best
+				improved = true
+				moved = true
+			}
+		}
+		if !improved {
+			break
+		}
+	}
+	return partition, moved
+}
+
+// aggregate Louvain 第二阶段: 将同一社区的节点合并为一个节点
+func aggregate(g *louvainGraph, partition []int, n int) *louvainGraph {
+	next := newLouvainGraph(n)
+	for i, neighbors := range g.adj {
+		for j, w := range neighbors {
+			ci, cj := partition[i], partition[j]
+			// 边在 adj 中按两个方向各记录一次, 直接累加后社区内部边自然成为两倍权重的自环
+			next.adj[ci][cj] += w
+			next.degree[ci] += w
+			next.total += w
+		}
+	}
+	return next
+}
+
+// renumber 将社区编号压缩为 0..n-1
+func renumber(partition []int) ([]int, int) {
+	ids := make(map[int]int)
+	result := make([]int, len(partition))
+	for i, c := range partition {
+		id, ok := ids[c]
+		if !ok {
+			id = len(ids)
+			ids[c] = id
+		}
+		result[i] = id
+	}
+	return result, len(ids)
+}
+
+func sortedCommunities(weights map[int]float64) []int {
+	keys := make([]int, 0, len(weights))
+	for k := range weights {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+func nodeNames(relations []types.Relation) []string {
+	seen := make(map[string]struct{})
+	var names []string
+	for _, r := range relations {
+		for _, name := range []string{r.SrcId, r.DstId} {
+			if name == "" {
+				continue
+			}
+			if _, ok := seen[name]; !ok {
+				seen[name] = struct{}{}
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
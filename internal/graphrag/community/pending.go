@@ -0,0 +1,48 @@
+package community
+
+import (
+	"context"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/stores/redis"
+)
+
+// PendingRebuildKey 待重建社区报告的知识库 (Redis ZSet), member 为知识库 id, score 为最早执行时间 (毫秒)
+// 图谱变化时登记并推迟执行时间, 连续导入的文档合并为一次重建, 由 graph_extract 消费者定时执行
+const PendingRebuildKey = "graphrag:community:pending"
+
+// RebuildLlmKey 知识库最近一次登记重建时使用的报告模型 (Redis Hash), field 为知识库 id
+const RebuildLlmKey = "graphrag:community:llm"
+
+// RebuildDelay 登记后延迟执行的时间, 期间再次登记会继续推迟
+const RebuildDelay = time.Minute
+
+// finishRebuildScript 重建完成后移除登记, 重建期间再次登记 (score 已变化) 时保留, 由下一轮重建
+var finishRebuildScript = redis.NewScript(`
+local score = redis.call('ZSCORE', KEYS[1], ARGV[1])
+if score and tonumber(score) == tonumber(ARGV[2]) then
+	redis.call('ZREM', KEYS[1], ARGV[1])
+	return 1
+end
+return 0`)
+
+// ScheduleRebuild 登记知识库社区报告重建, llmId 为空时沿用上次登记的模型
+func ScheduleRebuild(ctx context.Context, rds *redis.Redis, kbId, llmId string) error {
+	if llmId != "" {
+		if err := rds.HsetCtx(ctx, RebuildLlmKey, kbId, llmId); err != nil {
+			return err
+		}
+	}
+	_, err := rds.ZaddCtx(ctx, PendingRebuildKey, time.Now().Add(RebuildDelay).UnixMilli(), kbId)
+	return err
+}
+
+// FinishRebuild 移除 score 对应的重建登记, 返回是否已移除
+func FinishRebuild(ctx context.Context, rds *redis.Redis, kbId string, score int64) (bool, error) {
+	val, err := rds.ScriptRunCtx(ctx, finishRebuildScript, []string{PendingRebuildKey}, kbId, score)
+	if err != nil {
+		return false, err
+	}
+	n, _ := val.(int64)
+	return n == 1, nil
+}
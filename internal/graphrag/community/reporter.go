@@ -0,0 +1,185 @@
+package community
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gozero-rag/internal/graphrag/prompt"
+	"gozero-rag/internal/graphrag/types"
+	"gozero-rag/internal/model/graph"
+
+	"github.com/cloudwego/eino/components/model"
+)
+
+const (
+	MinSize            = 2   // 实体数少于该值的社区不生成报告
+	maxReportEntities  = 50  // 单个报告提示词中的实体上限, 按度数取前 N 个
+	maxReportRelations = 100 // 单个报告提示词中的关系上限, 按权重取前 N 个
+)
+
+// Reporter 调用 LLM 为社区生成报告
+type Reporter struct {
+	llm model.BaseChatModel
+}
+
+func NewReporter(llm model.BaseChatModel) *Reporter {
+	return &Reporter{llm: llm}
+}
+
+// reportOutput LLM 输出的报告 JSON
+type reportOutput struct {
+	Title             string                   `json:"title"`
+	Summary           string                   `json:"summary"`
+	Rating            float64                  `json:"rating"`
+	RatingExplanation string                   `json:"rating_explanation"`
+	Findings          []graph.CommunityFinding `json:"findings"`
+}
+
+// Generate 根据社区内的实体和关系生成报告, entities 为知识库实体 (按名称索引), relations 可包含社区外的关系
+func (r *Reporter) Generate(ctx context.Context, kbId string, c Community, entities map[string]types.Entity, relations []types.Relation) (*graph.CommunityReport, error) {
+	entityDf, relationDf := buildTables(c, entities, relations)
+
+	resp, err := r.llm.Generate(ctx, prompt.NewCommunityReportPrompt(entityDf, relationDf))
+	if err != nil {
+		return nil, fmt.Errorf("生成社区报告失败: %w", err)
+	}
+	output, err := parseReport(resp.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	return &graph.CommunityReport{
+		Id:                ReportId(kbId, c.Entities),
+		KbId:              kbId,
+		CommunityId:       c.Id,
+		Title:             output.Title,
+		Summary:           output.Summary,
+		Rating:            output.Rating,
+		RatingExplanation: output.RatingExplanation,
+		Findings:          output.Findings,
+		Entities:          c.Entities,
+		Content:           reportContent(output),
+		UpdatedAt:         time.Now().Format(time.RFC3339),
+	}, nil
+}
+
+// ReuseReports 成员集合未变化的社区沿用已有报告 (含向量), 返回沿用的报告及需要重新生成报告的社区
+func ReuseReports(kbId string, communities []Community, existing []*graph.CommunityReport) ([]*graph.CommunityReport, []Community) {
+	byId := make(map[string]*graph.CommunityReport, len(existing))
+	for _, r := range existing {
+		byId[r.Id] = r
+	}
+
+	var (
+		reused  []*graph.CommunityReport
+		pending []Community
+	)
+	for _, c := range communities {
+		old, ok := byId[ReportId(kbId, c.Entities)]
+		if !ok || len(old.Embedding) == 0 {
+			pending = append(pending, c)
+			continue
+		}
+		report := *old
+		report.CommunityId = c.Id
+		reused = append(reused, &report)
+	}
+	return reused, pending
+}
+
+// ReportId 按社区成员生成稳定的报告 ID, 成员不变时重建社区后 ID 保持不变
+func ReportId(kbId string, members []string) string {
+	sorted := append([]string(nil), members...)
+	sort.Strings(sorted)
+	hash := md5.Sum([]byte(kbId + "\x00" + strings.Join(sorted, "\x00")))
+	return "community_" + hex.EncodeToString(hash[:])
+}
+
+// buildTables 生成提示词中的实体表和关系表 (CSV), 只保留社区内部的关系
+func buildTables(c Community, entities map[string]types.Entity, relations []types.Relation) (string, string) {
+	members := make(map[string]struct{}, len(c.Entities))
+	for _, name := range c.Entities {
+		members[name] = struct{}{}
+	}
+
+	var inner []types.Relation
+	degree := make(map[string]int)
+	for _, rel := range relations {
+		_, srcOk := members[rel.SrcId]
+		_, dstOk := members[rel.DstId]
+		if srcOk && dstOk {
+			inner = append(inner, rel)
+			degree[rel.SrcId]++
+			degree[rel.DstId]++
+		}
+	}
+
+	names := append([]string(nil), c.Entities...)
+	sort.SliceStable(names, func(i, j int) bool { return degree[names[i]] > degree[names[j]] })
+	if len(names) > maxReportEntities {
+		names = names[:maxReportEntities]
+	}
+	sort.SliceStable(inner, func(i, j int) bool { return inner[i].Weight > inner[j].Weight })
+	if len(inner) > maxReportRelations {
+		inner = inner[:maxReportRelations]
+	}
+
+	entityRows := [][]string{{"id", "entity", "description"}}
+	for i, name := range names {
+		entityRows = append(entityRows, []string{strconv.Itoa(i), name, entities[name].Description})
+	}
+	relationRows := [][]string{{"id", "source", "target", "description"}}
+	for i, rel := range inner {
+		relationRows = append(relationRows, []string{strconv.Itoa(i), rel.SrcId, rel.DstId, rel.Description})
+	}
+	return toCSV(entityRows), toCSV(relationRows)
+}
+
+func toCSV(rows [][]string) string {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.WriteAll(rows)
+	return buf.String()
+}
+
+// parseReport 解析 LLM 输出的 JSON, 兼容 ```json 代码块及前后多余文本
+func parseReport(content string) (*reportOutput, error) {
+	start := strings.Index(content, "{")
+	end := strings.LastIndex(content, "}")
+	if start < 0 || end <= start {
+		return nil, fmt.Errorf("社区报告不是合法的 JSON: %s", content)
+	}
+
+	var output reportOutput
+	if err := json.Unmarshal([]byte(content[start:end+1]), &output); err != nil {
+		return nil, fmt.Errorf("解析社区报告失败: %w", err)
+	}
+	if output.Title == "" && output.Summary == "" {
+		return nil, fmt.Errorf("社区报告缺少标题和摘要")
+	}
+	return &output, nil
+}
+
+// reportContent 拼接报告全文, 用于生成向量和全文检索
+func reportContent(output *reportOutput) string {
+	var sb strings.Builder
+	sb.WriteString(output.Title)
+	sb.WriteString("\n")
+	sb.WriteString(output.Summary)
+	for _, f := range output.Findings {
+		sb.WriteString("\n")
+		sb.WriteString(f.Summary)
+		sb.WriteString(": ")
+		sb.WriteString(f.Explanation)
+	}
+	return sb.String()
+}
@@ -0,0 +1,92 @@
+package extractor
+
+import (
+	"strings"
+
+	"gozero-rag/internal/graphrag/types"
+)
+
+// entityTypeAliases 常见的同义类型, 仅当目标类型在配置中时才映射
+var entityTypeAliases = map[string]string{
+	"location":     "geo",
+	"place":        "geo",
+	"country":      "geo",
+	"city":         "geo",
+	"org":          "organization",
+	"organisation": "organization",
+	"company":      "organization",
+	"people":       "person",
+	"human":        "person",
+}
+
+// entityTypeMapper 将 LLM 输出的实体类型映射到配置的类型集合
+type entityTypeMapper struct {
+	entityTypes []string
+	allowed     map[string]string // 归一化类型 -> 配置中的原始写法
+}
+
+func newEntityTypeMapper(entityTypes []string) *entityTypeMapper {
+	if len(entityTypes) == 0 {
+		entityTypes = types.DefaultEntityTypes
+	}
+	m := &entityTypeMapper{
+		entityTypes: entityTypes,
+		allowed:     make(map[string]string, len(entityTypes)),
+	}
+	for _, t := range entityTypes {
+		m.allowed[normalizeEntityType(t)] = strings.TrimSpace(t)
+	}
+	return m
+}
+
+// normalizeEntityType 忽略大小写、引号和空白差异, 如 "Legal Party" -> legal_party
+func normalizeEntityType(t string) string {
+	t = strings.ToLower(strings.Trim(strings.TrimSpace(t), `"'`))
+	return strings.Join(strings.FieldsFunc(t, func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_'
+	}), "_")
+}
+
+// Map 返回配置中的类型, 无法映射时返回 false
+func (m *entityTypeMapper) Map(t string) (string, bool) {
+	key := normalizeEntityType(t)
+	if allowed, ok := m.allowed[key]; ok {
+		return allowed, true
+	}
+	if alias, ok := entityTypeAliases[key]; ok {
+		if allowed, ok := m.allowed[alias]; ok {
+			return allowed, true
+		}
+	}
+	return "", false
+}
+
+// Filter 映射实体类型, 丢弃配置之外的实体以及以其为端点的关系
+func (m *entityTypeMapper) Filter(entities []types.Entity, relations []types.Relation) ([]types.Entity, []types.Relation) {
+	kept := make([]types.Entity, 0, len(entities))
+	dropped := make(map[string]struct{})
+	for _, e := range entities {
+		t, ok := m.Map(e.Type)
+		if !ok {
+			dropped[e.Name] = struct{}{}
+			continue
+		}
+		e.Type = t
+		kept = append(kept, e)
+	}
+	if len(dropped) == 0 {
+		return kept, relations
+	}
+
+	keptRelations := make([]types.Relation, 0, len(relations))
+	for _, r := range relations {
+		if _, ok := dropped[r.SrcId]; ok {
+			continue
+		}
+		if _, ok := dropped[r.DstId]; ok {
+			continue
+		}
+		keptRelations = append(keptRelations, r)
+	}
+	return kept, keptRelations
+}
@@ -0,0 +1,56 @@
+package extractor
+
+import (
+	"testing"
+
+	"gozero-rag/internal/graphrag/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntityTypeMapper_Map(t *testing.T) {
+	m := newEntityTypeMapper([]string{"Statute", "party", "court", "geo"})
+
+	got, ok := m.Map(`"statute"`)
+	assert.True(t, ok)
+	assert.Equal(t, "Statute", got)
+
+	got, ok = m.Map("Location")
+	assert.True(t, ok)
+	assert.Equal(t, "geo", got)
+
+	// 别名目标不在配置中时不映射
+	_, ok = m.Map("company")
+	assert.False(t, ok)
+	_, ok = m.Map("person")
+	assert.False(t, ok)
+}
+
+func TestEntityTypeMapper_Default(t *testing.T) {
+	m := newEntityTypeMapper(nil)
+	assert.Equal(t, types.DefaultEntityTypes, m.entityTypes)
+
+	got, ok := m.Map("Organisation")
+	assert.True(t, ok)
+	assert.Equal(t, "organization", got)
+}
+
+func TestEntityTypeMapper_Filter(t *testing.T) {
+	m := newEntityTypeMapper([]string{"statute", "party"})
+
+	entities, relations := m.Filter(
+		[]types.Entity{
+			{Name: "民法典", Type: "Statute"},
+			{Name: "张三", Type: "party"},
+			{Name: "北京", Type: "geo"},
+		},
+		[]types.Relation{
+			{SrcId: "张三", DstId: "民法典"},
+			{SrcId: "张三", DstId: "北京"},
+		},
+	)
+
+	assert.Len(t, entities, 2)
+	assert.Equal(t, "statute", entities[0].Type)
+	assert.Equal(t, []types.Relation{{SrcId: "张三", DstId: "民法典"}}, relations)
+}
@@ -45,6 +45,7 @@ type GraphExtractionState struct {
 	IsDone        bool
 	CurrentPrompt []*schema.Message
 	CurrentOutput string
+	EntityTypes   []string // 抽取的实体类型, 为空时使用默认类型
 	LLM           model.ToolCallingChatModel
 }
 
@@ -58,9 +59,9 @@ func NewGraphExtractor(ctx context.Context) (*GraphExtractor, error) {
 		var err error
 
 		if state.LoopCount == 0 {
-			msgs, err = prompt.NewGraphPrompt(state.InputText)
+			msgs, err = prompt.NewGraphPrompt(state.InputText, state.EntityTypes)
 		} else {
-			msgs, err = prompt.NewGraphLoopPrompt(state.History)
+			msgs, err = prompt.NewGraphLoopPrompt(state.History, state.EntityTypes)
 		}
 		if err != nil {
 			return nil, err
@@ -115,8 +116,10 @@ func NewGraphExtractor(ctx context.Context) (*GraphExtractor, error) {
 	return &GraphExtractor{runnable: r}, nil
 }
 
-func (e *GraphExtractor) Extract(ctx context.Context, chunks []*chunk.Chunk, llm model.ToolCallingChatModel) (*types.GraphExtractionResult, error) {
+// Extract 逐个 chunk 抽取实体和关系, 实体类型不在 entityTypes 中的实体会被映射或丢弃
+func (e *GraphExtractor) Extract(ctx context.Context, chunks []*chunk.Chunk, llm model.ToolCallingChatModel, entityTypes []string) (*types.GraphExtractionResult, error) {
 	runnable := e.runnable
+	mapper := newEntityTypeMapper(entityTypes)
 
 	result := &types.GraphExtractionResult{
 		Entities:  make([]types.Entity, 0),
@@ -127,11 +130,12 @@ func (e *GraphExtractor) Extract(ctx context.Context, chunks []*chunk.Chunk, llm
 	cl := len(chunks)
 	for i, c := range chunks {
 		state := &GraphExtractionState{
-			InputText: c.Content,
-			History:   "",
-			LoopCount: 0,
-			IsDone:    false,
-			LLM:       llm,
+			InputText:   c.Content,
+			History:     "",
+			LoopCount:   0,
+			IsDone:      false,
+			EntityTypes: mapper.entityTypes,
+			LLM:         llm,
 		}
 
 		for state.LoopCount < MaxLoops && !state.IsDone {
@@ -146,6 +150,10 @@ func (e *GraphExtractor) Extract(ctx context.Context, chunks []*chunk.Chunk, llm
 
 		// Parse the accumulated history (which contains all results)
 		entities, relations := e.parseHistory(state.History, c.Id)
+		if kept, keptRelations := mapper.Filter(entities, relations); len(kept) < len(entities) {
+			logx.Infof("chunk %s 丢弃 %d 个类型不在配置中的实体", c.Id, len(entities)-len(kept))
+			entities, relations = kept, keptRelations
+		}
 		result.Entities = append(result.Entities, entities...)
 		result.Relations = append(result.Relations, relations...)
 		logx.Infof("知识图谱提取进度: %d/%d", i+1, cl)
@@ -162,6 +170,7 @@ func (e *GraphExtractor) ParallelExtract(
 	ctx context.Context,
 	chunks []*chunk.Chunk,
 	llm model.ToolCallingChatModel,
+	entityTypes []string,
 	numParts int,
 	concurrency int,
 ) (*types.GraphExtractionResult, error) {
@@ -191,7 +200,7 @@ func (e *GraphExtractor) ParallelExtract(
 			logx.Infof("开始提取第 %d 部分 (%d chunks)", partIndex+1, len(partChunks))
 
 			// 调用原有的 Extract 处理这部分 chunks
-			partResult, err := e.Extract(gctx, partChunks, llm)
+			partResult, err := e.Extract(gctx, partChunks, llm, entityTypes)
 			if err != nil {
 				logx.Errorf("提取第 %d 部分失败: %v", partIndex+1, err)
 				return err
@@ -316,6 +325,12 @@ func logCallback() callbacks.Handler {
 
 // Merge merges multiple GraphExtractionResults into one
 func (e *GraphExtractor) Merge(results []*types.GraphExtractionResult) *types.GraphExtractionResult {
+	return MergeResults(results)
+}
+
+// MergeResults 合并多份抽取结果, 同名实体/同向关系合并溯源信息和描述
+// 也用于按文档贡献重新计算知识库中的实体和关系
+func MergeResults(results []*types.GraphExtractionResult) *types.GraphExtractionResult {
 	mergedEntities := make(map[string]types.Entity)
 	mergedRelations := make(map[string]types.Relation)
 
@@ -326,6 +341,11 @@ func (e *GraphExtractor) Merge(results []*types.GraphExtractionResult) *types.Gr
 				existing.SourceId = append(existing.SourceId, entity.SourceId...)
 				// Deduplicate SourceId
 				existing.SourceId = uniqueStrings(existing.SourceId)
+				existing.DocIds = uniqueStrings(append(existing.DocIds, entity.DocIds...))
+				existing.Aliases = uniqueStrings(append(existing.Aliases, entity.Aliases...))
+				if existing.Type == "" {
+					existing.Type = entity.Type
+				}
 				// Merge Description (simple concatenation for now, or keep longest?)
 				// Let's keep the longest description as it might be more detailed,
 				// or concatenate if they are different.
@@ -344,6 +364,7 @@ func (e *GraphExtractor) Merge(results []*types.GraphExtractionResult) *types.Gr
 			if existing, ok := mergedRelations[key]; ok {
 				existing.SourceId = append(existing.SourceId, relation.SourceId...)
 				existing.SourceId = uniqueStrings(existing.SourceId)
+				existing.DocIds = uniqueStrings(append(existing.DocIds, relation.DocIds...))
 				// Average weight
 				existing.Weight = (existing.Weight + relation.Weight) / 2
 				// Merge Description
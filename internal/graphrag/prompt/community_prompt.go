@@ -1,5 +1,11 @@
 package prompt
 
+import (
+	"strings"
+
+	"github.com/cloudwego/eino/schema"
+)
+
 const COMMUNITY_REPORT = `
 你是一名 AI 助手，负责协助人类分析师进行通用信息发现。信息发现是指在网络中识别和评估与特定实体（例如组织和个人）相关的相关信息的过程。
 
@@ -149,3 +155,10 @@ Output:
 
 输出 (Output):
 `
+
+// NewCommunityReportPrompt 生成社区报告提示词, entityDf / relationDf 为 CSV 格式的实体和关系表
+// COMMUNITY_REPORT 中包含 JSON 示例, 不能使用 FString 模板, 直接替换占位符
+func NewCommunityReportPrompt(entityDf, relationDf string) []*schema.Message {
+	content := strings.NewReplacer("{entity_df}", entityDf, "{relation_df}", relationDf).Replace(COMMUNITY_REPORT)
+	return []*schema.Message{schema.UserMessage(content)}
+}
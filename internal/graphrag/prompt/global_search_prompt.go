@@ -0,0 +1,48 @@
+package prompt
+
+import (
+	"strings"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+const GLOBAL_SEARCH_MAP = `
+你是一名乐于助人的助手，负责根据提供的数据表回答关于数据集的问题。
+
+# 目标
+生成一个由关键要点组成的回答，用于回答用户的问题，并总结数据表中所有相关的信息。
+
+你应该使用下方数据表中提供的数据作为生成回答的主要依据。
+如果你不知道答案，或者数据表中没有足够的信息来给出答案，就直接说不知道，不要编造任何内容。
+
+回答中的每个关键要点应包含以下元素：
+- 描述 (description)：对该要点的全面描述。
+- 重要性评分 (score)：一个 0-100 之间的整数，表示该要点在回答用户问题时的重要程度。"不知道" 类型的回答评分应为 0。
+
+回答应按如下 JSON 格式输出（使用与用户问题相同的语言）：
+{
+    "points": [
+        {"description": "要点 1 的描述 [Data: Reports (报告id)]", "score": 评分},
+        {"description": "要点 2 的描述 [Data: Reports (报告id)]", "score": 评分}
+    ]
+}
+
+由数据支持的要点应列出相关报告作为引用，例如 "[Data: Reports (2, 7, 64)]"。
+不要在单个引用中列出超过 5 个报告 id，超过时列出前 5 个最相关的 id 并添加 "+more"。
+不要包含未提供支持证据的信息。
+
+# 用户问题
+{query}
+
+# 数据表 (社区报告)
+{context_data}
+
+输出 (Output):
+`
+
+// NewGlobalSearchMapPrompt 生成全局检索 map 阶段的提示词, contextData 为一批社区报告
+// GLOBAL_SEARCH_MAP 中包含 JSON 示例, 不能使用 FString 模板, 直接替换占位符
+func NewGlobalSearchMapPrompt(query, contextData string) []*schema.Message {
+	content := strings.NewReplacer("{query}", query, "{context_data}", contextData).Replace(GLOBAL_SEARCH_MAP)
+	return []*schema.Message{schema.UserMessage(content)}
+}
@@ -2,15 +2,15 @@ package prompt
 
 import (
 	"context"
-	"strings"
 
 	einoprompt "github.com/cloudwego/eino/components/prompt"
 	"github.com/cloudwego/eino/schema"
 )
 
-func NewGraphLoopPrompt(history string) ([]*schema.Message, error) {
+// NewGraphLoopPrompt 生成补充抽取 (gleaning) 提示词, 实体类型与首轮保持一致
+func NewGraphLoopPrompt(history string, entityTypes []string) ([]*schema.Message, error) {
 	variables := map[string]any{
-		"entity_types":         strings.Join([]string{"organization", "person", "geo", "event", "category"}, ","),
+		"entity_types":         joinEntityTypes(entityTypes),
 		"tuple_delimiter":      "<|>",
 		"record_delimiter":     "##",
 		"completion_delimiter": "<|DONE|>",
@@ -26,6 +26,7 @@ You just identified the following entities and relationships:
 {history}
 
 Are there any other entities or relationships that you missed? 
+Only extract entities of the following types: [{entity_types}]
 - If yes, please list them in the same format.
 - If no, please output {completion_delimiter}
 `))
@@ -4,13 +4,16 @@ import (
 	"context"
 	"strings"
 
+	"gozero-rag/internal/graphrag/types"
+
 	einoprompt "github.com/cloudwego/eino/components/prompt"
 	"github.com/cloudwego/eino/schema"
 )
 
-func NewGraphPrompt(inputText string) ([]*schema.Message, error) {
+// NewGraphPrompt 生成首轮抽取提示词, entityTypes 为空时使用默认实体类型
+func NewGraphPrompt(inputText string, entityTypes []string) ([]*schema.Message, error) {
 	variables := map[string]any{
-		"entity_types":         strings.Join([]string{"organization", "person", "geo", "event", "category"}, ","),
+		"entity_types":         joinEntityTypes(entityTypes),
 		"relation_types":       strings.Join([]string{"owns", "works_for", "invested_in", "collaborates_with", "belongs_to", "located_in", "founded", "manages", "produces", "related_to"}, ","),
 		"tuple_delimiter":      "<|>",
 		"record_delimiter":     "##",
@@ -22,6 +25,13 @@ func NewGraphPrompt(inputText string) ([]*schema.Message, error) {
 	return result, err
 }
 
+func joinEntityTypes(entityTypes []string) string {
+	if len(entityTypes) == 0 {
+		entityTypes = types.DefaultEntityTypes
+	}
+	return strings.Join(entityTypes, ",")
+}
+
 var graphTemplate = einoprompt.FromMessages(schema.FString, schema.SystemMessage(`-Goal-
 Given a text document that is potentially relevant to this activity and a list of entity types, identify all entities of those types from the text and all relationships among the identified entities.
 
@@ -1,13 +1,34 @@
 package prompt
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestPrompt(t *testing.T) {
 
-	p, err := NewGraphPrompt("eino adk构建第一个AI智能体")
+	p, err := NewGraphPrompt("eino adk构建第一个AI智能体", nil)
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	t.Logf("prompt:%v", p)
 }
+
+func TestPrompt_EntityTypes(t *testing.T) {
+	msgs, err := NewGraphPrompt("民法典第一千一百六十五条", []string{"statute", "party", "court"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(msgs[0].Content, "[statute,party,court]") {
+		t.Errorf("entity types not injected: %s", msgs[0].Content)
+	}
+
+	msgs, err = NewGraphLoopPrompt("", []string{"statute", "party", "court"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(msgs[0].Content, "[statute,party,court]") {
+		t.Errorf("entity types not injected into loop prompt: %s", msgs[0].Content)
+	}
+}
@@ -0,0 +1,25 @@
+package prompt
+
+import (
+	"context"
+
+	einoprompt "github.com/cloudwego/eino/components/prompt"
+	"github.com/cloudwego/eino/schema"
+)
+
+// NewEntityResolutionPrompt 生成实体归一化确认提示词, pairs 为编号后的候选实体对
+func NewEntityResolutionPrompt(pairs string) ([]*schema.Message, error) {
+	return entityResolutionTemplate.Format(context.Background(), map[string]any{
+		"pairs": pairs,
+	})
+}
+
+var entityResolutionTemplate = einoprompt.FromMessages(schema.FString, schema.SystemMessage(`你是一名知识图谱实体归一化专家。
+下面列出了若干组候选实体对, 每组包含两个实体的名称、类型和描述。
+请判断每组中的两个实体是否指向现实世界中的同一个对象 (例如全称与简称、中英文名称、同一对象的不同写法)。
+仅仅是相关、同类或存在上下级关系的实体不算同一个对象。
+
+输出要求:
+- 每组输出一行, 格式为 <编号>: yes 或 <编号>: no
+- 不要输出任何其他内容
+`), schema.UserMessage(`{pairs}`))
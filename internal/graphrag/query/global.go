@@ -0,0 +1,167 @@
+package query
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gozero-rag/internal/graphrag/prompt"
+	"gozero-rag/internal/model/graph"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/zeromicro/go-zero/core/logx"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	maxGlobalReports = 50   // 参与全局检索的社区报告上限, 按评分从高到低选取
+	reportsPerBatch  = 8    // map 阶段每次 LLM 调用处理的报告数
+	mapConcurrency   = 4    // map 阶段并发调用 LLM 的数量
+	maxContextRunes  = 8000 // reduce 后上下文的最大字符数
+)
+
+// ReportSource 社区报告来源, 由 graph.CommunityModel 实现
+type ReportSource interface {
+	List(ctx context.Context, kbId string, page, pageSize int64) ([]*graph.CommunityReport, int64, error)
+}
+
+// KeyPoint map 阶段 LLM 从社区报告中提取的关键要点
+type KeyPoint struct {
+	Description string `json:"description"`
+	Score       int    `json:"score"` // 重要性评分 (0-100)
+}
+
+// GlobalResult 全局检索结果
+type GlobalResult struct {
+	Points  []KeyPoint               // 按评分从高到低排列, 已去掉 0 分要点并按上下文长度截断
+	Reports []*graph.CommunityReport // 参与检索的社区报告
+	Context string                   // 拼接后的上下文, 用于回答生成
+}
+
+// GlobalSearcher 全局检索: 对社区报告做 map-reduce, 适合 "主要主题有哪些" 这类面向整个知识库的问题
+// map: 分批让 LLM 从报告中提取与问题相关的要点并打分; reduce: 汇总全部要点按评分截断为上下文
+type GlobalSearcher struct {
+	reports ReportSource
+	llm     model.BaseChatModel
+}
+
+func NewGlobalSearcher(reports ReportSource, llm model.BaseChatModel) *GlobalSearcher {
+	return &GlobalSearcher{reports: reports, llm: llm}
+}
+
+// Search 知识库没有社区报告时返回空结果
+func (s *GlobalSearcher) Search(ctx context.Context, kbId string, query string) (*GlobalResult, error) {
+	reports, _, err := s.reports.List(ctx, kbId, 1, maxGlobalReports)
+	if err != nil {
+		return nil, fmt.Errorf("查询社区报告失败: %w", err)
+	}
+	if len(reports) == 0 {
+		return &GlobalResult{}, nil
+	}
+
+	var (
+		mu     sync.Mutex
+		points []KeyPoint
+		failed int
+	)
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(mapConcurrency)
+	batches := 0
+	for start := 0; start < len(reports); start += reportsPerBatch {
+		batch := reports[start:min(start+reportsPerBatch, len(reports))]
+		batches++
+		g.Go(func() error {
+			batchPoints, err := s.mapReports(gCtx, query, batch)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				// 单批失败不影响其他批次
+				logx.Errorf("[GlobalSearcher] map 阶段失败, kb_id=%s, err=%v", kbId, err)
+				failed++
+				return nil
+			}
+			points = append(points, batchPoints...)
+			return nil
+		})
+	}
+	_ = g.Wait()
+	if failed == batches {
+		return nil, fmt.Errorf("全局检索失败: %d 个批次全部失败", batches)
+	}
+
+	points, content := reducePoints(points)
+	logx.Infof("[GlobalSearcher] 检索完成, kb_id=%s, 报告 %d 个, 要点 %d 个", kbId, len(reports), len(points))
+	return &GlobalResult{
+		Points:  points,
+		Reports: reports,
+		Context: content,
+	}, nil
+}
+
+func (s *GlobalSearcher) mapReports(ctx context.Context, query string, reports []*graph.CommunityReport) ([]KeyPoint, error) {
+	resp, err := s.llm.Generate(ctx, prompt.NewGlobalSearchMapPrompt(query, buildReportTable(reports)))
+	if err != nil {
+		return nil, fmt.Errorf("LLM 调用失败: %w", err)
+	}
+	return parsePoints(resp.Content)
+}
+
+// buildReportTable 生成 map 阶段提示词中的报告表 (CSV), id 使用社区编号以便 LLM 引用
+func buildReportTable(reports []*graph.CommunityReport) string {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{"id", "title", "rating", "content"})
+	for _, r := range reports {
+		_ = w.Write([]string{strconv.Itoa(r.CommunityId), r.Title, strconv.FormatFloat(r.Rating, 'f', 1, 64), r.Content})
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// parsePoints 解析 map 阶段输出的 JSON, 兼容 ```json 代码块及前后多余文本
+func parsePoints(content string) ([]KeyPoint, error) {
+	start := strings.Index(content, "{")
+	end := strings.LastIndex(content, "}")
+	if start < 0 || end <= start {
+		return nil, fmt.Errorf("map 输出不是合法的 JSON: %s", content)
+	}
+
+	var output struct {
+		Points []KeyPoint `json:"points"`
+	}
+	if err := json.Unmarshal([]byte(content[start:end+1]), &output); err != nil {
+		return nil, fmt.Errorf("解析 map 输出失败: %w", err)
+	}
+	return output.Points, nil
+}
+
+// reducePoints 去掉 0 分要点, 按评分从高到低拼接上下文, 超过 maxContextRunes 时截断
+func reducePoints(points []KeyPoint) ([]KeyPoint, string) {
+	kept := make([]KeyPoint, 0, len(points))
+	for _, p := range points {
+		if p.Score > 0 && strings.TrimSpace(p.Description) != "" {
+			kept = append(kept, p)
+		}
+	}
+	sort.SliceStable(kept, func(i, j int) bool { return kept[i].Score > kept[j].Score })
+
+	var sb strings.Builder
+	runes := 0
+	for i, p := range kept {
+		section := fmt.Sprintf("----- 分析 %d -----\n重要性评分: %d\n%s\n", i+1, p.Score, p.Description)
+		n := len([]rune(section))
+		if runes+n > maxContextRunes {
+			kept = kept[:i]
+			break
+		}
+		sb.WriteString(section)
+		runes += n
+	}
+	return kept, strings.TrimSuffix(sb.String(), "\n")
+}
@@ -0,0 +1,117 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"gozero-rag/internal/model/graph"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeReports struct {
+	reports []*graph.CommunityReport
+}
+
+func (f *fakeReports) List(_ context.Context, _ string, _, pageSize int64) ([]*graph.CommunityReport, int64, error) {
+	n := min(int(pageSize), len(f.reports))
+	return f.reports[:n], int64(len(f.reports)), nil
+}
+
+// fakeLlm 按提示词中包含的报告标题返回要点
+type fakeLlm struct {
+	mu      sync.Mutex
+	calls   int
+	answers map[string]string
+	fail    bool
+}
+
+func (f *fakeLlm) Generate(_ context.Context, msgs []*schema.Message, _ ...model.Option) (*schema.Message, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.fail {
+		return nil, errors.New("llm unavailable")
+	}
+	var points []string
+	for title, answer := range f.answers {
+		if strings.Contains(msgs[0].Content, title) {
+			points = append(points, answer)
+		}
+	}
+	return schema.AssistantMessage("```json\n{\"points\": ["+strings.Join(points, ",")+"]}\n```", nil), nil
+}
+
+func (f *fakeLlm) Stream(context.Context, []*schema.Message, ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	return nil, nil
+}
+
+func newReports(n int) []*graph.CommunityReport {
+	reports := make([]*graph.CommunityReport, 0, n)
+	for i := 0; i < n; i++ {
+		reports = append(reports, &graph.CommunityReport{
+			CommunityId: i,
+			Title:       fmt.Sprintf("社区%02d", i),
+			Rating:      float64(10 - i%10),
+			Content:     "内容",
+		})
+	}
+	return reports
+}
+
+func TestGlobalSearcher_Search(t *testing.T) {
+	llm := &fakeLlm{answers: map[string]string{
+		"社区00": `{"description": "电商平台是主要主题 [Data: Reports (0)]", "score": 60}`,
+		"社区03": `{"description": "不知道", "score": 0}`,
+		"社区09": `{"description": "支付安全同样重要 [Data: Reports (9)]", "score": 90}`,
+	}}
+
+	result, err := NewGlobalSearcher(&fakeReports{reports: newReports(10)}, llm).Search(context.Background(), "kb-1", "主要主题有哪些?")
+	require.NoError(t, err)
+
+	// 10 个报告分为 2 批
+	assert.Equal(t, 2, llm.calls)
+	assert.Len(t, result.Reports, 10)
+	require.Len(t, result.Points, 2)
+	assert.Equal(t, 90, result.Points[0].Score)
+	assert.Equal(t, 60, result.Points[1].Score)
+	assert.True(t, strings.Index(result.Context, "支付安全") < strings.Index(result.Context, "电商平台"))
+	assert.NotContains(t, result.Context, "不知道")
+}
+
+func TestGlobalSearcher_NoReports(t *testing.T) {
+	llm := &fakeLlm{}
+	result, err := NewGlobalSearcher(&fakeReports{}, llm).Search(context.Background(), "kb-1", "主要主题有哪些?")
+	require.NoError(t, err)
+	assert.Empty(t, result.Points)
+	assert.Equal(t, 0, llm.calls)
+}
+
+func TestGlobalSearcher_AllBatchesFailed(t *testing.T) {
+	_, err := NewGlobalSearcher(&fakeReports{reports: newReports(3)}, &fakeLlm{fail: true}).Search(context.Background(), "kb-1", "q")
+	assert.Error(t, err)
+}
+
+func TestReducePoints_Truncate(t *testing.T) {
+	long := strings.Repeat("长", maxContextRunes/2)
+	points, content := reducePoints([]KeyPoint{
+		{Description: long, Score: 50},
+		{Description: long, Score: 80},
+		{Description: long, Score: 70},
+	})
+	require.Len(t, points, 1)
+	assert.Equal(t, 80, points[0].Score)
+	assert.LessOrEqual(t, len([]rune(content)), maxContextRunes)
+}
+
+func TestParsePoints_Invalid(t *testing.T) {
+	_, err := parsePoints("无法回答")
+	assert.Error(t, err)
+}
@@ -0,0 +1,7 @@
+package query
+
+// 对话中可选的图谱检索模式
+const (
+	ModeLocal  = "local"  // 局部检索: 以命中实体为中心, 返回实体、关系及其来源分片, 由 retriever 的 graph 模式实现
+	ModeGlobal = "global" // 全局检索: 对社区报告做 map-reduce, 见 GlobalSearcher
+)
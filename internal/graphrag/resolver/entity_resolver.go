@@ -0,0 +1,466 @@
+package resolver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gozero-rag/internal/graphrag/prompt"
+	"gozero-rag/internal/graphrag/types"
+	"gozero-rag/internal/model/graph"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/zeromicro/go-zero/core/logx"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	candidateTopK       = 5   // 每个实体在知识库中检索的相似实体数
+	candidateThreshold  = 0.8 // 综合相似度阈值, 超过后交给 LLM 确认
+	embeddingWeight     = 0.7 // 综合相似度中向量相似度的权重, 其余为名称相似度
+	maxPairwiseEntities = 500 // 批内两两比较的实体数上限, 超过时只与知识库比较
+	confirmBatchSize    = 20  // 每次请求 LLM 确认的候选对数量
+	searchConcurrency   = 8   // 知识库相似实体检索并发数
+	maxDescriptionRunes = 200 // 提示词中实体描述的最大字符数
+)
+
+// EntitySearcher 在知识库图谱中检索相似实体, 由 graph.GraphModel 实现
+type EntitySearcher interface {
+	SearchSimilarEntities(ctx context.Context, kbId string, vector []float64, topK int) ([]*graph.SimilarEntity, error)
+}
+
+// EntityResolver 实体归一化: 召回候选重复实体 (向量相似度 + 名称相似度), 由 LLM 确认后合并
+type EntityResolver struct {
+	searcher EntitySearcher
+	llm      model.BaseChatModel
+}
+
+// Resolution 归一化结果
+type Resolution struct {
+	// Result 合并后的实体和关系, 关系端点已改写为规范实体
+	Result *types.GraphExtractionResult
+	// Aliases 别名 -> 规范实体名, 存储中已存在的别名实体需要合并到规范实体
+	Aliases map[string]string
+}
+
+func NewEntityResolver(searcher EntitySearcher, llm model.BaseChatModel) *EntityResolver {
+	return &EntityResolver{
+		searcher: searcher,
+		llm:      llm,
+	}
+}
+
+// candidatePair 待 LLM 确认的候选实体对
+type candidatePair struct {
+	a, b  string
+	score float64
+}
+
+// Resolve 对本次抽取的实体做跨文档归一化, 候选既包括批内实体也包括知识库中已有的实体
+func (r *EntityResolver) Resolve(ctx context.Context, kbId string, result *types.GraphExtractionResult) (*Resolution, error) {
+	entities := make(map[string]*types.Entity, len(result.Entities))
+	batch := make(map[string]bool, len(result.Entities))
+	for i := range result.Entities {
+		e := result.Entities[i]
+		entities[e.Name] = &e
+		batch[e.Name] = true
+	}
+
+	pairs := pairwiseCandidates(result.Entities)
+	existing, kbPairs, err := r.searchCandidates(ctx, kbId, result.Entities, batch)
+	if err != nil {
+		return nil, err
+	}
+	for name, e := range existing {
+		entities[name] = e
+	}
+	pairs = dedupePairs(append(pairs, kbPairs...))
+	if len(pairs) == 0 {
+		return &Resolution{Result: result, Aliases: map[string]string{}}, nil
+	}
+
+	confirmed := r.confirm(ctx, pairs, entities)
+	logx.Infof("实体归一化: 候选 %d 对, 确认合并 %d 对", len(pairs), len(confirmed))
+
+	return applyMerges(result, entities, batch, confirmed), nil
+}
+
+// searchCandidates 在知识库中为每个实体检索相似实体, 返回命中的已有实体及候选对
+func (r *EntityResolver) searchCandidates(ctx context.Context, kbId string, batchEntities []types.Entity, batch map[string]bool) (map[string]*types.Entity, []candidatePair, error) {
+	var (
+		mu       sync.Mutex
+		existing = make(map[string]*types.Entity)
+		pairs    []candidatePair
+	)
+
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(searchConcurrency)
+	for i := range batchEntities {
+		e := batchEntities[i]
+		if len(e.Embedding) == 0 {
+			continue
+		}
+		g.Go(func() error {
+			hits, err := r.searcher.SearchSimilarEntities(gCtx, kbId, e.Embedding, candidateTopK)
+			if err != nil {
+				return fmt.Errorf("检索相似实体失败: %w", err)
+			}
+			for _, hit := range hits {
+				// 批内实体由两两比较覆盖, 同名实体即为自身
+				if hit.EntityName == e.Name || batch[hit.EntityName] {
+					continue
+				}
+				other := entityFromDoc(hit.EsGraphDocument)
+				if !sameType(e.Type, other.Type) {
+					continue
+				}
+				score := combinedSimilarity(hit.Similarity, nameSimilarity(e.Name, other.Name))
+				if score < candidateThreshold {
+					continue
+				}
+				mu.Lock()
+				existing[other.Name] = other
+				pairs = append(pairs, candidatePair{a: e.Name, b: other.Name, score: score})
+				mu.Unlock()
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, nil, err
+	}
+	return existing, pairs, nil
+}
+
+// pairwiseCandidates 批内实体两两比较
+func pairwiseCandidates(entities []types.Entity) []candidatePair {
+	if len(entities) > maxPairwiseEntities {
+		logx.Infof("实体数 %d 超过 %d, 跳过批内两两比较", len(entities), maxPairwiseEntities)
+		return nil
+	}
+
+	var pairs []candidatePair
+	for i := range entities {
+		for j := i + 1; j < len(entities); j++ {
+			a, b := &entities[i], &entities[j]
+			if !sameType(a.Type, b.Type) {
+				continue
+			}
+			score := nameSimilarity(a.Name, b.Name)
+			if len(a.Embedding) > 0 && len(b.Embedding) > 0 {
+				score = combinedSimilarity(cosine(a.Embedding, b.Embedding), score)
+			}
+			if score >= candidateThreshold {
+				pairs = append(pairs, candidatePair{a: a.Name, b: b.Name, score: score})
+			}
+		}
+	}
+	return pairs
+}
+
+// dedupePairs 去除重复的候选对 (a,b) 与 (b,a), 按相似度从高到低排序
+func dedupePairs(pairs []candidatePair) []candidatePair {
+	seen := make(map[[2]string]struct{}, len(pairs))
+	result := make([]candidatePair, 0, len(pairs))
+	for _, p := range pairs {
+		key := [2]string{p.a, p.b}
+		if p.b < p.a {
+			key = [2]string{p.b, p.a}
+		}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		result = append(result, p)
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].score > result[j].score })
+	return result
+}
+
+// confirm 分批请求 LLM 确认候选对, 单批失败时该批视为不合并
+func (r *EntityResolver) confirm(ctx context.Context, pairs []candidatePair, entities map[string]*types.Entity) []candidatePair {
+	var confirmed []candidatePair
+	for start := 0; start < len(pairs); start += confirmBatchSize {
+		end := min(start+confirmBatchSize, len(pairs))
+		batch := pairs[start:end]
+
+		msgs, err := prompt.NewEntityResolutionPrompt(formatPairs(batch, entities))
+		if err != nil {
+			logx.Errorf("build entity resolution prompt failed: %v", err)
+			continue
+		}
+		resp, err := r.llm.Generate(ctx, msgs)
+		if err != nil {
+			logx.Errorf("entity resolution llm failed: %v", err)
+			continue
+		}
+		for _, idx := range parseConfirmed(resp.Content, len(batch)) {
+			confirmed = append(confirmed, batch[idx])
+		}
+	}
+	return confirmed
+}
+
+func formatPairs(pairs []candidatePair, entities map[string]*types.Entity) string {
+	var sb strings.Builder
+	for i, p := range pairs {
+		a, b := entities[p.a], entities[p.b]
+		fmt.Fprintf(&sb, "%d. 实体A: %s (%s) - %s\n   实体B: %s (%s) - %s\n",
+			i+1, a.Name, a.Type, truncate(a.Description), b.Name, b.Type, truncate(b.Description))
+	}
+	return sb.String()
+}
+
+var confirmLinePattern = regexp.MustCompile(`(?im)^\s*(\d+)\s*[:：.、]\s*(yes|no|是|否)`)
+
+// parseConfirmed 解析 LLM 输出, 返回确认合并的候选对下标 (从 0 开始)
+func parseConfirmed(content string, n int) []int {
+	var indexes []int
+	seen := make(map[int]struct{})
+	for _, m := range confirmLinePattern.FindAllStringSubmatch(content, -1) {
+		answer := strings.ToLower(m[2])
+		if answer != "yes" && answer != "是" {
+			continue
+		}
+		idx, err := strconv.Atoi(m[1])
+		if err != nil || idx < 1 || idx > n {
+			continue
+		}
+		if _, ok := seen[idx-1]; ok {
+			continue
+		}
+		seen[idx-1] = struct{}{}
+		indexes = append(indexes, idx-1)
+	}
+	return indexes
+}
+
+// applyMerges 按确认的候选对合并实体, 改写关系端点并记录别名
+func applyMerges(result *types.GraphExtractionResult, entities map[string]*types.Entity, batch map[string]bool, confirmed []candidatePair) *Resolution {
+	uf := newUnionFind()
+	for _, p := range confirmed {
+		uf.union(p.a, p.b)
+	}
+
+	// 每组选出规范实体: 优先知识库已有实体, 其次出处多的、名称短的
+	groups := make(map[string][]string)
+	for name := range uf.parent {
+		root := uf.find(name)
+		groups[root] = append(groups[root], name)
+	}
+	aliases := make(map[string]string)
+	merged := make(map[string]*types.Entity)
+	for _, members := range groups {
+		sort.Slice(members, func(i, j int) bool {
+			return preferCanonical(entities[members[i]], entities[members[j]], batch)
+		})
+		canonical := *entities[members[0]]
+		for _, name := range members[1:] {
+			mergeEntity(&canonical, entities[name])
+			aliases[name] = canonical.Name
+		}
+		merged[canonical.Name] = &canonical
+	}
+
+	resolved := &types.GraphExtractionResult{
+		Entities:  make([]types.Entity, 0, len(result.Entities)),
+		Relations: make([]types.Relation, 0, len(result.Relations)),
+	}
+	for _, e := range result.Entities {
+		if _, ok := aliases[e.Name]; ok {
+			continue
+		}
+		if m, ok := merged[e.Name]; ok {
+			e = *m
+			delete(merged, e.Name)
+		}
+		resolved.Entities = append(resolved.Entities, e)
+	}
+	// 规范实体为知识库已有实体时, 同样写回以更新描述、出处和别名
+	for _, name := range sortedKeys(merged) {
+		resolved.Entities = append(resolved.Entities, *merged[name])
+	}
+
+	for _, rel := range result.Relations {
+		if canonical, ok := aliases[rel.SrcId]; ok {
+			rel.SrcId = canonical
+		}
+		if canonical, ok := aliases[rel.DstId]; ok {
+			rel.DstId = canonical
+		}
+		if rel.SrcId == rel.DstId {
+			continue
+		}
+		resolved.Relations = append(resolved.Relations, rel)
+	}
+
+	return &Resolution{Result: resolved, Aliases: aliases}
+}
+
+// preferCanonical a 是否比 b 更适合作为规范实体
+func preferCanonical(a, b *types.Entity, batch map[string]bool) bool {
+	if batch[a.Name] != batch[b.Name] {
+		return !batch[a.Name]
+	}
+	if len(a.SourceId) != len(b.SourceId) {
+		return len(a.SourceId) > len(b.SourceId)
+	}
+	if ra, rb := len([]rune(a.Name)), len([]rune(b.Name)); ra != rb {
+		return ra < rb
+	}
+	return a.Name < b.Name
+}
+
+// mergeEntity 将 alias 的描述、出处和别名合并到 canonical
+func mergeEntity(canonical, alias *types.Entity) {
+	if alias.Description != "" && !strings.Contains(canonical.Description, alias.Description) {
+		if canonical.Description != "" {
+			canonical.Description += "\n" + alias.Description
+		} else {
+			canonical.Description = alias.Description
+		}
+	}
+	canonical.SourceId = appendUnique(canonical.SourceId, alias.SourceId...)
+	canonical.Aliases = appendUnique(canonical.Aliases, alias.Name)
+	canonical.Aliases = appendUnique(canonical.Aliases, alias.Aliases...)
+	if canonical.Type == "" {
+		canonical.Type = alias.Type
+	}
+}
+
+// entityFromDoc 将 ES 图谱文档还原为实体, 描述和出处以合并后的字段为准
+func entityFromDoc(doc *graph.EsGraphDocument) *types.Entity {
+	e := &types.Entity{}
+	if doc.ContentWithWeight != "" {
+		_ = json.Unmarshal([]byte(doc.ContentWithWeight), e)
+	}
+	e.Name = doc.EntityName
+	e.Description = doc.Description
+	e.SourceId = doc.SourceIds
+	e.Aliases = doc.Aliases
+	if doc.EntityType != "" {
+		e.Type = doc.EntityType
+	}
+	return e
+}
+
+func sameType(a, b string) bool {
+	return a == "" || b == "" || strings.EqualFold(a, b)
+}
+
+func combinedSimilarity(embedding, name float64) float64 {
+	return embeddingWeight*embedding + (1-embeddingWeight)*name
+}
+
+// nameSimilarity 基于编辑距离的名称相似度 (0-1), 忽略大小写和首尾空白
+func nameSimilarity(a, b string) float64 {
+	ra := []rune(strings.ToLower(strings.TrimSpace(a)))
+	rb := []rune(strings.ToLower(strings.TrimSpace(b)))
+	maxLen := max(len(ra), len(rb))
+	if maxLen == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(maxLen)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func cosine(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+func truncate(s string) string {
+	r := []rune(s)
+	if len(r) <= maxDescriptionRunes {
+		return s
+	}
+	return string(r[:maxDescriptionRunes]) + "..."
+}
+
+func appendUnique(list []string, items ...string) []string {
+	for _, item := range items {
+		found := false
+		for _, existing := range list {
+			if existing == item {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func sortedKeys(m map[string]*types.Entity) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// unionFind 并查集, 用于把传递确认的实体归为一组 (A=B, B=C => A=B=C)
+type unionFind struct {
+	parent map[string]string
+}
+
+func newUnionFind() *unionFind {
+	return &unionFind{parent: make(map[string]string)}
+}
+
+func (u *unionFind) find(x string) string {
+	if _, ok := u.parent[x]; !ok {
+		u.parent[x] = x
+	}
+	for u.parent[x] != x {
+		u.parent[x] = u.parent[u.parent[x]]
+		x = u.parent[x]
+	}
+	return x
+}
+
+func (u *unionFind) union(a, b string) {
+	ra, rb := u.find(a), u.find(b)
+	if ra != rb {
+		u.parent[ra] = rb
+	}
+}
@@ -0,0 +1,130 @@
+package resolver
+
+import (
+	"context"
+	"testing"
+
+	"gozero-rag/internal/graphrag/types"
+	"gozero-rag/internal/model/graph"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSearcher struct {
+	hits map[string][]*graph.SimilarEntity // 按查询向量的第一个分量区分
+}
+
+func (f *fakeSearcher) SearchSimilarEntities(_ context.Context, _ string, vector []float64, _ int) ([]*graph.SimilarEntity, error) {
+	if vector[0] == 1 {
+		return f.hits["字节跳动公司"], nil
+	}
+	return nil, nil
+}
+
+type fakeLlm struct {
+	answer string
+	prompt string
+}
+
+func (f *fakeLlm) Generate(_ context.Context, msgs []*schema.Message, _ ...model.Option) (*schema.Message, error) {
+	f.prompt = msgs[len(msgs)-1].Content
+	return schema.AssistantMessage(f.answer, nil), nil
+}
+
+func (f *fakeLlm) Stream(context.Context, []*schema.Message, ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	return nil, nil
+}
+
+func TestEntityResolver_Resolve(t *testing.T) {
+	searcher := &fakeSearcher{hits: map[string][]*graph.SimilarEntity{
+		"字节跳动公司": {{
+			EsGraphDocument: &graph.EsGraphDocument{
+				EntityName:  "字节跳动",
+				EntityType:  "organization",
+				Description: "互联网公司",
+				SourceIds:   []string{"chunk-old"},
+			},
+			Similarity: 0.95,
+		}},
+	}}
+	llm := &fakeLlm{answer: "1: yes"}
+
+	result := &types.GraphExtractionResult{
+		Entities: []types.Entity{
+			{Name: "字节跳动公司", Type: "organization", Description: "抖音的母公司", SourceId: []string{"chunk-1"}, Embedding: []float64{1, 0}},
+			{Name: "抖音", Type: "organization", SourceId: []string{"chunk-1"}, Embedding: []float64{0, 1}},
+		},
+		Relations: []types.Relation{
+			{SrcId: "字节跳动公司", DstId: "抖音", Type: "owns"},
+			{SrcId: "字节跳动公司", DstId: "字节跳动", Type: "related_to"},
+		},
+	}
+
+	resolution, err := NewEntityResolver(searcher, llm).Resolve(context.Background(), "kb-1", result)
+	require.NoError(t, err)
+
+	assert.Contains(t, llm.prompt, "字节跳动公司")
+	assert.Equal(t, map[string]string{"字节跳动公司": "字节跳动"}, resolution.Aliases)
+
+	// 知识库已有实体作为规范实体, 合并描述、出处与别名
+	require.Len(t, resolution.Result.Entities, 2)
+	canonical := resolution.Result.Entities[1]
+	assert.Equal(t, "字节跳动", canonical.Name)
+	assert.Equal(t, "互联网公司\n抖音的母公司", canonical.Description)
+	assert.Equal(t, []string{"chunk-old", "chunk-1"}, canonical.SourceId)
+	assert.Equal(t, []string{"字节跳动公司"}, canonical.Aliases)
+
+	// 关系端点改写为规范实体, 自环被丢弃
+	assert.Equal(t, []types.Relation{{SrcId: "字节跳动", DstId: "抖音", Type: "owns"}}, resolution.Result.Relations)
+}
+
+func TestEntityResolver_Rejected(t *testing.T) {
+	llm := &fakeLlm{answer: "1: no"}
+	result := &types.GraphExtractionResult{
+		Entities: []types.Entity{
+			{Name: "OpenAI", Type: "organization"},
+			{Name: "openai", Type: "organization"},
+		},
+	}
+
+	resolution, err := NewEntityResolver(&fakeSearcher{}, llm).Resolve(context.Background(), "kb-1", result)
+	require.NoError(t, err)
+	assert.Empty(t, resolution.Aliases)
+	assert.Len(t, resolution.Result.Entities, 2)
+}
+
+func TestParseConfirmed(t *testing.T) {
+	assert.Equal(t, []int{0, 2}, parseConfirmed("1: yes\n2: no\n3：是\n4: YES\n1: yes", 3))
+	assert.Empty(t, parseConfirmed("无法判断", 3))
+}
+
+func TestNameSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, nameSimilarity("OpenAI", "openai "))
+	assert.InDelta(t, 0.667, nameSimilarity("字节跳动", "字节跳动公司"), 0.001)
+	assert.Less(t, nameSimilarity("Apple", "Microsoft"), 0.5)
+}
+
+func TestApplyMerges_Transitive(t *testing.T) {
+	result := &types.GraphExtractionResult{
+		Entities: []types.Entity{
+			{Name: "A", SourceId: []string{"c1"}},
+			{Name: "B", SourceId: []string{"c1", "c2"}},
+			{Name: "C", SourceId: []string{"c3"}},
+		},
+	}
+	entities := map[string]*types.Entity{}
+	batch := map[string]bool{}
+	for i := range result.Entities {
+		entities[result.Entities[i].Name] = &result.Entities[i]
+		batch[result.Entities[i].Name] = true
+	}
+
+	resolution := applyMerges(result, entities, batch, []candidatePair{{a: "A", b: "B"}, {a: "B", b: "C"}})
+	assert.Equal(t, map[string]string{"A": "B", "C": "B"}, resolution.Aliases)
+	require.Len(t, resolution.Result.Entities, 1)
+	assert.ElementsMatch(t, []string{"c1", "c2", "c3"}, resolution.Result.Entities[0].SourceId)
+	assert.ElementsMatch(t, []string{"A", "C"}, resolution.Result.Entities[0].Aliases)
+}
@@ -0,0 +1,462 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gozero-rag/internal/graphrag/extractor"
+	"gozero-rag/internal/graphrag/types"
+	"gozero-rag/internal/model/graph"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+)
+
+const (
+	graphLockExpireSec     = 600                    // 图谱写入锁过期时间
+	graphLockWaitTimeout   = 2 * time.Minute        // 等待图谱写入锁的最长时间
+	graphLockRetryInterval = 200 * time.Millisecond // 重试获取锁的间隔
+)
+
+// graphLockKey 同一知识库的图谱写入串行执行
+func graphLockKey(kbId string) string {
+	return fmt.Sprintf("graphrag:graph:lock:%s", kbId)
+}
+
+// GraphStore 维护知识库图谱与文档的一致性
+// 每个文档的抽取结果作为一条贡献记录保存, Nebula 和 kg_graph 中的实体/关系由全部贡献合并得到:
+// 文档重新抽取时替换贡献, 文档删除时移除贡献, 受影响的实体和关系按剩余贡献重新计算, 没有剩余来源的被删除
+// 重新计算是读取贡献再覆盖写入, 同一知识库的写操作由 Redis 锁串行化, 避免并发写入互相覆盖
+type GraphStore struct {
+	redis         *redis.Redis
+	contributions graph.ContributionModel
+	graphModel    graph.GraphModel
+	nebulaModel   graph.NebulaGraphModel
+}
+
+func NewGraphStore(rds *redis.Redis, contributions graph.ContributionModel, graphModel graph.GraphModel, nebulaModel graph.NebulaGraphModel) *GraphStore {
+	return &GraphStore{
+		redis:         rds,
+		contributions: contributions,
+		graphModel:    graphModel,
+		nebulaModel:   nebulaModel,
+	}
+}
+
+// graphKeys 一次同步涉及的实体和关系
+type graphKeys struct {
+	entities  map[string]struct{}
+	relations map[string]types.Relation // RelationKey -> 关系端点
+}
+
+func newGraphKeys() *graphKeys {
+	return &graphKeys{
+		entities:  make(map[string]struct{}),
+		relations: make(map[string]types.Relation),
+	}
+}
+
+func (k *graphKeys) add(c *graph.GraphContribution) {
+	for _, e := range c.Entities {
+		k.entities[e.Name] = struct{}{}
+	}
+	for _, r := range c.Relations {
+		k.relations[graph.RelationKey(r.SrcId, r.DstId)] = types.Relation{SrcId: r.SrcId, DstId: r.DstId}
+	}
+}
+
+func (k *graphKeys) empty() bool {
+	return len(k.entities) == 0 && len(k.relations) == 0
+}
+
+// ApplyDocument 用文档最新的抽取结果替换其旧贡献, 并重新计算新旧结果涉及的实体和关系
+// chunkIds 为文档自身的分片, 实体归一化可能带入知识库已有实体的出处, 贡献中只保留本文档的出处
+func (s *GraphStore) ApplyDocument(ctx context.Context, kbId, docId string, chunkIds []string, result *types.GraphExtractionResult) error {
+	return s.withLock(ctx, kbId, func() error {
+		return s.applyDocument(ctx, kbId, docId, chunkIds, result)
+	})
+}
+
+func (s *GraphStore) applyDocument(ctx context.Context, kbId, docId string, chunkIds []string, result *types.GraphExtractionResult) error {
+	keys := newGraphKeys()
+	old, err := s.contributions.FindByDocIds(ctx, kbId, []string{docId})
+	if err != nil {
+		return fmt.Errorf("查询文档图谱贡献失败: %w", err)
+	}
+	for _, c := range old {
+		keys.add(c)
+	}
+
+	contribution := newContribution(kbId, docId, chunkIds, result)
+	if len(contribution.Entities) == 0 && len(contribution.Relations) == 0 {
+		if err := s.contributions.Delete(ctx, []string{contribution.Id}); err != nil {
+			return fmt.Errorf("删除文档图谱贡献失败: %w", err)
+		}
+	} else if err := s.contributions.Save(ctx, []*graph.GraphContribution{contribution}); err != nil {
+		return fmt.Errorf("保存文档图谱贡献失败: %w", err)
+	}
+	keys.add(contribution)
+
+	embeddings := make(map[string][]float64)
+	for _, e := range resultEntities(result) {
+		if len(e.Embedding) > 0 {
+			embeddings[e.Name] = e.Embedding
+		}
+	}
+	return s.sync(ctx, kbId, keys, embeddings, nil)
+}
+
+// RemoveDocuments 移除文档的图谱贡献, 只由这些文档贡献的实体和关系将被删除
+func (s *GraphStore) RemoveDocuments(ctx context.Context, kbId string, docIds []string) error {
+	if len(docIds) == 0 {
+		return nil
+	}
+	return s.withLock(ctx, kbId, func() error {
+		return s.removeDocuments(ctx, kbId, docIds)
+	})
+}
+
+func (s *GraphStore) removeDocuments(ctx context.Context, kbId string, docIds []string) error {
+	old, err := s.contributions.FindByDocIds(ctx, kbId, docIds)
+	if err != nil {
+		return fmt.Errorf("查询文档图谱贡献失败: %w", err)
+	}
+	if len(old) == 0 {
+		return nil
+	}
+
+	// 先按排除旧贡献后的结果同步, 成功后再删除贡献记录: 同步失败时贡献仍在, 重试可以重新计算
+	keys := newGraphKeys()
+	ids := make([]string, 0, len(old))
+	excluded := make(map[string]struct{}, len(old))
+	for _, c := range old {
+		keys.add(c)
+		ids = append(ids, c.Id)
+		excluded[c.Id] = struct{}{}
+	}
+	if err := s.sync(ctx, kbId, keys, nil, excluded); err != nil {
+		return err
+	}
+	if err := s.contributions.Delete(ctx, ids); err != nil {
+		return fmt.Errorf("删除文档图谱贡献失败: %w", err)
+	}
+	return nil
+}
+
+// MergeAliases 将别名实体合并到规范实体: 改写其他文档贡献中的别名, 迁移 Nebula 中的边并改写 kg_graph 中的关系文档
+// aliases: 别名 -> 规范实体名
+func (s *GraphStore) MergeAliases(ctx context.Context, kbId string, aliases map[string]string) error {
+	if len(aliases) == 0 {
+		return nil
+	}
+	return s.withLock(ctx, kbId, func() error {
+		return s.mergeAliases(ctx, kbId, aliases)
+	})
+}
+
+func (s *GraphStore) mergeAliases(ctx context.Context, kbId string, aliases map[string]string) error {
+	names := make([]string, 0, len(aliases))
+	for alias := range aliases {
+		names = append(names, alias)
+	}
+	affected, err := s.contributions.FindByGraphKeys(ctx, kbId, names, nil)
+	if err != nil {
+		return fmt.Errorf("查询别名实体贡献失败: %w", err)
+	}
+
+	keys := newGraphKeys()
+	for _, c := range affected {
+		keys.add(c)
+		renameEntities(c, aliases)
+		keys.add(c)
+	}
+	if err := s.contributions.Save(ctx, affected); err != nil {
+		return fmt.Errorf("保存别名实体贡献失败: %w", err)
+	}
+
+	// 兼容没有贡献记录的历史图谱: 由 Nebula 迁移别名实体的边
+	for alias, canonical := range aliases {
+		if err := s.nebulaModel.MergeEntity(ctx, kbId, alias, canonical); err != nil {
+			logx.Errorf("[GraphStore] merge entity %s into %s failed: %v", alias, canonical, err)
+		}
+		keys.entities[alias] = struct{}{}
+	}
+	// 历史图谱的 kg_graph 中存有关系文档, 改写其别名端点
+	if err := s.graphModel.RenameRelationEntities(ctx, kbId, aliases); err != nil {
+		return fmt.Errorf("改写别名关系失败: %w", err)
+	}
+	return s.sync(ctx, kbId, keys, nil, nil)
+}
+
+// DropKnowledgeBase 删除知识库的全部图谱数据
+func (s *GraphStore) DropKnowledgeBase(ctx context.Context, kbId string) error {
+	return s.withLock(ctx, kbId, func() error {
+		return s.dropKnowledgeBase(ctx, kbId)
+	})
+}
+
+func (s *GraphStore) dropKnowledgeBase(ctx context.Context, kbId string) error {
+	if err := s.nebulaModel.DropSpace(ctx, kbId); err != nil {
+		return fmt.Errorf("删除图谱空间失败: %w", err)
+	}
+	if err := s.graphModel.DeleteByKbId(ctx, kbId); err != nil {
+		return fmt.Errorf("删除图谱实体失败: %w", err)
+	}
+	if err := s.contributions.DeleteByKbId(ctx, kbId); err != nil {
+		return fmt.Errorf("删除图谱贡献失败: %w", err)
+	}
+	return nil
+}
+
+// withLock 持有知识库图谱写入锁执行 fn, 锁被占用时等待, 超时返回错误由调用方重试
+func (s *GraphStore) withLock(ctx context.Context, kbId string, fn func() error) error {
+	lock := redis.NewRedisLock(s.redis, graphLockKey(kbId))
+	lock.SetExpire(graphLockExpireSec)
+	deadline := time.Now().Add(graphLockWaitTimeout)
+	for {
+		ok, err := lock.AcquireCtx(ctx)
+		if err != nil {
+			return fmt.Errorf("获取图谱写入锁失败: %w", err)
+		}
+		if ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("知识库 %s 图谱正在写入, 等待超时", kbId)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(graphLockRetryInterval):
+		}
+	}
+	defer func() {
+		// 按加锁时的随机值释放, 锁过期后已被其他实例获取时不会误删
+		if _, err := lock.ReleaseCtx(context.Background()); err != nil {
+			logx.Errorf("release graph lock failed: kb=%s, err=%v", kbId, err)
+		}
+	}()
+	return fn()
+}
+
+// sync 按剩余贡献重新计算 keys 中的实体和关系: 仍有来源的覆盖写入, 没有来源的删除
+// embeddings 为新抽取实体的向量, 未提供时 kg_graph 保留已有向量; excluded 中的贡献即将删除, 不计入剩余来源
+func (s *GraphStore) sync(ctx context.Context, kbId string, keys *graphKeys, embeddings map[string][]float64, excluded map[string]struct{}) error {
+	if keys.empty() {
+		return nil
+	}
+
+	names := make([]string, 0, len(keys.entities))
+	for name := range keys.entities {
+		names = append(names, name)
+	}
+	relationKeys := make([]string, 0, len(keys.relations))
+	for key := range keys.relations {
+		relationKeys = append(relationKeys, key)
+	}
+	found, err := s.contributions.FindByGraphKeys(ctx, kbId, names, relationKeys)
+	if err != nil {
+		return fmt.Errorf("查询图谱贡献失败: %w", err)
+	}
+	remaining := make([]*graph.GraphContribution, 0, len(found))
+	for _, c := range found {
+		if _, ok := excluded[c.Id]; !ok {
+			remaining = append(remaining, c)
+		}
+	}
+	entities, relations := mergeContributions(remaining, keys)
+
+	var removedNames []string
+	for name := range keys.entities {
+		if _, ok := entities[name]; !ok {
+			removedNames = append(removedNames, name)
+		}
+	}
+	var removedRelations []types.Relation
+	for key, r := range keys.relations {
+		if _, ok := relations[key]; !ok {
+			removedRelations = append(removedRelations, r)
+		}
+	}
+
+	// 1. Nebula: 先删除后写入, 删除实体时会连带删除其边, 仍有来源的边随后重新写入
+	if err := s.nebulaModel.DeleteRelations(ctx, kbId, removedRelations); err != nil {
+		return err
+	}
+	if err := s.nebulaModel.DeleteEntities(ctx, kbId, removedNames); err != nil {
+		return err
+	}
+	if len(entities) > 0 || len(relations) > 0 {
+		if err := s.nebulaModel.EnsureSpaceAndSchema(ctx, kbId); err != nil {
+			return err
+		}
+		if err := s.nebulaModel.BatchUpsertEntities(ctx, kbId, mapValues(entities)); err != nil {
+			return err
+		}
+		if err := s.nebulaModel.BatchInsertRelations(ctx, kbId, mapValues(relations)); err != nil {
+			return err
+		}
+	}
+
+	// 2. ES: kg_graph 只存储实体
+	removedIds := make([]string, 0, len(removedNames))
+	for _, name := range removedNames {
+		removedIds = append(removedIds, graph.EntityDocId(kbId, name))
+	}
+	if err := s.graphModel.Delete(ctx, removedIds); err != nil {
+		return fmt.Errorf("删除图谱实体失败: %w", err)
+	}
+	docs := make([]*graph.EsGraphDocument, 0, len(entities))
+	for _, e := range entities {
+		docs = append(docs, toEsDocument(kbId, e, embeddings[e.Name]))
+	}
+	if err := s.graphModel.Save(ctx, docs); err != nil {
+		return fmt.Errorf("保存图谱实体失败: %w", err)
+	}
+
+	logx.Infof("[GraphStore] 图谱同步完成, kb_id=%s, 更新实体 %d 个, 关系 %d 条, 删除实体 %d 个, 关系 %d 条",
+		kbId, len(entities), len(relations), len(removedNames), len(removedRelations))
+	return nil
+}
+
+// newContribution 生成文档的贡献记录: 去掉向量, 出处限定为文档自身的分片, 并标记来源文档
+func newContribution(kbId, docId string, chunkIds []string, result *types.GraphExtractionResult) *graph.GraphContribution {
+	own := make(map[string]struct{}, len(chunkIds))
+	for _, id := range chunkIds {
+		own[id] = struct{}{}
+	}
+	ownSources := func(sourceIds []string) []string {
+		kept := make([]string, 0, len(sourceIds))
+		for _, id := range sourceIds {
+			if _, ok := own[id]; ok {
+				kept = append(kept, id)
+			}
+		}
+		return kept
+	}
+
+	c := &graph.GraphContribution{
+		Id:        graph.ContributionId(kbId, docId),
+		KbId:      kbId,
+		DocId:     docId,
+		UpdatedAt: time.Now().Format(time.RFC3339),
+	}
+	if result == nil {
+		return c
+	}
+	for _, e := range result.Entities {
+		e.Embedding = nil
+		e.SourceId = ownSources(e.SourceId)
+		e.DocIds = []string{docId}
+		c.Entities = append(c.Entities, e)
+		c.EntityNames = append(c.EntityNames, e.Name)
+	}
+	for _, r := range result.Relations {
+		r.SourceId = ownSources(r.SourceId)
+		r.DocIds = []string{docId}
+		c.Relations = append(c.Relations, r)
+		c.RelationKeys = append(c.RelationKeys, graph.RelationKey(r.SrcId, r.DstId))
+	}
+	return c
+}
+
+// renameEntities 将贡献中的别名实体改写为规范实体, 关系端点同步改写, 产生的自环丢弃
+func renameEntities(c *graph.GraphContribution, aliases map[string]string) {
+	rename := func(name string) string {
+		if canonical, ok := aliases[name]; ok {
+			return canonical
+		}
+		return name
+	}
+
+	for i, e := range c.Entities {
+		if canonical := rename(e.Name); canonical != e.Name {
+			c.Entities[i].Aliases = append(c.Entities[i].Aliases, e.Name)
+			c.Entities[i].Name = canonical
+		}
+	}
+	// 别名与规范实体可能同时出现在一个文档中, 改写后合并
+	c.Entities = extractor.MergeResults([]*types.GraphExtractionResult{{Entities: c.Entities}}).Entities
+	c.EntityNames = make([]string, 0, len(c.Entities))
+	for _, e := range c.Entities {
+		c.EntityNames = append(c.EntityNames, e.Name)
+	}
+
+	relations := make([]types.Relation, 0, len(c.Relations))
+	for _, r := range c.Relations {
+		r.SrcId, r.DstId = rename(r.SrcId), rename(r.DstId)
+		if r.SrcId == r.DstId {
+			continue
+		}
+		relations = append(relations, r)
+	}
+	c.Relations = extractor.MergeResults([]*types.GraphExtractionResult{{Relations: relations}}).Relations
+	c.RelationKeys = make([]string, 0, len(c.Relations))
+	for _, r := range c.Relations {
+		c.RelationKeys = append(c.RelationKeys, graph.RelationKey(r.SrcId, r.DstId))
+	}
+	c.UpdatedAt = time.Now().Format(time.RFC3339)
+}
+
+// mergeContributions 合并剩余贡献, 只返回 keys 涉及的实体和关系
+func mergeContributions(contributions []*graph.GraphContribution, keys *graphKeys) (map[string]types.Entity, map[string]types.Relation) {
+	results := make([]*types.GraphExtractionResult, 0, len(contributions))
+	for _, c := range contributions {
+		results = append(results, &types.GraphExtractionResult{Entities: c.Entities, Relations: c.Relations})
+	}
+	merged := extractor.MergeResults(results)
+
+	entities := make(map[string]types.Entity)
+	for _, e := range merged.Entities {
+		if _, ok := keys.entities[e.Name]; ok {
+			entities[e.Name] = e
+		}
+	}
+	relations := make(map[string]types.Relation)
+	for _, r := range merged.Relations {
+		key := graph.RelationKey(r.SrcId, r.DstId)
+		if _, ok := keys.relations[key]; ok {
+			relations[key] = r
+		}
+	}
+	return entities, relations
+}
+
+func resultEntities(result *types.GraphExtractionResult) []types.Entity {
+	if result == nil {
+		return nil
+	}
+	return result.Entities
+}
+
+func toEsDocument(kbId string, e types.Entity, embedding []float64) *graph.EsGraphDocument {
+	// Content backup (不含 embedding，避免重复存储)
+	backup := e
+	backup.Embedding = nil
+	content, _ := json.Marshal(backup)
+	return &graph.EsGraphDocument{
+		Id:                graph.EntityDocId(kbId, e.Name),
+		KbId:              kbId,
+		GraphType:         "entity",
+		EntityName:        e.Name,
+		Description:       e.Description,
+		Weight:            1.0,
+		SourceIds:         e.SourceId,
+		Embedding:         embedding,
+		EntityType:        e.Type,
+		Aliases:           e.Aliases,
+		DocIds:            e.DocIds,
+		ContentWithWeight: string(content),
+		UpdatedAt:         time.Now().Format(time.RFC3339),
+	}
+}
+
+func mapValues[T any](m map[string]T) []T {
+	values := make([]T, 0, len(m))
+	for _, v := range m {
+		values = append(values, v)
+	}
+	return values
+}
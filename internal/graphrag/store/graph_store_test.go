@@ -0,0 +1,353 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gozero-rag/internal/graphrag/types"
+	"gozero-rag/internal/model/graph"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+	"github.com/zeromicro/go-zero/core/stores/redis/redistest"
+)
+
+type fakeContributions struct {
+	records map[string]*graph.GraphContribution
+}
+
+func (f *fakeContributions) Save(_ context.Context, contributions []*graph.GraphContribution) error {
+	for _, c := range contributions {
+		f.records[c.Id] = c
+	}
+	return nil
+}
+
+func (f *fakeContributions) FindByDocIds(_ context.Context, kbId string, docIds []string) ([]*graph.GraphContribution, error) {
+	var found []*graph.GraphContribution
+	for _, c := range f.records {
+		if c.KbId == kbId && contains(docIds, c.DocId) {
+			found = append(found, c)
+		}
+	}
+	return found, nil
+}
+
+func (f *fakeContributions) FindByGraphKeys(_ context.Context, kbId string, entityNames, relationKeys []string) ([]*graph.GraphContribution, error) {
+	var found []*graph.GraphContribution
+	for _, c := range f.records {
+		if c.KbId == kbId && (overlaps(c.EntityNames, entityNames) || overlaps(c.RelationKeys, relationKeys)) {
+			found = append(found, c)
+		}
+	}
+	return found, nil
+}
+
+func (f *fakeContributions) Delete(_ context.Context, ids []string) error {
+	for _, id := range ids {
+		delete(f.records, id)
+	}
+	return nil
+}
+
+func (f *fakeContributions) DeleteByKbId(_ context.Context, kbId string) error {
+	for id, c := range f.records {
+		if c.KbId == kbId {
+			delete(f.records, id)
+		}
+	}
+	return nil
+}
+
+type fakeGraphModel struct {
+	graph.GraphModel
+	docs map[string]*graph.EsGraphDocument
+}
+
+func (f *fakeGraphModel) Save(_ context.Context, docs []*graph.EsGraphDocument) error {
+	for _, doc := range docs {
+		if old, ok := f.docs[doc.Id]; ok && len(doc.Embedding) == 0 {
+			doc.Embedding = old.Embedding
+		}
+		f.docs[doc.Id] = doc
+	}
+	return nil
+}
+
+func (f *fakeGraphModel) Delete(_ context.Context, ids []string) error {
+	for _, id := range ids {
+		delete(f.docs, id)
+	}
+	return nil
+}
+
+func (f *fakeGraphModel) RenameRelationEntities(_ context.Context, kbId string, aliases map[string]string) error {
+	for _, doc := range f.docs {
+		if doc.KbId != kbId || doc.GraphType != "relation" {
+			continue
+		}
+		if canonical, ok := aliases[doc.SrcName]; ok {
+			doc.SrcName = canonical
+		}
+		if canonical, ok := aliases[doc.DstName]; ok {
+			doc.DstName = canonical
+		}
+	}
+	return nil
+}
+
+func (f *fakeGraphModel) DeleteByKbId(_ context.Context, kbId string) error {
+	for id, doc := range f.docs {
+		if doc.KbId == kbId {
+			delete(f.docs, id)
+		}
+	}
+	return nil
+}
+
+type fakeNebula struct {
+	graph.NebulaGraphModel
+	entities  map[string]types.Entity
+	relations map[string]types.Relation
+	dropped   bool
+	deleteErr error
+}
+
+func (f *fakeNebula) EnsureSpaceAndSchema(context.Context, string) error { return nil }
+
+func (f *fakeNebula) BatchUpsertEntities(_ context.Context, _ string, entities []types.Entity) error {
+	for _, e := range entities {
+		f.entities[e.Name] = e
+	}
+	return nil
+}
+
+func (f *fakeNebula) BatchInsertRelations(_ context.Context, _ string, relations []types.Relation) error {
+	for _, r := range relations {
+		f.relations[graph.RelationKey(r.SrcId, r.DstId)] = r
+	}
+	return nil
+}
+
+func (f *fakeNebula) MergeEntity(context.Context, string, string, string) error { return nil }
+
+func (f *fakeNebula) DeleteEntities(_ context.Context, _ string, names []string) error {
+	for _, name := range names {
+		delete(f.entities, name)
+		for key, r := range f.relations {
+			if r.SrcId == name || r.DstId == name {
+				delete(f.relations, key)
+			}
+		}
+	}
+	return nil
+}
+
+func (f *fakeNebula) DeleteRelations(_ context.Context, _ string, relations []types.Relation) error {
+	if f.deleteErr != nil {
+		return f.deleteErr
+	}
+	for _, r := range relations {
+		delete(f.relations, graph.RelationKey(r.SrcId, r.DstId))
+	}
+	return nil
+}
+
+func (f *fakeNebula) DropSpace(context.Context, string) error {
+	f.dropped = true
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func overlaps(a, b []string) bool {
+	for _, v := range a {
+		if contains(b, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func newTestStore(t *testing.T) (*GraphStore, *fakeContributions, *fakeGraphModel, *fakeNebula) {
+	contributions := &fakeContributions{records: make(map[string]*graph.GraphContribution)}
+	graphModel := &fakeGraphModel{docs: make(map[string]*graph.EsGraphDocument)}
+	nebula := &fakeNebula{entities: make(map[string]types.Entity), relations: make(map[string]types.Relation)}
+	return NewGraphStore(redistest.CreateRedis(t), contributions, graphModel, nebula), contributions, graphModel, nebula
+}
+
+func TestGraphStore_ApplyAndRemoveDocuments(t *testing.T) {
+	s, contributions, graphModel, nebula := newTestStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, s.ApplyDocument(ctx, "kb-1", "doc-1", []string{"chunk-1"}, &types.GraphExtractionResult{
+		Entities: []types.Entity{
+			{Name: "字节跳动", Type: "organization", Description: "互联网公司", SourceId: []string{"chunk-1"}, Embedding: []float64{1, 0}},
+			{Name: "抖音", Type: "organization", Description: "短视频平台", SourceId: []string{"chunk-1"}, Embedding: []float64{0, 1}},
+		},
+		Relations: []types.Relation{
+			{SrcId: "字节跳动", DstId: "抖音", Description: "字节跳动拥有抖音", Weight: 8, SourceId: []string{"chunk-1"}},
+		},
+	}))
+	require.NoError(t, s.ApplyDocument(ctx, "kb-1", "doc-2", []string{"chunk-2"}, &types.GraphExtractionResult{
+		Entities: []types.Entity{
+			{Name: "字节跳动", Type: "organization", Description: "总部位于北京的互联网公司", SourceId: []string{"chunk-2", "chunk-1"}},
+		},
+	}))
+
+	// 两个文档的贡献合并, 出处只记录各自文档的分片, 向量不写入贡献
+	assert.Len(t, contributions.records, 2)
+	assert.Nil(t, contributions.records[graph.ContributionId("kb-1", "doc-1")].Entities[0].Embedding)
+	bytedance := nebula.entities["字节跳动"]
+	assert.Equal(t, "总部位于北京的互联网公司", bytedance.Description)
+	assert.ElementsMatch(t, []string{"chunk-1", "chunk-2"}, bytedance.SourceId)
+	assert.ElementsMatch(t, []string{"doc-1", "doc-2"}, bytedance.DocIds)
+	doc := graphModel.docs[graph.EntityDocId("kb-1", "字节跳动")]
+	require.NotNil(t, doc)
+	assert.Equal(t, []float64{1, 0}, doc.Embedding)
+
+	// 删除 doc-2: 字节跳动仍由 doc-1 贡献, 描述按剩余来源重新计算
+	require.NoError(t, s.RemoveDocuments(ctx, "kb-1", []string{"doc-2"}))
+	bytedance = nebula.entities["字节跳动"]
+	assert.Equal(t, "互联网公司", bytedance.Description)
+	assert.Equal(t, []string{"chunk-1"}, bytedance.SourceId)
+	assert.Equal(t, []string{"doc-1"}, graphModel.docs[graph.EntityDocId("kb-1", "字节跳动")].DocIds)
+	assert.Contains(t, nebula.relations, graph.RelationKey("字节跳动", "抖音"))
+
+	// 删除 doc-1: 没有剩余来源的实体和关系被删除
+	require.NoError(t, s.RemoveDocuments(ctx, "kb-1", []string{"doc-1"}))
+	assert.Empty(t, nebula.entities)
+	assert.Empty(t, nebula.relations)
+	assert.Empty(t, graphModel.docs)
+	assert.Empty(t, contributions.records)
+}
+
+func TestGraphStore_RemoveDocumentsRetry(t *testing.T) {
+	s, contributions, graphModel, nebula := newTestStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, s.ApplyDocument(ctx, "kb-1", "doc-1", []string{"chunk-1"}, &types.GraphExtractionResult{
+		Entities: []types.Entity{
+			{Name: "字节跳动", Type: "organization", SourceId: []string{"chunk-1"}},
+			{Name: "抖音", Type: "organization", SourceId: []string{"chunk-1"}},
+		},
+		Relations: []types.Relation{{SrcId: "字节跳动", DstId: "抖音", SourceId: []string{"chunk-1"}}},
+	}))
+
+	// 同步失败时保留贡献记录, 重试仍能找到需要删除的实体和关系
+	nebula.deleteErr = errors.New("nebula unavailable")
+	require.Error(t, s.RemoveDocuments(ctx, "kb-1", []string{"doc-1"}))
+	assert.Len(t, contributions.records, 1)
+
+	nebula.deleteErr = nil
+	require.NoError(t, s.RemoveDocuments(ctx, "kb-1", []string{"doc-1"}))
+	assert.Empty(t, contributions.records)
+	assert.Empty(t, nebula.entities)
+	assert.Empty(t, nebula.relations)
+	assert.Empty(t, graphModel.docs)
+}
+
+func TestGraphStore_ReapplyDocument(t *testing.T) {
+	s, contributions, graphModel, nebula := newTestStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, s.ApplyDocument(ctx, "kb-1", "doc-1", []string{"chunk-1"}, &types.GraphExtractionResult{
+		Entities: []types.Entity{
+			{Name: "A", Description: "旧描述", SourceId: []string{"chunk-1"}},
+			{Name: "B", SourceId: []string{"chunk-1"}},
+		},
+		Relations: []types.Relation{{SrcId: "A", DstId: "B", SourceId: []string{"chunk-1"}}},
+	}))
+
+	// 重新索引后不再包含 B, 且不会重复累加 A 的出处
+	require.NoError(t, s.ApplyDocument(ctx, "kb-1", "doc-1", []string{"chunk-3"}, &types.GraphExtractionResult{
+		Entities: []types.Entity{{Name: "A", Description: "新描述", SourceId: []string{"chunk-3"}}},
+	}))
+
+	assert.Len(t, contributions.records, 1)
+	assert.Equal(t, "新描述", nebula.entities["A"].Description)
+	assert.Equal(t, []string{"chunk-3"}, nebula.entities["A"].SourceId)
+	assert.NotContains(t, nebula.entities, "B")
+	assert.Empty(t, nebula.relations)
+	assert.Len(t, graphModel.docs, 1)
+}
+
+func TestGraphStore_MergeAliases(t *testing.T) {
+	s, contributions, graphModel, nebula := newTestStore(t)
+	ctx := context.Background()
+
+	// 历史图谱遗留的关系文档
+	legacy := &graph.EsGraphDocument{Id: "relation-1", KbId: "kb-1", GraphType: "relation", SrcName: "抖音", DstName: "字节跳动公司"}
+	graphModel.docs[legacy.Id] = legacy
+
+	require.NoError(t, s.ApplyDocument(ctx, "kb-1", "doc-1", []string{"chunk-1"}, &types.GraphExtractionResult{
+		Entities: []types.Entity{
+			{Name: "字节跳动公司", Description: "抖音的母公司", SourceId: []string{"chunk-1"}},
+			{Name: "抖音", SourceId: []string{"chunk-1"}},
+		},
+		Relations: []types.Relation{{SrcId: "字节跳动公司", DstId: "抖音", SourceId: []string{"chunk-1"}}},
+	}))
+
+	require.NoError(t, s.MergeAliases(ctx, "kb-1", map[string]string{"字节跳动公司": "字节跳动"}))
+
+	c := contributions.records[graph.ContributionId("kb-1", "doc-1")]
+	assert.ElementsMatch(t, []string{"字节跳动", "抖音"}, c.EntityNames)
+	assert.Equal(t, []string{graph.RelationKey("字节跳动", "抖音")}, c.RelationKeys)
+	assert.NotContains(t, nebula.entities, "字节跳动公司")
+	assert.Equal(t, []string{"字节跳动公司"}, nebula.entities["字节跳动"].Aliases)
+	assert.Contains(t, nebula.relations, graph.RelationKey("字节跳动", "抖音"))
+	assert.NotContains(t, nebula.relations, graph.RelationKey("字节跳动公司", "抖音"))
+	assert.Equal(t, "抖音", legacy.SrcName)
+	assert.Equal(t, "字节跳动", legacy.DstName)
+
+	// 别名合并后删除文档, 规范实体随之删除
+	require.NoError(t, s.RemoveDocuments(ctx, "kb-1", []string{"doc-1"}))
+	assert.Empty(t, nebula.entities)
+}
+
+func TestGraphStore_DropKnowledgeBase(t *testing.T) {
+	s, contributions, graphModel, nebula := newTestStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, s.ApplyDocument(ctx, "kb-1", "doc-1", []string{"chunk-1"}, &types.GraphExtractionResult{
+		Entities: []types.Entity{{Name: "A", SourceId: []string{"chunk-1"}}},
+	}))
+	require.NoError(t, s.DropKnowledgeBase(ctx, "kb-1"))
+
+	assert.True(t, nebula.dropped)
+	assert.Empty(t, graphModel.docs)
+	assert.Empty(t, contributions.records)
+}
+
+func TestGraphStore_SerializesPerKnowledgeBase(t *testing.T) {
+	s, contributions, _, _ := newTestStore(t)
+	result := &types.GraphExtractionResult{Entities: []types.Entity{{Name: "A", SourceId: []string{"chunk-1"}}}}
+
+	// 其他实例持有知识库的写入锁时等待, 上下文结束仍未获取到锁则不写入
+	held := redis.NewRedisLock(s.redis, graphLockKey("kb-1"))
+	ok, err := held.Acquire()
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*graphLockRetryInterval)
+	defer cancel()
+	require.ErrorIs(t, s.ApplyDocument(ctx, "kb-1", "doc-1", []string{"chunk-1"}, result), context.DeadlineExceeded)
+	assert.Empty(t, contributions.records)
+
+	// 其他知识库不受影响
+	require.NoError(t, s.ApplyDocument(context.Background(), "kb-2", "doc-2", []string{"chunk-1"}, result))
+
+	_, err = held.Release()
+	require.NoError(t, err)
+	require.NoError(t, s.ApplyDocument(context.Background(), "kb-1", "doc-1", []string{"chunk-1"}, result))
+	assert.Len(t, contributions.records, 2)
+}
@@ -1,5 +1,8 @@
 package types
 
+// DefaultEntityTypes 知识库未配置实体类型时使用的默认类型
+var DefaultEntityTypes = []string{"organization", "person", "geo", "event", "category"}
+
 type GraphExtractionResult struct {
 	Entities  []Entity
 	Relations []Relation
@@ -15,6 +18,8 @@ type Entity struct {
 	// 可能为 chunk-xxhash(chunk内容-chunk对应的文档的uuidv7)
 	// 也可能为 qa-xxhash(chunk内容-chunk对应的文档的uuidv7)
 	SourceId []string `json:"source_id"`
+	DocIds   []string `json:"doc_ids,omitempty"` // 贡献该实体的文档 id, 文档删除或重新索引时据此扣减
+	Aliases  []string `json:"aliases,omitempty"` // 实体归一化时合并进来的别名
 }
 
 type Relation struct {
@@ -28,4 +33,5 @@ type Relation struct {
 	// 可能为 chunk-xxhash(chunk内容-chunk对应的文档的uuidv7)
 	// 也可能为 qa-xxhash(chunk内容-chunk对应的文档的uuidv7)
 	SourceId []string `json:"source_id"`
+	DocIds   []string `json:"doc_ids,omitempty"` // 贡献该关系的文档 id
 }
@@ -0,0 +1,67 @@
+package indexctl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zeromicro/go-zero/core/stores/redis"
+)
+
+// ========================================
+// agentic 分片的 LLM 调用预算
+// 大文档拆分后各子任务分别切片, 预算按文档计数并在子任务间共享, 每轮索引开始时重置
+// ========================================
+
+const (
+	llmBudgetKeyPattern = "rag:index:llm_budget:%s" // 已预占的调用次数, 按文档区分
+	llmBudgetExpire     = 24 * 60 * 60              // 计数有效期(秒)
+)
+
+// reserveLlmBudgetScript 已用次数加 n 不超过上限时预占并返回 1, 否则不扣减返回 0
+var reserveLlmBudgetScript = redis.NewScript(`
+local used = tonumber(redis.call('GET', KEYS[1]) or '0')
+if used + tonumber(ARGV[1]) > tonumber(ARGV[2]) then
+	return 0
+end
+redis.call('INCRBY', KEYS[1], ARGV[1])
+redis.call('EXPIRE', KEYS[1], ARGV[3])
+return 1`)
+
+func llmBudgetKey(docId string) string {
+	return fmt.Sprintf(llmBudgetKeyPattern, docId)
+}
+
+// LlmBudgetStore 基于 Redis 的文档级 LLM 调用预算
+type LlmBudgetStore struct {
+	rds *redis.Redis
+}
+
+func NewLlmBudgetStore(rds *redis.Redis) *LlmBudgetStore {
+	return &LlmBudgetStore{rds: rds}
+}
+
+// Reset 清空文档已预占的次数, 每轮索引开始时调用
+func (s *LlmBudgetStore) Reset(ctx context.Context, docId string) error {
+	_, err := s.rds.DelCtx(ctx, llmBudgetKey(docId))
+	return err
+}
+
+// Document 返回文档的预算, 实现 types.LlmBudget
+func (s *LlmBudgetStore) Document(docId string) *DocumentLlmBudget {
+	return &DocumentLlmBudget{rds: s.rds, docId: docId}
+}
+
+// DocumentLlmBudget 单个文档的 LLM 调用预算
+type DocumentLlmBudget struct {
+	rds   *redis.Redis
+	docId string
+}
+
+func (b *DocumentLlmBudget) Reserve(ctx context.Context, n, limit int) (bool, error) {
+	val, err := b.rds.ScriptRunCtx(ctx, reserveLlmBudgetScript, []string{llmBudgetKey(b.docId)}, n, limit, llmBudgetExpire)
+	if err != nil {
+		return false, err
+	}
+	ok, _ := val.(int64)
+	return ok == 1, nil
+}
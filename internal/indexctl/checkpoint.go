@@ -0,0 +1,139 @@
+package indexctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/stores/redis"
+)
+
+// ========================================
+// embedding 检查点
+// 每完成一个 embedding 批次按 文档 + 分片 id 暂存向量, 暂停恢复或失败重试时跳过已生成向量的分片
+// 暂存向量不参与检索, 索引成功后随完整分片集合一次写入 ES, 随后删除检查点; 取消或最终失败时直接删除
+// ========================================
+
+const (
+	checkpointKeyPattern = "rag:index:checkpoint:%s" // Redis hash, field 为分片 id, value 为向量 JSON
+	attemptsKeyPattern   = "rag:index:attempts:%s"   // 失败重试次数, 与向量分开存储, 检查点失效时不受影响
+	checkpointExpire     = 7 * 24 * 60 * 60          // 检查点有效期(秒), 超过该时长未恢复需重新生成向量
+	checkpointModelField = "_embedding"              // 生成向量的 embedding 模型, 模型变更后检查点失效
+)
+
+func checkpointKey(docId string) string {
+	return fmt.Sprintf(checkpointKeyPattern, docId)
+}
+
+func attemptsKey(docId string) string {
+	return fmt.Sprintf(attemptsKeyPattern, docId)
+}
+
+// EncodeVectors 将分片向量编码为 hash field
+func EncodeVectors(vectors map[string][]float64) (map[string]string, error) {
+	fields := make(map[string]string, len(vectors))
+	for id, vec := range vectors {
+		data, err := json.Marshal(vec)
+		if err != nil {
+			return nil, err
+		}
+		fields[id] = string(data)
+	}
+	return fields, nil
+}
+
+// DecodeVectors 解析 hash field, 跳过损坏的向量; 生成向量的模型与 model 不一致时返回空
+func DecodeVectors(fields map[string]string, model string) map[string][]float64 {
+	if fields[checkpointModelField] != model {
+		return map[string][]float64{}
+	}
+	vectors := make(map[string][]float64, len(fields))
+	for id, data := range fields {
+		if strings.HasPrefix(id, "_") {
+			continue
+		}
+		var vec []float64
+		if err := json.Unmarshal([]byte(data), &vec); err != nil || len(vec) == 0 {
+			continue
+		}
+		vectors[id] = vec
+	}
+	return vectors
+}
+
+// IsStaleCheckpoint 检查点中存在向量且生成向量的模型与 model 不一致时返回 true
+func IsStaleCheckpoint(fields map[string]string, model string) bool {
+	if fields[checkpointModelField] == model {
+		return false
+	}
+	for id := range fields {
+		if !strings.HasPrefix(id, "_") {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckpointStore 基于 Redis 的 embedding 检查点存储
+type CheckpointStore struct {
+	rds *redis.Redis
+}
+
+func NewCheckpointStore(rds *redis.Redis) *CheckpointStore {
+	return &CheckpointStore{rds: rds}
+}
+
+// Save 追加一个批次的分片向量, model 为生成向量的 embedding 模型
+func (s *CheckpointStore) Save(ctx context.Context, docId, model string, vectors map[string][]float64) error {
+	if len(vectors) == 0 {
+		return nil
+	}
+	fields, err := EncodeVectors(vectors)
+	if err != nil {
+		return err
+	}
+	fields[checkpointModelField] = model
+	key := checkpointKey(docId)
+	if err := s.rds.HmsetCtx(ctx, key, fields); err != nil {
+		return err
+	}
+	return s.rds.ExpireCtx(ctx, key, checkpointExpire)
+}
+
+// Load 读取文档已暂存的分片向量, 模型已变更的检查点直接删除
+func (s *CheckpointStore) Load(ctx context.Context, docId, model string) (map[string][]float64, error) {
+	fields, err := s.rds.HgetallCtx(ctx, checkpointKey(docId))
+	if err != nil {
+		return nil, err
+	}
+	if IsStaleCheckpoint(fields, model) {
+		if _, err := s.rds.DelCtx(ctx, checkpointKey(docId)); err != nil {
+			return nil, err
+		}
+		return map[string][]float64{}, nil
+	}
+	return DecodeVectors(fields, model), nil
+}
+
+// IncrAttempts 记录一次失败重试, 返回累计次数
+func (s *CheckpointStore) IncrAttempts(ctx context.Context, docId string) (int, error) {
+	key := attemptsKey(docId)
+	n, err := s.rds.IncrCtx(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	return int(n), s.rds.ExpireCtx(ctx, key, checkpointExpire)
+}
+
+// ResetAttempts 重置失败重试次数, 已暂存的向量保留
+func (s *CheckpointStore) ResetAttempts(ctx context.Context, docId string) error {
+	_, err := s.rds.DelCtx(ctx, attemptsKey(docId))
+	return err
+}
+
+// Clear 删除文档的检查点及失败重试次数
+func (s *CheckpointStore) Clear(ctx context.Context, docId string) error {
+	_, err := s.rds.DelCtx(ctx, checkpointKey(docId), attemptsKey(docId))
+	return err
+}
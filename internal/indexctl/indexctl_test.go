@@ -0,0 +1,88 @@
+package indexctl
+
+import (
+	"testing"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignalError(t *testing.T) {
+	assert.ErrorIs(t, SignalError(SignalCancel), ErrCanceled)
+	assert.ErrorIs(t, SignalError(SignalPause), ErrPaused)
+	assert.NoError(t, SignalError(""))
+	assert.NoError(t, SignalError("unknown"))
+}
+
+func TestIsValidSignal(t *testing.T) {
+	assert.True(t, IsValidSignal(SignalCancel))
+	assert.True(t, IsValidSignal(SignalPause))
+	assert.False(t, IsValidSignal("resume"))
+}
+
+func TestEncodeDecodeVectors(t *testing.T) {
+	vectors := map[string][]float64{
+		"chunk-1": {0.1, 0.2, 0.3},
+		"qa-2":    {1, -1},
+	}
+	fields, err := EncodeVectors(vectors)
+	require.NoError(t, err)
+	require.Len(t, fields, 2)
+
+	fields["broken"] = "not-json"
+	fields["empty"] = "[]"
+	fields[checkpointModelField] = "bge-m3@OpenAI"
+
+	assert.Equal(t, vectors, DecodeVectors(fields, "bge-m3@OpenAI"))
+}
+
+func TestDecodeVectors_ModelChanged(t *testing.T) {
+	fields, err := EncodeVectors(map[string][]float64{"chunk-1": {0.1}})
+	require.NoError(t, err)
+	fields[checkpointModelField] = "bge-m3@OpenAI"
+
+	assert.Empty(t, DecodeVectors(fields, "text-embedding-3-small@OpenAI"))
+	delete(fields, checkpointModelField)
+	assert.Empty(t, DecodeVectors(fields, "bge-m3@OpenAI"))
+}
+
+func TestIsStaleCheckpoint(t *testing.T) {
+	// 尚无向量时不视为失效, 避免误删
+	assert.False(t, IsStaleCheckpoint(map[string]string{}, "bge-m3@OpenAI"))
+	assert.False(t, IsStaleCheckpoint(map[string]string{checkpointModelField: "old@OpenAI"}, "bge-m3@OpenAI"))
+
+	fields, err := EncodeVectors(map[string][]float64{"chunk-1": {0.1}})
+	require.NoError(t, err)
+	fields[checkpointModelField] = "bge-m3@OpenAI"
+	assert.False(t, IsStaleCheckpoint(fields, "bge-m3@OpenAI"))
+	assert.True(t, IsStaleCheckpoint(fields, "text-embedding-3-small@OpenAI"))
+}
+
+func TestStepProgress(t *testing.T) {
+	assert.Equal(t, 0.0, StepProgress(StepDownload, 0, 0))
+	assert.Equal(t, 0.3, StepProgress(StepEmbedding, 0, 10))
+	assert.InDelta(t, 0.575, StepProgress(StepEmbedding, 5, 10), 1e-9)
+	assert.InDelta(t, 0.85, StepProgress(StepEmbedding, 12, 10), 1e-9)
+	assert.Equal(t, 1.0, StepProgress(StepFinish, 0, 0))
+	assert.Equal(t, 0.0, StepProgress("unknown", 1, 1))
+}
+
+func TestProgressChannel(t *testing.T) {
+	assert.Equal(t, "rag:index:progress:kb-1", ProgressChannel("kb-1"))
+}
+
+func TestUniversalOptions(t *testing.T) {
+	node := universalOptions(redis.RedisConf{Host: "127.0.0.1:6379", Type: redis.NodeType, Pass: "secret"})
+	assert.Equal(t, []string{"127.0.0.1:6379"}, node.Addrs)
+	assert.Equal(t, "secret", node.Password)
+	assert.Nil(t, node.TLSConfig)
+	assert.IsType(t, &goredis.Client{}, goredis.NewUniversalClient(node))
+
+	cluster := universalOptions(redis.RedisConf{Host: "10.0.0.1:6379,10.0.0.2:6379", Type: redis.ClusterType, Tls: true})
+	assert.Equal(t, []string{"10.0.0.1:6379", "10.0.0.2:6379"}, cluster.Addrs)
+	require.NotNil(t, cluster.TLSConfig)
+	assert.IsType(t, &goredis.ClusterClient{}, goredis.NewUniversalClient(cluster))
+}
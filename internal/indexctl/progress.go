@@ -0,0 +1,162 @@
+package indexctl
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+)
+
+// ========================================
+// 索引进度
+// 消费者按步骤发布进度到知识库维度的 Redis pub/sub 频道, REST 端订阅后通过 SSE 推送给前端
+// ========================================
+
+type Step = string
+
+const (
+	StepDownload  Step = "download"  // 下载文件
+	StepParse     Step = "parse"     // 解析文档
+	StepSplit     Step = "split"     // 切片完成
+	StepQa        Step = "qa"        // 生成 QA
+	StepEmbedding Step = "embedding" // 生成向量
+	StepWrite     Step = "write"     // 写入 ES
+	StepGraph     Step = "graph"     // 投递图谱任务
+	StepFinish    Step = "finish"    // 索引结束, 结果见 RunStatus
+)
+
+const progressChannelPattern = "rag:index:progress:%s" // Redis pub/sub 频道, 按知识库区分
+
+// ProgressEvent 索引进度事件
+type ProgressEvent struct {
+	DocumentId      string  `json:"document_id"`
+	KnowledgeBaseId string  `json:"knowledge_base_id"`
+	Step            Step    `json:"step"`
+	Progress        float64 `json:"progress"` // 0-1
+	Current         int     `json:"current"`  // 当前步骤已完成数量, 如 embedding 批次
+	Total           int     `json:"total"`    // 当前步骤总数量
+	Message         string  `json:"message"`
+	RunStatus       string  `json:"run_status"`
+	Time            int64   `json:"time"` // 毫秒时间戳
+}
+
+func ProgressChannel(kbId string) string {
+	return fmt.Sprintf(progressChannelPattern, kbId)
+}
+
+// ProgressPublisher 发布索引进度
+type ProgressPublisher struct {
+	rds *redis.Redis
+}
+
+func NewProgressPublisher(rds *redis.Redis) *ProgressPublisher {
+	return &ProgressPublisher{rds: rds}
+}
+
+func (p *ProgressPublisher) Publish(ctx context.Context, ev *ProgressEvent) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	_, err = p.rds.PublishCtx(ctx, ProgressChannel(ev.KnowledgeBaseId), string(data))
+	return err
+}
+
+// ProgressSubscriber 订阅索引进度
+// go-zero 的 redis 客户端不支持订阅, 这里直接使用 go-redis
+type ProgressSubscriber struct {
+	client goredis.UniversalClient
+}
+
+func NewProgressSubscriber(conf redis.RedisConf) *ProgressSubscriber {
+	return &ProgressSubscriber{client: goredis.NewUniversalClient(universalOptions(conf))}
+}
+
+// universalOptions 按 go-zero 的方式解释 RedisConf: node 类型连接单个地址, cluster 类型按逗号拆分节点地址
+// Tls 与 go-zero 一致, 不校验服务端证书
+func universalOptions(conf redis.RedisConf) *goredis.UniversalOptions {
+	opts := &goredis.UniversalOptions{
+		Addrs:    []string{conf.Host},
+		Username: conf.User,
+		Password: conf.Pass,
+	}
+	if conf.Type == redis.ClusterType {
+		opts.Addrs = strings.Split(conf.Host, ",")
+		opts.IsClusterMode = true
+	}
+	if conf.Tls {
+		opts.TLSConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	return opts
+}
+
+// Subscribe 订阅知识库下所有文档的索引进度, ctx 结束时取消订阅并关闭返回的 channel
+func (s *ProgressSubscriber) Subscribe(ctx context.Context, kbId string) (<-chan *ProgressEvent, error) {
+	pubsub := s.client.Subscribe(ctx, ProgressChannel(kbId))
+	// 等待订阅确认, 确保返回后不会丢失事件
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return nil, err
+	}
+
+	events := make(chan *ProgressEvent, 16)
+	go func() {
+		defer close(events)
+		defer pubsub.Close()
+
+		ch := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+				var ev ProgressEvent
+				if err := json.Unmarshal([]byte(msg.Payload), &ev); err != nil {
+					logx.Errorf("[indexctl] 解析进度事件失败: %v", err)
+					continue
+				}
+				select {
+				case events <- &ev:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+// stepRanges 各步骤在整体进度中的区间
+var stepRanges = map[Step][2]float64{
+	StepDownload:  {0, 0.05},
+	StepParse:     {0.05, 0.1},
+	StepSplit:     {0.1, 0.1},
+	StepQa:        {0.1, 0.3},
+	StepEmbedding: {0.3, 0.85},
+	StepWrite:     {0.85, 0.95},
+	StepGraph:     {0.95, 1},
+	StepFinish:    {1, 1},
+}
+
+// StepProgress 根据步骤及步骤内完成数量计算整体进度 (0-1)
+func StepProgress(step Step, current, total int) float64 {
+	r, ok := stepRanges[step]
+	if !ok {
+		return 0
+	}
+	if total <= 0 || current <= 0 {
+		return r[0]
+	}
+	if current > total {
+		current = total
+	}
+	return r[0] + (r[1]-r[0])*float64(current)/float64(total)
+}
@@ -0,0 +1,96 @@
+package indexctl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+)
+
+// ========================================
+// 索引控制信号
+// REST 端写入取消/暂停信号, 索引消费者在流水线步骤之间及每个 embedding 批次前轮询
+// ========================================
+
+type Signal = string
+
+const (
+	SignalCancel Signal = "cancel" // 取消: 清理本次写入的分片
+	SignalPause  Signal = "pause"  // 暂停: 保留已完成的 embedding 批次, 恢复后继续
+)
+
+const (
+	signalKeyPattern = "rag:index:signal:%s" // Redis key, 按文档区分
+	signalExpire     = 24 * 60 * 60          // 信号有效期(秒), 防止消费者异常退出后残留
+)
+
+// RunningStaleTimeout 文档处于 running 但超过该时长未更新进度时, 视为消费者已退出或消息等待重试, 取消时不再等待消费者响应信号
+const RunningStaleTimeout = 10 * time.Minute
+
+var (
+	ErrCanceled = errors.New("indexctl: indexing canceled")
+	ErrPaused   = errors.New("indexctl: indexing paused")
+)
+
+func signalKey(docId string) string {
+	return fmt.Sprintf(signalKeyPattern, docId)
+}
+
+// IsValidSignal 校验信号是否合法
+func IsValidSignal(s Signal) bool {
+	return s == SignalCancel || s == SignalPause
+}
+
+// SignalError 将信号转换为对应的哨兵错误, 无信号时返回 nil
+func SignalError(s Signal) error {
+	switch s {
+	case SignalCancel:
+		return ErrCanceled
+	case SignalPause:
+		return ErrPaused
+	default:
+		return nil
+	}
+}
+
+// SignalStore 基于 Redis 的索引控制信号存储
+type SignalStore struct {
+	rds *redis.Redis
+}
+
+func NewSignalStore(rds *redis.Redis) *SignalStore {
+	return &SignalStore{rds: rds}
+}
+
+// Send 写入控制信号, 取消信号会覆盖暂停信号
+func (s *SignalStore) Send(ctx context.Context, docId string, signal Signal) error {
+	if !IsValidSignal(signal) {
+		return fmt.Errorf("indexctl: invalid signal %q", signal)
+	}
+	return s.rds.SetexCtx(ctx, signalKey(docId), signal, signalExpire)
+}
+
+// Get 读取控制信号, 无信号时返回空字符串
+func (s *SignalStore) Get(ctx context.Context, docId string) (Signal, error) {
+	return s.rds.GetCtx(ctx, signalKey(docId))
+}
+
+// Clear 清除控制信号
+func (s *SignalStore) Clear(ctx context.Context, docId string) error {
+	_, err := s.rds.DelCtx(ctx, signalKey(docId))
+	return err
+}
+
+// Check 检查是否收到控制信号, 收到时返回 ErrCanceled 或 ErrPaused
+// Redis 不可用时记录错误日志, 不中断索引
+func (s *SignalStore) Check(ctx context.Context, docId string) error {
+	signal, err := s.Get(ctx, docId)
+	if err != nil {
+		logx.WithContext(ctx).Errorf("[indexctl] 读取控制信号失败, doc_id=%s: %v", docId, err)
+		return nil
+	}
+	return SignalError(signal)
+}
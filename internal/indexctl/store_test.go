@@ -0,0 +1,117 @@
+package indexctl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+	"github.com/zeromicro/go-zero/core/stores/redis/redistest"
+)
+
+func TestSignalStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewSignalStore(redistest.CreateRedis(t))
+
+	assert.NoError(t, s.Check(ctx, "doc-1"))
+	assert.Error(t, s.Send(ctx, "doc-1", "resume"))
+
+	require.NoError(t, s.Send(ctx, "doc-1", SignalPause))
+	assert.ErrorIs(t, s.Check(ctx, "doc-1"), ErrPaused)
+	assert.NoError(t, s.Check(ctx, "doc-2"))
+
+	// 取消信号覆盖暂停信号
+	require.NoError(t, s.Send(ctx, "doc-1", SignalCancel))
+	assert.ErrorIs(t, s.Check(ctx, "doc-1"), ErrCanceled)
+
+	require.NoError(t, s.Clear(ctx, "doc-1"))
+	signal, err := s.Get(ctx, "doc-1")
+	require.NoError(t, err)
+	assert.Empty(t, signal)
+}
+
+func TestSignalStore_CheckRedisUnavailable(t *testing.T) {
+	s := NewSignalStore(redis.New("127.0.0.1:1"))
+
+	// 读取失败时不中断索引
+	_, err := s.Get(context.Background(), "doc-1")
+	require.Error(t, err)
+	assert.NoError(t, s.Check(context.Background(), "doc-1"))
+}
+
+func TestCheckpointStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewCheckpointStore(redistest.CreateRedis(t))
+	const model = "bge-m3@OpenAI"
+
+	vectors, err := s.Load(ctx, "doc-1", model)
+	require.NoError(t, err)
+	assert.Empty(t, vectors)
+
+	require.NoError(t, s.Save(ctx, "doc-1", model, map[string][]float64{"chunk-1": {0.1}}))
+	require.NoError(t, s.Save(ctx, "doc-1", model, map[string][]float64{"chunk-2": {0.2}}))
+	vectors, err = s.Load(ctx, "doc-1", model)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]float64{"chunk-1": {0.1}, "chunk-2": {0.2}}, vectors)
+
+	n, err := s.IncrAttempts(ctx, "doc-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	n, err = s.IncrAttempts(ctx, "doc-1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	// 重置重试次数时保留向量
+	require.NoError(t, s.ResetAttempts(ctx, "doc-1"))
+	n, err = s.IncrAttempts(ctx, "doc-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	vectors, err = s.Load(ctx, "doc-1", model)
+	require.NoError(t, err)
+	assert.Len(t, vectors, 2)
+
+	// 模型变更后检查点失效并被删除
+	vectors, err = s.Load(ctx, "doc-1", "text-embedding-3-small@OpenAI")
+	require.NoError(t, err)
+	assert.Empty(t, vectors)
+	vectors, err = s.Load(ctx, "doc-1", model)
+	require.NoError(t, err)
+	assert.Empty(t, vectors)
+
+	require.NoError(t, s.Save(ctx, "doc-1", model, map[string][]float64{"chunk-1": {0.1}}))
+	require.NoError(t, s.Clear(ctx, "doc-1"))
+	vectors, err = s.Load(ctx, "doc-1", model)
+	require.NoError(t, err)
+	assert.Empty(t, vectors)
+	n, err = s.IncrAttempts(ctx, "doc-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
+
+func TestLlmBudgetStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewLlmBudgetStore(redistest.CreateRedis(t))
+
+	// 同一文档的多个预算实例 (各子任务) 共享计数
+	ok, err := store.Document("doc1").Reserve(ctx, 3, 5)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = store.Document("doc1").Reserve(ctx, 3, 5)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = store.Document("doc1").Reserve(ctx, 2, 5)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// 其他文档互不影响
+	ok, err = store.Document("doc2").Reserve(ctx, 5, 5)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// 新一轮索引重置计数
+	require.NoError(t, store.Reset(ctx, "doc1"))
+	ok, err = store.Document("doc1").Reserve(ctx, 5, 5)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
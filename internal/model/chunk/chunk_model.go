@@ -1,21 +1,100 @@
 package chunk
 
-import "context"
+import (
+	"context"
+	"fmt"
+
+	"github.com/cespare/xxhash/v2"
+)
 
 type Chunk struct {
-	Id            string    `json:"id"`
-	DocId         string    `json:"doc_id"`
-	KbIds         []string  `json:"kb_ids"` // 支持多库, 实际存储时映射为 kb_id 数组
-	Content       string    `json:"content"`
-	ContentVector []float64 `json:"content_vector"` // 对应ES中的 q_{dim}_vec
-	DocName       string    `json:"doc_name"`
-	ImportantKw   []string  `json:"important_keywords"`
-	QuestionKw    []string  `json:"question_keywords"`
-	ImgId         string    `json:"img_id"`
-	PageNum       []int     `json:"page_num_int"`
-	CreateTime    float64   `json:"create_timestamp_flt"`
-	Available     int       `json:"available_int"`
-	Score         float64   `json:"score,omitempty"` // Search score
+	Id                 string    `json:"id"`
+	DocId              string    `json:"doc_id"`
+	KbIds              []string  `json:"kb_ids"` // 支持多库, 实际存储时映射为 kb_id 数组
+	Content            string    `json:"content"`
+	ContentVector      []float64 `json:"content_vector"` // 对应ES中的 q_{dim}_vec
+	DocName            string    `json:"doc_name"`
+	ImportantKw        []string  `json:"important_keywords"`
+	QuestionKw         []string  `json:"question_keywords"`
+	ImgId              string    `json:"img_id"`
+	PageNum            []int     `json:"page_num_int"`
+	CreateTime         float64   `json:"create_timestamp_flt"`
+	Available          int       `json:"available_int"`
+	SheetName          string    `json:"sheet_name,omitempty"`          // 表格模式: 所属工作表
+	RowNum             []int     `json:"row_num_int,omitempty"`         // 表格模式: 包含的数据行号
+	ChunkType          string    `json:"chunk_type,omitempty"`          // 分片类型, 为空表示普通分片
+	ParentId           string    `json:"parent_id,omitempty"`           // 父子分片: 子块所属父块ID
+	SourceId           string    `json:"source_id,omitempty"`           // QA 分片: 生成问答对的来源分片ID
+	Question           string    `json:"question,omitempty"`            // QA 分片: 问题
+	Answer             string    `json:"answer,omitempty"`              // QA 分片: 答案
+	QaStatus           string    `json:"qa_status,omitempty"`           // QA 分片: 审核状态
+	QualityScore       *float64  `json:"quality_score_flt,omitempty"`   // 质量评分 (0-100), 仅内容分片; 未评分为 nil, 0 分照常存储
+	QualityIssues      []string  `json:"quality_issues,omitempty"`      // 质量问题
+	QualitySuggestions []string  `json:"quality_suggestions,omitempty"` // 改进建议
+	Score              float64   `json:"score,omitempty"`               // Search score
+}
+
+const (
+	// ChunkTypeParent 父块: 仅用于命中子块后扩展上下文, 不生成向量, 不参与检索
+	ChunkTypeParent = "parent"
+	// ChunkTypeQa QA 分片: 由来源分片生成的问答对, 使用问题生成向量, 命中后返回来源分片
+	ChunkTypeQa = "qa"
+)
+
+// DefaultEmbeddingDims content_vector 的向量维度, 写入分片与生成问题向量时都必须使用该维度
+const DefaultEmbeddingDims = 1024
+
+const (
+	// QaStatusPending 自动生成, 未审核
+	QaStatusPending = "pending"
+	// QaStatusApproved 已审核 (含人工编辑), 重新索引时保留
+	QaStatusApproved = "approved"
+)
+
+// GenerateId 分片 id 由 xxhash(content + docId) 生成, 内容不变则 id 不变
+func GenerateId(prefix, content, docId string) string {
+	hash := xxhash.Sum64String(fmt.Sprintf("%s-%s", content, docId))
+	return fmt.Sprintf("%s-%x", prefix, hash)
+}
+
+// QaContent QA 分片的文本内容, 用于关键词检索
+func QaContent(question, answer string) string {
+	return fmt.Sprintf("Question: %s\nAnswer: %s", question, answer)
+}
+
+// SearchFilter 检索过滤条件, 字段为空表示不过滤
+type SearchFilter struct {
+	SheetNames []string // 仅检索指定工作表的 chunk
+}
+
+// QaFilter QA 分片查询条件, 字段为空表示不过滤
+type QaFilter struct {
+	SourceId string // 来源分片ID
+	Status   string // 审核状态
+}
+
+// QualityBucket 质量评分区间 [From, To)
+type QualityBucket struct {
+	From  float64
+	To    float64
+	Count int64
+}
+
+// TermCount 问题或建议的出现次数
+type TermCount struct {
+	Term  string
+	Count int64
+}
+
+// QualityReport 文档内容分片的质量统计
+type QualityReport struct {
+	Total        int64           // 已评分的分片数
+	Disabled     int64           // 已禁用的分片数
+	AvgScore     float64         // 平均分
+	Distribution []QualityBucket // 评分分布
+	Issues       []TermCount     // 常见问题, 按出现次数降序
+	Suggestions  []TermCount     // 常见建议, 按出现次数降序
+	Worst        []*Chunk        // 评分最低的分片
 }
 
 // ChunkListResult 分页查询切片结果
@@ -33,7 +112,8 @@ type ChunkModel interface {
 	// query: 文本查询
 	// vector: 向量查询
 	// topK: 返回条数
-	HybridSearch(ctx context.Context, kbId string, query string, vector []float64, topK int) ([]*Chunk, error)
+	// filter: 额外过滤条件 (可选)
+	HybridSearch(ctx context.Context, kbId string, query string, vector []float64, topK int, filter *SearchFilter) ([]*Chunk, error)
 
 	// ListByDocId 按文档ID分页查询切片
 	// kbId: 知识库ID
@@ -43,6 +123,28 @@ type ChunkModel interface {
 	// pageSize: 每页条数
 	ListByDocId(ctx context.Context, docId string, keyword string, page int64, pageSize int64) (*ChunkListResult, error)
 
+	// ListQaByDocId 按文档ID分页查询 QA 分片
+	ListQaByDocId(ctx context.Context, docId string, filter *QaFilter, page int64, pageSize int64) (*ChunkListResult, error)
+
+	// QualityReport 统计文档内容分片的质量评分, worstN 为返回的最低分分片数
+	QualityReport(ctx context.Context, docId string, worstN int) (*QualityReport, error)
+
+	// GetByIds 按分片ID批量查询, 不存在的ID忽略
+	GetByIds(ctx context.Context, ids []string) ([]*Chunk, error)
+
+	// ListAllByDocId 查询文档的全部分片 (包括父块), 用于增量索引
+	// withVector 为 false 时不返回 content_vector
+	ListAllByDocId(ctx context.Context, kbId string, docId string, withVector bool) ([]*Chunk, error)
+
+	// ListRelatedByIds 查询文档内 id 或来源分片 (source_id) 属于 ids 的分片, 用于子任务只加载本范围的已有分片
+	ListRelatedByIds(ctx context.Context, kbId string, docId string, ids []string, withVector bool) ([]*Chunk, error)
+
+	// Update 按分片ID局部更新字段
+	Update(ctx context.Context, id string, fields map[string]interface{}) error
+
+	// DeleteByIds 按分片ID批量删除
+	DeleteByIds(ctx context.Context, ids []string) error
+
 	// DeleteByDocId 按文档ID删除 (用于删除文件)
 	DeleteByDocId(ctx context.Context, kbId string, docId string) error
 
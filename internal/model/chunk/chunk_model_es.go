@@ -63,55 +63,14 @@ func (m *EsChunkModel) SetupIndex(ctx context.Context) error {
 	defer res.Body.Close()
 
 	// 200 OK means exists, 404 Not Found means does not exist
+	// 索引已存在时补齐新增字段的映射, 新字段写入前必须先有正确的类型
 	if res.StatusCode == 200 {
-		return nil
+		return m.updateMapping(ctx)
 	}
 
-	// Define Mapping
 	mapping := map[string]interface{}{
 		"mappings": map[string]interface{}{
-			"properties": map[string]interface{}{
-				"id": map[string]interface{}{
-					"type": "keyword",
-				},
-				"doc_id": map[string]interface{}{
-					"type": "keyword",
-				},
-				"kb_ids": map[string]interface{}{
-					"type": "keyword",
-				},
-				"content": map[string]interface{}{
-					"type":            "text",
-					"analyzer":        "ik_max_word",
-					"search_analyzer": "ik_smart",
-					"fields": map[string]interface{}{
-						"keyword": map[string]interface{}{
-							"type":         "keyword",
-							"ignore_above": 256,
-						},
-					},
-				},
-				"content_vector": map[string]interface{}{
-					"type":       "dense_vector",
-					"dims":       1024,
-					"index":      true,
-					"similarity": "cosine",
-				},
-				"doc_name": map[string]interface{}{
-					"type": "keyword",
-				},
-				"question_keywords": map[string]interface{}{
-					"type":            "text",
-					"analyzer":        "ik_max_word",
-					"search_analyzer": "ik_smart",
-				},
-				"create_timestamp_flt": map[string]interface{}{
-					"type": "double",
-				},
-				"available_int": map[string]interface{}{
-					"type": "integer",
-				},
-			},
+			"properties": chunkMappingProperties(),
 		},
 	}
 
@@ -136,6 +95,113 @@ func (m *EsChunkModel) SetupIndex(ctx context.Context) error {
 	return nil
 }
 
+// chunkMappingProperties 分片索引的字段映射
+func chunkMappingProperties() map[string]interface{} {
+	return map[string]interface{}{
+		"id": map[string]interface{}{
+			"type": "keyword",
+		},
+		"doc_id": map[string]interface{}{
+			"type": "keyword",
+		},
+		"kb_ids": map[string]interface{}{
+			"type": "keyword",
+		},
+		"content": map[string]interface{}{
+			"type":            "text",
+			"analyzer":        "ik_max_word",
+			"search_analyzer": "ik_smart",
+			"fields": map[string]interface{}{
+				"keyword": map[string]interface{}{
+					"type":         "keyword",
+					"ignore_above": 256,
+				},
+			},
+		},
+		"content_vector": map[string]interface{}{
+			"type":       "dense_vector",
+			"dims":       DefaultEmbeddingDims,
+			"index":      true,
+			"similarity": "cosine",
+		},
+		"doc_name": map[string]interface{}{
+			"type": "keyword",
+		},
+		"question_keywords": map[string]interface{}{
+			"type":            "text",
+			"analyzer":        "ik_max_word",
+			"search_analyzer": "ik_smart",
+		},
+		"create_timestamp_flt": map[string]interface{}{
+			"type": "double",
+		},
+		"available_int": map[string]interface{}{
+			"type": "integer",
+		},
+		"sheet_name": map[string]interface{}{
+			"type": "keyword",
+		},
+		"row_num_int": map[string]interface{}{
+			"type": "integer",
+		},
+		"chunk_type": map[string]interface{}{
+			"type": "keyword",
+		},
+		"parent_id": map[string]interface{}{
+			"type": "keyword",
+		},
+		"source_id": map[string]interface{}{
+			"type": "keyword",
+		},
+		"question": map[string]interface{}{
+			"type":            "text",
+			"analyzer":        "ik_max_word",
+			"search_analyzer": "ik_smart",
+		},
+		"answer": map[string]interface{}{
+			"type":            "text",
+			"analyzer":        "ik_max_word",
+			"search_analyzer": "ik_smart",
+		},
+		"qa_status": map[string]interface{}{
+			"type": "keyword",
+		},
+		"quality_score_flt": map[string]interface{}{
+			"type": "double",
+		},
+		"quality_issues": map[string]interface{}{
+			"type": "keyword",
+		},
+		"quality_suggestions": map[string]interface{}{
+			"type": "keyword",
+		},
+	}
+}
+
+// updateMapping 为已存在的索引补齐字段映射
+// 已有字段的映射不变时 ES 视为无操作; 若字段已被动态映射为其他类型则返回错误, 需要重建索引
+func (m *EsChunkModel) updateMapping(ctx context.Context) error {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(map[string]interface{}{"properties": chunkMappingProperties()}); err != nil {
+		return err
+	}
+
+	res, err := m.client.Indices.PutMapping(
+		[]string{m.index},
+		&buf,
+		m.client.Indices.PutMapping.WithContext(ctx),
+	)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("update index mapping failed, reindex may be required: %s", res.String())
+	}
+	return nil
+}
+
 func (m *EsChunkModel) Put(ctx context.Context, chunks []*Chunk) error {
 	if len(chunks) == 0 {
 		return nil
@@ -173,7 +239,7 @@ func (m *EsChunkModel) Put(ctx context.Context, chunks []*Chunk) error {
 	return nil
 }
 
-func (m *EsChunkModel) HybridSearch(ctx context.Context, kbId string, query string, vector []float64, topK int) ([]*Chunk, error) {
+func (m *EsChunkModel) HybridSearch(ctx context.Context, kbId string, query string, vector []float64, topK int, filter *SearchFilter) ([]*Chunk, error) {
 	// Build ES Query
 	// Must: kb_id filter
 	// Should:
@@ -186,25 +252,19 @@ func (m *EsChunkModel) HybridSearch(ctx context.Context, kbId string, query stri
 	// Here, let's implement a Bool query combining vector similarity (via script_score or proper knn section) and keyword match.
 	// For simplicity and compatibility, we'll use Knn query with query filter.
 
+	filters := m.buildSearchFilters(kbId, filter)
+
 	queryBody := map[string]interface{}{
 		"knn": map[string]interface{}{
 			"field":          "content_vector", // Ensure mapping matches this name
 			"query_vector":   vector,
 			"k":              topK,
 			"num_candidates": topK * 10,
-			"filter": map[string]interface{}{
-				"term": map[string]interface{}{
-					"kb_ids": kbId,
-				},
-			},
+			"filter":         filters,
 		},
 		"query": map[string]interface{}{
 			"bool": map[string]interface{}{
-				"filter": map[string]interface{}{
-					"term": map[string]interface{}{
-						"kb_ids": kbId,
-					},
-				},
+				"filter": filters,
 				"should": []map[string]interface{}{
 					{
 						"match": map[string]interface{}{
@@ -268,6 +328,361 @@ func (m *EsChunkModel) HybridSearch(ctx context.Context, kbId string, query stri
 	return chunks, nil
 }
 
+// buildSearchFilters 构造检索过滤条件, knn 与 bool 查询共用
+func (m *EsChunkModel) buildSearchFilters(kbId string, filter *SearchFilter) []map[string]interface{} {
+	filters := []map[string]interface{}{
+		{"term": map[string]interface{}{"kb_ids": kbId}},
+		// 禁用的分片不参与检索
+		{"term": map[string]interface{}{"available_int": 1}},
+		excludeParentFilter(),
+	}
+	if filter == nil {
+		return filters
+	}
+	if len(filter.SheetNames) > 0 {
+		filters = append(filters, map[string]interface{}{"terms": map[string]interface{}{"sheet_name": filter.SheetNames}})
+	}
+	return filters
+}
+
+// excludeParentFilter 父块只用于扩展上下文, 检索和列表中均排除
+func excludeParentFilter() map[string]interface{} {
+	return excludeTypesFilter(ChunkTypeParent)
+}
+
+// excludeTypesFilter 排除指定类型的分片
+func excludeTypesFilter(chunkTypes ...string) map[string]interface{} {
+	return map[string]interface{}{
+		"bool": map[string]interface{}{
+			"must_not": map[string]interface{}{
+				"terms": map[string]interface{}{"chunk_type": chunkTypes},
+			},
+		},
+	}
+}
+
+// qualityRanges 质量评分分布区间
+var qualityRanges = []QualityBucket{{From: 0, To: 20}, {From: 20, To: 40}, {From: 40, To: 60}, {From: 60, To: 80}, {From: 80, To: 100}}
+
+// qualityTermsSize 常见问题/建议返回的条数
+const qualityTermsSize = 10
+
+func (m *EsChunkModel) QualityReport(ctx context.Context, docId string, worstN int) (*QualityReport, error) {
+	ranges := make([]map[string]interface{}, 0, len(qualityRanges))
+	for i, r := range qualityRanges {
+		bucket := map[string]interface{}{"from": r.From}
+		// 最后一个区间包含满分
+		if i < len(qualityRanges)-1 {
+			bucket["to"] = r.To
+		}
+		ranges = append(ranges, bucket)
+	}
+
+	queryBody := map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": []map[string]interface{}{
+					{"term": map[string]interface{}{"doc_id": docId}},
+					{"exists": map[string]interface{}{"field": "quality_score_flt"}},
+					// 只统计内容分片
+					excludeTypesFilter(ChunkTypeParent, ChunkTypeQa),
+				},
+			},
+		},
+		"size":             worstN,
+		"track_total_hits": true,
+		"_source":          map[string]interface{}{"excludes": []string{"content_vector"}},
+		"sort": []map[string]interface{}{
+			{"quality_score_flt": map[string]interface{}{"order": "asc"}},
+			{"id": map[string]interface{}{"order": "asc"}},
+		},
+		"aggs": map[string]interface{}{
+			"avg_score":    map[string]interface{}{"avg": map[string]interface{}{"field": "quality_score_flt"}},
+			"distribution": map[string]interface{}{"range": map[string]interface{}{"field": "quality_score_flt", "ranges": ranges}},
+			"disabled":     map[string]interface{}{"filter": map[string]interface{}{"term": map[string]interface{}{"available_int": 0}}},
+			"issues":       map[string]interface{}{"terms": map[string]interface{}{"field": "quality_issues", "size": qualityTermsSize}},
+			"suggestions":  map[string]interface{}{"terms": map[string]interface{}{"field": "quality_suggestions", "size": qualityTermsSize}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(queryBody); err != nil {
+		return nil, err
+	}
+
+	res, err := m.client.Search(
+		m.client.Search.WithContext(ctx),
+		m.client.Search.WithIndex(m.index),
+		m.client.Search.WithBody(&buf),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		body, _ := io.ReadAll(res.Body)
+		return nil, fmt.Errorf("search failed: %s, body: %s", res.Status(), string(body))
+	}
+
+	var result struct {
+		Hits struct {
+			Total struct {
+				Value int64 `json:"value"`
+			} `json:"total"`
+			Hits []struct {
+				Source Chunk `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+		Aggregations struct {
+			AvgScore struct {
+				Value *float64 `json:"value"`
+			} `json:"avg_score"`
+			Distribution struct {
+				Buckets []struct {
+					DocCount int64 `json:"doc_count"`
+				} `json:"buckets"`
+			} `json:"distribution"`
+			Disabled struct {
+				DocCount int64 `json:"doc_count"`
+			} `json:"disabled"`
+			Issues      termsAgg `json:"issues"`
+			Suggestions termsAgg `json:"suggestions"`
+		} `json:"aggregations"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	aggs := result.Aggregations
+	report := &QualityReport{
+		Total:       result.Hits.Total.Value,
+		Disabled:    aggs.Disabled.DocCount,
+		Issues:      aggs.Issues.counts(),
+		Suggestions: aggs.Suggestions.counts(),
+	}
+	if aggs.AvgScore.Value != nil {
+		report.AvgScore = *aggs.AvgScore.Value
+	}
+	for i, r := range qualityRanges {
+		if i < len(aggs.Distribution.Buckets) {
+			r.Count = aggs.Distribution.Buckets[i].DocCount
+		}
+		report.Distribution = append(report.Distribution, r)
+	}
+	for i := range result.Hits.Hits {
+		report.Worst = append(report.Worst, &result.Hits.Hits[i].Source)
+	}
+	return report, nil
+}
+
+// termsAgg terms 聚合结果
+type termsAgg struct {
+	Buckets []struct {
+		Key      string `json:"key"`
+		DocCount int64  `json:"doc_count"`
+	} `json:"buckets"`
+}
+
+func (a termsAgg) counts() []TermCount {
+	counts := make([]TermCount, 0, len(a.Buckets))
+	for _, b := range a.Buckets {
+		counts = append(counts, TermCount{Term: b.Key, Count: b.DocCount})
+	}
+	return counts
+}
+
+// GetByIds 按分片ID批量查询
+func (m *EsChunkModel) GetByIds(ctx context.Context, ids []string) ([]*Chunk, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	queryBody := map[string]interface{}{
+		"query": map[string]interface{}{
+			"ids": map[string]interface{}{"values": ids},
+		},
+		"size":    len(ids),
+		"_source": map[string]interface{}{"excludes": []string{"content_vector"}},
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(queryBody); err != nil {
+		return nil, err
+	}
+
+	res, err := m.client.Search(
+		m.client.Search.WithContext(ctx),
+		m.client.Search.WithIndex(m.index),
+		m.client.Search.WithBody(&buf),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		body, _ := io.ReadAll(res.Body)
+		return nil, fmt.Errorf("search failed: %s, body: %s", res.Status(), string(body))
+	}
+
+	var result map[string]interface{}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	hits := result["hits"].(map[string]interface{})["hits"].([]interface{})
+	chunks := make([]*Chunk, 0, len(hits))
+	for _, hit := range hits {
+		source := hit.(map[string]interface{})["_source"]
+		sourceBytes, _ := json.Marshal(source)
+		var chunk Chunk
+		if err := json.Unmarshal(sourceBytes, &chunk); err != nil {
+			logx.Errorf("failed to unmarshal chunk: %v", err)
+			continue
+		}
+		chunks = append(chunks, &chunk)
+	}
+
+	return chunks, nil
+}
+
+// listAllPageSize listAll 每页条数
+const listAllPageSize = 1000
+
+func (m *EsChunkModel) ListAllByDocId(ctx context.Context, kbId string, docId string, withVector bool) ([]*Chunk, error) {
+	return m.listAll(ctx, map[string]interface{}{
+		"bool": map[string]interface{}{
+			"filter": []map[string]interface{}{
+				{"term": map[string]interface{}{"kb_ids": kbId}},
+				{"term": map[string]interface{}{"doc_id": docId}},
+			},
+		},
+	}, withVector)
+}
+
+func (m *EsChunkModel) ListRelatedByIds(ctx context.Context, kbId string, docId string, ids []string, withVector bool) ([]*Chunk, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return m.listAll(ctx, map[string]interface{}{
+		"bool": map[string]interface{}{
+			"filter": []map[string]interface{}{
+				{"term": map[string]interface{}{"kb_ids": kbId}},
+				{"term": map[string]interface{}{"doc_id": docId}},
+			},
+			"should": []map[string]interface{}{
+				{"ids": map[string]interface{}{"values": ids}},
+				{"terms": map[string]interface{}{"source_id": ids}},
+			},
+			"minimum_should_match": 1,
+		},
+	}, withVector)
+}
+
+// listAll 按 id 排序 search_after 翻页, 返回查询命中的全部分片
+func (m *EsChunkModel) listAll(ctx context.Context, query map[string]interface{}, withVector bool) ([]*Chunk, error) {
+	var (
+		chunks      []*Chunk
+		searchAfter []interface{}
+	)
+
+	for {
+		queryBody := map[string]interface{}{
+			"query": query,
+			"size":  listAllPageSize,
+			"sort": []map[string]interface{}{
+				{"id": map[string]interface{}{"order": "asc"}},
+			},
+		}
+		if !withVector {
+			queryBody["_source"] = map[string]interface{}{"excludes": []string{"content_vector"}}
+		}
+		if searchAfter != nil {
+			queryBody["search_after"] = searchAfter
+		}
+
+		var buf bytes.Buffer
+		if err := json.NewEncoder(&buf).Encode(queryBody); err != nil {
+			return nil, err
+		}
+
+		res, err := m.client.Search(
+			m.client.Search.WithContext(ctx),
+			m.client.Search.WithIndex(m.index),
+			m.client.Search.WithBody(&buf),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		var result map[string]interface{}
+		if res.IsError() {
+			body, _ := io.ReadAll(res.Body)
+			res.Body.Close()
+			return nil, fmt.Errorf("search failed: %s, body: %s", res.Status(), string(body))
+		}
+		err = json.NewDecoder(res.Body).Decode(&result)
+		res.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		hits := result["hits"].(map[string]interface{})["hits"].([]interface{})
+		for _, hit := range hits {
+			hitMap := hit.(map[string]interface{})
+			sourceBytes, _ := json.Marshal(hitMap["_source"])
+			var chunk Chunk
+			if err := json.Unmarshal(sourceBytes, &chunk); err != nil {
+				logx.Errorf("failed to unmarshal chunk: %v", err)
+				continue
+			}
+			chunks = append(chunks, &chunk)
+			searchAfter, _ = hitMap["sort"].([]interface{})
+		}
+
+		if len(hits) < listAllPageSize {
+			return chunks, nil
+		}
+	}
+}
+
+func (m *EsChunkModel) Update(ctx context.Context, id string, fields map[string]interface{}) error {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(map[string]interface{}{"doc": fields}); err != nil {
+		return err
+	}
+
+	req := esapi.UpdateRequest{
+		Index:      m.index,
+		DocumentID: id,
+		Body:       &buf,
+		Refresh:    "true",
+	}
+	res, err := req.Do(ctx, m.client)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("update chunk failed: %s", res.String())
+	}
+	return nil
+}
+
+func (m *EsChunkModel) DeleteByIds(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	query := map[string]interface{}{
+		"query": map[string]interface{}{
+			"ids": map[string]interface{}{"values": ids},
+		},
+	}
+	return m.deleteByQuery(ctx, query)
+}
+
 func (m *EsChunkModel) DeleteByDocId(ctx context.Context, kbId string, docId string) error {
 	query := map[string]interface{}{
 		"query": map[string]interface{}{
@@ -335,7 +750,7 @@ func (m *EsChunkModel) deleteByQuery(ctx context.Context, query map[string]inter
 	return nil
 }
 
-// ListByDocId 按文档ID分页查询切片
+// ListByDocId 按文档ID分页查询切片, 父块和 QA 分片不在切片列表中展示
 func (m *EsChunkModel) ListByDocId(ctx context.Context, docId string, keyword string, page int64, pageSize int64) (*ChunkListResult, error) {
 	// 计算分页偏移量
 	from := (page - 1) * pageSize
@@ -357,7 +772,8 @@ func (m *EsChunkModel) ListByDocId(ctx context.Context, docId string, keyword st
 	queryBody := map[string]interface{}{
 		"query": map[string]interface{}{
 			"bool": map[string]interface{}{
-				"must": mustClauses,
+				"must":   mustClauses,
+				"filter": []map[string]interface{}{excludeTypesFilter(ChunkTypeParent, ChunkTypeQa)},
 			},
 		},
 		"from": from,
@@ -367,6 +783,42 @@ func (m *EsChunkModel) ListByDocId(ctx context.Context, docId string, keyword st
 		},
 	}
 
+	return m.searchList(ctx, queryBody)
+}
+
+// ListQaByDocId 按文档ID分页查询 QA 分片
+func (m *EsChunkModel) ListQaByDocId(ctx context.Context, docId string, filter *QaFilter, page int64, pageSize int64) (*ChunkListResult, error) {
+	filters := []map[string]interface{}{
+		{"term": map[string]interface{}{"doc_id": docId}},
+		{"term": map[string]interface{}{"chunk_type": ChunkTypeQa}},
+	}
+	if filter != nil && filter.SourceId != "" {
+		filters = append(filters, map[string]interface{}{"term": map[string]interface{}{"source_id": filter.SourceId}})
+	}
+	if filter != nil && filter.Status != "" {
+		filters = append(filters, map[string]interface{}{"term": map[string]interface{}{"qa_status": filter.Status}})
+	}
+
+	queryBody := map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": filters,
+			},
+		},
+		"from":    (page - 1) * pageSize,
+		"size":    pageSize,
+		"_source": map[string]interface{}{"excludes": []string{"content_vector"}},
+		"sort": []map[string]interface{}{
+			{"create_timestamp_flt": map[string]interface{}{"order": "asc"}},
+			{"id": map[string]interface{}{"order": "asc"}},
+		},
+	}
+
+	return m.searchList(ctx, queryBody)
+}
+
+// searchList 执行分页查询并返回总数
+func (m *EsChunkModel) searchList(ctx context.Context, queryBody map[string]interface{}) (*ChunkListResult, error) {
 	var buf bytes.Buffer
 	if err := json.NewEncoder(&buf).Encode(queryBody); err != nil {
 		return nil, err
@@ -0,0 +1,53 @@
+package chunk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 索引已存在时 SetupIndex 通过 PutMapping 补齐 QA 等新增字段
+func TestSetupIndex_ExistingIndexUpdatesMapping(t *testing.T) {
+	var mapping struct {
+		Properties map[string]map[string]any `json:"properties"`
+	}
+	putCalled := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		switch {
+		case r.Method == http.MethodHead && r.URL.Path == "/"+DefaultIndexName:
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodPut && r.URL.Path == "/"+DefaultIndexName+"/_mapping":
+			putCalled = true
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&mapping))
+			_, _ = w.Write([]byte(`{"acknowledged":true}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := elasticsearch.NewClient(elasticsearch.Config{Addresses: []string{srv.URL}})
+	require.NoError(t, err)
+	m := &EsChunkModel{client: client, index: DefaultIndexName}
+
+	require.NoError(t, m.SetupIndex(context.Background()))
+	require.True(t, putCalled)
+
+	for field, typ := range map[string]string{
+		"source_id": "keyword",
+		"qa_status": "keyword",
+		"question":  "text",
+		"answer":    "text",
+	} {
+		require.Contains(t, mapping.Properties, field)
+		assert.Equal(t, typ, mapping.Properties[field]["type"], field)
+	}
+	assert.EqualValues(t, DefaultEmbeddingDims, mapping.Properties["content_vector"]["dims"])
+}
@@ -0,0 +1,350 @@
+package graph
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+)
+
+const DefaultCommunityIndexName = "kg_community_report"
+
+var ErrCommunityNotFound = errors.New("community report not found")
+
+// CommunityReport 社区报告: 由 LLM 根据社区内的实体和关系生成
+type CommunityReport struct {
+	Id                string             `json:"id"`
+	KbId              string             `json:"kb_id"`
+	CommunityId       int                `json:"community_id"`
+	Title             string             `json:"title"`
+	Summary           string             `json:"summary"`
+	Rating            float64            `json:"rating"`             // 影响严重程度评分 (0-10)
+	RatingExplanation string             `json:"rating_explanation"` // 评分解释
+	Findings          []CommunityFinding `json:"findings"`
+	Entities          []string           `json:"entities"`            // 社区包含的实体名称
+	Content           string             `json:"content"`             // 标题、摘要与发现拼接的全文, 用于检索
+	Embedding         []float64          `json:"embedding,omitempty"` // Content 的向量嵌入
+	UpdatedAt         string             `json:"updated_at"`
+}
+
+// CommunityFinding 社区报告中的关键发现
+type CommunityFinding struct {
+	Summary     string `json:"summary"`
+	Explanation string `json:"explanation"`
+}
+
+type CommunityModel interface {
+	// Replace 用新生成的报告替换知识库的全部社区报告
+	Replace(ctx context.Context, kbId string, reports []*CommunityReport) error
+	// List 按评分从高到低分页查询社区报告
+	List(ctx context.Context, kbId string, page, pageSize int64) ([]*CommunityReport, int64, error)
+	FindOne(ctx context.Context, id string) (*CommunityReport, error)
+	// FindByIds 按报告 ID 批量查询 (含向量), 不存在的 ID 忽略
+	FindByIds(ctx context.Context, ids []string) ([]*CommunityReport, error)
+	// Search 按向量检索知识库内最相关的社区报告
+	Search(ctx context.Context, kbId string, vector []float64, topK int) ([]*CommunityReport, error)
+}
+
+type EsCommunityModel struct {
+	client *elasticsearch.Client
+	index  string
+}
+
+func NewEsCommunityModel(addresses []string, username, password string) (*EsCommunityModel, error) {
+	client, err := elasticsearch.NewClient(elasticsearch.Config{
+		Addresses: addresses,
+		Username:  username,
+		Password:  password,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	model := &EsCommunityModel{
+		client: client,
+		index:  DefaultCommunityIndexName,
+	}
+	if err := model.SetupIndex(context.Background()); err != nil {
+		return nil, err
+	}
+	return model, nil
+}
+
+func (m *EsCommunityModel) SetupIndex(ctx context.Context) error {
+	res, err := m.client.Indices.Exists([]string{m.index}, m.client.Indices.Exists.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == 200 {
+		return nil
+	}
+
+	mapping := map[string]interface{}{
+		"mappings": map[string]interface{}{
+			"properties": map[string]interface{}{
+				"id": map[string]interface{}{
+					"type": "keyword",
+				},
+				"kb_id": map[string]interface{}{
+					"type": "keyword",
+				},
+				"community_id": map[string]interface{}{
+					"type": "integer",
+				},
+				"title": map[string]interface{}{
+					"type":     "text",
+					"analyzer": "ik_max_word",
+				},
+				"summary": map[string]interface{}{
+					"type":     "text",
+					"analyzer": "ik_max_word",
+				},
+				"rating": map[string]interface{}{
+					"type": "float",
+				},
+				"rating_explanation": map[string]interface{}{
+					"type":  "text",
+					"index": false,
+				},
+				"findings": map[string]interface{}{
+					"type":    "object",
+					"enabled": false,
+				},
+				"entities": map[string]interface{}{
+					"type": "keyword",
+				},
+				"content": map[string]interface{}{
+					"type":     "text",
+					"analyzer": "ik_max_word",
+				},
+				"embedding": map[string]interface{}{
+					"type":       "dense_vector",
+					"dims":       DefaultEmbeddingDims,
+					"index":      true,
+					"similarity": "cosine",
+				},
+				"updated_at": map[string]interface{}{
+					"type": "date",
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(mapping); err != nil {
+		return err
+	}
+
+	resp, err := m.client.Indices.Create(
+		m.index,
+		m.client.Indices.Create.WithContext(ctx),
+		m.client.Indices.Create.WithBody(&buf),
+	)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		// Ignore if it already exists (race condition)
+		if resp.StatusCode == 400 {
+			return nil
+		}
+		return fmt.Errorf("create index failed: %s", resp.String())
+	}
+	return nil
+}
+
+// Replace 先写入新报告, 再删除知识库中不在本次结果里的旧报告, 避免替换期间查询为空
+func (m *EsCommunityModel) Replace(ctx context.Context, kbId string, reports []*CommunityReport) error {
+	ids := make([]string, 0, len(reports))
+	if len(reports) > 0 {
+		var buf bytes.Buffer
+		for _, report := range reports {
+			ids = append(ids, report.Id)
+			buf.WriteString(fmt.Sprintf(`{ "index" : { "_index" : "%s", "_id" : "%s" } }%s`, m.index, report.Id, "\n"))
+			data, err := json.Marshal(report)
+			if err != nil {
+				return err
+			}
+			buf.Write(data)
+			buf.WriteString("\n")
+		}
+
+		req := esapi.BulkRequest{
+			Body:    &buf,
+			Refresh: "true",
+		}
+		res, err := req.Do(ctx, m.client)
+		if err != nil {
+			return err
+		}
+		defer res.Body.Close()
+		if res.IsError() {
+			body, _ := io.ReadAll(res.Body)
+			return fmt.Errorf("bulk indexing failed: %s, body: %s", res.Status(), string(body))
+		}
+	}
+
+	query := map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": []map[string]interface{}{
+					{"term": map[string]interface{}{"kb_id": kbId}},
+				},
+				"must_not": []map[string]interface{}{
+					{"ids": map[string]interface{}{"values": ids}},
+				},
+			},
+		},
+	}
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(query); err != nil {
+		return err
+	}
+
+	refresh := true
+	req := esapi.DeleteByQueryRequest{
+		Index:   []string{m.index},
+		Body:    &buf,
+		Refresh: &refresh,
+	}
+	res, err := req.Do(ctx, m.client)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		body, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("delete stale reports failed: %s, body: %s", res.Status(), string(body))
+	}
+	return nil
+}
+
+func (m *EsCommunityModel) List(ctx context.Context, kbId string, page, pageSize int64) ([]*CommunityReport, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+
+	query := map[string]interface{}{
+		"query": map[string]interface{}{
+			"term": map[string]interface{}{"kb_id": kbId},
+		},
+		"from":             (page - 1) * pageSize,
+		"size":             pageSize,
+		"track_total_hits": true,
+		"_source":          map[string]interface{}{"excludes": []string{"embedding"}},
+		"sort": []map[string]interface{}{
+			{"rating": map[string]interface{}{"order": "desc"}},
+			{"community_id": map[string]interface{}{"order": "asc"}},
+		},
+	}
+	return m.search(ctx, query)
+}
+
+func (m *EsCommunityModel) FindOne(ctx context.Context, id string) (*CommunityReport, error) {
+	query := map[string]interface{}{
+		"query": map[string]interface{}{
+			"ids": map[string]interface{}{"values": []string{id}},
+		},
+		"_source": map[string]interface{}{"excludes": []string{"embedding"}},
+	}
+	reports, _, err := m.search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(reports) == 0 {
+		return nil, ErrCommunityNotFound
+	}
+	return reports[0], nil
+}
+
+func (m *EsCommunityModel) FindByIds(ctx context.Context, ids []string) ([]*CommunityReport, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	query := map[string]interface{}{
+		"query": map[string]interface{}{
+			"ids": map[string]interface{}{"values": ids},
+		},
+		"size": len(ids),
+	}
+	reports, _, err := m.search(ctx, query)
+	return reports, err
+}
+
+func (m *EsCommunityModel) Search(ctx context.Context, kbId string, vector []float64, topK int) ([]*CommunityReport, error) {
+	if len(vector) == 0 || topK <= 0 {
+		return nil, nil
+	}
+
+	query := map[string]interface{}{
+		"knn": map[string]interface{}{
+			"field":          "embedding",
+			"query_vector":   vector,
+			"k":              topK,
+			"num_candidates": topK * 10,
+			"filter":         map[string]interface{}{"term": map[string]interface{}{"kb_id": kbId}},
+		},
+		"size":    topK,
+		"_source": map[string]interface{}{"excludes": []string{"embedding"}},
+	}
+	reports, _, err := m.search(ctx, query)
+	return reports, err
+}
+
+func (m *EsCommunityModel) search(ctx context.Context, query map[string]interface{}) ([]*CommunityReport, int64, error) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(query); err != nil {
+		return nil, 0, err
+	}
+
+	res, err := m.client.Search(
+		m.client.Search.WithContext(ctx),
+		m.client.Search.WithIndex(m.index),
+		m.client.Search.WithBody(&buf),
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		// 索引尚未创建时视为空
+		if res.StatusCode == 404 {
+			return nil, 0, nil
+		}
+		body, _ := io.ReadAll(res.Body)
+		return nil, 0, fmt.Errorf("search community reports failed: %s, body: %s", res.Status(), string(body))
+	}
+
+	var result struct {
+		Hits struct {
+			Total struct {
+				Value int64 `json:"value"`
+			} `json:"total"`
+			Hits []struct {
+				Source CommunityReport `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, 0, err
+	}
+
+	reports := make([]*CommunityReport, 0, len(result.Hits.Hits))
+	for i := range result.Hits.Hits {
+		reports = append(reports, &result.Hits.Hits[i].Source)
+	}
+	return reports, result.Hits.Total.Value, nil
+}
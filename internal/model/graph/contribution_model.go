@@ -0,0 +1,333 @@
+package graph
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"gozero-rag/internal/graphrag/types"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+)
+
+const (
+	DefaultContributionIndexName = "kg_graph_contribution"
+	maxContributionHits          = 10000 // 单次查询返回的贡献记录上限 (ES 默认 max_result_window)
+)
+
+// GraphContribution 单个文档对知识库图谱的贡献: 该文档抽取出的实体和关系
+// 图谱中的实体和关系由全部贡献合并得到, 文档删除或重新抽取时据此重新计算
+type GraphContribution struct {
+	Id           string           `json:"id"`
+	KbId         string           `json:"kb_id"`
+	DocId        string           `json:"doc_id"`
+	EntityNames  []string         `json:"entity_names"`  // 实体名称, 用于按实体反查贡献
+	RelationKeys []string         `json:"relation_keys"` // 关系键 (见 RelationKey), 用于按关系反查贡献
+	Entities     []types.Entity   `json:"entities"`
+	Relations    []types.Relation `json:"relations"`
+	UpdatedAt    string           `json:"updated_at"`
+}
+
+// ContributionId 按知识库和文档生成贡献记录 ID
+func ContributionId(kbId, docId string) string {
+	hash := md5.Sum([]byte(kbId + docId))
+	return "contribution_" + hex.EncodeToString(hash[:])
+}
+
+// RelationKey 关系的唯一键, 与抽取结果合并时的键一致
+func RelationKey(src, dst string) string {
+	return src + "->" + dst
+}
+
+type ContributionModel interface {
+	// Save 按 ID 覆盖写入贡献记录
+	Save(ctx context.Context, contributions []*GraphContribution) error
+	FindByDocIds(ctx context.Context, kbId string, docIds []string) ([]*GraphContribution, error)
+	// FindByGraphKeys 查询包含任一实体或关系的贡献记录
+	FindByGraphKeys(ctx context.Context, kbId string, entityNames, relationKeys []string) ([]*GraphContribution, error)
+	Delete(ctx context.Context, ids []string) error
+	DeleteByKbId(ctx context.Context, kbId string) error
+}
+
+type EsContributionModel struct {
+	client *elasticsearch.Client
+	index  string
+}
+
+func NewEsContributionModel(addresses []string, username, password string) (*EsContributionModel, error) {
+	client, err := elasticsearch.NewClient(elasticsearch.Config{
+		Addresses: addresses,
+		Username:  username,
+		Password:  password,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	model := &EsContributionModel{
+		client: client,
+		index:  DefaultContributionIndexName,
+	}
+	if err := model.SetupIndex(context.Background()); err != nil {
+		return nil, err
+	}
+	return model, nil
+}
+
+func (m *EsContributionModel) SetupIndex(ctx context.Context) error {
+	res, err := m.client.Indices.Exists([]string{m.index}, m.client.Indices.Exists.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == 200 {
+		return nil
+	}
+
+	mapping := map[string]interface{}{
+		"mappings": map[string]interface{}{
+			"properties": map[string]interface{}{
+				"id": map[string]interface{}{
+					"type": "keyword",
+				},
+				"kb_id": map[string]interface{}{
+					"type": "keyword",
+				},
+				"doc_id": map[string]interface{}{
+					"type": "keyword",
+				},
+				"entity_names": map[string]interface{}{
+					"type": "keyword",
+				},
+				"relation_keys": map[string]interface{}{
+					"type": "keyword",
+				},
+				// 贡献明细只做存储, 不建索引
+				"entities": map[string]interface{}{
+					"type":    "object",
+					"enabled": false,
+				},
+				"relations": map[string]interface{}{
+					"type":    "object",
+					"enabled": false,
+				},
+				"updated_at": map[string]interface{}{
+					"type": "date",
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(mapping); err != nil {
+		return err
+	}
+
+	resp, err := m.client.Indices.Create(
+		m.index,
+		m.client.Indices.Create.WithContext(ctx),
+		m.client.Indices.Create.WithBody(&buf),
+	)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		// Ignore if it already exists (race condition)
+		if resp.StatusCode == 400 {
+			return nil
+		}
+		return fmt.Errorf("create index failed: %s", resp.String())
+	}
+	return nil
+}
+
+func (m *EsContributionModel) Save(ctx context.Context, contributions []*GraphContribution) error {
+	if len(contributions) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	for _, c := range contributions {
+		buf.WriteString(fmt.Sprintf(`{ "index" : { "_index" : "%s", "_id" : "%s" } }%s`, m.index, c.Id, "\n"))
+		data, err := json.Marshal(c)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+		buf.WriteString("\n")
+	}
+
+	req := esapi.BulkRequest{
+		Body:    &buf,
+		Refresh: "true",
+	}
+	res, err := req.Do(ctx, m.client)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		body, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("bulk indexing failed: %s, body: %s", res.Status(), string(body))
+	}
+	return nil
+}
+
+func (m *EsContributionModel) FindByDocIds(ctx context.Context, kbId string, docIds []string) ([]*GraphContribution, error) {
+	if len(docIds) == 0 {
+		return nil, nil
+	}
+	query := map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": []map[string]interface{}{
+					{"term": map[string]interface{}{"kb_id": kbId}},
+					{"terms": map[string]interface{}{"doc_id": docIds}},
+				},
+			},
+		},
+		"size": maxContributionHits,
+	}
+	return m.search(ctx, query)
+}
+
+func (m *EsContributionModel) FindByGraphKeys(ctx context.Context, kbId string, entityNames, relationKeys []string) ([]*GraphContribution, error) {
+	var should []map[string]interface{}
+	if len(entityNames) > 0 {
+		should = append(should, map[string]interface{}{"terms": map[string]interface{}{"entity_names": entityNames}})
+	}
+	if len(relationKeys) > 0 {
+		should = append(should, map[string]interface{}{"terms": map[string]interface{}{"relation_keys": relationKeys}})
+	}
+	if len(should) == 0 {
+		return nil, nil
+	}
+
+	query := map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": []map[string]interface{}{
+					{"term": map[string]interface{}{"kb_id": kbId}},
+				},
+				"should":               should,
+				"minimum_should_match": 1,
+			},
+		},
+		"size": maxContributionHits,
+	}
+	return m.search(ctx, query)
+}
+
+func (m *EsContributionModel) Delete(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	for _, id := range ids {
+		buf.WriteString(fmt.Sprintf(`{ "delete" : { "_index" : "%s", "_id" : "%s" } }%s`, m.index, id, "\n"))
+	}
+
+	req := esapi.BulkRequest{
+		Body:    &buf,
+		Refresh: "true",
+	}
+	res, err := req.Do(ctx, m.client)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		body, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("bulk delete failed: %s, body: %s", res.Status(), string(body))
+	}
+	return nil
+}
+
+func (m *EsContributionModel) DeleteByKbId(ctx context.Context, kbId string) error {
+	return deleteByKbId(ctx, m.client, m.index, kbId)
+}
+
+func (m *EsContributionModel) search(ctx context.Context, query map[string]interface{}) ([]*GraphContribution, error) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(query); err != nil {
+		return nil, err
+	}
+
+	res, err := m.client.Search(
+		m.client.Search.WithContext(ctx),
+		m.client.Search.WithIndex(m.index),
+		m.client.Search.WithBody(&buf),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		// 索引尚未创建时视为空
+		if res.StatusCode == 404 {
+			return nil, nil
+		}
+		body, _ := io.ReadAll(res.Body)
+		return nil, fmt.Errorf("search graph contributions failed: %s, body: %s", res.Status(), string(body))
+	}
+
+	var result struct {
+		Hits struct {
+			Hits []struct {
+				Source GraphContribution `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	contributions := make([]*GraphContribution, 0, len(result.Hits.Hits))
+	for i := range result.Hits.Hits {
+		contributions = append(contributions, &result.Hits.Hits[i].Source)
+	}
+	return contributions, nil
+}
+
+// deleteByKbId 删除索引中属于知识库的全部文档
+func deleteByKbId(ctx context.Context, client *elasticsearch.Client, index, kbId string) error {
+	query := map[string]interface{}{
+		"query": map[string]interface{}{
+			"term": map[string]interface{}{"kb_id": kbId},
+		},
+	}
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(query); err != nil {
+		return err
+	}
+
+	refresh := true
+	req := esapi.DeleteByQueryRequest{
+		Index:   []string{index},
+		Body:    &buf,
+		Refresh: &refresh,
+	}
+	res, err := req.Do(ctx, client)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		if res.StatusCode == 404 {
+			return nil
+		}
+		body, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("delete by kb_id failed: %s, body: %s", res.Status(), string(body))
+	}
+	return nil
+}
@@ -3,10 +3,13 @@ package graph
 import (
 	"bytes"
 	"context"
+	"crypto/md5"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/elastic/go-elasticsearch/v8"
 	"github.com/elastic/go-elasticsearch/v8/esapi"
@@ -31,15 +34,38 @@ type EsGraphDocument struct {
 	Description string    `json:"description"`
 	Weight      float64   `json:"weight"`
 	SourceIds   []string  `json:"source_ids"`
-	Embedding   []float64 `json:"embedding,omitempty"` // 实体向量嵌入 (仅 entity)
+	Embedding   []float64 `json:"embedding,omitempty"`   // 实体向量嵌入 (仅 entity)
+	EntityType  string    `json:"entity_type,omitempty"` // 实体类型 (仅 entity)
+	Aliases     []string  `json:"aliases,omitempty"`     // 实体别名 (仅 entity), 由实体归一化合并而来
+	DocIds      []string  `json:"doc_ids,omitempty"`     // 贡献该实体的文档 id
 
 	ContentWithWeight string `json:"content_with_weight"` // 原始 JSON 备份
 	UpdatedAt         string `json:"updated_at"`
 }
 
+// SimilarEntity 向量相似的实体及其余弦相似度
+type SimilarEntity struct {
+	*EsGraphDocument
+	Similarity float64
+}
+
 type GraphModel interface {
 	Put(ctx context.Context, docs []*EsGraphDocument) error
-	// TODO: Add search methods if needed
+	// SearchSimilarEntities 在知识库内按向量检索相似实体
+	SearchSimilarEntities(ctx context.Context, kbId string, vector []float64, topK int) ([]*SimilarEntity, error)
+	// Save 按 ID 覆盖写入图谱文档, Embedding 为空时保留已有向量
+	Save(ctx context.Context, docs []*EsGraphDocument) error
+	// Delete 按 ID 删除图谱文档
+	Delete(ctx context.Context, ids []string) error
+	// RenameRelationEntities 将关系文档中的别名端点改写为规范实体名, aliases: 别名 -> 规范实体名
+	RenameRelationEntities(ctx context.Context, kbId string, aliases map[string]string) error
+	DeleteByKbId(ctx context.Context, kbId string) error
+}
+
+// EntityDocId 实体在 kg_graph 中的文档 ID, 同一知识库内按名称确定
+func EntityDocId(kbId, name string) string {
+	hash := md5.Sum([]byte(kbId + name))
+	return "entity_" + hex.EncodeToString(hash[:])
 }
 
 type EsGraphModel struct {
@@ -129,6 +155,15 @@ func (m *EsGraphModel) SetupIndex(ctx context.Context) error {
 				"source_ids": map[string]interface{}{
 					"type": "keyword",
 				},
+				"entity_type": map[string]interface{}{
+					"type": "keyword",
+				},
+				"aliases": map[string]interface{}{
+					"type": "keyword",
+				},
+				"doc_ids": map[string]interface{}{
+					"type": "keyword",
+				},
 				"content_with_weight": map[string]interface{}{
 					"type":  "text",
 					"index": false,
@@ -208,9 +243,26 @@ if (ctx._source.graph_type == 'relation') {
 // but usually Entity weight is frequency or relevance. Let's assume average for now to be consistent.
 // Or if Entity has fixed weight 1.0, average remains 1.0.
 
-// 3. Merge Description
-if (params.new_description != null && !ctx._source.description.contains(params.new_description)) {
-	ctx._source.description = ctx._source.description + "\n" + params.new_description;
+// 3. Merge Description (新描述已包含旧描述时直接替换, 如实体归一化后的合并描述)
+if (params.new_description != null) {
+	if (params.new_description.contains(ctx._source.description)) {
+		ctx._source.description = params.new_description;
+	} else if (!ctx._source.description.contains(params.new_description)) {
+		ctx._source.description = ctx._source.description + "\n" + params.new_description;
+	}
+}
+
+// 4. Merge Aliases
+if (params.new_aliases != null) {
+	if (ctx._source.aliases == null) {
+		ctx._source.aliases = params.new_aliases;
+	} else {
+		for (item in params.new_aliases) {
+			if (!ctx._source.aliases.contains(item)) {
+				ctx._source.aliases.add(item);
+			}
+		}
+	}
 }
 ctx._source.updated_at = params.now;
 `,
@@ -219,6 +271,7 @@ ctx._source.updated_at = params.now;
 				"new_source_ids":  doc.SourceIds,
 				"new_weight":      doc.Weight,
 				"new_description": doc.Description,
+				"new_aliases":     doc.Aliases,
 				"now":             doc.UpdatedAt,
 			},
 		}
@@ -258,3 +311,218 @@ ctx._source.updated_at = params.now;
 
 	return nil
 }
+
+func (m *EsGraphModel) SearchSimilarEntities(ctx context.Context, kbId string, vector []float64, topK int) ([]*SimilarEntity, error) {
+	if len(vector) == 0 || topK <= 0 {
+		return nil, nil
+	}
+
+	// 不按 entity_type 过滤: 旧数据未记录该字段, 类型由调用方比较
+	filters := []map[string]interface{}{
+		{"term": map[string]interface{}{"kb_id": kbId}},
+		{"term": map[string]interface{}{"graph_type": "entity"}},
+	}
+
+	queryBody := map[string]interface{}{
+		"knn": map[string]interface{}{
+			"field":          "embedding",
+			"query_vector":   vector,
+			"k":              topK,
+			"num_candidates": topK * 10,
+			"filter":         map[string]interface{}{"bool": map[string]interface{}{"filter": filters}},
+		},
+		"size":    topK,
+		"_source": map[string]interface{}{"excludes": []string{"embedding"}},
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(queryBody); err != nil {
+		return nil, err
+	}
+
+	res, err := m.client.Search(
+		m.client.Search.WithContext(ctx),
+		m.client.Search.WithIndex(m.index),
+		m.client.Search.WithBody(&buf),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		body, _ := io.ReadAll(res.Body)
+		return nil, fmt.Errorf("search similar entities failed: %s, body: %s", res.Status(), string(body))
+	}
+
+	var result struct {
+		Hits struct {
+			Hits []struct {
+				Score  float64         `json:"_score"`
+				Source EsGraphDocument `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	entities := make([]*SimilarEntity, 0, len(result.Hits.Hits))
+	for i := range result.Hits.Hits {
+		hit := &result.Hits.Hits[i]
+		entities = append(entities, &SimilarEntity{
+			EsGraphDocument: &hit.Source,
+			// cosine 相似度在 ES 中的得分为 (1 + cos) / 2
+			Similarity: 2*hit.Score - 1,
+		})
+	}
+	return entities, nil
+}
+
+func (m *EsGraphModel) Save(ctx context.Context, docs []*EsGraphDocument) error {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	for _, doc := range docs {
+		data, err := json.Marshal(doc)
+		if err != nil {
+			return err
+		}
+		fields := make(map[string]interface{})
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return err
+		}
+		// 局部更新时数组字段整体替换, 需显式写入空数组才能清除旧值
+		if _, ok := fields["aliases"]; !ok {
+			fields["aliases"] = []string{}
+		}
+		if _, ok := fields["doc_ids"]; !ok {
+			fields["doc_ids"] = []string{}
+		}
+
+		payload, err := json.Marshal(map[string]interface{}{
+			"doc":           fields,
+			"doc_as_upsert": true,
+		})
+		if err != nil {
+			return err
+		}
+		buf.WriteString(fmt.Sprintf(`{ "update" : { "_index" : "%s", "_id" : "%s" } }%s`, m.index, doc.Id, "\n"))
+		buf.Write(payload)
+		buf.WriteString("\n")
+	}
+
+	req := esapi.BulkRequest{
+		Body:    &buf,
+		Refresh: "true",
+	}
+	res, err := req.Do(ctx, m.client)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		body, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("bulk update failed: %s, body: %s", res.Status(), string(body))
+	}
+	return nil
+}
+
+func (m *EsGraphModel) DeleteByKbId(ctx context.Context, kbId string) error {
+	return deleteByKbId(ctx, m.client, m.index, kbId)
+}
+
+func (m *EsGraphModel) Delete(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	for _, id := range ids {
+		buf.WriteString(fmt.Sprintf(`{ "delete" : { "_index" : "%s", "_id" : "%s" } }%s`, m.index, id, "\n"))
+	}
+
+	req := esapi.BulkRequest{
+		Body:    &buf,
+		Refresh: "true",
+	}
+	res, err := req.Do(ctx, m.client)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		body, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("bulk delete failed: %s, body: %s", res.Status(), string(body))
+	}
+	return nil
+}
+
+func (m *EsGraphModel) RenameRelationEntities(ctx context.Context, kbId string, aliases map[string]string) error {
+	if len(aliases) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(aliases))
+	for alias := range aliases {
+		names = append(names, alias)
+	}
+
+	body := map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": []map[string]interface{}{
+					{"term": map[string]interface{}{"kb_id": kbId}},
+					{"term": map[string]interface{}{"graph_type": "relation"}},
+				},
+				"should": []map[string]interface{}{
+					{"terms": map[string]interface{}{"src_name": names}},
+					{"terms": map[string]interface{}{"dst_name": names}},
+				},
+				"minimum_should_match": 1,
+			},
+		},
+		"script": map[string]interface{}{
+			"source": `
+if (params.aliases.containsKey(ctx._source.src_name)) {
+	ctx._source.src_name = params.aliases[ctx._source.src_name];
+}
+if (params.aliases.containsKey(ctx._source.dst_name)) {
+	ctx._source.dst_name = params.aliases[ctx._source.dst_name];
+}
+ctx._source.updated_at = params.now;
+`,
+			"lang": "painless",
+			"params": map[string]interface{}{
+				"aliases": aliases,
+				"now":     time.Now().Format(time.RFC3339),
+			},
+		},
+	}
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(body); err != nil {
+		return err
+	}
+
+	refresh := true
+	req := esapi.UpdateByQueryRequest{
+		Index:     []string{m.index},
+		Body:      &buf,
+		Refresh:   &refresh,
+		Conflicts: "proceed",
+	}
+	res, err := req.Do(ctx, m.client)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		body, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("update relations by query failed: %s, body: %s", res.Status(), string(body))
+	}
+	return nil
+}
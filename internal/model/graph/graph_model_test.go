@@ -0,0 +1,65 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	nebula "github.com/vesoft-inc/nebula-go/v3"
+	ngen "github.com/vesoft-inc/nebula-go/v3/nebula"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 别名合并时按 src_name / dst_name 改写知识库内的关系文档
+func TestRenameRelationEntities(t *testing.T) {
+	var body struct {
+		Query  map[string]any `json:"query"`
+		Script struct {
+			Params struct {
+				Aliases map[string]string `json:"aliases"`
+			} `json:"params"`
+		} `json:"script"`
+	}
+	called := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		if r.Method == http.MethodPost && r.URL.Path == "/"+DefaultGraphIndexName+"/_update_by_query" {
+			called = true
+			assert.Equal(t, "proceed", r.URL.Query().Get("conflicts"))
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			_, _ = w.Write([]byte(`{"updated":1}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	client, err := elasticsearch.NewClient(elasticsearch.Config{Addresses: []string{srv.URL}})
+	require.NoError(t, err)
+	m := &EsGraphModel{client: client, index: DefaultGraphIndexName}
+
+	require.NoError(t, m.RenameRelationEntities(context.Background(), "kb-1", nil))
+	assert.False(t, called)
+
+	aliases := map[string]string{"字节跳动公司": "字节跳动"}
+	require.NoError(t, m.RenameRelationEntities(context.Background(), "kb-1", aliases))
+	require.True(t, called)
+	assert.Equal(t, aliases, body.Script.Params.Aliases)
+
+	query, err := json.Marshal(body.Query)
+	require.NoError(t, err)
+	assert.Contains(t, string(query), `{"term":{"graph_type":"relation"}}`)
+	assert.Contains(t, string(query), `{"terms":{"src_name":["字节跳动公司"]}}`)
+	assert.Contains(t, string(query), `{"terms":{"dst_name":["字节跳动公司"]}}`)
+}
+
+func TestIsExistedError(t *testing.T) {
+	assert.True(t, isExistedError(nebula.ErrorCode(ngen.ErrorCode_E_EXISTED), ""))
+	assert.True(t, isExistedError(nebula.ErrorCode(ngen.ErrorCode_E_EXECUTION_ERROR), "Existed!"))
+	assert.False(t, isExistedError(nebula.ErrorCode(ngen.ErrorCode_E_SEMANTIC_ERROR), "SemanticError: prop not found"))
+}
@@ -25,12 +25,27 @@ type NebulaGraphModel interface {
 	// BatchInsertRelations 批量写入关系到指定 kbId 的 Space
 	BatchInsertRelations(ctx context.Context, kbId string, relations []types.Relation) error
 
+	// MergeEntity 将别名实体的边迁移到规范实体并删除别名实体, 别名实体不存在时忽略
+	MergeEntity(ctx context.Context, kbId string, alias string, canonical string) error
+
 	// GetGraph 获取图谱数据 (支持 limit 限制)
 	GetGraph(ctx context.Context, kbId string, limit int) ([]types.Entity, []types.Relation, error)
 
 	// SearchGraphNodes 搜索图谱节点
 	SearchGraphNodes(ctx context.Context, kbId string, query string) ([]types.Entity, error)
 
+	// DeleteEntities 删除实体及其关联的边
+	DeleteEntities(ctx context.Context, kbId string, names []string) error
+
+	// DeleteRelations 删除关系 (按 SrcId -> DstId)
+	DeleteRelations(ctx context.Context, kbId string, relations []types.Relation) error
+
+	// DropSpace 删除知识库对应的 Space, Space 不存在时忽略
+	DropSpace(ctx context.Context, kbId string) error
+
+	// ExpandNeighbors 从给定实体出发沿关系 (不区分方向) 扩展 hops 跳, 返回途经的实体和关系, 关系数不超过 limit
+	ExpandNeighbors(ctx context.Context, kbId string, names []string, hops int, limit int) ([]types.Entity, []types.Relation, error)
+
 	// Close 关闭连接池
 	Close()
 }
@@ -91,6 +106,23 @@ func (m *nebulaGraphModel) Close() {
 	}
 }
 
+// alterSchema 为已有 Space 补充属性, 属性已存在时忽略, 其余错误返回
+func alterSchema(session *nebula.Session, stmt string) error {
+	result, err := session.Execute(stmt)
+	if err != nil {
+		return fmt.Errorf("%s failed: %w", stmt, err)
+	}
+	if result.IsSucceed() || isExistedError(result.GetErrorCode(), result.GetErrorMsg()) {
+		return nil
+	}
+	return fmt.Errorf("%s failed: %s", stmt, result.GetErrorMsg())
+}
+
+// isExistedError 判断 Nebula 错误是否为属性已存在
+func isExistedError(code nebula.ErrorCode, msg string) bool {
+	return code == nebula.ErrorCode(ngen.ErrorCode_E_EXISTED) || strings.Contains(strings.ToLower(msg), "existed")
+}
+
 // getSpaceName 根据 kbId 生成 Space 名称
 func getSpaceName(kbId string) string {
 	safeKbId := strings.ReplaceAll(kbId, "-", "_")
@@ -135,12 +167,21 @@ func (m *nebulaGraphModel) EnsureSpaceAndSchema(ctx context.Context, kbId string
 			name string,
 			type string,
 			description string,
-			source_ids string
+			source_ids string,
+			aliases string,
+			doc_ids string
 		);
 	`
 	if _, err := session.Execute(createEntityTagNgql); err != nil {
 		return fmt.Errorf("create entity tag failed: %w", err)
 	}
+	// 兼容已有 Space: 补充 aliases / doc_ids 属性
+	if err := alterSchema(session, "ALTER TAG entity ADD (aliases string);"); err != nil {
+		return err
+	}
+	if err := alterSchema(session, "ALTER TAG entity ADD (doc_ids string);"); err != nil {
+		return err
+	}
 
 	// 4. 创建 EdgeType: relates_to
 	createEdgeNgql := `
@@ -148,12 +189,16 @@ func (m *nebulaGraphModel) EnsureSpaceAndSchema(ctx context.Context, kbId string
 			type string,
 			description string,
 			weight double,
-			source_ids string
+			source_ids string,
+			doc_ids string
 		);
 	`
 	if _, err := session.Execute(createEdgeNgql); err != nil {
 		return fmt.Errorf("create edge type failed: %w", err)
 	}
+	if err := alterSchema(session, "ALTER EDGE relates_to ADD (doc_ids string);"); err != nil {
+		return err
+	}
 
 	// 5. 创建 Index (MATCH查询需要)
 	createIndexNgql := "CREATE TAG INDEX IF NOT EXISTS entity_index ON entity(name(64));"
@@ -206,8 +251,11 @@ func (m *nebulaGraphModel) BatchUpsertEntities(ctx context.Context, kbId string,
 			SET name = "%s",
 				type = "%s",
 				description = "%s",
-				source_ids = "%s";
-		`, vid, escapeString(e.Name), escapeString(e.Type), escapeString(e.Description), escapeString(sourceIdsStr))
+				source_ids = "%s",
+				aliases = "%s",
+				doc_ids = "%s";
+		`, vid, escapeString(e.Name), escapeString(e.Type), escapeString(e.Description), escapeString(sourceIdsStr),
+			escapeString(strings.Join(e.Aliases, ",")), escapeString(strings.Join(e.DocIds, ",")))
 
 		result, err := session.Execute(ngql)
 		if err != nil {
@@ -257,9 +305,9 @@ func (m *nebulaGraphModel) BatchInsertRelations(ctx context.Context, kbId string
 		sourceIdsStr := strings.Join(r.SourceId, ",")
 
 		ngql := fmt.Sprintf(`
-			INSERT EDGE relates_to(type, description, weight, source_ids) VALUES 
-			"%s"->"%s":("%s", "%s", %f, "%s");
-		`, srcVid, dstVid, escapeString(r.Type), escapeString(r.Description), r.Weight, escapeString(sourceIdsStr))
+			INSERT EDGE relates_to(type, description, weight, source_ids, doc_ids) VALUES 
+			"%s"->"%s":("%s", "%s", %f, "%s", "%s");
+		`, srcVid, dstVid, escapeString(r.Type), escapeString(r.Description), r.Weight, escapeString(sourceIdsStr), escapeString(strings.Join(r.DocIds, ",")))
 
 		result, err := session.Execute(ngql)
 		if err != nil {
@@ -277,6 +325,105 @@ func (m *nebulaGraphModel) BatchInsertRelations(ctx context.Context, kbId string
 	return nil
 }
 
+// MergeEntity 将别名实体的出边/入边迁移到规范实体, 然后删除别名实体及其边
+func (m *nebulaGraphModel) MergeEntity(ctx context.Context, kbId string, alias string, canonical string) error {
+	if alias == canonical {
+		return nil
+	}
+	spaceName := getSpaceName(kbId)
+
+	session, err := m.pool.GetSession(m.username, m.password)
+	if err != nil {
+		return fmt.Errorf("get session failed: %w", err)
+	}
+	defer session.Release()
+
+	if result, err := session.Execute(fmt.Sprintf("USE %s;", spaceName)); err != nil {
+		return fmt.Errorf("use space failed: %w", err)
+	} else if !result.IsSucceed() {
+		return fmt.Errorf("use space failed: %s", result.GetErrorMsg())
+	}
+
+	aliasVid := escapeVid(alias)
+	var relations []types.Relation
+
+	// 出边: alias -> dst 迁移为 canonical -> dst
+	outNgql := fmt.Sprintf(`GO FROM "%s" OVER relates_to YIELD dst(edge) AS peer, properties(edge) AS edge;`, aliasVid)
+	outRows, err := m.queryEdges(session, outNgql)
+	if err != nil {
+		return err
+	}
+	for _, row := range outRows {
+		row.relation.SrcId, row.relation.DstId = canonical, row.peer
+		relations = append(relations, row.relation)
+	}
+
+	// 入边: src -> alias 迁移为 src -> canonical
+	inNgql := fmt.Sprintf(`GO FROM "%s" OVER relates_to REVERSELY YIELD src(edge) AS peer, properties(edge) AS edge;`, aliasVid)
+	inRows, err := m.queryEdges(session, inNgql)
+	if err != nil {
+		return err
+	}
+	for _, row := range inRows {
+		row.relation.SrcId, row.relation.DstId = row.peer, canonical
+		relations = append(relations, row.relation)
+	}
+
+	// 去掉合并后形成的自环
+	kept := relations[:0]
+	for _, r := range relations {
+		if r.SrcId != r.DstId && r.SrcId != alias && r.DstId != alias {
+			kept = append(kept, r)
+		}
+	}
+	if err := m.BatchInsertRelations(ctx, kbId, kept); err != nil {
+		return err
+	}
+
+	result, err := session.Execute(fmt.Sprintf(`DELETE VERTEX "%s" WITH EDGE;`, aliasVid))
+	if err != nil {
+		return fmt.Errorf("delete alias vertex failed: %w", err)
+	}
+	if !result.IsSucceed() {
+		return fmt.Errorf("delete alias vertex failed: %s", result.GetErrorMsg())
+	}
+
+	logx.Infof("merged entity %s into %s, moved %d relations (space: %s)", alias, canonical, len(kept), spaceName)
+	return nil
+}
+
+// edgeRow GO 查询返回的对端实体和边属性
+type edgeRow struct {
+	peer     string
+	relation types.Relation
+}
+
+func (m *nebulaGraphModel) queryEdges(session *nebula.Session, ngql string) ([]edgeRow, error) {
+	resultSet, err := session.Execute(ngql)
+	if err != nil {
+		return nil, fmt.Errorf("execute ngql failed: %w", err)
+	}
+	if !resultSet.IsSucceed() {
+		return nil, fmt.Errorf("query edges failed: %s", resultSet.GetErrorMsg())
+	}
+
+	rows := make([]edgeRow, 0, len(resultSet.GetRows()))
+	for _, row := range resultSet.GetRows() {
+		if len(row.Values) < 2 || len(row.Values[0].SVal) == 0 {
+			continue
+		}
+		var edgeKvs map[string]*ngen.Value
+		if row.Values[1].GetMVal() != nil {
+			edgeKvs = row.Values[1].GetMVal().Kvs
+		}
+		rows = append(rows, edgeRow{
+			peer:     string(row.Values[0].SVal),
+			relation: parseRelationFromMap(edgeKvs),
+		})
+	}
+	return rows, nil
+}
+
 // GetGraph 获取图谱数据 (支持 limit 限制)
 func (m *nebulaGraphModel) GetGraph(ctx context.Context, kbId string, limit int) ([]types.Entity, []types.Relation, error) {
 	spaceName := getSpaceName(kbId)
@@ -408,6 +555,186 @@ func (m *nebulaGraphModel) SearchGraphNodes(ctx context.Context, kbId string, qu
 	return entities, nil
 }
 
+// DeleteEntities 删除实体顶点及其关联的边
+func (m *nebulaGraphModel) DeleteEntities(ctx context.Context, kbId string, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	ngql := fmt.Sprintf(`DELETE VERTEX %s WITH EDGE;`, joinVids(names))
+	if err := m.executeInSpace(kbId, ngql); err != nil {
+		return fmt.Errorf("delete entities failed: %w", err)
+	}
+	logx.Infof("deleted %d entities from space %s", len(names), getSpaceName(kbId))
+	return nil
+}
+
+// DeleteRelations 删除 relates_to 边
+func (m *nebulaGraphModel) DeleteRelations(ctx context.Context, kbId string, relations []types.Relation) error {
+	if len(relations) == 0 {
+		return nil
+	}
+	edges := make([]string, 0, len(relations))
+	for _, r := range relations {
+		edges = append(edges, fmt.Sprintf(`"%s"->"%s"`, escapeVid(r.SrcId), escapeVid(r.DstId)))
+	}
+	ngql := fmt.Sprintf(`DELETE EDGE relates_to %s;`, strings.Join(edges, ", "))
+	if err := m.executeInSpace(kbId, ngql); err != nil {
+		return fmt.Errorf("delete relations failed: %w", err)
+	}
+	logx.Infof("deleted %d relations from space %s", len(relations), getSpaceName(kbId))
+	return nil
+}
+
+// DropSpace 删除知识库对应的 Space
+func (m *nebulaGraphModel) DropSpace(ctx context.Context, kbId string) error {
+	spaceName := getSpaceName(kbId)
+
+	session, err := m.pool.GetSession(m.username, m.password)
+	if err != nil {
+		return fmt.Errorf("get session failed: %w", err)
+	}
+	defer session.Release()
+
+	result, err := session.Execute(fmt.Sprintf("DROP SPACE IF EXISTS %s;", spaceName))
+	if err != nil {
+		return fmt.Errorf("drop space failed: %w", err)
+	}
+	if !result.IsSucceed() {
+		return fmt.Errorf("drop space failed: %s", result.GetErrorMsg())
+	}
+
+	logx.Infof("dropped nebula space %s for kb: %s", spaceName, kbId)
+	return nil
+}
+
+// executeInSpace 切换到知识库的 Space 后执行 nGQL, Space 不存在时视为成功
+func (m *nebulaGraphModel) executeInSpace(kbId string, ngql string) error {
+	session, err := m.pool.GetSession(m.username, m.password)
+	if err != nil {
+		return fmt.Errorf("get session failed: %w", err)
+	}
+	defer session.Release()
+
+	result, err := session.Execute(fmt.Sprintf("USE %s;", getSpaceName(kbId)))
+	if err != nil {
+		return fmt.Errorf("use space failed: %w", err)
+	}
+	if !result.IsSucceed() {
+		if strings.Contains(result.GetErrorMsg(), "SpaceNotFound") {
+			return nil
+		}
+		return fmt.Errorf("use space failed: %s", result.GetErrorMsg())
+	}
+
+	result, err = session.Execute(ngql)
+	if err != nil {
+		return fmt.Errorf("execute ngql failed: %w", err)
+	}
+	if !result.IsSucceed() {
+		return fmt.Errorf("%s", result.GetErrorMsg())
+	}
+	return nil
+}
+
+// ExpandNeighbors 逐跳执行 GO ... BIDIRECT 扩展邻居, 最后批量 FETCH 实体属性
+func (m *nebulaGraphModel) ExpandNeighbors(ctx context.Context, kbId string, names []string, hops int, limit int) ([]types.Entity, []types.Relation, error) {
+	if len(names) == 0 || hops <= 0 || limit <= 0 {
+		return nil, nil, nil
+	}
+	spaceName := getSpaceName(kbId)
+
+	session, err := m.pool.GetSession(m.username, m.password)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get session failed: %w", err)
+	}
+	defer session.Release()
+
+	if result, err := session.Execute(fmt.Sprintf("USE %s;", spaceName)); err != nil {
+		return nil, nil, fmt.Errorf("use space failed: %w", err)
+	} else if !result.IsSucceed() {
+		// 知识库尚未构建图谱
+		if strings.Contains(result.GetErrorMsg(), "SpaceNotFound") {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("use space failed: %s", result.GetErrorMsg())
+	}
+
+	visited := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		visited[name] = struct{}{}
+	}
+	seenEdges := make(map[string]struct{})
+	var relations []types.Relation
+
+	frontier := names
+	for hop := 0; hop < hops && len(frontier) > 0 && len(relations) < limit; hop++ {
+		ngql := fmt.Sprintf(`GO FROM %s OVER relates_to BIDIRECT YIELD src(edge) AS src, dst(edge) AS dst, properties(edge) AS edge | LIMIT %d;`,
+			joinVids(frontier), limit-len(relations))
+		resultSet, err := session.Execute(ngql)
+		if err != nil {
+			return nil, nil, fmt.Errorf("execute ngql failed: %w", err)
+		}
+		if !resultSet.IsSucceed() {
+			return nil, nil, fmt.Errorf("expand neighbors failed: %s", resultSet.GetErrorMsg())
+		}
+
+		var next []string
+		for _, row := range resultSet.GetRows() {
+			if len(row.Values) < 3 || len(row.Values[0].SVal) == 0 || len(row.Values[1].SVal) == 0 {
+				continue
+			}
+			src, dst := string(row.Values[0].SVal), string(row.Values[1].SVal)
+			// BIDIRECT 下同一条边可能从两端各返回一次
+			key := src + "\x00" + dst
+			if _, ok := seenEdges[key]; ok {
+				continue
+			}
+			seenEdges[key] = struct{}{}
+
+			var edgeKvs map[string]*ngen.Value
+			if row.Values[2].GetMVal() != nil {
+				edgeKvs = row.Values[2].GetMVal().Kvs
+			}
+			rel := parseRelationFromMap(edgeKvs)
+			rel.SrcId, rel.DstId = src, dst
+			relations = append(relations, rel)
+
+			for _, name := range []string{src, dst} {
+				if _, ok := visited[name]; !ok {
+					visited[name] = struct{}{}
+					next = append(next, name)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	all := make([]string, 0, len(visited))
+	for name := range visited {
+		all = append(all, name)
+	}
+	resultSet, err := session.Execute(fmt.Sprintf(`FETCH PROP ON entity %s YIELD properties(vertex) AS node;`, joinVids(all)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("execute ngql failed: %w", err)
+	}
+	if !resultSet.IsSucceed() {
+		return nil, nil, fmt.Errorf("fetch entities failed: %s", resultSet.GetErrorMsg())
+	}
+
+	entities := make([]types.Entity, 0, len(all))
+	for _, row := range resultSet.GetRows() {
+		if len(row.Values) < 1 || row.Values[0].GetMVal() == nil {
+			continue
+		}
+		entity := parseEntityFromMap(row.Values[0].GetMVal().Kvs)
+		if entity.Name != "" {
+			entities = append(entities, entity)
+		}
+	}
+
+	return entities, relations, nil
+}
+
 func parseEntityFromMap(m map[string]*ngen.Value) types.Entity {
 	e := types.Entity{}
 	if m == nil {
@@ -427,6 +754,12 @@ func parseEntityFromMap(m map[string]*ngen.Value) types.Entity {
 		// 分割逗号
 		e.SourceId = strings.Split(string(val.SVal), ",")
 	}
+	if val, ok := m["aliases"]; ok && len(val.SVal) > 0 {
+		e.Aliases = strings.Split(string(val.SVal), ",")
+	}
+	if val, ok := m["doc_ids"]; ok && len(val.SVal) > 0 {
+		e.DocIds = strings.Split(string(val.SVal), ",")
+	}
 	return e
 }
 
@@ -451,6 +784,9 @@ func parseRelationFromMap(m map[string]*ngen.Value) types.Relation {
 	if val, ok := m["source_ids"]; ok && len(val.SVal) > 0 {
 		r.SourceId = strings.Split(string(val.SVal), ",")
 	}
+	if val, ok := m["doc_ids"]; ok && len(val.SVal) > 0 {
+		r.DocIds = strings.Split(string(val.SVal), ",")
+	}
 	return r
 }
 
@@ -461,6 +797,15 @@ func escapeVid(vid string) string {
 	return vid
 }
 
+// joinVids 将实体名称拼接为 nGQL 的 VID 列表
+func joinVids(names []string) string {
+	vids := make([]string, 0, len(names))
+	for _, name := range names {
+		vids = append(vids, `"`+escapeVid(name)+`"`)
+	}
+	return strings.Join(vids, ", ")
+}
+
 // escapeString 转义字符串中的特殊字符
 func escapeString(s string) string {
 	s = strings.ReplaceAll(s, `\`, `\\`)
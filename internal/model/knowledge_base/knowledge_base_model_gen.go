@@ -60,6 +60,8 @@ type (
 		Status                 int64          `db:"status"`                   // 状态: 1-启用, 0-禁用
 		ParserId               string         `db:"parser_id"`                // 解析器ID,目前仅支持 general | resume
 		ParserConfig           sql.NullString `db:"parser_config"`            // 解析器配置, 默认是 {}
+		DedupPolicy            string         `db:"dedup_policy"`             // 重复文档策略: allow|skip|reject
+		AutoParse              int64          `db:"auto_parse"`               // 上传后自动解析: 1-开启, 0-关闭
 		CreatedTime            int64          `db:"created_time"`             // 创建时间戳(ms)
 		UpdatedTime            int64          `db:"updated_time"`             // 更新时间戳(ms)
 		CreatedDate            time.Time      `db:"created_date"`             // 创建日期
@@ -144,8 +146,8 @@ func (m *defaultKnowledgeBaseModel) Insert(ctx context.Context, data *KnowledgeB
 	knowledgeBaseIdKey := fmt.Sprintf("%s%v", cacheKnowledgeBaseIdPrefix, data.Id)
 	knowledgeBaseTenantIdNameKey := fmt.Sprintf("%s%v:%v", cacheKnowledgeBaseTenantIdNamePrefix, data.TenantId, data.Name)
 	ret, err := m.ExecCtx(ctx, func(ctx context.Context, conn sqlx.SqlConn) (result sql.Result, err error) {
-		query := fmt.Sprintf("insert into %s (%s) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", m.table, knowledgeBaseRowsExpectAutoSet)
-		return conn.ExecCtx(ctx, query, data.Id, data.Avatar, data.TenantId, data.Name, data.Language, data.Description, data.EmbdId, data.Permission, data.CreatedBy, data.DocNum, data.TokenNum, data.ChunkNum, data.SimilarityThreshold, data.VectorSimilarityWeight, data.Status, data.ParserId, data.ParserConfig, data.CreatedTime, data.UpdatedTime, data.CreatedDate, data.UpdatedDate)
+		query := fmt.Sprintf("insert into %s (%s) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", m.table, knowledgeBaseRowsExpectAutoSet)
+		return conn.ExecCtx(ctx, query, data.Id, data.Avatar, data.TenantId, data.Name, data.Language, data.Description, data.EmbdId, data.Permission, data.CreatedBy, data.DocNum, data.TokenNum, data.ChunkNum, data.SimilarityThreshold, data.VectorSimilarityWeight, data.Status, data.ParserId, data.ParserConfig, data.DedupPolicy, data.AutoParse, data.CreatedTime, data.UpdatedTime, data.CreatedDate, data.UpdatedDate)
 	}, knowledgeBaseIdKey, knowledgeBaseTenantIdNameKey)
 	return ret, err
 }
@@ -170,7 +172,7 @@ func (m *defaultKnowledgeBaseModel) Update(ctx context.Context, newData *Knowled
 	knowledgeBaseTenantIdNameKey := fmt.Sprintf("%s%v:%v", cacheKnowledgeBaseTenantIdNamePrefix, data.TenantId, data.Name)
 	_, err = m.ExecCtx(ctx, func(ctx context.Context, conn sqlx.SqlConn) (result sql.Result, err error) {
 		query := fmt.Sprintf("update %s set %s where `id` = ?", m.table, knowledgeBaseRowsWithPlaceHolder)
-		return conn.ExecCtx(ctx, query, newData.Avatar, newData.TenantId, newData.Name, newData.Language, newData.Description, newData.EmbdId, newData.Permission, newData.CreatedBy, newData.DocNum, newData.TokenNum, newData.ChunkNum, newData.SimilarityThreshold, newData.VectorSimilarityWeight, newData.Status, newData.ParserId, newData.ParserConfig, newData.CreatedTime, newData.UpdatedTime, newData.CreatedDate, newData.UpdatedDate, newData.Id)
+		return conn.ExecCtx(ctx, query, newData.Avatar, newData.TenantId, newData.Name, newData.Language, newData.Description, newData.EmbdId, newData.Permission, newData.CreatedBy, newData.DocNum, newData.TokenNum, newData.ChunkNum, newData.SimilarityThreshold, newData.VectorSimilarityWeight, newData.Status, newData.ParserId, newData.ParserConfig, newData.DedupPolicy, newData.AutoParse, newData.CreatedTime, newData.UpdatedTime, newData.CreatedDate, newData.UpdatedDate, newData.Id)
 	}, knowledgeBaseIdKey, knowledgeBaseTenantIdNameKey)
 	return err
 }
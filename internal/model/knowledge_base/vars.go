@@ -3,3 +3,19 @@ package knowledge_base
 import "github.com/zeromicro/go-zero/core/stores/sqlx"
 
 var ErrNotFound = sqlx.ErrNotFound
+
+// 重复文档策略: 上传文件与知识库内已有文档内容哈希相同时的处理方式
+const (
+	DedupPolicyAllow  = "allow"  // 允许重复上传
+	DedupPolicySkip   = "skip"   // 跳过上传, 返回已有文档ID
+	DedupPolicyReject = "reject" // 拒绝上传
+)
+
+// IsValidDedupPolicy 校验重复文档策略是否合法
+func IsValidDedupPolicy(policy string) bool {
+	switch policy {
+	case DedupPolicyAllow, DedupPolicySkip, DedupPolicyReject:
+		return true
+	}
+	return false
+}
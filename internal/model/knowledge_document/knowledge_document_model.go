@@ -23,9 +23,21 @@ type (
 		CountByKnowledgeBaseId(ctx context.Context, kbId string, keyword string) (int64, error)
 		FindManyByIdsAndKbId(ctx context.Context, ids []string, kbId string) ([]*KnowledgeDocument, error)
 		UpdateRunStatus(ctx context.Context, id, status, msg string) error
+		UpdateProgress(ctx context.Context, id string, progress float64, msg string) error
+		CompareAndSetRunStatus(ctx context.Context, id, expected, status, msg string) (bool, error)
 		UpdateStatusWithChunkCount(ctx context.Context, id, status string, chunkNum, tokenNum int64) error
+		UpdateIndexResult(ctx context.Context, id, status string, chunkNum, tokenNum int64, diff ChunkDiff) error
+		IncrIndexResult(ctx context.Context, id string, chunkNum, tokenNum int64, diff ChunkDiff) error
+		UpdateParserConfig(ctx context.Context, id, parserId, parserConfig string) error
+		UpdateEffectiveConfig(ctx context.Context, id, effectiveConfig string) error
 		FindAllNonIndexingByKbId(ctx context.Context, kbId string) ([]*KnowledgeDocument, error)
+		FindUnfinishedByKbId(ctx context.Context, kbId string) ([]*KnowledgeDocument, error)
 		DeleteAllNonIndexingByKbId(ctx context.Context, kbId string) error
+		FindOneByKbIdFileHash(ctx context.Context, kbId, fileHash string) (*KnowledgeDocument, error)
+		FindDuplicatesByTenantId(ctx context.Context, tenantId string) ([]*KnowledgeDocument, error)
+		TransactCtx(ctx context.Context, fn func(context.Context, sqlx.Session) error) error
+		InsertWithSession(ctx context.Context, session sqlx.Session, data *KnowledgeDocument) error
+		UpdateWithSession(ctx context.Context, session sqlx.Session, data *KnowledgeDocument) error
 	}
 
 	customKnowledgeDocumentModel struct {
@@ -135,6 +147,35 @@ func (m *customKnowledgeDocumentModel) UpdateRunStatus(ctx context.Context, id,
 	return err
 }
 
+// CompareAndSetRunStatus 仅当文档处于 expected 状态时更新运行状态, 返回是否更新成功
+func (m *customKnowledgeDocumentModel) CompareAndSetRunStatus(ctx context.Context, id, expected, status, msg string) (bool, error) {
+	knowledgeDocumentIdKey := fmt.Sprintf("%s%v", cacheKnowledgeDocumentIdPrefix, id)
+	result, err := m.ExecCtx(ctx, func(ctx context.Context, conn sqlx.SqlConn) (result sql.Result, err error) {
+		query := fmt.Sprintf("update %s set run_status = ?, progress_msg = ?, updated_time = ?, updated_date = ? where `id` = ? and run_status = ?", m.table)
+		now := time.Now()
+		return conn.ExecCtx(ctx, query, status, msg, now.UnixMilli(), now, id, expected)
+	}, knowledgeDocumentIdKey)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// UpdateProgress 更新文档索引进度
+func (m *customKnowledgeDocumentModel) UpdateProgress(ctx context.Context, id string, progress float64, msg string) error {
+	knowledgeDocumentIdKey := fmt.Sprintf("%s%v", cacheKnowledgeDocumentIdPrefix, id)
+	_, err := m.ExecCtx(ctx, func(ctx context.Context, conn sqlx.SqlConn) (result sql.Result, err error) {
+		query := fmt.Sprintf("update %s set progress = ?, progress_msg = ?, updated_time = ?, updated_date = ? where `id` = ?", m.table)
+		now := time.Now()
+		return conn.ExecCtx(ctx, query, progress, msg, now.UnixMilli(), now, id)
+	}, knowledgeDocumentIdKey)
+	return err
+}
+
 // UpdateStatusWithChunkCount 更新文档状态及切片统计
 func (m *customKnowledgeDocumentModel) UpdateStatusWithChunkCount(ctx context.Context, id, status string, chunkNum, tokenNum int64) error {
 	knowledgeDocumentIdKey := fmt.Sprintf("%s%v", cacheKnowledgeDocumentIdPrefix, id)
@@ -146,6 +187,55 @@ func (m *customKnowledgeDocumentModel) UpdateStatusWithChunkCount(ctx context.Co
 	return err
 }
 
+// ChunkDiff 一次索引的分片增量统计
+type ChunkDiff struct {
+	Added   int64
+	Removed int64
+	Reused  int64
+}
+
+// UpdateIndexResult 更新文档状态、切片统计及本次索引的分片增量统计
+func (m *customKnowledgeDocumentModel) UpdateIndexResult(ctx context.Context, id, status string, chunkNum, tokenNum int64, diff ChunkDiff) error {
+	knowledgeDocumentIdKey := fmt.Sprintf("%s%v", cacheKnowledgeDocumentIdPrefix, id)
+	_, err := m.ExecCtx(ctx, func(ctx context.Context, conn sqlx.SqlConn) (result sql.Result, err error) {
+		query := fmt.Sprintf("update %s set run_status = ?, chunk_num = ?, token_num = ?, chunk_added = ?, chunk_removed = ?, chunk_reused = ?, updated_time = ?, updated_date = ? where `id` = ?", m.table)
+		now := time.Now()
+		return conn.ExecCtx(ctx, query, status, chunkNum, tokenNum, diff.Added, diff.Removed, diff.Reused, now.UnixMilli(), now, id)
+	}, knowledgeDocumentIdKey)
+	return err
+}
+
+// IncrIndexResult 累加切片统计及分片增量统计, 用于子任务分别上报各自范围的结果
+func (m *customKnowledgeDocumentModel) IncrIndexResult(ctx context.Context, id string, chunkNum, tokenNum int64, diff ChunkDiff) error {
+	knowledgeDocumentIdKey := fmt.Sprintf("%s%v", cacheKnowledgeDocumentIdPrefix, id)
+	_, err := m.ExecCtx(ctx, func(ctx context.Context, conn sqlx.SqlConn) (result sql.Result, err error) {
+		query := fmt.Sprintf("update %s set chunk_num = chunk_num + ?, token_num = token_num + ?, chunk_added = chunk_added + ?, chunk_removed = chunk_removed + ?, chunk_reused = chunk_reused + ? where `id` = ?", m.table)
+		return conn.ExecCtx(ctx, query, chunkNum, tokenNum, diff.Added, diff.Removed, diff.Reused, id)
+	}, knowledgeDocumentIdKey)
+	return err
+}
+
+// UpdateParserConfig 更新文档解析模板及解析配置
+func (m *customKnowledgeDocumentModel) UpdateParserConfig(ctx context.Context, id, parserId, parserConfig string) error {
+	knowledgeDocumentIdKey := fmt.Sprintf("%s%v", cacheKnowledgeDocumentIdPrefix, id)
+	_, err := m.ExecCtx(ctx, func(ctx context.Context, conn sqlx.SqlConn) (result sql.Result, err error) {
+		query := fmt.Sprintf("update %s set parser_id = ?, parser_config = ?, updated_time = ?, updated_date = ? where `id` = ?", m.table)
+		now := time.Now()
+		return conn.ExecCtx(ctx, query, parserId, parserConfig, now.UnixMilli(), now, id)
+	}, knowledgeDocumentIdKey)
+	return err
+}
+
+// UpdateEffectiveConfig 记录最近一次索引实际生效的解析配置, 不影响文档自身的覆盖配置
+func (m *customKnowledgeDocumentModel) UpdateEffectiveConfig(ctx context.Context, id, effectiveConfig string) error {
+	knowledgeDocumentIdKey := fmt.Sprintf("%s%v", cacheKnowledgeDocumentIdPrefix, id)
+	_, err := m.ExecCtx(ctx, func(ctx context.Context, conn sqlx.SqlConn) (result sql.Result, err error) {
+		query := fmt.Sprintf("update %s set effective_config = ? where `id` = ?", m.table)
+		return conn.ExecCtx(ctx, query, effectiveConfig, id)
+	}, knowledgeDocumentIdKey)
+	return err
+}
+
 func (m *customKnowledgeDocumentModel) FindAllNonIndexingByKbId(ctx context.Context, kbId string) ([]*KnowledgeDocument, error) {
 	query := fmt.Sprintf("select %s from %s where knowledge_base_id = ? and run_status != 'indexing'", knowledgeDocumentRows, m.table)
 	var resp []*KnowledgeDocument
@@ -156,6 +246,17 @@ func (m *customKnowledgeDocumentModel) FindAllNonIndexingByKbId(ctx context.Cont
 	return resp, nil
 }
 
+// FindUnfinishedByKbId 查询知识库内待处理、索引中及已暂停的文档
+func (m *customKnowledgeDocumentModel) FindUnfinishedByKbId(ctx context.Context, kbId string) ([]*KnowledgeDocument, error) {
+	query := fmt.Sprintf("select %s from %s where knowledge_base_id = ? and run_status in (?, ?, ?)", knowledgeDocumentRows, m.table)
+	var resp []*KnowledgeDocument
+	err := m.QueryRowsNoCacheCtx(ctx, &resp, query, kbId, RunStatePending, RunStateRunning, RunStatePaused)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
 func (m *customKnowledgeDocumentModel) DeleteAllNonIndexingByKbId(ctx context.Context, kbId string) error {
 	// 注意：这里没有清除缓存，因为是大批量删除，且 ID 未知。
 	// 实际生产中可能需要先查 ID 再删缓存，或者直接忽略缓存一致性（如果是列表查询为主）。
@@ -165,3 +266,75 @@ func (m *customKnowledgeDocumentModel) DeleteAllNonIndexingByKbId(ctx context.Co
 	_, err := m.ExecNoCacheCtx(ctx, query, kbId)
 	return err
 }
+
+// FindOneByKbIdFileHash 查询知识库内内容哈希相同的最早上传的文档
+func (m *customKnowledgeDocumentModel) FindOneByKbIdFileHash(ctx context.Context, kbId, fileHash string) (*KnowledgeDocument, error) {
+	query := fmt.Sprintf("select %s from %s where knowledge_base_id = ? and file_hash = ? order by created_time asc limit 1", knowledgeDocumentRows, m.table)
+	var resp KnowledgeDocument
+	err := m.QueryRowNoCacheCtx(ctx, &resp, query, kbId, fileHash)
+	switch err {
+	case nil:
+		return &resp, nil
+	case sqlx.ErrNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, err
+	}
+}
+
+// FindDuplicatesByTenantId 查询租户下所有知识库中内容哈希重复的文档, 按哈希分组排列
+func (m *customKnowledgeDocumentModel) FindDuplicatesByTenantId(ctx context.Context, tenantId string) ([]*KnowledgeDocument, error) {
+	columns := make([]string, len(knowledgeDocumentFieldNames))
+	for i, name := range knowledgeDocumentFieldNames {
+		columns[i] = "d." + name
+	}
+
+	query := fmt.Sprintf(`select %s from %s d join knowledge_base kb on d.knowledge_base_id = kb.id
+where kb.tenant_id = ? and d.file_hash in (
+	select t.file_hash from (
+		select d2.file_hash from %s d2 join knowledge_base kb2 on d2.knowledge_base_id = kb2.id
+		where kb2.tenant_id = ? and d2.file_hash != '' group by d2.file_hash having count(*) > 1
+	) t
+) order by d.file_hash, d.created_time`, strings.Join(columns, ","), m.table, m.table)
+
+	var resp []*KnowledgeDocument
+	err := m.QueryRowsNoCacheCtx(ctx, &resp, query, tenantId, tenantId)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// InsertWithSession 在事务中插入文档, 用于与本地消息表等记录同事务提交
+func (m *customKnowledgeDocumentModel) InsertWithSession(ctx context.Context, session sqlx.Session, data *KnowledgeDocument) error {
+	now := time.Now()
+	data.CreatedTime = now.UnixMilli()
+	data.UpdatedTime = now.UnixMilli()
+	data.CreatedDate = now
+	data.UpdatedDate = now
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(strings.Split(knowledgeDocumentRowsExpectAutoSet, ","))), ", ")
+	query := fmt.Sprintf("insert into %s (%s) values (%s)", m.table, knowledgeDocumentRowsExpectAutoSet, placeholders)
+	_, err := session.ExecCtx(ctx, query, data.Id, data.KnowledgeBaseId, data.DocName, data.DocType, data.DocSize, data.Description, data.StoragePath, data.SourceType, data.CreatedBy, data.TokenNum, data.ChunkNum, data.Progress, data.ProgressMsg, data.ProcessBeginAt, data.ProcessDuration, data.RunStatus, data.Status, data.ParserId, data.ParserConfig, data.FileHash, data.EffectiveConfig, data.ChunkAdded, data.ChunkRemoved, data.ChunkReused, data.CreatedTime, data.UpdatedTime, data.CreatedDate, data.UpdatedDate, data.MetaFields)
+	if err != nil {
+		return err
+	}
+
+	// 清理可能存在的空值缓存
+	return m.DelCacheCtx(ctx, fmt.Sprintf("%s%v", cacheKnowledgeDocumentIdPrefix, data.Id))
+}
+
+// UpdateWithSession 在事务中更新文档, 用于与本地消息表等记录同事务提交
+func (m *customKnowledgeDocumentModel) UpdateWithSession(ctx context.Context, session sqlx.Session, data *KnowledgeDocument) error {
+	now := time.Now()
+	data.UpdatedTime = now.UnixMilli()
+	data.UpdatedDate = now
+
+	query := fmt.Sprintf("update %s set %s where `id` = ?", m.table, knowledgeDocumentRowsWithPlaceHolder)
+	_, err := session.ExecCtx(ctx, query, data.KnowledgeBaseId, data.DocName, data.DocType, data.DocSize, data.Description, data.StoragePath, data.SourceType, data.CreatedBy, data.TokenNum, data.ChunkNum, data.Progress, data.ProgressMsg, data.ProcessBeginAt, data.ProcessDuration, data.RunStatus, data.Status, data.ParserId, data.ParserConfig, data.FileHash, data.EffectiveConfig, data.ChunkAdded, data.ChunkRemoved, data.ChunkReused, data.CreatedTime, data.UpdatedTime, data.CreatedDate, data.UpdatedDate, data.MetaFields, data.Id)
+	if err != nil {
+		return err
+	}
+
+	return m.DelCacheCtx(ctx, fmt.Sprintf("%s%v", cacheKnowledgeDocumentIdPrefix, data.Id))
+}
@@ -60,6 +60,11 @@ type (
 		Status          int64          `db:"status"`            // 状态: 1-有效, 0-删除/禁用
 		ParserId        string         `db:"parser_id"`         // 解析器ID,目前仅支持 general | resume
 		ParserConfig    string         `db:"parser_config"`     // 解析配置(JSON)
+		FileHash        string         `db:"file_hash"`         // 文件 SHA-256, 用于重复文档检测
+		EffectiveConfig sql.NullString `db:"effective_config"`  // 最近一次索引实际生效的解析配置(JSON)
+		ChunkAdded      int64          `db:"chunk_added"`       // 最近一次索引新增的分片数
+		ChunkRemoved    int64          `db:"chunk_removed"`     // 最近一次索引删除的分片数
+		ChunkReused     int64          `db:"chunk_reused"`      // 最近一次索引复用的分片数
 		CreatedTime     int64          `db:"created_time"`      // 创建时间戳(ms)
 		UpdatedTime     int64          `db:"updated_time"`      // 更新时间戳(ms)
 		CreatedDate     time.Time      `db:"created_date"`      // 创建日期
@@ -118,8 +123,8 @@ func (m *defaultKnowledgeDocumentModel) Insert(ctx context.Context, data *Knowle
 
 	knowledgeDocumentIdKey := fmt.Sprintf("%s%v", cacheKnowledgeDocumentIdPrefix, data.Id)
 	ret, err := m.ExecCtx(ctx, func(ctx context.Context, conn sqlx.SqlConn) (result sql.Result, err error) {
-		query := fmt.Sprintf("insert into %s (%s) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", m.table, knowledgeDocumentRowsExpectAutoSet)
-		return conn.ExecCtx(ctx, query, data.Id, data.KnowledgeBaseId, data.DocName, data.DocType, data.DocSize, data.Description, data.StoragePath, data.SourceType, data.CreatedBy, data.TokenNum, data.ChunkNum, data.Progress, data.ProgressMsg, data.ProcessBeginAt, data.ProcessDuration, data.RunStatus, data.Status, data.ParserId, data.ParserConfig, data.CreatedTime, data.UpdatedTime, data.CreatedDate, data.UpdatedDate, data.MetaFields)
+		query := fmt.Sprintf("insert into %s (%s) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", m.table, knowledgeDocumentRowsExpectAutoSet)
+		return conn.ExecCtx(ctx, query, data.Id, data.KnowledgeBaseId, data.DocName, data.DocType, data.DocSize, data.Description, data.StoragePath, data.SourceType, data.CreatedBy, data.TokenNum, data.ChunkNum, data.Progress, data.ProgressMsg, data.ProcessBeginAt, data.ProcessDuration, data.RunStatus, data.Status, data.ParserId, data.ParserConfig, data.FileHash, data.EffectiveConfig, data.ChunkAdded, data.ChunkRemoved, data.ChunkReused, data.CreatedTime, data.UpdatedTime, data.CreatedDate, data.UpdatedDate, data.MetaFields)
 	}, knowledgeDocumentIdKey)
 	return ret, err
 }
@@ -138,7 +143,7 @@ func (m *defaultKnowledgeDocumentModel) Update(ctx context.Context, data *Knowle
 	knowledgeDocumentIdKey := fmt.Sprintf("%s%v", cacheKnowledgeDocumentIdPrefix, data.Id)
 	_, err := m.ExecCtx(ctx, func(ctx context.Context, conn sqlx.SqlConn) (result sql.Result, err error) {
 		query := fmt.Sprintf("update %s set %s where `id` = ?", m.table, knowledgeDocumentRowsWithPlaceHolder)
-		return conn.ExecCtx(ctx, query, data.KnowledgeBaseId, data.DocName, data.DocType, data.DocSize, data.Description, data.StoragePath, data.SourceType, data.CreatedBy, data.TokenNum, data.ChunkNum, data.Progress, data.ProgressMsg, data.ProcessBeginAt, data.ProcessDuration, data.RunStatus, data.Status, data.ParserId, data.ParserConfig, data.CreatedTime, data.UpdatedTime, data.CreatedDate, data.UpdatedDate, data.MetaFields, data.Id)
+		return conn.ExecCtx(ctx, query, data.KnowledgeBaseId, data.DocName, data.DocType, data.DocSize, data.Description, data.StoragePath, data.SourceType, data.CreatedBy, data.TokenNum, data.ChunkNum, data.Progress, data.ProgressMsg, data.ProcessBeginAt, data.ProcessDuration, data.RunStatus, data.Status, data.ParserId, data.ParserConfig, data.FileHash, data.EffectiveConfig, data.ChunkAdded, data.ChunkRemoved, data.ChunkReused, data.CreatedTime, data.UpdatedTime, data.CreatedDate, data.UpdatedDate, data.MetaFields, data.Id)
 	}, knowledgeDocumentIdKey)
 	return err
 }
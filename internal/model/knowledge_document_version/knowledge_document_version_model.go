@@ -0,0 +1,106 @@
+package knowledge_document_version
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+)
+
+var _ KnowledgeDocumentVersionModel = (*customKnowledgeDocumentVersionModel)(nil)
+
+type (
+	// KnowledgeDocumentVersionModel is an interface to be customized, add more methods here,
+	// and implement the added methods in customKnowledgeDocumentVersionModel.
+	KnowledgeDocumentVersionModel interface {
+		knowledgeDocumentVersionModel
+		FindListByDocumentId(ctx context.Context, documentId string) ([]*KnowledgeDocumentVersion, error)
+		FindLatestVersion(ctx context.Context, documentId string) (int64, error)
+		DeleteByDocumentId(ctx context.Context, documentId string) error
+		FindListByDocumentIds(ctx context.Context, documentIds []string) ([]*KnowledgeDocumentVersion, error)
+		DeleteByDocumentIds(ctx context.Context, documentIds []string) error
+		DeleteByKnowledgeBaseId(ctx context.Context, kbId string) error
+	}
+
+	customKnowledgeDocumentVersionModel struct {
+		*defaultKnowledgeDocumentVersionModel
+	}
+)
+
+// NewKnowledgeDocumentVersionModel returns a model for the database table.
+func NewKnowledgeDocumentVersionModel(conn sqlx.SqlConn) KnowledgeDocumentVersionModel {
+	return &customKnowledgeDocumentVersionModel{
+		defaultKnowledgeDocumentVersionModel: newKnowledgeDocumentVersionModel(conn),
+	}
+}
+
+// FindListByDocumentId 查询文档的版本历史, 按版本号倒序
+func (m *customKnowledgeDocumentVersionModel) FindListByDocumentId(ctx context.Context, documentId string) ([]*KnowledgeDocumentVersion, error) {
+	query := fmt.Sprintf("select %s from %s where document_id = ? order by version desc", knowledgeDocumentVersionRows, m.table)
+	var resp []*KnowledgeDocumentVersion
+	err := m.conn.QueryRowsCtx(ctx, &resp, query, documentId)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// FindLatestVersion 查询文档的最大版本号, 没有版本记录时返回 0
+func (m *customKnowledgeDocumentVersionModel) FindLatestVersion(ctx context.Context, documentId string) (int64, error) {
+	query := fmt.Sprintf("select coalesce(max(version), 0) from %s where document_id = ?", m.table)
+	var resp int64
+	err := m.conn.QueryRowCtx(ctx, &resp, query, documentId)
+	if err != nil {
+		return 0, err
+	}
+	return resp, nil
+}
+
+func (m *customKnowledgeDocumentVersionModel) DeleteByDocumentId(ctx context.Context, documentId string) error {
+	query := fmt.Sprintf("delete from %s where document_id = ?", m.table)
+	_, err := m.conn.ExecCtx(ctx, query, documentId)
+	return err
+}
+
+// FindListByDocumentIds 批量查询多个文档的版本记录
+func (m *customKnowledgeDocumentVersionModel) FindListByDocumentIds(ctx context.Context, documentIds []string) ([]*KnowledgeDocumentVersion, error) {
+	if len(documentIds) == 0 {
+		return nil, nil
+	}
+	query := fmt.Sprintf("select %s from %s where document_id in (%s)", knowledgeDocumentVersionRows, m.table, placeholders(len(documentIds)))
+	var resp []*KnowledgeDocumentVersion
+	err := m.conn.QueryRowsCtx(ctx, &resp, query, toArgs(documentIds)...)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (m *customKnowledgeDocumentVersionModel) DeleteByDocumentIds(ctx context.Context, documentIds []string) error {
+	if len(documentIds) == 0 {
+		return nil
+	}
+	query := fmt.Sprintf("delete from %s where document_id in (%s)", m.table, placeholders(len(documentIds)))
+	_, err := m.conn.ExecCtx(ctx, query, toArgs(documentIds)...)
+	return err
+}
+
+// DeleteByKnowledgeBaseId 删除知识库下全部文档的版本记录, 需在删除文档记录之前调用
+func (m *customKnowledgeDocumentVersionModel) DeleteByKnowledgeBaseId(ctx context.Context, kbId string) error {
+	query := fmt.Sprintf("delete from %s where document_id in (select id from `knowledge_document` where knowledge_base_id = ?)", m.table)
+	_, err := m.conn.ExecCtx(ctx, query, kbId)
+	return err
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+func toArgs(ids []string) []any {
+	args := make([]any, 0, len(ids))
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	return args
+}
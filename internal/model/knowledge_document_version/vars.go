@@ -0,0 +1,5 @@
+package knowledge_document_version
+
+import "github.com/zeromicro/go-zero/core/stores/sqlx"
+
+var ErrNotFound = sqlx.ErrNotFound
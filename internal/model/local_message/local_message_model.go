@@ -39,6 +39,7 @@ type (
 		UpdatePermanentFail(ctx context.Context, id uint64, reason string) error
 		UpdateRetrying(ctx context.Context, id uint64) error
 		InsertAndGetId(ctx context.Context, data *LocalMessage) (uint64, error)
+		InsertWithSession(ctx context.Context, session sqlx.Session, data *LocalMessage) (uint64, error)
 	}
 
 	customLocalMessageModel struct {
@@ -134,6 +135,11 @@ func (m *customLocalMessageModel) InsertAndGetId(ctx context.Context, data *Loca
 	return uint64(id), nil
 }
 
+// InsertWithSession 在业务事务中写入本地消息, 业务数据与消息同时提交或回滚
+func (m *customLocalMessageModel) InsertWithSession(ctx context.Context, session sqlx.Session, data *LocalMessage) (uint64, error) {
+	return m.withSession(session).InsertAndGetId(ctx, data)
+}
+
 // NewLocalMessage 创建新的本地消息记录
 func NewLocalMessage(taskType string, reqSnapshot string) *LocalMessage {
 	return &LocalMessage{
@@ -1,6 +1,10 @@
 package task
 
 import (
+	"context"
+	"database/sql"
+	"fmt"
+
 	"github.com/zeromicro/go-zero/core/stores/cache"
 	"github.com/zeromicro/go-zero/core/stores/sqlx"
 )
@@ -12,6 +16,8 @@ type (
 	// and implement the added methods in customTaskModel.
 	TaskModel interface {
 		taskModel
+		FindByDocIdTaskType(ctx context.Context, docId, taskType string) ([]*Task, error)
+		DeleteByDocIdTaskType(ctx context.Context, docId, taskType string) error
 	}
 
 	customTaskModel struct {
@@ -25,3 +31,32 @@ func NewTaskModel(conn sqlx.SqlConn, c cache.CacheConf, opts ...cache.Option) Ta
 		defaultTaskModel: newTaskModel(conn, c, opts...),
 	}
 }
+
+// FindByDocIdTaskType 查询文档指定类型的全部任务, 按起始位置排序
+func (m *customTaskModel) FindByDocIdTaskType(ctx context.Context, docId, taskType string) ([]*Task, error) {
+	query := fmt.Sprintf("select %s from %s where `doc_id` = ? and `task_type` = ? and `delete_at` is null order by `from_page` asc", taskRows, m.table)
+	var resp []*Task
+	err := m.QueryRowsNoCacheCtx(ctx, &resp, query, docId, taskType)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// DeleteByDocIdTaskType 删除文档指定类型的全部任务, 同时清除缓存
+func (m *customTaskModel) DeleteByDocIdTaskType(ctx context.Context, docId, taskType string) error {
+	tasks, err := m.FindByDocIdTaskType(ctx, docId, taskType)
+	if err != nil || len(tasks) == 0 {
+		return err
+	}
+
+	keys := make([]string, 0, len(tasks))
+	for _, t := range tasks {
+		keys = append(keys, fmt.Sprintf("%s%v", cacheTaskIdPrefix, t.Id))
+	}
+	_, err = m.ExecCtx(ctx, func(ctx context.Context, conn sqlx.SqlConn) (sql.Result, error) {
+		query := fmt.Sprintf("delete from %s where `doc_id` = ? and `task_type` = ?", m.table)
+		return conn.ExecCtx(ctx, query, docId, taskType)
+	}, keys...)
+	return err
+}
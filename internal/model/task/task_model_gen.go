@@ -44,10 +44,10 @@ type (
 		Id              string         `db:"id"`               // 任务唯一ID (UUID)
 		DocId           string         `db:"doc_id"`           // 关联的文档ID
 		TaskType        string         `db:"task_type"`        // 任务类型: parse, graphrag,后期考虑实现raptor模型
-		FromPage        int64          `db:"from_page"`        // 起始页/行 (包含)
+		FromPage        int64          `db:"from_page"`        // 起始页/行 (包含), parse 子任务为切片序号
 		ToPage          int64          `db:"to_page"`          // 结束页/行 (不包含)
 		Progress        float64        `db:"progress"`         // 任务进度 0.0-1.0
-		Status          string         `db:"status"`           // 状态: pending | running | success | fail | paused
+		Status          string         `db:"status"`           // 状态: pending | running | success | fail | paused | canceled
 		ProgressMsg     sql.NullString `db:"progress_msg"`     // 当前进度的详细日志/最后一条消息
 		FailReason      sql.NullString `db:"fail_reason"`      // 如果失败，记录具体堆栈或错误信息
 		RetryCount      int64          `db:"retry_count"`      // 重试次数
@@ -3,3 +3,19 @@ package task
 import "github.com/zeromicro/go-zero/core/stores/sqlx"
 
 var ErrNotFound = sqlx.ErrNotFound
+
+// 任务类型
+const (
+	TaskTypeParse    = "parse"    // 大文档拆分后的解析子任务
+	TaskTypeGraphRag = "graphrag" // 知识图谱提取
+)
+
+// 任务状态
+const (
+	StatusPending  = "pending"
+	StatusRunning  = "running"
+	StatusSuccess  = "success"
+	StatusFail     = "fail"
+	StatusPaused   = "paused"
+	StatusCanceled = "canceled"
+)
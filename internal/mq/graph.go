@@ -12,11 +12,22 @@ type GraphGenerateMsg struct {
 	EntityTypes            []string `json:"entity_types"`
 	EnableEntityResolution bool     `json:"enable_entity_resolution"`
 	EnableCommunity        bool     `json:"enable_community"`
+	// RemoveDocumentIds 文档删除时设置: 只移除这些文档的图谱贡献并登记社区报告重建, 不做抽取
+	RemoveDocumentIds []string `json:"remove_document_ids,omitempty"`
 
 	// 本地消息表补偿字段
 	LocalMessageId uint64 `json:"local_message_id,omitempty"` // 补偿投递时设置
 }
 
+// NewGraphRemoveMsg 构造移除文档图谱贡献的任务, 与抽取任务共用 topic 及本地消息表补偿
+func NewGraphRemoveMsg(tenantId, kbId string, docIds []string) *GraphGenerateMsg {
+	return &GraphGenerateMsg{
+		KnowledgeBaseId:   kbId,
+		TenantId:          tenantId,
+		RemoveDocumentIds: docIds,
+	}
+}
+
 // GetLocalMessageId 实现 RetryableMsg 接口
 func (m *GraphGenerateMsg) GetLocalMessageId() uint64 {
 	return m.LocalMessageId
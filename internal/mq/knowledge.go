@@ -9,6 +9,7 @@ type KnowledgeDocumentIndexMsg struct {
 	TenantId        string `json:"tenant_id"`         // 租户id
 	KnowledgeBaseId string `json:"knowledge_base_id"` // knowledge_base.id
 	DocumentId      string `json:"document_id"`       // knowledge_document.id
+	TaskId          string `json:"task_id,omitempty"` // 大文档拆分后的子任务 task.id, 为空时处理整个文档
 
 	// 本地消息表补偿字段
 	LocalMessageId uint64 `json:"local_message_id,omitempty"` // 补偿投递时设置
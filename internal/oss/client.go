@@ -2,16 +2,55 @@ package oss
 
 import (
 	"context"
+	"errors"
 	"io"
+	"time"
 )
 
+// ErrObjectNotFound 对象或分片上传任务不存在
+var ErrObjectNotFound = errors.New("oss: object not found")
+
+// ObjectInfo 对象元信息
+type ObjectInfo struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ETag        string
+}
+
+// Part 分片上传中的单个分片
+type Part struct {
+	PartNumber int
+	ETag       string
+	Size       int64
+}
+
 type Client interface {
 	// PutObject uploads data. size can be -1 if unknown (for some providers)
 	PutObject(ctx context.Context, bucket, key string, reader io.Reader, size int64, contentType string) (string, error)
 	// FGetObject downloads data to a local file path
 	FGetObject(ctx context.Context, bucket, key, localPath string) error
+	// GetObject returns a reader of the object content, ErrObjectNotFound if the object does not exist
+	GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error)
 	// RemoveObject deletes data
 	RemoveObject(ctx context.Context, bucket, key string) error
+	// RemoveObjectsWithPrefix deletes every object whose key starts with prefix
+	RemoveObjectsWithPrefix(ctx context.Context, bucket, prefix string) error
 	// EnsureBucket ensures the bucket exists
 	EnsureBucket(ctx context.Context, bucket string) error
+	// StatObject returns object metadata, ErrObjectNotFound if the object does not exist
+	StatObject(ctx context.Context, bucket, key string) (ObjectInfo, error)
+	// PresignedPutObject returns a URL the client can PUT the whole object to directly
+	PresignedPutObject(ctx context.Context, bucket, key string, expires time.Duration) (string, error)
+
+	// NewMultipartUpload initiates a multipart upload and returns its upload id
+	NewMultipartUpload(ctx context.Context, bucket, key, contentType string) (string, error)
+	// PresignedUploadPart returns a URL the client can PUT a single part to directly
+	PresignedUploadPart(ctx context.Context, bucket, key, uploadId string, partNumber int, expires time.Duration) (string, error)
+	// ListParts lists the parts uploaded so far, ordered by part number
+	ListParts(ctx context.Context, bucket, key, uploadId string) ([]Part, error)
+	// CompleteMultipartUpload assembles the given parts into the final object
+	CompleteMultipartUpload(ctx context.Context, bucket, key, uploadId string, parts []Part) error
+	// AbortMultipartUpload discards a multipart upload and its uploaded parts
+	AbortMultipartUpload(ctx context.Context, bucket, key, uploadId string) error
 }
@@ -2,7 +2,11 @@ package oss
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
@@ -11,6 +15,7 @@ import (
 
 type MinioClient struct {
 	client *minio.Client
+	core   *minio.Core // 分片上传等底层接口
 }
 
 func NewMinioClient(endpoint, accessKey, secretKey string, useSSL bool) (*MinioClient, error) {
@@ -21,7 +26,7 @@ func NewMinioClient(endpoint, accessKey, secretKey string, useSSL bool) (*MinioC
 	if err != nil {
 		return nil, err
 	}
-	return &MinioClient{client: client}, nil
+	return &MinioClient{client: client, core: &minio.Core{Client: client}}, nil
 }
 
 func (m *MinioClient) PutObject(ctx context.Context, bucket, key string, reader io.Reader, size int64, contentType string) (string, error) {
@@ -40,10 +45,32 @@ func (m *MinioClient) FGetObject(ctx context.Context, bucket, key, localPath str
 	return m.client.FGetObject(ctx, bucket, key, localPath, minio.GetObjectOptions{})
 }
 
+func (m *MinioClient) GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	// minio 的 GetObject 延迟到首次读取才发起请求, 先 Stat 以便及时返回对象不存在
+	if _, err := m.client.StatObject(ctx, bucket, key, minio.StatObjectOptions{}); err != nil {
+		return nil, convertMinioErr(err)
+	}
+	obj, err := m.client.GetObject(ctx, bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, convertMinioErr(err)
+	}
+	return obj, nil
+}
+
 func (m *MinioClient) RemoveObject(ctx context.Context, bucket, key string) error {
 	return m.client.RemoveObject(ctx, bucket, key, minio.RemoveObjectOptions{})
 }
 
+func (m *MinioClient) RemoveObjectsWithPrefix(ctx context.Context, bucket, prefix string) error {
+	objects := m.client.ListObjects(ctx, bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true})
+	for res := range m.client.RemoveObjects(ctx, bucket, objects, minio.RemoveObjectsOptions{}) {
+		if res.Err != nil {
+			return fmt.Errorf("remove %s: %w", res.ObjectName, res.Err)
+		}
+	}
+	return nil
+}
+
 func (m *MinioClient) EnsureBucket(ctx context.Context, bucket string) error {
 	exists, err := m.client.BucketExists(ctx, bucket)
 	if err != nil {
@@ -58,3 +85,86 @@ func (m *MinioClient) EnsureBucket(ctx context.Context, bucket string) error {
 	}
 	return nil
 }
+
+func (m *MinioClient) StatObject(ctx context.Context, bucket, key string) (ObjectInfo, error) {
+	info, err := m.client.StatObject(ctx, bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return ObjectInfo{}, convertMinioErr(err)
+	}
+	return ObjectInfo{
+		Key:         info.Key,
+		Size:        info.Size,
+		ContentType: info.ContentType,
+		ETag:        info.ETag,
+	}, nil
+}
+
+func (m *MinioClient) PresignedPutObject(ctx context.Context, bucket, key string, expires time.Duration) (string, error) {
+	u, err := m.client.PresignedPutObject(ctx, bucket, key, expires)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+func (m *MinioClient) NewMultipartUpload(ctx context.Context, bucket, key, contentType string) (string, error) {
+	opts := minio.PutObjectOptions{}
+	if contentType != "" {
+		opts.ContentType = contentType
+	}
+	return m.core.NewMultipartUpload(ctx, bucket, key, opts)
+}
+
+func (m *MinioClient) PresignedUploadPart(ctx context.Context, bucket, key, uploadId string, partNumber int, expires time.Duration) (string, error) {
+	params := url.Values{}
+	params.Set("uploadId", uploadId)
+	params.Set("partNumber", strconv.Itoa(partNumber))
+	u, err := m.client.Presign(ctx, "PUT", bucket, key, expires, params)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+func (m *MinioClient) ListParts(ctx context.Context, bucket, key, uploadId string) ([]Part, error) {
+	var parts []Part
+	marker := 0
+	for {
+		result, err := m.core.ListObjectParts(ctx, bucket, key, uploadId, marker, 1000)
+		if err != nil {
+			return nil, convertMinioErr(err)
+		}
+		for _, p := range result.ObjectParts {
+			parts = append(parts, Part{PartNumber: p.PartNumber, ETag: p.ETag, Size: p.Size})
+		}
+		if !result.IsTruncated {
+			return parts, nil
+		}
+		marker = result.NextPartNumberMarker
+	}
+}
+
+func (m *MinioClient) CompleteMultipartUpload(ctx context.Context, bucket, key, uploadId string, parts []Part) error {
+	completeParts := make([]minio.CompletePart, 0, len(parts))
+	for _, p := range parts {
+		completeParts = append(completeParts, minio.CompletePart{PartNumber: p.PartNumber, ETag: p.ETag})
+	}
+	_, err := m.core.CompleteMultipartUpload(ctx, bucket, key, uploadId, completeParts, minio.PutObjectOptions{})
+	return convertMinioErr(err)
+}
+
+func (m *MinioClient) AbortMultipartUpload(ctx context.Context, bucket, key, uploadId string) error {
+	return convertMinioErr(m.core.AbortMultipartUpload(ctx, bucket, key, uploadId))
+}
+
+// convertMinioErr 将对象/上传任务不存在的错误统一为 ErrObjectNotFound
+func convertMinioErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch minio.ToErrorResponse(err).Code {
+	case "NoSuchKey", "NoSuchUpload":
+		return ErrObjectNotFound
+	}
+	return err
+}
@@ -0,0 +1,232 @@
+package osstest
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gozero-rag/internal/oss"
+
+	"github.com/google/uuid"
+)
+
+// MemoryClient oss.Client 的内存实现, 仅供测试使用, 不在 oss.NewClient 中注册
+// 预签名 URL 形如 memory://bucket/key, 客户端直传通过 UploadPart 模拟
+type MemoryClient struct {
+	mu      sync.Mutex
+	buckets map[string]bool
+	objects map[string]*memoryObject
+	uploads map[string]*memoryUpload
+}
+
+type memoryObject struct {
+	data        []byte
+	contentType string
+}
+
+type memoryUpload struct {
+	bucket      string
+	key         string
+	contentType string
+	parts       map[int][]byte
+}
+
+func NewMemoryClient() *MemoryClient {
+	return &MemoryClient{
+		buckets: make(map[string]bool),
+		objects: make(map[string]*memoryObject),
+		uploads: make(map[string]*memoryUpload),
+	}
+}
+
+func objectPath(bucket, key string) string {
+	return bucket + "/" + key
+}
+
+func etagOf(data []byte) string {
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func (m *MemoryClient) PutObject(ctx context.Context, bucket, key string, reader io.Reader, size int64, contentType string) (string, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+	if size >= 0 && int64(len(data)) != size {
+		return "", fmt.Errorf("oss: size mismatch, expected %d, got %d", size, len(data))
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[objectPath(bucket, key)] = &memoryObject{data: data, contentType: contentType}
+	return key, nil
+}
+
+func (m *MemoryClient) FGetObject(ctx context.Context, bucket, key, localPath string) error {
+	m.mu.Lock()
+	obj, ok := m.objects[objectPath(bucket, key)]
+	m.mu.Unlock()
+	if !ok {
+		return oss.ErrObjectNotFound
+	}
+	return os.WriteFile(localPath, obj.data, 0644)
+}
+
+func (m *MemoryClient) GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	m.mu.Lock()
+	obj, ok := m.objects[objectPath(bucket, key)]
+	m.mu.Unlock()
+	if !ok {
+		return nil, oss.ErrObjectNotFound
+	}
+	return io.NopCloser(bytes.NewReader(obj.data)), nil
+}
+
+func (m *MemoryClient) RemoveObject(ctx context.Context, bucket, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.objects, objectPath(bucket, key))
+	return nil
+}
+
+func (m *MemoryClient) RemoveObjectsWithPrefix(ctx context.Context, bucket, prefix string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for path := range m.objects {
+		if strings.HasPrefix(path, objectPath(bucket, prefix)) {
+			delete(m.objects, path)
+		}
+	}
+	return nil
+}
+
+func (m *MemoryClient) EnsureBucket(ctx context.Context, bucket string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.buckets[bucket] = true
+	return nil
+}
+
+func (m *MemoryClient) StatObject(ctx context.Context, bucket, key string) (oss.ObjectInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	obj, ok := m.objects[objectPath(bucket, key)]
+	if !ok {
+		return oss.ObjectInfo{}, oss.ErrObjectNotFound
+	}
+	return oss.ObjectInfo{
+		Key:         key,
+		Size:        int64(len(obj.data)),
+		ContentType: obj.contentType,
+		ETag:        etagOf(obj.data),
+	}, nil
+}
+
+func (m *MemoryClient) PresignedPutObject(ctx context.Context, bucket, key string, expires time.Duration) (string, error) {
+	return fmt.Sprintf("memory://%s?expires=%d", objectPath(bucket, key), int64(expires.Seconds())), nil
+}
+
+func (m *MemoryClient) NewMultipartUpload(ctx context.Context, bucket, key, contentType string) (string, error) {
+	uploadId := uuid.NewString()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.uploads[uploadId] = &memoryUpload{
+		bucket:      bucket,
+		key:         key,
+		contentType: contentType,
+		parts:       make(map[int][]byte),
+	}
+	return uploadId, nil
+}
+
+func (m *MemoryClient) PresignedUploadPart(ctx context.Context, bucket, key, uploadId string, partNumber int, expires time.Duration) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.uploads[uploadId]; !ok {
+		return "", oss.ErrObjectNotFound
+	}
+	return fmt.Sprintf("memory://%s?uploadId=%s&partNumber=%s&expires=%d",
+		objectPath(bucket, key), uploadId, strconv.Itoa(partNumber), int64(expires.Seconds())), nil
+}
+
+// UploadPart 模拟客户端通过预签名 URL 上传分片, 返回分片 ETag
+func (m *MemoryClient) UploadPart(uploadId string, partNumber int, data []byte) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	upload, ok := m.uploads[uploadId]
+	if !ok {
+		return "", oss.ErrObjectNotFound
+	}
+	upload.parts[partNumber] = bytes.Clone(data)
+	return etagOf(data), nil
+}
+
+func (m *MemoryClient) ListParts(ctx context.Context, bucket, key, uploadId string) ([]oss.Part, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	upload, ok := m.uploads[uploadId]
+	if !ok {
+		return nil, oss.ErrObjectNotFound
+	}
+
+	parts := make([]oss.Part, 0, len(upload.parts))
+	for num, data := range upload.parts {
+		parts = append(parts, oss.Part{PartNumber: num, ETag: etagOf(data), Size: int64(len(data))})
+	}
+	sort.Slice(parts, func(i, j int) bool {
+		return parts[i].PartNumber < parts[j].PartNumber
+	})
+	return parts, nil
+}
+
+func (m *MemoryClient) CompleteMultipartUpload(ctx context.Context, bucket, key, uploadId string, parts []oss.Part) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	upload, ok := m.uploads[uploadId]
+	if !ok {
+		return oss.ErrObjectNotFound
+	}
+
+	var buf bytes.Buffer
+	prev := 0
+	for _, p := range parts {
+		if p.PartNumber <= prev {
+			return fmt.Errorf("oss: parts must be in ascending order, got %d after %d", p.PartNumber, prev)
+		}
+		prev = p.PartNumber
+
+		data, ok := upload.parts[p.PartNumber]
+		if !ok {
+			return fmt.Errorf("oss: part %d not uploaded", p.PartNumber)
+		}
+		if p.ETag != "" && p.ETag != etagOf(data) {
+			return fmt.Errorf("oss: part %d etag mismatch", p.PartNumber)
+		}
+		buf.Write(data)
+	}
+
+	m.objects[objectPath(bucket, key)] = &memoryObject{data: buf.Bytes(), contentType: upload.contentType}
+	delete(m.uploads, uploadId)
+	return nil
+}
+
+func (m *MemoryClient) AbortMultipartUpload(ctx context.Context, bucket, key, uploadId string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.uploads[uploadId]; !ok {
+		return oss.ErrObjectNotFound
+	}
+	delete(m.uploads, uploadId)
+	return nil
+}
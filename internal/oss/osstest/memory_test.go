@@ -0,0 +1,136 @@
+package osstest
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gozero-rag/internal/oss"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ oss.Client = (*MemoryClient)(nil)
+
+func TestMemoryClient_PutStatGet(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryClient()
+
+	_, err := c.PutObject(ctx, "b", "k.txt", bytes.NewReader([]byte("hello")), 5, "text/plain")
+	require.NoError(t, err)
+
+	info, err := c.StatObject(ctx, "b", "k.txt")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), info.Size)
+	assert.Equal(t, "text/plain", info.ContentType)
+
+	local := filepath.Join(t.TempDir(), "k.txt")
+	require.NoError(t, c.FGetObject(ctx, "b", "k.txt", local))
+	data, err := os.ReadFile(local)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+
+	rc, err := c.GetObject(ctx, "b", "k.txt")
+	require.NoError(t, err)
+	data, err = io.ReadAll(rc)
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+	assert.Equal(t, "hello", string(data))
+
+	require.NoError(t, c.RemoveObject(ctx, "b", "k.txt"))
+	_, err = c.StatObject(ctx, "b", "k.txt")
+	assert.ErrorIs(t, err, oss.ErrObjectNotFound)
+}
+
+func TestMemoryClient_PutObjectSizeMismatch(t *testing.T) {
+	c := NewMemoryClient()
+	_, err := c.PutObject(context.Background(), "b", "k", bytes.NewReader([]byte("abc")), 10, "")
+	assert.Error(t, err)
+}
+
+func TestMemoryClient_MultipartUpload(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryClient()
+
+	uploadId, err := c.NewMultipartUpload(ctx, "b", "big.pdf", "application/pdf")
+	require.NoError(t, err)
+
+	u, err := c.PresignedUploadPart(ctx, "b", "big.pdf", uploadId, 1, time.Hour)
+	require.NoError(t, err)
+	assert.Contains(t, u, "partNumber=1")
+
+	// 乱序上传, 中断后通过 ListParts 恢复
+	etag2, err := c.UploadPart(uploadId, 2, []byte("world"))
+	require.NoError(t, err)
+	parts, err := c.ListParts(ctx, "b", "big.pdf", uploadId)
+	require.NoError(t, err)
+	require.Len(t, parts, 1)
+	assert.Equal(t, 2, parts[0].PartNumber)
+
+	etag1, err := c.UploadPart(uploadId, 1, []byte("hello "))
+	require.NoError(t, err)
+
+	err = c.CompleteMultipartUpload(ctx, "b", "big.pdf", uploadId, []oss.Part{
+		{PartNumber: 1, ETag: etag1},
+		{PartNumber: 2, ETag: etag2},
+	})
+	require.NoError(t, err)
+
+	info, err := c.StatObject(ctx, "b", "big.pdf")
+	require.NoError(t, err)
+	assert.Equal(t, int64(len("hello world")), info.Size)
+	assert.Equal(t, "application/pdf", info.ContentType)
+
+	// 完成后上传任务不再存在
+	_, err = c.ListParts(ctx, "b", "big.pdf", uploadId)
+	assert.ErrorIs(t, err, oss.ErrObjectNotFound)
+}
+
+func TestMemoryClient_CompleteRejectsBadParts(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryClient()
+
+	uploadId, err := c.NewMultipartUpload(ctx, "b", "k", "")
+	require.NoError(t, err)
+	_, err = c.UploadPart(uploadId, 1, []byte("a"))
+	require.NoError(t, err)
+
+	assert.Error(t, c.CompleteMultipartUpload(ctx, "b", "k", uploadId, []oss.Part{{PartNumber: 2}}))
+	assert.Error(t, c.CompleteMultipartUpload(ctx, "b", "k", uploadId, []oss.Part{{PartNumber: 1, ETag: "bad"}}))
+}
+
+func TestMemoryClient_AbortMultipartUpload(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryClient()
+
+	uploadId, err := c.NewMultipartUpload(ctx, "b", "k", "")
+	require.NoError(t, err)
+	require.NoError(t, c.AbortMultipartUpload(ctx, "b", "k", uploadId))
+
+	_, err = c.UploadPart(uploadId, 1, []byte("a"))
+	assert.ErrorIs(t, err, oss.ErrObjectNotFound)
+	assert.ErrorIs(t, c.AbortMultipartUpload(ctx, "b", "k", uploadId), oss.ErrObjectNotFound)
+}
+
+func TestMemoryClient_RemoveObjectsWithPrefix(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryClient()
+	for _, key := range []string{"kb_1/a.txt", "kb_1/a_v2.txt", "kb_12/b.txt"} {
+		_, err := c.PutObject(ctx, "b", key, bytes.NewReader([]byte("x")), 1, "text/plain")
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, c.RemoveObjectsWithPrefix(ctx, "b", "kb_1/"))
+
+	_, err := c.StatObject(ctx, "b", "kb_1/a.txt")
+	assert.ErrorIs(t, err, oss.ErrObjectNotFound)
+	_, err = c.StatObject(ctx, "b", "kb_1/a_v2.txt")
+	assert.ErrorIs(t, err, oss.ErrObjectNotFound)
+	_, err = c.StatObject(ctx, "b", "kb_12/b.txt")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,27 @@
+package oss
+
+import "encoding/json"
+
+// PendingUploadsKey 未完成分片上传登记表 (Redis ZSet), score 为上传会话的过期时间 (毫秒)
+// 直传会话过期后由 compensator 按登记信息中止分片上传, 清理存储端残留的分片
+const PendingUploadsKey = "rag:upload:pending"
+
+// PendingUpload 登记表成员
+type PendingUpload struct {
+	Bucket   string `json:"bucket"`
+	Key      string `json:"key"`
+	UploadId string `json:"upload_id"`
+}
+
+// Member 返回登记表成员值, 同一上传任务始终得到相同的值, 便于完成或取消时移除
+func (p PendingUpload) Member() string {
+	data, _ := json.Marshal(p)
+	return string(data)
+}
+
+// ParsePendingUpload 解析登记表成员值
+func ParsePendingUpload(member string) (PendingUpload, error) {
+	var p PendingUpload
+	err := json.Unmarshal([]byte(member), &p)
+	return p, err
+}
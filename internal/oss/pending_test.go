@@ -0,0 +1,20 @@
+package oss
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPendingUpload_MemberRoundTrip(t *testing.T) {
+	p := PendingUpload{Bucket: "b", Key: "tenant_1/kb_1/doc.pdf", UploadId: "upload-1"}
+	assert.Equal(t, p.Member(), PendingUpload{Bucket: "b", Key: "tenant_1/kb_1/doc.pdf", UploadId: "upload-1"}.Member())
+
+	parsed, err := ParsePendingUpload(p.Member())
+	require.NoError(t, err)
+	assert.Equal(t, p, parsed)
+
+	_, err = ParsePendingUpload("not json")
+	assert.Error(t, err)
+}
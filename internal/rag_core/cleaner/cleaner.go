@@ -0,0 +1,254 @@
+package cleaner
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"gozero-rag/internal/rag_core/types"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+const (
+	// 出现在该数量及以上文档 (页) 中的短行视为页眉页脚
+	boilerplateMinRepeat = 3
+	// 页眉页脚的最大长度 (字符数)
+	boilerplateMaxLength = 50
+)
+
+var (
+	urlRe    = regexp.MustCompile(`(?:https?://|www\.)[^\s<>"'()（）【】\[\]]+`)
+	emailRe  = regexp.MustCompile(`([A-Za-z0-9._%+-])[A-Za-z0-9._%+-]*(@[A-Za-z0-9.-]+\.[A-Za-z]{2,})`)
+	phoneRe  = regexp.MustCompile(`\b(1[3-9]\d)\d{4}(\d{4})\b`)
+	idCardRe = regexp.MustCompile(`\b(\d{6})\d{8}(\d{3}[\dXx])\b`)
+
+	spacesRe   = regexp.MustCompile(`[ \t\x{00A0}]+`)
+	blankRe    = regexp.MustCompile(`\n{3,}`)
+	pageNumRes = []*regexp.Regexp{
+		regexp.MustCompile(`^第\s*\d+\s*页(\s*[/,，]?\s*共\s*\d+\s*页)?$`),
+		regexp.MustCompile(`^[-—–]\s*\d+\s*[-—–]$`),
+		regexp.MustCompile(`^\d+\s*/\s*\d+$`),
+		regexp.MustCompile(`(?i)^page\s*\d+(\s*of\s*\d+)?$`),
+	}
+	// 纯数字页码容易与正文中的数字行混淆, 仅在去掉页眉页脚后的首尾行出现时删除
+	barePageNumRe = regexp.MustCompile(`^\d{1,4}$`)
+)
+
+// Cleaner 分片前的文本清洗器, 按以下顺序执行已开启的规则:
+// 控制字符 -> 全角转半角 -> 页眉页脚 -> 自定义正则 -> URL/邮箱 -> 脱敏 -> 空白规范化
+type Cleaner struct {
+	rule             types.IndexConfigPreCleanRule
+	removeRes        []*regexp.Regexp
+	boilerplateLines map[string]bool
+}
+
+func NewCleaner(rule types.IndexConfigPreCleanRule) (*Cleaner, error) {
+	c := &Cleaner{
+		rule:             rule,
+		boilerplateLines: make(map[string]bool, len(rule.BoilerplateLines)),
+	}
+
+	for _, pattern := range rule.RemovePatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("自定义清洗正则无效 %s: %w", pattern, err)
+		}
+		c.removeRes = append(c.removeRes, re)
+	}
+	for _, line := range rule.BoilerplateLines {
+		if line = strings.TrimSpace(line); line != "" {
+			c.boilerplateLines[line] = true
+		}
+	}
+
+	return c, nil
+}
+
+// Enabled 是否开启了任一清洗规则
+func (c *Cleaner) Enabled() bool {
+	r := c.rule
+	return r.CleanWhitespace || r.RemoveUrlsEmails || r.NormalizeWidth || r.StripControlChars ||
+		r.MaskPhone || r.MaskIdCard || r.MaskEmail || r.RemoveBoilerplate ||
+		len(c.removeRes) > 0 || len(c.boilerplateLines) > 0
+}
+
+// Clean 清洗文档内容, 清洗后内容为空的文档会被丢弃
+// 重复出现的页眉页脚在所有文档范围内统计 (如 PDF 每页一个文档)
+func (c *Cleaner) Clean(docs []*schema.Document) []*schema.Document {
+	if !c.Enabled() {
+		return docs
+	}
+
+	var repeated map[string]bool
+	if c.rule.RemoveBoilerplate {
+		repeated = repeatedLines(docs)
+	}
+
+	result := make([]*schema.Document, 0, len(docs))
+	for _, doc := range docs {
+		doc.Content = c.cleanText(doc.Content, repeated)
+		if strings.TrimSpace(doc.Content) == "" {
+			continue
+		}
+		result = append(result, doc)
+	}
+	return result
+}
+
+// CleanText 清洗单段文本, 不统计重复行
+func (c *Cleaner) CleanText(content string) string {
+	return c.cleanText(content, nil)
+}
+
+func (c *Cleaner) cleanText(content string, repeated map[string]bool) string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+
+	if c.rule.StripControlChars {
+		content = stripControlChars(content)
+	}
+	if c.rule.NormalizeWidth {
+		content = normalizeWidth(content)
+	}
+	if c.rule.RemoveBoilerplate || len(c.boilerplateLines) > 0 {
+		content = c.removeBoilerplate(content, repeated)
+	}
+	for _, re := range c.removeRes {
+		content = re.ReplaceAllString(content, "")
+	}
+	if c.rule.RemoveUrlsEmails {
+		content = urlRe.ReplaceAllString(content, "")
+		content = emailRe.ReplaceAllString(content, "")
+	}
+	if c.rule.MaskIdCard {
+		content = idCardRe.ReplaceAllString(content, "${1}********${2}")
+	}
+	if c.rule.MaskPhone {
+		content = phoneRe.ReplaceAllString(content, "${1}****${2}")
+	}
+	if c.rule.MaskEmail {
+		content = emailRe.ReplaceAllString(content, "${1}***${2}")
+	}
+	if c.rule.CleanWhitespace {
+		content = normalizeWhitespace(content)
+	}
+
+	return content
+}
+
+func (c *Cleaner) removeBoilerplate(content string, repeated map[string]bool) string {
+	lines := strings.Split(content, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed != "" && (c.boilerplateLines[trimmed] || (c.rule.RemoveBoilerplate && (repeated[trimmed] || isPageNumber(trimmed)))) {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	if c.rule.RemoveBoilerplate {
+		kept = removeEdgePageNumbers(kept)
+	}
+	return strings.Join(kept, "\n")
+}
+
+// removeEdgePageNumbers 删除位于首尾非空行的纯数字页码, 正文中的数字行保留
+func removeEdgePageNumbers(lines []string) []string {
+	first, last := -1, -1
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	if first < 0 {
+		return lines
+	}
+
+	kept := lines[:0]
+	for i, line := range lines {
+		if (i == first || i == last) && barePageNumRe.MatchString(strings.TrimSpace(line)) {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return kept
+}
+
+// repeatedLines 统计在多个文档 (页) 中重复出现的短行 (页眉页脚)
+// 每行在同一文档中只计一次, 出现在至少 boilerplateMinRepeat 个文档中才视为页眉页脚; 文档数不足时不统计, 避免误删单文档正文中的重复行
+func repeatedLines(docs []*schema.Document) map[string]bool {
+	repeated := make(map[string]bool)
+	if len(docs) < boilerplateMinRepeat {
+		return repeated
+	}
+
+	counts := make(map[string]int)
+	for _, doc := range docs {
+		seen := make(map[string]bool)
+		for _, line := range strings.Split(doc.Content, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || seen[line] || len([]rune(line)) > boilerplateMaxLength {
+				continue
+			}
+			seen[line] = true
+			counts[line]++
+		}
+	}
+
+	for line, count := range counts {
+		if count >= boilerplateMinRepeat {
+			repeated[line] = true
+		}
+	}
+	return repeated
+}
+
+func isPageNumber(line string) bool {
+	for _, re := range pageNumRes {
+		if re.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// stripControlChars 删除控制字符 (保留换行和制表符) 及零宽字符
+func stripControlChars(content string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' || r == '\t':
+			return r
+		case unicode.IsControl(r), r == '\u200b', r == '\u200c', r == '\u200d', r == '\ufeff':
+			return -1
+		}
+		return r
+	}, content)
+}
+
+// normalizeWidth 全角字母、数字、空格转半角, 中文标点保持不变
+func normalizeWidth(content string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\u3000':
+			return ' '
+		case r >= '０' && r <= '９', r >= 'Ａ' && r <= 'Ｚ', r >= 'ａ' && r <= 'ｚ':
+			return r - 0xFEE0
+		}
+		return r
+	}, content)
+}
+
+// normalizeWhitespace 保留行首缩进, 合并行内连续空白, 去除行尾空白, 连续空行压缩为一个
+func normalizeWhitespace(content string) string {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		body := strings.TrimLeft(line, " \t")
+		indent := line[:len(line)-len(body)]
+		lines[i] = indent + strings.TrimRight(spacesRe.ReplaceAllString(body, " "), " ")
+	}
+	return strings.TrimSpace(blankRe.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
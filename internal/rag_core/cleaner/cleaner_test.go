@@ -0,0 +1,130 @@
+package cleaner
+
+import (
+	"testing"
+
+	"gozero-rag/internal/rag_core/types"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCleaner_CleanText(t *testing.T) {
+	cases := []struct {
+		name    string
+		rule    types.IndexConfigPreCleanRule
+		content string
+		want    string
+	}{
+		{
+			name:    "空白规范化",
+			rule:    types.IndexConfigPreCleanRule{CleanWhitespace: true},
+			content: "第一行   多个  空格  \n\n\n\n  缩进\t\t保留\n",
+			want:    "第一行 多个 空格\n\n  缩进 保留",
+		},
+		{
+			name:    "删除 URL 和邮箱",
+			rule:    types.IndexConfigPreCleanRule{RemoveUrlsEmails: true},
+			content: "官网 https://example.com/a?b=1 联系 admin@example.com",
+			want:    "官网  联系 ",
+		},
+		{
+			name:    "全角转半角",
+			rule:    types.IndexConfigPreCleanRule{NormalizeWidth: true},
+			content: "ＧＰＴ－４\u3000版本１２３，保留中文标点",
+			want:    "GPT－4 版本123，保留中文标点",
+		},
+		{
+			name:    "删除控制字符",
+			rule:    types.IndexConfigPreCleanRule{StripControlChars: true},
+			content: "a\x00b\u200bc\r\nd\te",
+			want:    "abc\nd\te",
+		},
+		{
+			name:    "自定义正则",
+			rule:    types.IndexConfigPreCleanRule{RemovePatterns: []string{`【广告】[^\n]*`}},
+			content: "正文\n【广告】点击购买",
+			want:    "正文\n",
+		},
+		{
+			name:    "脱敏",
+			rule:    types.IndexConfigPreCleanRule{MaskPhone: true, MaskIdCard: true, MaskEmail: true},
+			content: "电话13812345678, 身份证11010119900307123X, 邮箱 zhangsan@example.com",
+			want:    "电话138****5678, 身份证110101********123X, 邮箱 z***@example.com",
+		},
+		{
+			name:    "页码和固定页眉",
+			rule:    types.IndexConfigPreCleanRule{RemoveBoilerplate: true, BoilerplateLines: []string{"内部资料 请勿外传"}},
+			content: "内部资料 请勿外传\n正文内容\n第 3 页 / 共 10 页\n- 4 -",
+			want:    "正文内容",
+		},
+		{
+			name:    "纯数字页码只删除首尾行",
+			rule:    types.IndexConfigPreCleanRule{RemoveBoilerplate: true, BoilerplateLines: []string{"内部资料 请勿外传"}},
+			content: "内部资料 请勿外传\n12\n年度营收 (亿元)\n2023\n128\n正文结束\n13",
+			want:    "年度营收 (亿元)\n2023\n128\n正文结束",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cleaner, err := NewCleaner(c.rule)
+			require.NoError(t, err)
+			assert.Equal(t, c.want, cleaner.CleanText(c.content))
+		})
+	}
+}
+
+func TestCleaner_Clean(t *testing.T) {
+	cleaner, err := NewCleaner(types.IndexConfigPreCleanRule{RemoveBoilerplate: true, CleanWhitespace: true})
+	require.NoError(t, err)
+
+	docs := []*schema.Document{
+		{ID: "1", Content: "XX公司年度报告\n第一页正文"},
+		{ID: "2", Content: "XX公司年度报告\n第二页正文"},
+		{ID: "3", Content: "XX公司年度报告\n第三页正文"},
+		{ID: "4", Content: "XX公司年度报告\n12"},
+	}
+
+	result := cleaner.Clean(docs)
+	require.Len(t, result, 3)
+	assert.Equal(t, "第一页正文", result[0].Content)
+	assert.Equal(t, "第三页正文", result[2].Content)
+}
+
+func TestCleaner_Clean_SingleDocumentKeepsRepeatedLines(t *testing.T) {
+	cleaner, err := NewCleaner(types.IndexConfigPreCleanRule{RemoveBoilerplate: true})
+	require.NoError(t, err)
+
+	content := "func a() {\n}\n---\nfunc b() {\n}\n---\n| --- |\n是\n| --- |\n是\n}\n---\n| --- |\n是"
+	result := cleaner.Clean([]*schema.Document{{ID: "1", Content: content}})
+	require.Len(t, result, 1)
+	assert.Equal(t, content, result[0].Content)
+}
+
+func TestCleaner_Clean_CountsOncePerDocument(t *testing.T) {
+	cleaner, err := NewCleaner(types.IndexConfigPreCleanRule{RemoveBoilerplate: true})
+	require.NoError(t, err)
+
+	// "是" 在单页内多次出现, 但只出现在 2 页中, 不视为页眉页脚
+	docs := []*schema.Document{
+		{ID: "1", Content: "页眉\n是\n是\n是\n正文一"},
+		{ID: "2", Content: "页眉\n是\n正文二"},
+		{ID: "3", Content: "页眉\n正文三"},
+	}
+	result := cleaner.Clean(docs)
+	require.Len(t, result, 3)
+	assert.Equal(t, "是\n是\n是\n正文一", result[0].Content)
+	assert.Equal(t, "是\n正文二", result[1].Content)
+	assert.Equal(t, "正文三", result[2].Content)
+}
+
+func TestNewCleaner_InvalidPattern(t *testing.T) {
+	_, err := NewCleaner(types.IndexConfigPreCleanRule{RemovePatterns: []string{"("}})
+	assert.Error(t, err)
+
+	cleaner, err := NewCleaner(types.IndexConfigPreCleanRule{})
+	require.NoError(t, err)
+	assert.False(t, cleaner.Enabled())
+}
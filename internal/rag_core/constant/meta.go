@@ -30,6 +30,32 @@ const (
 	MetaChunkIndex = "chunk_index"
 	// MetaChunkTotal 文档总 chunk 数 (可选，后处理填充)
 	MetaChunkTotal = "chunk_total"
+	// MetaParentId 父子分片: 子块所属父块 ID
+	MetaParentId = "parent_id"
+	// MetaParentContent 父子分片: 父块内容, 入库时据此生成父块
+	MetaParentContent = "parent_content"
+
+	// --- 表格信息 (Table Parser/Transformer 注入) ---
+
+	// MetaSheetName 工作表名称 (CSV/TSV 使用文件名)
+	MetaSheetName = "sheet_name"
+	// MetaTableHeaders 表头 ([]string)
+	MetaTableHeaders = "table_headers"
+	// MetaRowIndex 数据行号 (从1开始，表头不计入)，chunk 级别为 []int
+	MetaRowIndex = "row_index"
+	// MetaRowValues 数据行的原始单元格 ([]string)
+	MetaRowValues = "row_values"
+
+	// --- 解析模板信息 (Template Transformer 注入) ---
+
+	// MetaQuestion 问答模板的问题, 存在时该 chunk 即为 QA chunk, 使用问题生成向量
+	MetaQuestion = "question"
+	// MetaArticles 法律法规模板: chunk 包含的条款编号 ([]string, 如 "第十条")
+	MetaArticles = "articles"
+	// MetaChapterIndex 书籍模板: 章节序号 (0 为前言)
+	MetaChapterIndex = "chapter_index"
+	// MetaResumeFields 简历模板: 提取出的结构化字段 (map[string]string)
+	MetaResumeFields = "resume_fields"
 
 	// --- QA Checker 注入 (预留) ---
 
@@ -2,6 +2,7 @@ package doc_processor
 
 import (
 	"context"
+	"gozero-rag/internal/rag_core/cleaner"
 	"gozero-rag/internal/rag_core/constant"
 	"gozero-rag/internal/rag_core/loader"
 	"gozero-rag/internal/rag_core/qa"
@@ -18,13 +19,16 @@ import (
 type RunnableInput = *types.ProcessRequest
 type RunnableOutput = []*schema.Document
 type ProcessorService struct {
-	indexer compose.Runnable[RunnableInput, RunnableOutput]
+	indexer     compose.Runnable[RunnableInput, RunnableOutput]
+	loader      document.Loader
+	transformer document.Transformer
 }
 
 func NewDocProcessService(ctx context.Context) (*ProcessorService, error) {
 	const (
 		NodeReqToLoader = "Req2Loader"
 		NodeLoader      = "Loader"
+		NodePreCleaner  = "PreCleaner"
 		NodeTransformer = "Transformer"
 		NodeQaChecker   = "QaChecker"
 		NodeIndexer     = "Indexer"
@@ -50,6 +54,17 @@ func NewDocProcessService(ctx context.Context) (*ProcessorService, error) {
 		return output, err
 	}), compose.WithNodeName(NodeReqToLoader))
 	_ = g.AddLoaderNode(NodeLoader, loader1)
+	_ = g.AddLambdaNode(NodePreCleaner, compose.InvokableLambda(func(ctx context.Context, input []*schema.Document) (output []*schema.Document, err error) {
+		config, ok := ctx.Value(constant.CtxKeyIndexConfig).(types.ProcessConfig)
+		if !ok {
+			return input, nil
+		}
+		c, err := cleaner.NewCleaner(config.PreCleanRule)
+		if err != nil {
+			return nil, err
+		}
+		return c.Clean(input), nil
+	}), compose.WithNodeName(NodePreCleaner))
 	_ = g.AddDocumentTransformerNode(NodeTransformer, transformer2)
 	_ = g.AddLambdaNode(NodeQaChecker, compose.InvokableLambda(func(ctx context.Context, input []*schema.Document) (output []*schema.Document, err error) {
 		config, ok := ctx.Value(constant.CtxKeyIndexConfig).(types.ProcessConfig)
@@ -65,7 +80,8 @@ func NewDocProcessService(ctx context.Context) (*ProcessorService, error) {
 
 	_ = g.AddEdge(compose.START, NodeReqToLoader)
 	_ = g.AddEdge(NodeReqToLoader, NodeLoader)
-	_ = g.AddEdge(NodeLoader, NodeTransformer)
+	_ = g.AddEdge(NodeLoader, NodePreCleaner)
+	_ = g.AddEdge(NodePreCleaner, NodeTransformer)
 	_ = g.AddEdge(NodeTransformer, NodeQaChecker)
 	_ = g.AddEdge(NodeQaChecker, compose.END)
 
@@ -75,9 +91,40 @@ func NewDocProcessService(ctx context.Context) (*ProcessorService, error) {
 	}
 
 	return &ProcessorService{
-		indexer: r,
+		indexer:     r,
+		loader:      loader1,
+		transformer: transformer2,
 	}, nil
 }
+
+// Load 只执行加载与预清洗, 返回切片前的文档内容
+// 与 Split 配合使用, 大文档加载后可按章节拆分, 由多个消费者并行切片
+func (l *ProcessorService) Load(ctx context.Context, input RunnableInput) ([]*schema.Document, error) {
+	ctx = context.WithValue(ctx, constant.CtxKeyIndexConfig, input.IndexConfig)
+	docs, err := l.loader.Load(ctx, document.Source{URI: input.URI})
+	if err != nil {
+		return nil, err
+	}
+	c, err := cleaner.NewCleaner(input.IndexConfig.PreCleanRule)
+	if err != nil {
+		return nil, err
+	}
+	return c.Clean(docs), nil
+}
+
+// Split 对 Load 返回的文档执行切片与 QA 检查, 结果与 Invoke 一致
+func (l *ProcessorService) Split(ctx context.Context, config types.ProcessConfig, docs []*schema.Document) ([]*schema.Document, error) {
+	ctx = context.WithValue(ctx, constant.CtxKeyIndexConfig, config)
+	chunks, err := l.transformer.Transform(ctx, docs)
+	if err != nil {
+		return nil, err
+	}
+	if !config.EnableQACheck {
+		return chunks, nil
+	}
+	return qa.NewQaChecker().Check(ctx, chunks)
+}
+
 func (l *ProcessorService) Invoke(ctx context.Context, input RunnableInput, opts ...compose.Option) (output RunnableOutput, err error) {
 	withCallbacks := compose.WithCallbacks(logCallback())
 
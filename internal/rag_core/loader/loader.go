@@ -3,7 +3,9 @@ package loader
 import (
 	"context"
 	"fmt"
+	"gozero-rag/internal/rag_core/constant"
 	"gozero-rag/internal/rag_core/parser"
+	"gozero-rag/internal/rag_core/types"
 
 	"github.com/cloudwego/eino-ext/components/document/loader/file"
 	"github.com/cloudwego/eino/components/document"
@@ -14,7 +16,8 @@ import (
 )
 
 type Loader struct {
-	fileLoader document.Loader
+	fileLoader  document.Loader
+	tableLoader document.Loader // 表格模式和问答模板 (parser_id = table/qa) 使用
 }
 
 func NewLoader(ctx context.Context) (document.Loader, error) {
@@ -31,8 +34,22 @@ func NewLoader(ctx context.Context) (document.Loader, error) {
 	if err != nil {
 		return nil, err
 	}
+
+	tp, err := parser.NewTableExtParser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tableLoader, err := file.NewFileLoader(ctx, &file.FileLoaderConfig{
+		UseNameAsID: true,
+		Parser:      tp,
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	return &Loader{
-		fileLoader: fldr,
+		fileLoader:  fldr,
+		tableLoader: tableLoader,
 	}, nil
 }
 
@@ -51,5 +68,12 @@ func (l *Loader) Load(ctx context.Context, src document.Source, opts ...document
 
 	// 规范化拓展名,支持大小写不敏感的解析
 
+	if conf, ok := ctx.Value(constant.CtxKeyIndexConfig).(types.ProcessConfig); ok {
+		// 问答模板同样需要按行读取两列表格
+		if conf.ParserId == parser.ParserIdTable || conf.ParserId == parser.ParserIdQa {
+			return l.tableLoader.Load(ctx, src, opts...)
+		}
+	}
+
 	return l.fileLoader.Load(ctx, src, opts...)
 }
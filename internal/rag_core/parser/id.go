@@ -4,5 +4,18 @@ type ParserId = string
 
 const (
 	ParserIdGeneral = "general"
-	ParserIdResume  = "resume"
+	ParserIdResume  = "resume" // 简历模板: 提取姓名/联系方式等结构化字段
+	ParserIdTable   = "table"  // 表格模式: xlsx/csv/tsv 按行切片
+	ParserIdQa      = "qa"     // 问答模板: "Q:/A:" 文本或两列表格直接生成问答对
+	ParserIdLaws    = "laws"   // 法律法规模板: 按 "第X条" 切分
+	ParserIdBook    = "book"   // 书籍模板: 按章节切分
+)
+
+// 分片策略 (ParserConfigGeneral.ChunkMethod), 仅对未注册解析模板的 parser_id (general) 生效
+const (
+	ChunkMethodRecursive = "recursive" // 按分隔符递归切分
+	ChunkMethodStructure = "structure" // 启发式标题检测后按章节切分
+	ChunkMethodMarkdown  = "markdown"  // 按 markdown 标题切分
+	ChunkMethodAgentic   = "agentic"   // LLM 判断话题边界
+	ChunkMethodSemantic  = "semantic"  // 基于向量相似度判断话题边界
 )
@@ -9,6 +9,38 @@ type ParserConfigGeneral struct {
 	QaLlmId              string          `json:"qa_llm_id"`
 	PdfParser            string          `json:"pdf_parser"`
 	GraphRag             *GraphRagConfig `json:"graph_rag,omitempty"` // 可选，兼容旧数据
+
+	ChunkMethod        string `json:"chunk_method,omitempty"`          // 分片策略: recursive | structure | markdown | agentic | semantic, 为空时按文档类型自动选择
+	ChunkLlmId         string `json:"chunk_llm_id,omitempty"`          // agentic 分片使用的模型, 格式: model@factory
+	AgenticMaxLlmCalls int    `json:"agentic_max_llm_calls,omitempty"` // agentic 分片单个文档的 LLM 调用上限, <=0 使用默认值
+	SemanticPercentile int    `json:"semantic_percentile,omitempty"`   // semantic 分片: 相邻句子相似度低于该百分位时切分 (1-99), <=0 使用默认值 10
+
+	ParentChild *ParentChildConfig `json:"parent_child,omitempty"` // 父子分片, 可选
+	PreClean    *PreCleanConfig    `json:"pre_clean,omitempty"`    // 分片前的文本清洗规则, 可选
+
+	MinQualityScore float64 `json:"min_quality_score,omitempty"` // 新增切片的质量评分 (0-100) 低于该值时自动禁用, 0 表示不启用
+}
+
+// PreCleanConfig 文本预清洗配置, 在加载文档之后、分片之前执行
+type PreCleanConfig struct {
+	CleanWhitespace   bool     `json:"clean_whitespace"`            // 合并连续空白、去除行尾空白、压缩多余空行
+	RemoveUrlsEmails  bool     `json:"remove_urls_emails"`          // 删除 URL 和邮箱
+	NormalizeWidth    bool     `json:"normalize_width"`             // 全角字母、数字、空格转半角 (不转换中文标点)
+	StripControlChars bool     `json:"strip_control_chars"`         // 删除控制字符和零宽字符
+	RemovePatterns    []string `json:"remove_patterns,omitempty"`   // 自定义删除的正则表达式
+	MaskPhone         bool     `json:"mask_phone"`                  // 手机号脱敏: 138****1234
+	MaskIdCard        bool     `json:"mask_id_card"`                // 身份证号脱敏: 110101********1234
+	MaskEmail         bool     `json:"mask_email"`                  // 邮箱脱敏: a***@example.com
+	RemoveBoilerplate bool     `json:"remove_boilerplate"`          // 删除页码及重复出现的页眉页脚行
+	BoilerplateLines  []string `json:"boilerplate_lines,omitempty"` // 额外需要整行删除的固定文本
+}
+
+// ParentChildConfig 父子分片配置
+// 按 chunk_token_num 切出的分片作为父块, 再切分为更小的子块用于向量检索, 命中子块后返回父块作为上下文
+type ParentChildConfig struct {
+	Enable         bool `json:"enable"`
+	ChildChunkSize int  `json:"child_chunk_size"` // 子块最大字符数, <=0 使用默认值
+	ChildOverlap   int  `json:"child_overlap"`    // 子块重叠字符数
 }
 
 // GraphRagConfig 知识图谱配置
@@ -19,3 +51,37 @@ type GraphRagConfig struct {
 	EnableEntityResolution bool     `json:"enable_entity_resolution,omitempty"` // 实体归一化
 	EnableCommunity        bool     `json:"enable_community,omitempty"`         // 社区报告
 }
+
+// ParserConfigTable 表格解析配置 (parser_id = table)
+// 在通用配置基础上增加按行切片相关的参数
+type ParserConfigTable struct {
+	ParserConfigGeneral
+	RowsPerChunk int    `json:"rows_per_chunk"`      // 每个 chunk 包含的行数, <=0 时仅按 chunk_token_num 聚合
+	Delimiter    string `json:"delimiter,omitempty"` // CSV 分隔符, 为空时自动探测
+}
+
+// ParserConfigQa 问答模板配置 (parser_id = qa)
+type ParserConfigQa struct {
+	ParserConfigGeneral
+	QuestionPrefixes []string `json:"question_prefixes,omitempty"` // 问题行前缀, 为空时使用 "Q:"/"问:" 等默认值
+	AnswerPrefixes   []string `json:"answer_prefixes,omitempty"`   // 答案行前缀, 为空时使用 "A:"/"答:" 等默认值
+	HasHeader        *bool    `json:"has_header,omitempty"`        // 表格首行是否为表头, 为空时按首行前两列是否为 "问题/答案" 等列名自动识别
+}
+
+// ParserConfigLaws 法律法规模板配置 (parser_id = laws)
+type ParserConfigLaws struct {
+	ParserConfigGeneral
+	ArticlesPerChunk int `json:"articles_per_chunk"` // 每个 chunk 包含的条数, <=0 时每条单独成块
+}
+
+// ParserConfigBook 书籍模板配置 (parser_id = book)
+type ParserConfigBook struct {
+	ParserConfigGeneral
+	ChapterPattern string `json:"chapter_pattern,omitempty"` // 自定义章节标题正则, 为空时识别 "第X章/回/卷" 和 "Chapter N"
+}
+
+// ParserConfigResume 简历模板配置 (parser_id = resume)
+type ParserConfigResume struct {
+	ParserConfigGeneral
+	ExtraSections []string `json:"extra_sections,omitempty"` // 额外识别的分段标题, 如 "获奖情况"
+}
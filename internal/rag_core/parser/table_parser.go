@@ -0,0 +1,328 @@
+package parser
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"gozero-rag/internal/rag_core/constant"
+	"gozero-rag/internal/rag_core/types"
+
+	"github.com/cloudwego/eino/components/document/parser"
+	"github.com/cloudwego/eino/schema"
+	"github.com/xuri/excelize/v2"
+)
+
+// 参与自动探测的 CSV 分隔符 (按优先级排列)
+var sniffDelimiters = []rune{',', '\t', ';', '|'}
+
+const (
+	sniffSampleLines = 10        // 探测分隔符时最多采样的行数
+	sniffSampleBytes = 64 * 1024 // 探测分隔符时最多采样的字节数
+)
+
+// 问答表格自动识别表头时, 问题列和答案列可使用的列名 (忽略大小写和末尾冒号)
+var (
+	qaQuestionHeaders = []string{"问题", "问", "提问", "题目", "question", "questions", "q"}
+	qaAnswerHeaders   = []string{"答案", "答", "回答", "解答", "answer", "answers", "a"}
+)
+
+// TableParser 表格解析器, 支持 xlsx(多工作表) / csv / tsv
+// 每一行数据输出为一个 Document, 内容渲染为 "列名: 值",
+// 并在 MetaData 中记录工作表名称、表头和行号, 供 table transformer 聚合
+type TableParser struct{}
+
+type tableSheet struct {
+	name string
+	rows [][]string
+}
+
+func NewTableParser(ctx context.Context) (*TableParser, error) {
+	return &TableParser{}, nil
+}
+
+// NewTableExtParser 表格模式使用的解析器, 表格类文件走 TableParser, 其余文件沿用通用解析器
+func NewTableExtParser(ctx context.Context) (parser.Parser, error) {
+	tableParser, err := NewTableParser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	generalParser, err := NewParser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return parser.NewExtParser(ctx, &parser.ExtParserConfig{
+		Parsers: map[string]parser.Parser{
+			".xlsx": tableParser,
+			".csv":  tableParser,
+			".tsv":  tableParser,
+		},
+		FallbackParser: generalParser,
+	})
+}
+
+func (p *TableParser) Parse(ctx context.Context, reader io.Reader, opts ...parser.Option) ([]*schema.Document, error) {
+	option := parser.GetCommonOptions(&parser.Options{}, opts...)
+	ext := strings.ToLower(filepath.Ext(option.URI))
+
+	var (
+		sheets []*tableSheet
+		err    error
+	)
+	switch ext {
+	case ".csv", ".tsv":
+		name := strings.TrimSuffix(filepath.Base(option.URI), filepath.Ext(option.URI))
+		sheets, err = p.readCsv(reader, name, p.getDelimiter(ctx, ext))
+	default:
+		sheets, err = p.readXlsx(reader)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []*schema.Document
+	for _, sheet := range sheets {
+		docs = append(docs, p.buildRowDocs(sheet, option.ExtraMeta, p.hasHeader(ctx))...)
+	}
+	return docs, nil
+}
+
+// hasHeader 返回判断首行是否为表头的函数: 问答模板按配置或列名识别, 其余模板首行总是表头
+func (p *TableParser) hasHeader(ctx context.Context) func(row []string) bool {
+	if conf, ok := ctx.Value(constant.CtxKeyIndexConfig).(types.ProcessConfig); ok {
+		if qaConf, ok := conf.Template.(*ParserConfigQa); ok {
+			if qaConf.HasHeader != nil {
+				hasHeader := *qaConf.HasHeader
+				return func([]string) bool { return hasHeader }
+			}
+			return isQaHeader
+		}
+	}
+	return func([]string) bool { return true }
+}
+
+// isQaHeader 首行前两列分别为问题、答案的列名时视为表头
+func isQaHeader(row []string) bool {
+	cells := trimCells(row)
+	if len(cells) < 2 {
+		return false
+	}
+	return matchHeader(cells[0], qaQuestionHeaders) && matchHeader(cells[1], qaAnswerHeaders)
+}
+
+func matchHeader(cell string, names []string) bool {
+	cell = strings.TrimRight(cell, ":：")
+	for _, name := range names {
+		if strings.EqualFold(cell, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// getDelimiter 获取 CSV 分隔符: 配置优先, tsv 默认制表符, 否则返回 0 表示需要自动探测
+func (p *TableParser) getDelimiter(ctx context.Context, ext string) rune {
+	if conf, ok := ctx.Value(constant.CtxKeyIndexConfig).(types.ProcessConfig); ok {
+		if tableConf, ok := conf.Template.(*ParserConfigTable); ok && tableConf.Delimiter != "" {
+			if tableConf.Delimiter == `\t` {
+				return '\t'
+			}
+			return []rune(tableConf.Delimiter)[0]
+		}
+	}
+	if ext == ".tsv" {
+		return '\t'
+	}
+	return 0
+}
+
+func (p *TableParser) readXlsx(reader io.Reader) ([]*tableSheet, error) {
+	xlFile, err := excelize.OpenReader(reader)
+	if err != nil {
+		return nil, err
+	}
+	defer xlFile.Close()
+
+	var sheets []*tableSheet
+	for _, name := range xlFile.GetSheetList() {
+		rows, err := xlFile.GetRows(name)
+		if err != nil {
+			return nil, fmt.Errorf("读取工作表 %s 失败: %w", name, err)
+		}
+		sheets = append(sheets, &tableSheet{name: name, rows: rows})
+	}
+	return sheets, nil
+}
+
+func (p *TableParser) readCsv(reader io.Reader, name string, delimiter rune) ([]*tableSheet, error) {
+	// 缓冲区需容纳探测采样, 默认 4KB 的缓冲区 Peek 时只能取到前 4KB
+	br := bufio.NewReaderSize(reader, sniffSampleBytes)
+	if delimiter == 0 {
+		delimiter = sniffDelimiter(br)
+	}
+
+	csvReader := csv.NewReader(br)
+	csvReader.Comma = delimiter
+	csvReader.LazyQuotes = true
+	csvReader.FieldsPerRecord = -1
+
+	rows, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("解析 CSV 失败: %w", err)
+	}
+
+	// 去掉 UTF-8 BOM
+	if len(rows) > 0 && len(rows[0]) > 0 {
+		rows[0][0] = strings.TrimPrefix(rows[0][0], "\ufeff")
+	}
+
+	return []*tableSheet{{name: name, rows: rows}}, nil
+}
+
+// sniffDelimiter 采样前若干行, 选择每行出现次数稳定且最多的候选分隔符
+func sniffDelimiter(br *bufio.Reader) rune {
+	// Peek 不消费数据, 后续 csv.Reader 仍从头读取
+	sample, _ := br.Peek(sniffSampleBytes)
+
+	var lines []string
+	for _, line := range strings.Split(string(sample), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lines = append(lines, line)
+		if len(lines) >= sniffSampleLines {
+			break
+		}
+	}
+	if len(lines) == 0 {
+		return ','
+	}
+
+	best, bestCount := ',', 0
+	for _, d := range sniffDelimiters {
+		minCount := -1
+		for _, line := range lines {
+			c := strings.Count(line, string(d))
+			if minCount < 0 || c < minCount {
+				minCount = c
+			}
+		}
+		if minCount > bestCount {
+			best, bestCount = d, minCount
+		}
+	}
+	return best
+}
+
+// buildRowDocs 第一行非空数据由 hasHeader 判断是否作为表头, 其余每行生成一个 Document
+// 没有表头时列名使用 "列N" 占位, 第一行同样作为数据行
+func (p *TableParser) buildRowDocs(sheet *tableSheet, extraMeta map[string]any, hasHeader func(row []string) bool) []*schema.Document {
+	firstIdx := -1
+	for i, row := range sheet.rows {
+		if !isEmptyRow(row) {
+			firstIdx = i
+			break
+		}
+	}
+	if firstIdx < 0 {
+		return nil
+	}
+
+	dataIdx := firstIdx
+	var headers []string
+	if hasHeader(sheet.rows[firstIdx]) {
+		headers = normalizeHeaders(sheet.rows[firstIdx])
+		dataIdx++
+	} else {
+		headers = normalizeHeaders(make([]string, len(sheet.rows[firstIdx])))
+	}
+
+	var docs []*schema.Document
+	rowIndex := 0
+	for _, row := range sheet.rows[dataIdx:] {
+		if isEmptyRow(row) {
+			continue
+		}
+		rowIndex++
+
+		content := renderRow(headers, row)
+		if content == "" {
+			continue
+		}
+
+		meta := make(map[string]any, len(extraMeta)+4)
+		for k, v := range extraMeta {
+			meta[k] = v
+		}
+		meta[constant.MetaSheetName] = sheet.name
+		meta[constant.MetaTableHeaders] = headers
+		meta[constant.MetaRowIndex] = rowIndex
+		meta[constant.MetaRowValues] = trimCells(row)
+
+		docs = append(docs, &schema.Document{
+			ID:       fmt.Sprintf("%s_%d", sheet.name, rowIndex),
+			Content:  content,
+			MetaData: meta,
+		})
+	}
+	return docs
+}
+
+// normalizeHeaders 去除空白, 空表头使用 "列N" 占位
+func normalizeHeaders(row []string) []string {
+	headers := make([]string, len(row))
+	for i, h := range row {
+		h = strings.TrimSpace(h)
+		if h == "" {
+			h = fmt.Sprintf("列%d", i+1)
+		}
+		headers[i] = h
+	}
+	return headers
+}
+
+// renderRow 将一行渲染为 "列名: 值" 的多行文本, 空单元格跳过, 超出表头的列使用 "列N"
+func renderRow(headers []string, row []string) string {
+	var sb strings.Builder
+	for i, cell := range row {
+		cell = strings.TrimSpace(cell)
+		if cell == "" {
+			continue
+		}
+		header := fmt.Sprintf("列%d", i+1)
+		if i < len(headers) {
+			header = headers[i]
+		}
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(header)
+		sb.WriteString(": ")
+		sb.WriteString(cell)
+	}
+	return sb.String()
+}
+
+// trimCells 去除单元格首尾空白, 保留原始列位置
+func trimCells(row []string) []string {
+	cells := make([]string, len(row))
+	for i, cell := range row {
+		cells[i] = strings.TrimSpace(cell)
+	}
+	return cells
+}
+
+func isEmptyRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
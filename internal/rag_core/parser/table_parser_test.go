@@ -0,0 +1,114 @@
+package parser
+
+import (
+	"bufio"
+	"context"
+	"strings"
+	"testing"
+
+	"gozero-rag/internal/rag_core/constant"
+	"gozero-rag/internal/rag_core/types"
+
+	"github.com/cloudwego/eino/components/document/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableParser_ParseCsv(t *testing.T) {
+	tests := []struct {
+		name    string
+		uri     string
+		content string
+	}{
+		{"逗号分隔", "/tmp/员工.csv", "姓名,部门,年龄\n张三,研发,28\n\n李四,市场,\n"},
+		{"分号分隔", "/tmp/员工.csv", "姓名;部门;年龄\n张三;研发;28\n李四;市场;\n"},
+		{"制表符分隔", "/tmp/员工.tsv", "姓名\t部门\t年龄\n张三\t研发\t28\n李四\t市场\t\n"},
+	}
+
+	p, err := NewTableParser(context.Background())
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docs, err := p.Parse(context.Background(), strings.NewReader(tt.content), parser.WithURI(tt.uri))
+			require.NoError(t, err)
+			require.Len(t, docs, 2)
+
+			assert.Equal(t, "姓名: 张三\n部门: 研发\n年龄: 28", docs[0].Content)
+			// 空单元格不输出
+			assert.Equal(t, "姓名: 李四\n部门: 市场", docs[1].Content)
+
+			assert.Equal(t, "员工", docs[0].MetaData[constant.MetaSheetName])
+			assert.Equal(t, []string{"姓名", "部门", "年龄"}, docs[0].MetaData[constant.MetaTableHeaders])
+			assert.Equal(t, 1, docs[0].MetaData[constant.MetaRowIndex])
+			assert.Equal(t, 2, docs[1].MetaData[constant.MetaRowIndex])
+		})
+	}
+}
+
+func TestSniffDelimiter_LongLines(t *testing.T) {
+	// 首行超过 bufio 默认的 4KB 缓冲区时仍能采样到后续行; 只看首行时逗号和分号各出现一次, 会误选逗号
+	long := strings.Repeat("x", 5000)
+	content := "a,b;" + long + "\n1;2;3\n4;5;6\n"
+	assert.Equal(t, ',', sniffDelimiter(bufio.NewReader(strings.NewReader(content))))
+	assert.Equal(t, ';', sniffDelimiter(bufio.NewReaderSize(strings.NewReader(content), sniffSampleBytes)))
+
+	p, err := NewTableParser(context.Background())
+	require.NoError(t, err)
+	docs, err := p.Parse(context.Background(), strings.NewReader(content), parser.WithURI("/tmp/long.csv"))
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	assert.Equal(t, []string{"4", "5", "6"}, docs[1].MetaData[constant.MetaRowValues])
+}
+
+func TestNormalizeHeaders(t *testing.T) {
+	assert.Equal(t, []string{"姓名", "列2", "年龄"}, normalizeHeaders([]string{" 姓名 ", "", "年龄"}))
+}
+
+func TestTableParser_QaHeader(t *testing.T) {
+	p, err := NewTableParser(context.Background())
+	require.NoError(t, err)
+
+	qaCtx := func(hasHeader *bool) context.Context {
+		conf := types.ProcessConfig{ParserId: ParserIdQa, Template: &ParserConfigQa{HasHeader: hasHeader}}
+		return context.WithValue(context.Background(), constant.CtxKeyIndexConfig, conf)
+	}
+	parse := func(ctx context.Context, content string) [][]string {
+		docs, err := p.Parse(ctx, strings.NewReader(content), parser.WithURI("/tmp/faq.csv"))
+		require.NoError(t, err)
+		rows := make([][]string, 0, len(docs))
+		for _, doc := range docs {
+			rows = append(rows, doc.MetaData[constant.MetaRowValues].([]string))
+		}
+		return rows
+	}
+
+	withHeader := "问题：,答案\n营业时间？,9:00-18:00\n"
+	noHeader := "营业时间？,9:00-18:00\n如何退款？,联系客服\n"
+
+	// 自动识别: 首行为问题/答案列名时作为表头, 否则作为数据行
+	assert.Equal(t, [][]string{{"营业时间？", "9:00-18:00"}}, parse(qaCtx(nil), withHeader))
+	rows := parse(qaCtx(nil), noHeader)
+	require.Len(t, rows, 2)
+	assert.Equal(t, []string{"营业时间？", "9:00-18:00"}, rows[0])
+
+	// 显式配置优先于自动识别
+	noHeaderFlag, headerFlag := false, true
+	assert.Len(t, parse(qaCtx(&noHeaderFlag), withHeader), 2)
+	assert.Len(t, parse(qaCtx(&headerFlag), noHeader), 1)
+
+	// 无表头时列名使用占位
+	docs, err := p.Parse(qaCtx(nil), strings.NewReader(noHeader), parser.WithURI("/tmp/faq.csv"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"列1", "列2"}, docs[0].MetaData[constant.MetaTableHeaders])
+
+	// 非问答模板首行总是表头
+	assert.Len(t, parse(context.Background(), noHeader), 1)
+}
+
+func TestIsQaHeader(t *testing.T) {
+	assert.True(t, isQaHeader([]string{" Question ", "Answer:", "备注"}))
+	assert.True(t, isQaHeader([]string{"Q", "A"}))
+	assert.False(t, isQaHeader([]string{"问题"}))
+	assert.False(t, isQaHeader([]string{"营业时间？", "9:00-18:00"}))
+}
@@ -0,0 +1,272 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"gozero-rag/internal/rag_core/types"
+)
+
+// ParserConfig 解析模板配置
+// 每个 parser_id 对应一个配置结构体, 均内嵌 ParserConfigGeneral 作为公共配置 (分片大小、QA、知识图谱等)
+type ParserConfig interface {
+	// General 返回公共配置
+	General() *ParserConfigGeneral
+	// Validate 校验配置是否合法
+	Validate() error
+}
+
+// parserConfigRegistry parser_id -> 配置构造函数
+var parserConfigRegistry = map[ParserId]func() ParserConfig{
+	ParserIdGeneral: func() ParserConfig { return &ParserConfigGeneral{} },
+	ParserIdTable:   func() ParserConfig { return &ParserConfigTable{} },
+	ParserIdQa:      func() ParserConfig { return &ParserConfigQa{} },
+	ParserIdLaws:    func() ParserConfig { return &ParserConfigLaws{} },
+	ParserIdBook:    func() ParserConfig { return &ParserConfigBook{} },
+	ParserIdResume:  func() ParserConfig { return &ParserConfigResume{} },
+}
+
+// IsSupported parser_id 是否已注册
+func IsSupported(parserId ParserId) bool {
+	_, ok := parserConfigRegistry[parserId]
+	return ok
+}
+
+// SupportedParserIds 所有已注册的 parser_id (有序)
+func SupportedParserIds() []string {
+	ids := make([]string, 0, len(parserConfigRegistry))
+	for id := range parserConfigRegistry {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// ParseParserConfig 按 parser_id 解析并校验配置 JSON, raw 为空时使用默认值
+func ParseParserConfig(parserId ParserId, raw string) (ParserConfig, error) {
+	newConfig, ok := parserConfigRegistry[parserId]
+	if !ok {
+		return nil, fmt.Errorf("尚不支持该解析类型: %s", parserId)
+	}
+
+	config := newConfig()
+	if raw != "" {
+		if err := json.Unmarshal([]byte(raw), config); err != nil {
+			return nil, fmt.Errorf("解析配置无效: %w", err)
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// ResolveParserConfig 按顺序叠加多层配置 JSON (如 知识库默认配置 -> 文档配置), 后面的层覆盖前面的同名字段
+// 嵌套对象 (如 graph_rag / parent_child) 按字段合并, 空字符串的层被忽略
+func ResolveParserConfig(parserId ParserId, layers ...string) (ParserConfig, error) {
+	merged := make(map[string]any)
+	for _, layer := range layers {
+		if layer == "" {
+			continue
+		}
+		var m map[string]any
+		if err := json.Unmarshal([]byte(layer), &m); err != nil {
+			return nil, fmt.Errorf("解析配置无效: %w", err)
+		}
+		mergeConfigMap(merged, m)
+	}
+
+	raw, err := json.Marshal(merged)
+	if err != nil {
+		return nil, err
+	}
+	return ParseParserConfig(parserId, string(raw))
+}
+
+func mergeConfigMap(dst, src map[string]any) {
+	for k, v := range src {
+		srcMap, ok := v.(map[string]any)
+		if !ok {
+			dst[k] = v
+			continue
+		}
+		dstMap, ok := dst[k].(map[string]any)
+		if !ok {
+			dstMap = make(map[string]any)
+			dst[k] = dstMap
+		}
+		mergeConfigMap(dstMap, srcMap)
+	}
+}
+
+func (c *ParserConfigGeneral) General() *ParserConfigGeneral {
+	return c
+}
+
+func (c *ParserConfigGeneral) Validate() error {
+	if c.ChunkTokenNum < 0 || c.ChunkOverlapTokenNum < 0 || c.QaNum < 0 {
+		return fmt.Errorf("chunk_token_num / chunk_overlap_token_num / qa_num 不能为负数")
+	}
+	if c.ChunkTokenNum > 0 && c.ChunkOverlapTokenNum >= c.ChunkTokenNum {
+		return fmt.Errorf("chunk_overlap_token_num 必须小于 chunk_token_num")
+	}
+
+	switch c.ChunkMethod {
+	case "", ChunkMethodRecursive, ChunkMethodStructure, ChunkMethodMarkdown, ChunkMethodSemantic:
+	case ChunkMethodAgentic:
+		if c.ChunkLlmId == "" {
+			return fmt.Errorf("chunk_method 为 agentic 时必须指定 chunk_llm_id")
+		}
+	default:
+		return fmt.Errorf("不支持的 chunk_method: %s", c.ChunkMethod)
+	}
+	if c.AgenticMaxLlmCalls < 0 {
+		return fmt.Errorf("agentic_max_llm_calls 不能为负数")
+	}
+	if c.SemanticPercentile < 0 || c.SemanticPercentile >= 100 {
+		return fmt.Errorf("semantic_percentile 必须在 0-99 之间")
+	}
+	if c.MinQualityScore < 0 || c.MinQualityScore > 100 {
+		return fmt.Errorf("min_quality_score 必须在 0-100 之间")
+	}
+	if g := c.GraphRag; g != nil {
+		if err := g.Validate(); err != nil {
+			return err
+		}
+	}
+	if pc := c.PreClean; pc != nil {
+		for _, pattern := range pc.RemovePatterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("remove_patterns 不是合法的正则表达式 %s: %w", pattern, err)
+			}
+		}
+	}
+	if pc := c.ParentChild; pc != nil && pc.Enable {
+		if pc.ChildChunkSize < 0 || pc.ChildOverlap < 0 {
+			return fmt.Errorf("child_chunk_size / child_overlap 不能为负数")
+		}
+		if c.ChunkTokenNum > 0 && pc.ChildChunkSize >= c.ChunkTokenNum {
+			return fmt.Errorf("child_chunk_size 必须小于 chunk_token_num")
+		}
+		if pc.ChildChunkSize > 0 && pc.ChildOverlap >= pc.ChildChunkSize {
+			return fmt.Errorf("child_overlap 必须小于 child_chunk_size")
+		}
+	}
+	return nil
+}
+
+const (
+	maxEntityTypes      = 20 // 实体类型最多个数
+	maxEntityTypeLength = 32 // 单个实体类型最大字符数
+)
+
+// entityTypeForbidden 实体类型中不允许出现的字符, 会与抽取提示词的分隔符冲突
+var entityTypeForbidden = []string{"<|>", "##", ",", "[", "]", "\"", "\n"}
+
+// Validate 校验实体类型: 非空、不重复 (忽略大小写)、不包含提示词分隔符
+func (c *GraphRagConfig) Validate() error {
+	if len(c.EntityTypes) > maxEntityTypes {
+		return fmt.Errorf("entity_types 最多 %d 个", maxEntityTypes)
+	}
+	seen := make(map[string]struct{}, len(c.EntityTypes))
+	for _, entityType := range c.EntityTypes {
+		name := strings.TrimSpace(entityType)
+		if name == "" {
+			return fmt.Errorf("entity_types 不能包含空值")
+		}
+		if utf8.RuneCountInString(name) > maxEntityTypeLength {
+			return fmt.Errorf("实体类型 %s 超过 %d 个字符", name, maxEntityTypeLength)
+		}
+		for _, forbidden := range entityTypeForbidden {
+			if strings.Contains(name, forbidden) {
+				return fmt.Errorf("实体类型 %s 包含非法字符 %q", name, forbidden)
+			}
+		}
+		key := strings.ToLower(name)
+		if _, ok := seen[key]; ok {
+			return fmt.Errorf("实体类型重复: %s", name)
+		}
+		seen[key] = struct{}{}
+	}
+	return nil
+}
+
+func (c *ParserConfigTable) Validate() error {
+	if c.RowsPerChunk < 0 {
+		return fmt.Errorf("rows_per_chunk 不能为负数")
+	}
+	if c.Delimiter != "" && c.Delimiter != `\t` && len([]rune(c.Delimiter)) != 1 {
+		return fmt.Errorf("delimiter 只能是单个字符: %s", c.Delimiter)
+	}
+	return c.ParserConfigGeneral.Validate()
+}
+
+func (c *ParserConfigQa) Validate() error {
+	return c.ParserConfigGeneral.Validate()
+}
+
+func (c *ParserConfigLaws) Validate() error {
+	if c.ArticlesPerChunk < 0 {
+		return fmt.Errorf("articles_per_chunk 不能为负数")
+	}
+	return c.ParserConfigGeneral.Validate()
+}
+
+func (c *ParserConfigBook) Validate() error {
+	if c.ChapterPattern != "" {
+		if _, err := regexp.Compile(c.ChapterPattern); err != nil {
+			return fmt.Errorf("chapter_pattern 不是合法的正则表达式: %w", err)
+		}
+	}
+	return c.ParserConfigGeneral.Validate()
+}
+
+func (c *ParserConfigResume) Validate() error {
+	return c.ParserConfigGeneral.Validate()
+}
+
+// NewProcessConfig 将解析模板配置转换为 DocProcessService 使用的分片配置
+// 不包含 KnowledgeName / EnableQACheck / LlmConfig 等运行时字段, 由调用方补充
+func NewProcessConfig(parserId ParserId, config ParserConfig) types.ProcessConfig {
+	general := config.General()
+	processConfig := types.ProcessConfig{
+		ParserId:       parserId,
+		QaNum:          general.QaNum,
+		Separators:     general.Separator,
+		ChunkOverlap:   general.ChunkOverlapTokenNum,
+		MaxChunkLength: general.ChunkTokenNum,
+		Template:       config,
+
+		ChunkMethod:        general.ChunkMethod,
+		AgenticMaxLlmCalls: general.AgenticMaxLlmCalls,
+		SemanticPercentile: general.SemanticPercentile,
+	}
+
+	if pc := general.PreClean; pc != nil {
+		processConfig.PreCleanRule = types.IndexConfigPreCleanRule{
+			CleanWhitespace:   pc.CleanWhitespace,
+			RemoveUrlsEmails:  pc.RemoveUrlsEmails,
+			NormalizeWidth:    pc.NormalizeWidth,
+			StripControlChars: pc.StripControlChars,
+			RemovePatterns:    pc.RemovePatterns,
+			MaskPhone:         pc.MaskPhone,
+			MaskIdCard:        pc.MaskIdCard,
+			MaskEmail:         pc.MaskEmail,
+			RemoveBoilerplate: pc.RemoveBoilerplate,
+			BoilerplateLines:  pc.BoilerplateLines,
+		}
+	}
+	if pc := general.ParentChild; pc != nil && pc.Enable {
+		processConfig.ParentChild = types.ParentChildConfig{
+			Enable:         true,
+			ChildChunkSize: pc.ChildChunkSize,
+			ChildOverlap:   pc.ChildOverlap,
+		}
+	}
+	return processConfig
+}
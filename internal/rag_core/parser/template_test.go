@@ -0,0 +1,84 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseParserConfig(t *testing.T) {
+	config, err := ParseParserConfig(ParserIdLaws, `{"chunk_token_num": 512, "articles_per_chunk": 3, "qa_num": 2}`)
+	require.NoError(t, err)
+
+	laws, ok := config.(*ParserConfigLaws)
+	require.True(t, ok)
+	assert.Equal(t, 3, laws.ArticlesPerChunk)
+	assert.Equal(t, 512, config.General().ChunkTokenNum)
+	assert.Equal(t, 2, config.General().QaNum)
+
+	// 空配置使用默认值
+	config, err = ParseParserConfig(ParserIdGeneral, "")
+	require.NoError(t, err)
+	assert.Equal(t, 0, config.General().ChunkTokenNum)
+}
+
+func TestParseParserConfig_Invalid(t *testing.T) {
+	cases := []struct {
+		name     string
+		parserId string
+		raw      string
+	}{
+		{"未注册的 parser_id", "unknown", ""},
+		{"非法 JSON", ParserIdGeneral, "{"},
+		{"重叠大于分片", ParserIdGeneral, `{"chunk_token_num": 100, "chunk_overlap_token_num": 100}`},
+		{"多字符分隔符", ParserIdTable, `{"delimiter": ";;"}`},
+		{"非法章节正则", ParserIdBook, `{"chapter_pattern": "("}`},
+		{"未知分片策略", ParserIdGeneral, `{"chunk_method": "unknown"}`},
+		{"agentic 未指定模型", ParserIdGeneral, `{"chunk_method": "agentic"}`},
+		{"百分位越界", ParserIdGeneral, `{"chunk_method": "semantic", "semantic_percentile": 100}`},
+		{"非法清洗正则", ParserIdGeneral, `{"pre_clean": {"remove_patterns": ["("]}}`},
+		{"质量阈值越界", ParserIdGeneral, `{"min_quality_score": 101}`},
+		{"实体类型为空", ParserIdGeneral, `{"graph_rag": {"entity_types": ["statute", " "]}}`},
+		{"实体类型重复", ParserIdGeneral, `{"graph_rag": {"entity_types": ["Party", "party"]}}`},
+		{"实体类型含分隔符", ParserIdGeneral, `{"graph_rag": {"entity_types": ["court<|>judge"]}}`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := ParseParserConfig(c.parserId, c.raw)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNewProcessConfig(t *testing.T) {
+	config, err := ParseParserConfig(ParserIdGeneral, `{"chunk_token_num": 512, "chunk_overlap_token_num": 50, "chunk_method": "semantic", "parent_child": {"enable": true, "child_chunk_size": 128}}`)
+	require.NoError(t, err)
+
+	processConfig := NewProcessConfig(ParserIdGeneral, config)
+	assert.Equal(t, 512, processConfig.MaxChunkLength)
+	assert.Equal(t, 50, processConfig.ChunkOverlap)
+	assert.Equal(t, ChunkMethodSemantic, processConfig.ChunkMethod)
+	assert.True(t, processConfig.ParentChild.Enable)
+	assert.Equal(t, 128, processConfig.ParentChild.ChildChunkSize)
+	assert.Same(t, config, processConfig.Template)
+}
+
+func TestResolveParserConfig(t *testing.T) {
+	kbConfig := `{"chunk_token_num": 512, "chunk_overlap_token_num": 50, "graph_rag": {"enable_graph": true, "entity_types": ["person"]}}`
+	docConfig := `{"chunk_token_num": 256, "graph_rag": {"entity_types": ["org"]}}`
+
+	config, err := ResolveParserConfig(ParserIdGeneral, kbConfig, docConfig, "")
+	require.NoError(t, err)
+
+	general := config.General()
+	assert.Equal(t, 256, general.ChunkTokenNum)
+	assert.Equal(t, 50, general.ChunkOverlapTokenNum)
+	assert.True(t, general.GraphRag.EnableGraph)
+	assert.Equal(t, []string{"org"}, general.GraphRag.EntityTypes)
+
+	// 叠加后的配置同样需要校验
+	_, err = ResolveParserConfig(ParserIdGeneral, kbConfig, `{"chunk_overlap_token_num": 600}`)
+	assert.Error(t, err)
+}
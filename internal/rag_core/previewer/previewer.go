@@ -3,73 +3,103 @@ package previewer
 import (
 	"context"
 	"fmt"
-	"github.com/cloudwego/eino-ext/components/document/transformer/splitter/markdown"
-	"github.com/cloudwego/eino-ext/components/document/transformer/splitter/recursive"
-	"github.com/cloudwego/eino/components/document"
+	"strings"
+	"unicode/utf8"
+
+	"gozero-rag/internal/rag_core/constant"
+	"gozero-rag/internal/rag_core/doc_processor"
+	"gozero-rag/internal/rag_core/qa"
+	"gozero-rag/internal/rag_core/types"
+
 	"github.com/cloudwego/eino/schema"
 )
 
+// Previewer 使用与索引相同的 loader/cleaner/transformer 切分已上传的文档, 结果不落库
+// 用于在正式索引前调整分片配置
 type Previewer struct {
-	previewConf      PreviewConf
-	markdownSplitter document.Transformer
-
-	recursiveSplitter document.Transformer
+	processor *doc_processor.ProcessorService
+	scorer    *qa.Scorer
 }
 
-func NewPreviewer(ctx context.Context, previewConf PreviewConf) (*Previewer, error) {
-	mdSplitter, err := markdown.NewHeaderSplitter(ctx, &markdown.HeaderConfig{
-		Headers: map[string]string{
-			"#":    "h1", // 一级标题
-			"##":   "h2", // 二级标题
-			"###":  "h3", // 三级标题
-			"####": "h4", // 四级标题
-
-		},
-		TrimHeaders: false, // 是否在输出中保留标题行
-	})
-
+func NewPreviewer(ctx context.Context) (*Previewer, error) {
+	processor, err := doc_processor.NewDocProcessService(ctx)
 	if err != nil {
 		return nil, err
 	}
-
-	recursiveSplitter, err := recursive.NewSplitter(ctx, &recursive.Config{
-		ChunkSize:   previewConf.MaxChunkLength,
-		Separators:  previewConf.Separator,
-		OverlapSize: previewConf.ChunkOverlap,
-
-		LenFunc:  nil,                    // 可选：自定义长度计算函数
-		KeepType: recursive.KeepTypeNone, // 可选：分隔符保留策略
-	})
-
 	return &Previewer{
-		previewConf:       previewConf,
-		markdownSplitter:  mdSplitter,
-		recursiveSplitter: recursiveSplitter,
+		processor: processor,
+		scorer:    qa.NewScorer(),
 	}, nil
 }
 
-func (p *Previewer) Preview(ctx context.Context, docType DocType, docId string, content string) (result []*schema.Document, err error) {
-	docs := []*schema.Document{
-		{
-			ID:      docId,
-			Content: content,
-		},
+// Preview 切分文档的前 MaxPreviewContentLength 个字符, 返回前 limit 个 chunk 及质量评分
+// 预览不生成 QA (EnableQACheck 强制关闭), 避免额外的 LLM 调用, 因此评分中的 QA 分项为 0
+func (p *Previewer) Preview(ctx context.Context, req *types.ProcessRequest, limit int) (*PreviewResult, error) {
+	if limit <= 0 {
+		limit = DefaultPreviewLimit
 	}
+	limit = min(limit, MaxPreviewLimit)
 
-	switch docType {
-	case DocTypeMarkdown:
-		result, err = p.markdownSplitter.Transform(ctx, docs)
-		if err != nil {
-			return nil, err
-		}
-	case DocTypeText:
-		result, err = p.recursiveSplitter.Transform(ctx, docs)
-		if err != nil {
-			return nil, err
+	sections, err := p.processor.Load(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("加载文档失败: %w", err)
+	}
+
+	result := &PreviewResult{}
+	for _, s := range sections {
+		result.ContentLength += utf8.RuneCountInString(s.Content)
+	}
+	sections, result.Truncated = previewPrefix(sections, MaxPreviewContentLength)
+
+	config := req.IndexConfig
+	config.EnableQACheck = false
+	docs, err := p.processor.Split(ctx, config, sections)
+	if err != nil {
+		return nil, fmt.Errorf("切分文档失败: %w", err)
+	}
+
+	result.TotalChunks = len(docs)
+	prevContent := ""
+	for i, doc := range docs {
+		length := utf8.RuneCountInString(doc.Content)
+		result.TotalLength += length
+
+		if i < limit {
+			chunk := &PreviewChunk{
+				Index:   i,
+				Content: doc.Content,
+				Length:  length,
+				Quality: p.scorer.Score(doc.Content, nil, prevContent),
+			}
+			chunk.Header, _ = doc.MetaData[constant.MetaHeaderContext].(string)
+			chunk.HeaderType, _ = doc.MetaData[constant.MetaHeaderType].(string)
+			result.Chunks = append(result.Chunks, chunk)
 		}
-	default:
-		return nil, fmt.Errorf("不支持的的doc type类型:%v", docType)
+		prevContent = doc.Content
 	}
 
 	return result, nil
 }
+
+// previewPrefix 截取前 maxLen 个字符的章节, 超出的章节在最后一个换行处截断 (没有换行时按字符截断)
+func previewPrefix(sections []*schema.Document, maxLen int) ([]*schema.Document, bool) {
+	remaining := maxLen
+	for i, s := range sections {
+		length := utf8.RuneCountInString(s.Content)
+		if length <= remaining {
+			remaining -= length
+			continue
+		}
+
+		prefix := sections[:i:i]
+		if remaining > 0 {
+			content := string([]rune(s.Content)[:remaining])
+			if idx := strings.LastIndex(content, "\n"); idx > 0 {
+				content = content[:idx+1]
+			}
+			prefix = append(prefix, &schema.Document{ID: s.ID, Content: content, MetaData: s.MetaData})
+		}
+		return prefix, true
+	}
+	return sections, false
+}
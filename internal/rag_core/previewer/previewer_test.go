@@ -0,0 +1,43 @@
+package previewer
+
+import (
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviewPrefix(t *testing.T) {
+	sections := []*schema.Document{
+		{ID: "a", Content: "第一章内容"},
+		{ID: "b", Content: "第一段\n第二段\n第三段", MetaData: map[string]any{"sheet": "s1"}},
+		{ID: "c", Content: "第三章"},
+	}
+
+	// 未超过上限时原样返回
+	prefix, truncated := previewPrefix(sections, 100)
+	assert.False(t, truncated)
+	assert.Len(t, prefix, 3)
+
+	// 超出的章节在最后一个换行处截断, 保留元数据, 不修改原章节
+	prefix, truncated = previewPrefix(sections, 5+9)
+	assert.True(t, truncated)
+	require.Len(t, prefix, 2)
+	assert.Equal(t, "第一章内容", prefix[0].Content)
+	assert.Equal(t, "第一段\n第二段\n", prefix[1].Content)
+	assert.Equal(t, "s1", prefix[1].MetaData["sheet"])
+	assert.Equal(t, "第一段\n第二段\n第三段", sections[1].Content)
+
+	// 没有换行时按字符截断
+	prefix, truncated = previewPrefix(sections, 3)
+	assert.True(t, truncated)
+	require.Len(t, prefix, 1)
+	assert.Equal(t, "第一章", prefix[0].Content)
+
+	// 上限恰好落在章节边界时不产生空章节
+	prefix, truncated = previewPrefix(sections, 5)
+	assert.True(t, truncated)
+	require.Len(t, prefix, 1)
+	assert.Equal(t, "a", prefix[0].ID)
+}
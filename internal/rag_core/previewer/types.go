@@ -1,16 +1,31 @@
 package previewer
 
-// PreviewConf 定义切分规则
-
-type DocType = string
+import "gozero-rag/internal/rag_core/types"
 
+// 预览默认/最大返回的 chunk 数量
 const (
-	DocTypeMarkdown DocType = "markdown"
-	DocTypeText     DocType = "text"
+	DefaultPreviewLimit = 10
+	MaxPreviewLimit     = 50
 )
 
-type PreviewConf struct {
-	Separator      []string
-	MaxChunkLength int
-	ChunkOverlap   int
+// MaxPreviewContentLength 预览切分的文档前缀长度 (字符数), 超出部分不参与切分, 避免大文档预览触发大量 Embedding/LLM 调用
+const MaxPreviewContentLength = 50000
+
+// PreviewChunk 单个预览分片
+type PreviewChunk struct {
+	Index      int
+	Content    string
+	Length     int // 字符数
+	Header     string
+	HeaderType string
+	Quality    *types.ChunkQualityScore
+}
+
+// PreviewResult 文档预览结果
+type PreviewResult struct {
+	ContentLength int  // 文档清洗后的字符总数
+	Truncated     bool // 文档超过 MaxPreviewContentLength, 只切分了前缀
+	TotalChunks   int  // 预览内容按候选配置切分得到的 chunk 总数
+	TotalLength   int  // 预览内容所有 chunk 的字符总数
+	Chunks        []*PreviewChunk
 }
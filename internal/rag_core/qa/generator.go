@@ -78,9 +78,12 @@ func (g *Generator) GenerateBatch(ctx context.Context, docs []*schema.Document,
 			logx.Errorf("生成第 %d 个 chunk 的 QA 失败: %v", i, err)
 			// 失败不阻塞，继续处理其他 chunk
 			results[i] = nil
-			continue
+		} else {
+			results[i] = qaPairs
+		}
+		if g.config.QaProgress != nil {
+			g.config.QaProgress(i+1, len(docs))
 		}
-		results[i] = qaPairs
 	}
 
 	return results, nil
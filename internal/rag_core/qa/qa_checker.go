@@ -37,29 +37,16 @@ func (c *QaChecker) Check(ctx context.Context, input []*schema.Document) (output
 	logx.Infof("[QaChecker] 开始检查 %d 个 chunk, 知识库: %s", len(input), knowledgeName)
 
 	// 第一轮: 评分并识别需要生成 QA 的 chunk
+	c.Score(input)
+
 	var needQAChunks []*schema.Document
 	var needQAIndices []int
 
 	for i, doc := range input {
-		// 获取已有的 QA pairs
-		qaPairs := c.getQAPairs(doc)
-
-		// 获取前一个 chunk 的内容 (用于计算重复度)
-		prevContent := ""
-		if i > 0 {
-			prevContent = input[i-1].Content
-		}
-
-		// 计算评分
-		score := c.scorer.Score(doc.Content, qaPairs, prevContent)
-
-		// 存储评分结果到 Metadata
-		c.storeScore(doc, score)
-
 		// 检查是否需要生成 QA
 		// 条件: QA 数量不足 且 内容长度足够 (过短的 chunk 不值得生成 QA)
 		contentLen := len([]rune(doc.Content))
-		if len(qaPairs) < types.QAMinCount && contentLen >= types.LengthTooShort {
+		if len(c.getQAPairs(doc)) < types.QAMinCount && contentLen >= types.LengthTooShort {
 			needQAChunks = append(needQAChunks, doc)
 			needQAIndices = append(needQAIndices, i)
 		}
@@ -102,6 +89,18 @@ func (c *QaChecker) Check(ctx context.Context, input []*schema.Document) (output
 	return input, nil
 }
 
+// Score 仅对 chunk 评分并存储到 Metadata, 不生成 QA
+func (c *QaChecker) Score(input []*schema.Document) {
+	for i, doc := range input {
+		// 获取前一个 chunk 的内容 (用于计算重复度)
+		prevContent := ""
+		if i > 0 {
+			prevContent = input[i-1].Content
+		}
+		c.storeScore(doc, c.scorer.Score(doc.Content, c.getQAPairs(doc), prevContent))
+	}
+}
+
 // getKnowledgeName 获取知识库名称
 func (c *QaChecker) getKnowledgeName(docs []*schema.Document) string {
 	if len(docs) == 0 {
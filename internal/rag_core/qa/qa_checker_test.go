@@ -10,6 +10,7 @@ import (
 
 	"github.com/cloudwego/eino/schema"
 	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestQaChecker_Check(t *testing.T) {
@@ -129,3 +130,21 @@ func truncate(s string, maxLen int) string {
 	}
 	return string(runes[:maxLen]) + "..."
 }
+
+func TestQaChecker_Score(t *testing.T) {
+	docs := []*schema.Document{
+		{Content: "这是一段很短的内容，"},
+		{Content: "第二段内容"},
+	}
+
+	NewQaChecker().Score(docs)
+
+	for _, doc := range docs {
+		score, ok := doc.MetaData[constant.MetaQualityScore].(float64)
+		assert.True(t, ok)
+		assert.Greater(t, score, 0.0)
+		details, ok := doc.MetaData[constant.MetaQualityDetails].(*types.ChunkQualityScore)
+		assert.True(t, ok)
+		assert.Contains(t, details.Issues, types.IssueChunkTooShort)
+	}
+}
@@ -36,15 +36,9 @@ func (r *ChunkRetriever) Retrieve(ctx context.Context, query string, opts ...ret
 
 	logx.Infof("[ChunkRetriever] 开始检索, query=%s, kb_id=%s, mode=%s", query, req.KnowledgeBaseId, req.Mode)
 
-	// String KB ID from request (will be updated to string in orchestration.go)
-	kbId := req.KnowledgeBaseId
-	topK := req.TopK * 3 // Fetch more for fusion/rerank
-
-	var chunks []*chunk.Chunk
-	var err error
-
 	// 2. 准备向量 if needed
 	var queryVector []float64
+	var err error
 	if req.Mode == RetrieveModeVector || req.Mode == RetrieveModeHybrid {
 		queryVector, err = r.embedQuery(ctx, req, query)
 		if err != nil {
@@ -52,11 +46,20 @@ func (r *ChunkRetriever) Retrieve(ctx context.Context, query string, opts ...ret
 		}
 	}
 
-	// 3. 调用 ChunkModel.HybridSearch
+	return r.search(ctx, req, query, queryVector)
+}
+
+// search 使用已生成的查询向量执行检索, queryVector 为空时只做关键词检索
+func (r *ChunkRetriever) search(ctx context.Context, req *RetrieveRequest, query string, queryVector []float64) ([]*schema.Document, error) {
+	// 调用 ChunkModel.HybridSearch
 	// ChunkModel handles vector (if provided) and keyword search logic.
 	// If queryVector is nil/empty, it usually falls back to keyword only if implemented handles it.
 	// My ChunkModelEs.HybridSearch implementation checks if vector is empty.
-	chunks, err = r.chunkModel.HybridSearch(ctx, kbId, query, queryVector, topK)
+	var filter *chunk.SearchFilter
+	if len(req.SheetNames) > 0 {
+		filter = &chunk.SearchFilter{SheetNames: req.SheetNames}
+	}
+	chunks, err := r.chunkModel.HybridSearch(ctx, req.KnowledgeBaseId, query, queryVector, req.TopK*3, filter) // Fetch more for fusion/rerank
 	if err != nil {
 		logx.Errorf("[ChunkRetriever] 检索失败: %v", err)
 		return nil, err
@@ -64,31 +67,33 @@ func (r *ChunkRetriever) Retrieve(ctx context.Context, query string, opts ...ret
 
 	logx.Infof("[ChunkRetriever] 检索完成, 返回 %d 条结果", len(chunks))
 
-	// 4. Convert to Eino Documents
+	// Convert to Eino Documents
 	docs := make([]*schema.Document, 0, len(chunks))
 	for _, c := range chunks {
-		doc := &schema.Document{
-			ID:      c.Id,
-			Content: c.Content,
-			MetaData: map[string]any{
-				"chunk_id":          c.Id,
-				"doc_id":            c.DocId,
-				"knowledge_base_id": c.KbIds, // Note: ChunkModel stores []string, but here we work contextually with one KB usually.
-				// Add score if available from ES result (currently Chunk struct doesn't strictly have a Score field exported from ES explicitly in my model?
-				// Wait, EsChunkModel unmarshals _source. Score is in hit["_score"].
-				// My ChunkModel implementation returns []*Chunk, which is just data. It loses the score!
-				// ERROR: My previous ChunkModel implementation LOSES the score from ES!
-				// The _source doesn't contain the score. The score is meta.
-				// I need to fix ChunkModel implementation to Populate Score.
-			},
-		}
-		// doc.WithScore?
-		docs = append(docs, doc)
+		docs = append(docs, chunkToDocument(c))
 	}
 
 	return docs, nil
 }
 
+// chunkToDocument 将分片转换为 Eino Document, 保留 QA 与父子分片的元数据供后续节点使用
+func chunkToDocument(c *chunk.Chunk) *schema.Document {
+	return &schema.Document{
+		ID:      c.Id,
+		Content: c.Content,
+		MetaData: map[string]any{
+			"chunk_id":          c.Id,
+			"doc_id":            c.DocId,
+			"sheet_name":        c.SheetName,
+			MetaParentID:        c.ParentId,
+			MetaType:            c.ChunkType,
+			MetaSourceID:        c.SourceId,
+			MetaQuestion:        c.Question,
+			"knowledge_base_id": c.KbIds,
+		},
+	}
+}
+
 // embedQuery 生成查询向量 (Copy from VectorRetriever)
 func (r *ChunkRetriever) embedQuery(ctx context.Context, req *RetrieveRequest, query string) ([]float64, error) {
 	embeddingConfig := req.EmbeddingModelConfig
@@ -0,0 +1,313 @@
+package retriever
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cloudwego/eino/components/retriever"
+	"github.com/cloudwego/eino/schema"
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"gozero-rag/internal/graphrag/types"
+	"gozero-rag/internal/model/chunk"
+	"gozero-rag/internal/model/graph"
+)
+
+const (
+	graphSeedTopK        = 10  // 向量检索命中的种子实体数量
+	graphMinSimilarity   = 0.5 // 种子实体的最低余弦相似度
+	graphMaxHops         = 3   // 邻居扩展的最大跳数
+	graphMaxRelations    = 100 // 邻居扩展返回的关系上限
+	graphContextRelLim   = 30  // 图谱上下文中保留的关系数量
+	graphRelationFactor  = 0.5 // 关系溯源分片相对种子实体溯源分片的计分系数
+	graphContextIdPrefix = "graph_context_"
+	rrfK                 = 60 // RRF 融合常数
+)
+
+// GraphRetriever 图谱检索: 查询向量命中 kg_graph 中的实体, 在 Nebula 中扩展邻居,
+// 返回由实体/关系描述组成的图谱上下文, 以及实体和关系溯源 (source_id) 的分片
+type GraphRetriever struct {
+	graphModel  graph.GraphModel
+	nebulaModel graph.NebulaGraphModel
+	chunkModel  chunk.ChunkModel
+}
+
+func NewGraphRetriever(graphModel graph.GraphModel, nebulaModel graph.NebulaGraphModel, chunkModel chunk.ChunkModel) *GraphRetriever {
+	return &GraphRetriever{
+		graphModel:  graphModel,
+		nebulaModel: nebulaModel,
+		chunkModel:  chunkModel,
+	}
+}
+
+// search 使用查询向量执行图谱检索, 知识库未构建图谱时返回空结果
+func (r *GraphRetriever) search(ctx context.Context, req *RetrieveRequest, queryVector []float64) ([]*schema.Document, error) {
+	similar, err := r.graphModel.SearchSimilarEntities(ctx, req.KnowledgeBaseId, queryVector, graphSeedTopK)
+	if err != nil {
+		return nil, fmt.Errorf("检索相似实体失败: %w", err)
+	}
+	seeds := make(map[string]float64, len(similar))
+	names := make([]string, 0, len(similar))
+	for _, s := range similar {
+		if s.Similarity < graphMinSimilarity || s.EntityName == "" {
+			continue
+		}
+		seeds[s.EntityName] = s.Similarity
+		names = append(names, s.EntityName)
+	}
+	if len(names) == 0 {
+		logx.Infof("[GraphRetriever] 未命中实体, kb_id=%s", req.KnowledgeBaseId)
+		return nil, nil
+	}
+
+	entities, relations, err := r.nebulaModel.ExpandNeighbors(ctx, req.KnowledgeBaseId, names, graphHops(req), graphMaxRelations)
+	if err != nil {
+		return nil, fmt.Errorf("扩展实体邻居失败: %w", err)
+	}
+
+	docs := make([]*schema.Document, 0)
+	if content := buildGraphContext(names, entities, relations); content != "" {
+		// 多知识库检索时结果会合并, 上下文 ID 按知识库区分
+		contextId := graphContextIdPrefix + req.KnowledgeBaseId
+		docs = append(docs, &schema.Document{
+			ID:      contextId,
+			Content: content,
+			MetaData: map[string]any{
+				MetaChunkID:         contextId,
+				MetaKnowledgeBaseID: req.KnowledgeBaseId,
+				MetaType:            RetrieveModeGraph,
+				MetaSource:          RetrieveModeGraph,
+				MetaGraphEntities:   entities,
+				MetaGraphRelations:  relations,
+			},
+		})
+	}
+
+	chunkIds := rankSourceChunks(seeds, entities, relations, req.TopK*3)
+	if len(chunkIds) > 0 {
+		chunks, err := r.chunkModel.GetByIds(ctx, chunkIds)
+		if err != nil {
+			return nil, fmt.Errorf("查询图谱来源分片失败: %w", err)
+		}
+		chunkMap := make(map[string]*chunk.Chunk, len(chunks))
+		for _, c := range chunks {
+			if c.Available == 0 {
+				continue
+			}
+			chunkMap[c.Id] = c
+		}
+		// 保持溯源得分顺序, 已删除或已禁用的分片忽略
+		for _, id := range chunkIds {
+			if c, ok := chunkMap[id]; ok {
+				doc := chunkToDocument(c)
+				doc.MetaData[MetaSource] = RetrieveModeGraph
+				docs = append(docs, doc)
+			}
+		}
+	}
+
+	logx.Infof("[GraphRetriever] 检索完成, 种子实体 %d 个, 扩展实体 %d 个, 关系 %d 条, 返回 %d 条结果",
+		len(names), len(entities), len(relations), len(docs))
+	return docs, nil
+}
+
+func graphHops(req *RetrieveRequest) int {
+	if req.GraphHops <= 0 {
+		return 1
+	}
+	return min(req.GraphHops, graphMaxHops)
+}
+
+// rankSourceChunks 按溯源得分对分片排序: 种子实体的分片按实体相似度计分,
+// 关系的分片按关系权重计分 (权重 1-10 归一化后乘以 graphRelationFactor)
+func rankSourceChunks(seeds map[string]float64, entities []types.Entity, relations []types.Relation, limit int) []string {
+	scores := make(map[string]float64)
+	for _, e := range entities {
+		if sim, ok := seeds[e.Name]; ok {
+			for _, id := range e.SourceId {
+				scores[id] += sim
+			}
+		}
+	}
+	for _, rel := range relations {
+		w := rel.Weight
+		if w <= 0 {
+			w = 1
+		}
+		for _, id := range rel.SourceId {
+			scores[id] += min(w, 10) / 10 * graphRelationFactor
+		}
+	}
+	delete(scores, "")
+
+	ids := make([]string, 0, len(scores))
+	for id := range scores {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if scores[ids[i]] != scores[ids[j]] {
+			return scores[ids[i]] > scores[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	if limit > 0 && len(ids) > limit {
+		ids = ids[:limit]
+	}
+	return ids
+}
+
+// buildGraphContext 拼接种子实体及其邻域的描述, 关系按权重保留前 graphContextRelLim 条
+func buildGraphContext(seedNames []string, entities []types.Entity, relations []types.Relation) string {
+	if len(entities) == 0 && len(relations) == 0 {
+		return ""
+	}
+
+	entityMap := make(map[string]types.Entity, len(entities))
+	for _, e := range entities {
+		entityMap[e.Name] = e
+	}
+
+	var sb strings.Builder
+	sb.WriteString("相关实体:\n")
+	seen := make(map[string]bool, len(seedNames))
+	writeEntity := func(name string) {
+		e, ok := entityMap[name]
+		if !ok || seen[name] {
+			return
+		}
+		seen[name] = true
+		sb.WriteString("- ")
+		sb.WriteString(e.Name)
+		if e.Type != "" {
+			sb.WriteString(" (" + e.Type + ")")
+		}
+		if e.Description != "" {
+			sb.WriteString(": " + e.Description)
+		}
+		sb.WriteString("\n")
+	}
+	// 种子实体在前, 其余实体按名称排序
+	for _, name := range seedNames {
+		writeEntity(name)
+	}
+	others := make([]string, 0, len(entityMap))
+	for name := range entityMap {
+		others = append(others, name)
+	}
+	sort.Strings(others)
+	for _, name := range others {
+		writeEntity(name)
+	}
+
+	sorted := append([]types.Relation(nil), relations...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Weight > sorted[j].Weight })
+	if len(sorted) > graphContextRelLim {
+		sorted = sorted[:graphContextRelLim]
+	}
+	if len(sorted) > 0 {
+		sb.WriteString("实体关系:\n")
+		for _, rel := range sorted {
+			sb.WriteString(fmt.Sprintf("- %s -> %s", rel.SrcId, rel.DstId))
+			if rel.Description != "" {
+				sb.WriteString(": " + rel.Description)
+			}
+			sb.WriteString("\n")
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// IsGraphContext 判断文档是否为图谱检索生成的上下文 (而非分片)
+func IsGraphContext(doc *schema.Document) bool {
+	t, _ := doc.MetaData[MetaType].(string)
+	return t == RetrieveModeGraph
+}
+
+// splitGraphContext 分离图谱上下文与分片, 图谱上下文不参与重排序和 TopK 截断
+func splitGraphContext(docs []*schema.Document) (graphDocs, chunkDocs []*schema.Document) {
+	for _, doc := range docs {
+		if IsGraphContext(doc) {
+			graphDocs = append(graphDocs, doc)
+		} else {
+			chunkDocs = append(chunkDocs, doc)
+		}
+	}
+	return graphDocs, chunkDocs
+}
+
+// fusionRetriever 检索节点入口: graph 模式下并行执行分片混合检索和图谱检索并用 RRF 融合, 其余模式直接使用分片检索
+type fusionRetriever struct {
+	chunkRetriever *ChunkRetriever
+	graphRetriever *GraphRetriever
+}
+
+func (r *fusionRetriever) Retrieve(ctx context.Context, query string, opts ...retriever.Option) ([]*schema.Document, error) {
+	req := getRetrieveRequest(ctx)
+	if req == nil {
+		return nil, fmt.Errorf("未传递 RetrieveRequest，请使用 WithRetrieveRequest 设置")
+	}
+	if req.Mode != RetrieveModeGraph {
+		return r.chunkRetriever.Retrieve(ctx, query, opts...)
+	}
+
+	logx.Infof("[FusionRetriever] 开始图谱增强检索, query=%s, kb_id=%s", query, req.KnowledgeBaseId)
+
+	// 查询向量只生成一次, 两路检索共用
+	queryVector, err := r.chunkRetriever.embedQuery(ctx, req, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		wg                   sync.WaitGroup
+		chunkDocs, graphDocs []*schema.Document
+		chunkErr, graphErr   error
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		chunkDocs, chunkErr = r.chunkRetriever.search(ctx, req, query, queryVector)
+	}()
+	go func() {
+		defer wg.Done()
+		graphDocs, graphErr = r.graphRetriever.search(ctx, req, queryVector)
+	}()
+	wg.Wait()
+
+	if chunkErr != nil {
+		return nil, chunkErr
+	}
+	// 图谱检索失败 (如知识库未构建图谱) 时退化为分片检索
+	if graphErr != nil {
+		logx.Errorf("[FusionRetriever] 图谱检索失败, 仅使用分片检索结果: %v", graphErr)
+		return chunkDocs, nil
+	}
+
+	return fuseRRF(chunkDocs, graphDocs), nil
+}
+
+// fuseRRF 按 Reciprocal Rank Fusion 融合多路结果, 相同 ID 的文档累加得分并保留首次出现的文档
+func fuseRRF(lists ...[]*schema.Document) []*schema.Document {
+	scores := make(map[string]float64)
+	docs := make(map[string]*schema.Document)
+	var order []string
+	for _, list := range lists {
+		for rank, doc := range list {
+			if _, ok := docs[doc.ID]; !ok {
+				docs[doc.ID] = doc
+				order = append(order, doc.ID)
+			}
+			scores[doc.ID] += 1.0 / float64(rrfK+rank+1)
+		}
+	}
+
+	result := make([]*schema.Document, 0, len(order))
+	for _, id := range order {
+		result = append(result, docs[id].WithScore(scores[id]))
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Score() > result[j].Score() })
+	return result
+}
@@ -0,0 +1,187 @@
+package retriever
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gozero-rag/internal/graphrag/types"
+	"gozero-rag/internal/model/chunk"
+	"gozero-rag/internal/model/graph"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeChunkModel struct {
+	chunk.ChunkModel
+	chunks map[string]*chunk.Chunk
+}
+
+func (f *fakeChunkModel) GetByIds(_ context.Context, ids []string) ([]*chunk.Chunk, error) {
+	var result []*chunk.Chunk
+	for _, id := range ids {
+		if c, ok := f.chunks[id]; ok {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+type fakeGraphModel struct {
+	graph.GraphModel
+	similar []*graph.SimilarEntity
+}
+
+func (f *fakeGraphModel) SearchSimilarEntities(context.Context, string, []float64, int) ([]*graph.SimilarEntity, error) {
+	return f.similar, nil
+}
+
+type fakeNebula struct {
+	graph.NebulaGraphModel
+	entities  []types.Entity
+	relations []types.Relation
+	hops      int
+}
+
+func (f *fakeNebula) ExpandNeighbors(_ context.Context, _ string, _ []string, hops int, _ int) ([]types.Entity, []types.Relation, error) {
+	f.hops = hops
+	return f.entities, f.relations, nil
+}
+
+func similarEntity(name string, similarity float64) *graph.SimilarEntity {
+	return &graph.SimilarEntity{EsGraphDocument: &graph.EsGraphDocument{EntityName: name}, Similarity: similarity}
+}
+
+func TestGraphHops(t *testing.T) {
+	assert.Equal(t, 1, graphHops(&RetrieveRequest{}))
+	assert.Equal(t, 1, graphHops(&RetrieveRequest{GraphHops: -1}))
+	assert.Equal(t, 2, graphHops(&RetrieveRequest{GraphHops: 2}))
+	assert.Equal(t, graphMaxHops, graphHops(&RetrieveRequest{GraphHops: 10}))
+}
+
+func TestRankSourceChunks(t *testing.T) {
+	seeds := map[string]float64{"A": 0.9, "B": 0.6}
+	entities := []types.Entity{
+		{Name: "A", SourceId: []string{"c1", "c2"}},
+		{Name: "B", SourceId: []string{"c2"}},
+		{Name: "C", SourceId: []string{"c9"}}, // 非种子实体不计分
+	}
+	relations := []types.Relation{
+		{SrcId: "A", DstId: "C", Weight: 10, SourceId: []string{"c3"}},
+		{SrcId: "B", DstId: "C", Weight: 0, SourceId: []string{"c4", ""}}, // 未设置权重按 1 计
+	}
+
+	// c2 = 0.9 + 0.6, c1 = 0.9, c3 = 10/10*0.5, c4 = 1/10*0.5
+	assert.Equal(t, []string{"c2", "c1", "c3", "c4"}, rankSourceChunks(seeds, entities, relations, 0))
+	assert.Equal(t, []string{"c2", "c1"}, rankSourceChunks(seeds, entities, relations, 2))
+	assert.Empty(t, rankSourceChunks(nil, nil, nil, 10))
+}
+
+func TestBuildGraphContext(t *testing.T) {
+	assert.Empty(t, buildGraphContext([]string{"A"}, nil, nil))
+
+	entities := []types.Entity{
+		{Name: "C", Type: "person"},
+		{Name: "B", Description: "实体B"},
+		{Name: "A", Type: "organization", Description: "实体A"},
+	}
+	relations := []types.Relation{
+		{SrcId: "A", DstId: "C", Weight: 2},
+		{SrcId: "A", DstId: "B", Weight: 9, Description: "A 包含 B"},
+	}
+	content := buildGraphContext([]string{"B"}, entities, relations)
+
+	// 种子实体在前, 其余按名称排序; 关系按权重降序
+	assert.Equal(t, strings.Join([]string{
+		"相关实体:",
+		"- B: 实体B",
+		"- A (organization): 实体A",
+		"- C (person)",
+		"实体关系:",
+		"- A -> B: A 包含 B",
+		"- A -> C",
+	}, "\n"), content)
+
+	many := make([]types.Relation, graphContextRelLim+5)
+	for i := range many {
+		many[i] = types.Relation{SrcId: "A", DstId: "B", Weight: float64(i)}
+	}
+	content = buildGraphContext(nil, entities[:1], many)
+	assert.Equal(t, graphContextRelLim, strings.Count(content, "- A -> B"))
+}
+
+func TestFuseRRF(t *testing.T) {
+	chunkDocs := []*schema.Document{{ID: "c1"}, {ID: "c2"}}
+	graphDocs := []*schema.Document{{ID: "g"}, {ID: "c2"}}
+
+	fused := fuseRRF(chunkDocs, graphDocs)
+	require.Len(t, fused, 3)
+	// c2 在两路中都出现, 得分累加后排在最前
+	assert.Equal(t, "c2", fused[0].ID)
+	assert.InDelta(t, 1.0/62+1.0/62, fused[0].Score(), 1e-9)
+	// 同分时保持首次出现的顺序
+	assert.Equal(t, "c1", fused[1].ID)
+	assert.Equal(t, "g", fused[2].ID)
+	assert.InDelta(t, fused[1].Score(), fused[2].Score(), 1e-9)
+}
+
+func TestGraphRetriever_Search(t *testing.T) {
+	chunkModel := &fakeChunkModel{chunks: map[string]*chunk.Chunk{
+		"c1": {Id: "c1", Content: "A 的介绍", Available: 1},
+		"c2": {Id: "c2", Content: "已禁用", Available: 0},
+	}}
+	graphModel := &fakeGraphModel{similar: []*graph.SimilarEntity{
+		similarEntity("A", 0.9),
+		similarEntity("B", 0.3), // 低于相似度阈值
+	}}
+	nebula := &fakeNebula{
+		entities:  []types.Entity{{Name: "A", SourceId: []string{"c1", "c2", "deleted"}}},
+		relations: []types.Relation{{SrcId: "A", DstId: "C", Weight: 5}},
+	}
+	r := NewGraphRetriever(graphModel, nebula, chunkModel)
+
+	docs, err := r.search(context.Background(), &RetrieveRequest{KnowledgeBaseId: "kb1", TopK: 3, GraphHops: 2}, []float64{1})
+	require.NoError(t, err)
+	assert.Equal(t, 2, nebula.hops)
+
+	// 图谱上下文在前, 来源分片跳过已禁用和已删除的分片
+	require.Len(t, docs, 2)
+	assert.True(t, IsGraphContext(docs[0]))
+	assert.Equal(t, graphContextIdPrefix+"kb1", docs[0].ID)
+	assert.Equal(t, "c1", docs[1].ID)
+	assert.Equal(t, RetrieveModeGraph, docs[1].MetaData[MetaSource])
+
+	// 未命中实体时返回空结果
+	graphModel.similar = []*graph.SimilarEntity{similarEntity("B", 0.3)}
+	docs, err = r.search(context.Background(), &RetrieveRequest{KnowledgeBaseId: "kb1", TopK: 3}, []float64{1})
+	require.NoError(t, err)
+	assert.Empty(t, docs)
+}
+
+func TestQaResolver_Resolve(t *testing.T) {
+	chunkModel := &fakeChunkModel{chunks: map[string]*chunk.Chunk{
+		"s1": {Id: "s1", Content: "来源1", Available: 1},
+		"s2": {Id: "s2", Content: "来源2", Available: 0},
+	}}
+	qa := func(id, sourceId, question string) *schema.Document {
+		return &schema.Document{ID: id, MetaData: map[string]any{MetaSourceID: sourceId, MetaQuestion: question}}
+	}
+	docs := []*schema.Document{
+		qa("q1", "s1", "问题1"),
+		qa("q2", "s2", "问题2"),   // 来源已禁用
+		qa("q3", "s1", "问题3"),   // 与 q1 同一来源
+		qa("q4", "gone", "问题4"), // 来源已删除, 保留原样
+		{ID: "c1", MetaData: map[string]any{}},
+	}
+
+	result, err := newQaResolver(chunkModel).Resolve(context.Background(), docs)
+	require.NoError(t, err)
+	require.Len(t, result, 3)
+	assert.Equal(t, "s1", result[0].ID)
+	assert.Equal(t, "来源1", result[0].Content)
+	assert.Equal(t, []string{"问题1", "问题3"}, result[0].MetaData[MetaMatchedQuestion])
+	assert.Equal(t, "q4", result[1].ID)
+	assert.Equal(t, "c1", result[2].ID)
+}
@@ -21,6 +21,13 @@ const (
 	MetaType            = "type"
 	MetaScore           = "score"
 	MetaSource          = "source"
+	MetaParentID        = "parent_id"
+	MetaChildIDs        = "child_ids"
+	MetaSourceID        = "source_id"         // QA 分片的来源分片
+	MetaQuestion        = "question"          // QA 分片的问题
+	MetaMatchedQuestion = "matched_questions" // 命中来源分片的 QA 问题
+	MetaGraphEntities   = "graph_entities"    // 图谱上下文中的实体 ([]types.Entity)
+	MetaGraphRelations  = "graph_relations"   // 图谱上下文中的关系 ([]types.Relation)
 )
 
 // ExtractDocMeta 从 Document 中提取元数据
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"gozero-rag/internal/model/chunk"
+	"gozero-rag/internal/model/graph"
 	"gozero-rag/internal/rag_core/metric"
 	"gozero-rag/internal/rag_core/rerank"
 
@@ -40,20 +41,26 @@ type RetrieveRequest struct {
 
 	VectorWeight  float64
 	KeywordWeight float64
+
+	SheetNames []string // 表格知识库: 仅检索指定工作表
+
+	GraphHops int // graph 模式: 从命中实体向外扩展的跳数, 默认 1, 最大 3
 }
 
-func NewRetrieverService(ctx context.Context, chunkModel chunk.ChunkModel) (*RetrieverService, error) {
+func NewRetrieverService(ctx context.Context, chunkModel chunk.ChunkModel, graphModel graph.GraphModel, nebulaModel graph.NebulaGraphModel) (*RetrieverService, error) {
 	const (
-		NodeRetriever = "Retriever"
-		NodeRerank    = "Rerank"
-		NodeFilter    = "Filter"
+		NodeRetriever    = "Retriever"
+		NodeResolveQa    = "ResolveQa"
+		NodeExpandParent = "ExpandParent"
+		NodeRerank       = "Rerank"
+		NodeFilter       = "Filter"
 	)
 
 	g := compose.NewGraph[string, []*schema.Document]()
 
-	rtr, err := NewChunkRetriever(chunkModel)
-	if err != nil {
-		return nil, err
+	rtr := &fusionRetriever{
+		chunkRetriever: &ChunkRetriever{chunkModel: chunkModel},
+		graphRetriever: NewGraphRetriever(graphModel, nebulaModel, chunkModel),
 	}
 
 	reranker, err := rerank.NewOpenAiReranker()
@@ -62,12 +69,16 @@ func NewRetrieverService(ctx context.Context, chunkModel chunk.ChunkModel) (*Ret
 	}
 
 	_ = g.AddRetrieverNode(NodeRetriever, rtr)
+	_ = g.AddLambdaNode(NodeResolveQa, compose.InvokableLambda(newQaResolver(chunkModel).Resolve))
+	_ = g.AddLambdaNode(NodeExpandParent, compose.InvokableLambda(newParentExpander(chunkModel).Expand))
 	_ = g.AddLambdaNode(NodeRerank, compose.InvokableLambda(func(ctx context.Context, docs []*schema.Document) ([]*schema.Document, error) {
 		conf := getRetrieveRequest(ctx)
 		if conf == nil {
 			return nil, fmt.Errorf("未传递request")
 		}
 
+		graphDocs, docs := splitGraphContext(docs)
+
 		start := time.Now()
 		result, err := reranker.Rerank(ctx, &rerank.RerankRequest{
 			BaseUrl:   conf.RerankModelConfig.BaseUrl,
@@ -79,13 +90,18 @@ func NewRetrieverService(ctx context.Context, chunkModel chunk.ChunkModel) (*Ret
 		})
 		// 记录 Rerank 延迟指标
 		metric.RerankDuration.WithLabelValues(conf.RerankModelConfig.ModelName).Observe(time.Since(start).Seconds())
-		return result, err
+		if err != nil {
+			return nil, err
+		}
+		return append(graphDocs, result...), nil
 
 	}))
 	_ = g.AddLambdaNode(NodeFilter, compose.InvokableLambda(filterDocs))
 
 	_ = g.AddEdge(compose.START, NodeRetriever)
-	_ = g.AddEdge(NodeRetriever, NodeRerank)
+	_ = g.AddEdge(NodeRetriever, NodeResolveQa)
+	_ = g.AddEdge(NodeResolveQa, NodeExpandParent)
+	_ = g.AddEdge(NodeExpandParent, NodeRerank)
 	_ = g.AddEdge(NodeRerank, NodeFilter)
 	_ = g.AddEdge(NodeFilter, compose.END)
 
@@ -193,6 +209,9 @@ func filterDocs(ctx context.Context, docs []*schema.Document) ([]*schema.Documen
 		return nil, fmt.Errorf("retrieve request not found in context")
 	}
 
+	// 图谱上下文不按分数过滤, 始终排在最前
+	graphDocs, docs := splitGraphContext(docs)
+
 	// 1. Filter by ScoreThreshold
 	var filteredDocs []*schema.Document
 	for _, doc := range docs {
@@ -211,5 +230,5 @@ func filterDocs(ctx context.Context, docs []*schema.Document) ([]*schema.Documen
 		filteredDocs = filteredDocs[:conf.TopK]
 	}
 
-	return filteredDocs, nil
+	return append(graphDocs, filteredDocs...), nil
 }
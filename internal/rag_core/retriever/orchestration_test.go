@@ -2,41 +2,46 @@ package retriever
 
 import (
 	"context"
-	"gozero-rag/internal/config"
-	vectorstore "gozero-rag/internal/vector_store"
 	"os"
 	"testing"
+
+	"gozero-rag/internal/model/chunk"
+	"gozero-rag/internal/model/graph"
 )
 
 func TestNewRetrieverService(t *testing.T) {
+	apiKey := os.Getenv("EMB_API_KEY")
+	modelName := os.Getenv("EMB_MODEL_NAME")
+	baseUrl := os.Getenv("EMB_BASE_URL")
+	if apiKey == "" || modelName == "" || baseUrl == "" {
+		t.Skip("EMB_API_KEY / EMB_MODEL_NAME / EMB_BASE_URL not set")
+	}
+	esAddr := os.Getenv("ES_ADDR")
+	kbId := os.Getenv("KB_ID")
+	if esAddr == "" || kbId == "" {
+		t.Skip("ES_ADDR / KB_ID not set")
+	}
+
 	ctx := context.Background()
-	client, err := vectorstore.NewMilvusClient(config.VectorStoreConf{
-		Type:     "milvus",
-		Endpoint: "localhost:19530",
-		Username: "",
-		Password: "",
-		Database: "",
-	})
+	chunkModel, err := chunk.NewEsChunkModel([]string{esAddr}, os.Getenv("ES_USERNAME"), os.Getenv("ES_PASSWORD"))
 	if err != nil {
 		t.Fatal(err)
 	}
-	svc, err := NewRetrieverService(ctx, client)
+	graphModel, err := graph.NewEsGraphModel([]string{esAddr}, os.Getenv("ES_USERNAME"), os.Getenv("ES_PASSWORD"))
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	apiKey := os.Getenv("EMB_API_KEY")
-	modelName := os.Getenv("EMB_MODEL_NAME")
-	baseUrl := os.Getenv("EMB_BASE_URL")
-
-	if apiKey == "" || modelName == "" || baseUrl == "" {
-		t.Skip("EMB_API_KEY / EMB_MODEL_NAME / EMB_BASE_URL not set")
+	// 非 graph 模式不访问 Nebula
+	svc, err := NewRetrieverService(ctx, chunkModel, graphModel, nil)
+	if err != nil {
+		t.Fatal(err)
 	}
 
 	query, err := svc.Query(ctx, &RetrieveRequest{
 		Query:           "电商场景如何防止超卖？",
-		KnowledgeBaseId: 31,
+		KnowledgeBaseId: kbId,
 		TopK:            3,
+		Mode:            RetrieveModeHybrid,
 		EmbeddingModelConfig: ModelConfig{
 			ModelName: modelName,
 			BaseUrl:   baseUrl,
@@ -51,5 +56,4 @@ func TestNewRetrieverService(t *testing.T) {
 	for _, doc := range query {
 		t.Logf("%#v\n\n", doc)
 	}
-
 }
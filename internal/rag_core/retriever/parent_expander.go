@@ -0,0 +1,80 @@
+package retriever
+
+import (
+	"context"
+	"fmt"
+
+	"gozero-rag/internal/model/chunk"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// parentExpander 父子分片: 子块用于检索, 命中后替换为所属父块, 为 LLM 提供完整上下文
+// 未开启父子分片的知识库中 chunk 没有 parent_id, 原样返回
+type parentExpander struct {
+	chunkModel chunk.ChunkModel
+}
+
+func newParentExpander(chunkModel chunk.ChunkModel) *parentExpander {
+	return &parentExpander{chunkModel: chunkModel}
+}
+
+// Expand 按首次命中顺序将子块替换为父块, 同一父块的多个子块只保留一个
+func (e *parentExpander) Expand(ctx context.Context, docs []*schema.Document) ([]*schema.Document, error) {
+	var parentIds []string
+	childIds := make(map[string][]string)
+	for _, doc := range docs {
+		parentId, _ := doc.MetaData[MetaParentID].(string)
+		if parentId == "" {
+			continue
+		}
+		if _, ok := childIds[parentId]; !ok {
+			parentIds = append(parentIds, parentId)
+		}
+		childIds[parentId] = append(childIds[parentId], doc.ID)
+	}
+	if len(parentIds) == 0 {
+		return docs, nil
+	}
+
+	parents, err := e.chunkModel.GetByIds(ctx, parentIds)
+	if err != nil {
+		return nil, fmt.Errorf("查询父块失败: %w", err)
+	}
+	parentMap := make(map[string]*chunk.Chunk, len(parents))
+	for _, p := range parents {
+		parentMap[p.Id] = p
+	}
+
+	result := make([]*schema.Document, 0, len(docs))
+	seen := make(map[string]bool, len(parentIds))
+	for _, doc := range docs {
+		parentId, _ := doc.MetaData[MetaParentID].(string)
+		parent, ok := parentMap[parentId]
+		if !ok {
+			// 非父子分片或父块已被删除, 保留子块
+			result = append(result, doc)
+			continue
+		}
+		if seen[parentId] {
+			continue
+		}
+		seen[parentId] = true
+
+		result = append(result, &schema.Document{
+			ID:      parent.Id,
+			Content: parent.Content,
+			MetaData: map[string]any{
+				MetaChunkID:         parent.Id,
+				MetaDocID:           parent.DocId,
+				"sheet_name":        parent.SheetName,
+				MetaKnowledgeBaseID: parent.KbIds,
+				MetaChildIDs:        childIds[parentId],
+			},
+		})
+	}
+
+	logx.Infof("[ParentExpander] %d 个子块扩展为 %d 个父块, 共 %d 条结果", len(docs), len(seen), len(result))
+	return result, nil
+}
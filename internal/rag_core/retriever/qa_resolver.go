@@ -0,0 +1,93 @@
+package retriever
+
+import (
+	"context"
+	"fmt"
+
+	"gozero-rag/internal/model/chunk"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// qaResolver QA 分片: 问题向量用于检索, 命中后替换为来源分片, 避免将合成的问答文本交给 LLM
+// 替换后的来源分片保留 parent_id, 后续由 parentExpander 继续扩展
+type qaResolver struct {
+	chunkModel chunk.ChunkModel
+}
+
+func newQaResolver(chunkModel chunk.ChunkModel) *qaResolver {
+	return &qaResolver{chunkModel: chunkModel}
+}
+
+// Resolve 按首次命中顺序将 QA 分片替换为来源分片, 来源分片已直接命中或被多个 QA 命中时只保留一个
+// 来源分片不可用 (available_int=0) 时丢弃对应的 QA 分片
+func (r *qaResolver) Resolve(ctx context.Context, docs []*schema.Document) ([]*schema.Document, error) {
+	var sourceIds []string
+	questions := make(map[string][]string)
+	for _, doc := range docs {
+		sourceId, _ := doc.MetaData[MetaSourceID].(string)
+		if sourceId == "" {
+			continue
+		}
+		if _, ok := questions[sourceId]; !ok {
+			sourceIds = append(sourceIds, sourceId)
+		}
+		question, _ := doc.MetaData[MetaQuestion].(string)
+		questions[sourceId] = append(questions[sourceId], question)
+	}
+	if len(sourceIds) == 0 {
+		return docs, nil
+	}
+
+	sources, err := r.chunkModel.GetByIds(ctx, sourceIds)
+	if err != nil {
+		return nil, fmt.Errorf("查询 QA 来源分片失败: %w", err)
+	}
+	// 来源分片被禁用时, 其 QA 分片一并丢弃, 不能绕过禁用把来源内容交给 LLM
+	sourceMap := make(map[string]*chunk.Chunk, len(sources))
+	disabled := make(map[string]bool)
+	for _, s := range sources {
+		if s.Available == 0 {
+			disabled[s.Id] = true
+			continue
+		}
+		sourceMap[s.Id] = s
+	}
+
+	result := make([]*schema.Document, 0, len(docs))
+	seen := make(map[string]bool, len(docs))
+	resolved := 0
+	for _, doc := range docs {
+		sourceId, _ := doc.MetaData[MetaSourceID].(string)
+		if disabled[sourceId] {
+			continue
+		}
+		if source, ok := sourceMap[sourceId]; ok {
+			resolved++
+			doc = &schema.Document{
+				ID:      source.Id,
+				Content: source.Content,
+				MetaData: map[string]any{
+					MetaChunkID:         source.Id,
+					MetaDocID:           source.DocId,
+					"sheet_name":        source.SheetName,
+					MetaParentID:        source.ParentId,
+					MetaKnowledgeBaseID: source.KbIds,
+				},
+			}
+		}
+		// 来源分片被删除的 QA 分片保留原样
+		if seen[doc.ID] {
+			continue
+		}
+		seen[doc.ID] = true
+		if qs, ok := questions[doc.ID]; ok && doc.MetaData != nil {
+			doc.MetaData[MetaMatchedQuestion] = qs
+		}
+		result = append(result, doc)
+	}
+
+	logx.Infof("[QaResolver] %d 个 QA 分片替换为 %d 个来源分片, 共 %d 条结果", resolved, len(sourceMap), len(result))
+	return result, nil
+}
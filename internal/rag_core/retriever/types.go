@@ -6,6 +6,7 @@ const (
 	RetrieveModeVector   RetrieveMode = "vector"
 	RetrieveModeFulltext RetrieveMode = "fulltext"
 	RetrieveModeHybrid   RetrieveMode = "hybrid"
+	RetrieveModeGraph    RetrieveMode = "graph" // 分片混合检索 + 知识图谱检索
 
 	HybridRankTypeWeighted = "weighted"
 	HybridRankTypeRerank   = "rerank"
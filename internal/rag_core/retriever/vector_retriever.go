@@ -38,10 +38,10 @@ func (r *VectorRetriever) Retrieve(ctx context.Context, query string, opts ...re
 		return nil, fmt.Errorf("未传递 RetrieveRequest，请使用 WithRetrieveRequest 设置")
 	}
 
-	logx.Infof("[VectorRetriever] 开始检索, query=%s, kb_id=%d, mode=%s", query, req.KnowledgeBaseId, req.Mode)
+	logx.Infof("[VectorRetriever] 开始检索, query=%s, kb_id=%s, mode=%s", query, req.KnowledgeBaseId, req.Mode)
 
 	// 2. 构建 collection 名称
-	collectionName := fmt.Sprintf("kb_%d", req.KnowledgeBaseId)
+	collectionName := fmt.Sprintf("kb_%s", req.KnowledgeBaseId)
 	searchTopK := req.TopK * 3 // 搜索时多取一些，融合后再截取
 
 	var searchResults []*vectorstore.SearchResult
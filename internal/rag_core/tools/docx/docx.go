@@ -1,6 +1,10 @@
 package docx
 
-import "github.com/cloudwego/eino/schema"
+import (
+	"gozero-rag/internal/rag_core/constant"
+
+	"github.com/cloudwego/eino/schema"
+)
 
 func IsMarkdown(doc *schema.Document) bool {
 	ext, ok := doc.MetaData["_extension"]
@@ -17,3 +21,9 @@ func IsPdf(doc *schema.Document) bool {
 	}
 	return ext == ".pdf" || ext == ".PDF"
 }
+
+// IsTableRow 是否为表格解析器输出的行数据
+func IsTableRow(doc *schema.Document) bool {
+	_, ok := doc.MetaData[constant.MetaSheetName]
+	return ok
+}
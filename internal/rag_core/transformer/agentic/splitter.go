@@ -3,6 +3,7 @@ package agentic
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
@@ -22,11 +23,33 @@ const (
 	maxSentencesPerBatch = 20
 	// 默认最大 chunk 长度
 	defaultMaxChunkLength = 500
+	// 默认单个文档的 LLM 调用上限
+	defaultMaxLlmCalls = 50
 )
 
+// ErrLlmBudgetExceeded 文档所需的 LLM 调用次数超过预算
+var ErrLlmBudgetExceeded = errors.New("agentic 分片所需的 LLM 调用次数超过预算")
+
+// localBudget 未提供文档级预算时使用, 一次 Transform 内的各章节共享计数
+type localBudget struct {
+	used int
+}
+
+func (b *localBudget) Reserve(_ context.Context, n, limit int) (bool, error) {
+	if b.used+n > limit {
+		return false, nil
+	}
+	b.used += n
+	return true, nil
+}
+
 // AgenticSplitter 使用 LLM 进行 Bottom-Up 智能分块
 type AgenticSplitter struct {
 	llm model.BaseChatModel
+	// 单个知识文档的 LLM 调用上限, <=0 表示不限制; 文档的各章节及子任务共享, 计数见 types.LlmBudget
+	maxLlmCalls int
+	// 预算不足或 LLM 调用失败时使用的回退分片器, 为空时保留原文档
+	fallback document.Transformer
 }
 
 // NewAgenticSplitter 创建 AgenticSplitter 实例
@@ -53,7 +76,24 @@ func NewAgenticSplitterFromConfig(ctx context.Context, cfg types.ProcessConfig)
 		return nil, fmt.Errorf("failed to create ChatModel: %w", err)
 	}
 
-	return NewAgenticSplitter(llm), nil
+	maxLlmCalls := cfg.AgenticMaxLlmCalls
+	if maxLlmCalls <= 0 {
+		maxLlmCalls = defaultMaxLlmCalls
+	}
+
+	return NewAgenticSplitter(llm).WithMaxLlmCalls(maxLlmCalls), nil
+}
+
+// WithMaxLlmCalls 设置单个知识文档的 LLM 调用上限
+func (s *AgenticSplitter) WithMaxLlmCalls(maxLlmCalls int) *AgenticSplitter {
+	s.maxLlmCalls = maxLlmCalls
+	return s
+}
+
+// WithFallback 设置回退分片器, 设置后任一批次 LLM 调用失败即整篇文档回退, 而不是把该批次全部视为边界
+func (s *AgenticSplitter) WithFallback(fallback document.Transformer) *AgenticSplitter {
+	s.fallback = fallback
+	return s
 }
 
 // Transform 实现 document.Transformer 接口
@@ -76,15 +116,29 @@ func (s *AgenticSplitter) Transform(ctx context.Context, src []*schema.Document,
 
 	logx.Infof("[AgenticSplitter] Using config: MaxChunkLength=%d, ChunkOverlap=%d", cfg.MaxChunkLength, cfg.ChunkOverlap)
 
+	// src 通常是同一知识文档的多个章节, 调用上限按文档计算而不是按章节
+	budget := cfg.AgenticLlmBudget
+	if budget == nil {
+		budget = &localBudget{}
+	}
+
 	var result []*schema.Document
 
 	for _, doc := range src {
-		chunks, err := s.splitDocument(ctx, doc, cfg)
+		chunks, err := s.splitDocument(ctx, doc, cfg, budget)
 		if err != nil {
 			logx.Errorf("[AgenticSplitter] Failed to split document %s: %v", doc.ID, err)
-			// 如果分割失败，返回原文档
-			result = append(result, doc)
-			continue
+			if s.fallback == nil {
+				// 没有回退分片器，返回原文档
+				result = append(result, doc)
+				continue
+			}
+
+			logx.Infof("[AgenticSplitter] Document %s falls back to %T", doc.ID, s.fallback)
+			chunks, err = s.fallback.Transform(ctx, []*schema.Document{doc})
+			if err != nil {
+				return nil, err
+			}
 		}
 		result = append(result, chunks...)
 	}
@@ -112,6 +166,15 @@ func atomize(content string) []string {
 	return sentences
 }
 
+// estimateLlmCalls 按批次窗口估算边界检测需要的 LLM 调用次数, 与 detectBoundariesBatchWindowed 的分批方式一致
+func estimateLlmCalls(sentenceNum int) int {
+	if sentenceNum <= 1 {
+		return 0
+	}
+	step := maxSentencesPerBatch - 1
+	return (sentenceNum - 1 + step - 1) / step
+}
+
 // detectBoundariesBatchWindowed 分批次判断相邻句子的话题边界
 // 每次只处理 maxSentencesPerBatch 个句子，避免 LLM 上下文溢出
 func (s *AgenticSplitter) detectBoundariesBatchWindowed(ctx context.Context, sentences []string) ([]BoundaryInfo, error) {
@@ -139,6 +202,9 @@ func (s *AgenticSplitter) detectBoundariesBatchWindowed(ctx context.Context, sen
 		batchBoundaries, err := s.detectBoundariesSingleBatch(ctx, batchSentences)
 		if err != nil {
 			logx.Errorf("[AgenticSplitter] Failed to detect batch boundaries at start=%d: %v", start, err)
+			if s.fallback != nil {
+				return nil, err
+			}
 			// 失败时默认全部作为边界（避免合并错误）
 			for i := start; i < start+len(batchSentences)-1 && i < len(allBoundaries); i++ {
 				allBoundaries[i] = BoundaryInfo{IsBoundary: true}
@@ -321,7 +387,7 @@ func aggregateWithMaxLength(sentences []string, boundaries []BoundaryInfo, maxCh
 }
 
 // splitDocument 使用 Bottom-Up 方法分割单个文档
-func (s *AgenticSplitter) splitDocument(ctx context.Context, doc *schema.Document, cfg types.ProcessConfig) ([]*schema.Document, error) {
+func (s *AgenticSplitter) splitDocument(ctx context.Context, doc *schema.Document, cfg types.ProcessConfig, budget types.LlmBudget) ([]*schema.Document, error) {
 	content := doc.Content
 	if len(content) == 0 {
 		return []*schema.Document{doc}, nil
@@ -335,12 +401,21 @@ func (s *AgenticSplitter) splitDocument(ctx context.Context, doc *schema.Documen
 		return []*schema.Document{doc}, nil
 	}
 
+	// 调用 LLM 之前先预占预算, 剩余预算不足的章节不产生任何调用
+	if calls := estimateLlmCalls(len(sentences)); s.maxLlmCalls > 0 {
+		ok, err := budget.Reserve(ctx, calls, s.maxLlmCalls)
+		if err != nil {
+			return nil, fmt.Errorf("预占 LLM 调用预算失败: %w", err)
+		}
+		if !ok {
+			return nil, fmt.Errorf("%w: 需要 %d 次, 文档上限 %d 次", ErrLlmBudgetExceeded, calls, s.maxLlmCalls)
+		}
+	}
+
 	// Step 2: 分批次检测话题边界 (避免上下文溢出)
 	boundaries, err := s.detectBoundariesBatchWindowed(ctx, sentences)
 	if err != nil {
-		logx.Errorf("[AgenticSplitter] Failed to detect boundaries: %v", err)
-		// 失败时返回原文档
-		return []*schema.Document{doc}, nil
+		return nil, err
 	}
 
 	// Step 3: 聚合同话题句子，同时尊重 MaxChunkLength 和 ChunkOverlap
@@ -2,14 +2,18 @@ package agentic
 
 import (
 	"context"
+	"errors"
 	"os"
 	"strings"
 	"testing"
 	"unicode/utf8"
 
+	"gozero-rag/internal/rag_core/constant"
 	"gozero-rag/internal/rag_core/types"
 
 	"github.com/cloudwego/eino-ext/components/model/openai"
+	"github.com/cloudwego/eino/components/document"
+	"github.com/cloudwego/eino/components/model"
 	"github.com/cloudwego/eino/schema"
 	"github.com/joho/godotenv"
 	"github.com/stretchr/testify/assert"
@@ -219,3 +223,97 @@ func truncate(s string, maxLen int) string {
 	}
 	return string(runes[:maxLen]) + "..."
 }
+
+// stubChatModel 记录调用次数, 按需返回错误
+type stubChatModel struct {
+	calls int
+	err   error
+}
+
+func (m *stubChatModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	m.calls++
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &schema.Message{Role: schema.Assistant, Content: "[]"}, nil
+}
+
+func (m *stubChatModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	return nil, errors.New("not implemented")
+}
+
+// stubFallback 原样返回文档并标记
+type stubFallback struct{}
+
+func (stubFallback) Transform(ctx context.Context, src []*schema.Document, opts ...document.TransformerOption) ([]*schema.Document, error) {
+	var result []*schema.Document
+	for _, doc := range src {
+		result = append(result, &schema.Document{ID: doc.ID + "_fallback", Content: doc.Content})
+	}
+	return result, nil
+}
+
+func TestAgenticSplitter_Fallback(t *testing.T) {
+	ctx := context.Background()
+	// 40 个句子需要 3 次 LLM 调用
+	content := strings.Repeat("这是一个句子。", 40)
+
+	t.Run("超出预算不调用 LLM", func(t *testing.T) {
+		llm := &stubChatModel{}
+		splitter := NewAgenticSplitter(llm).WithMaxLlmCalls(2).WithFallback(stubFallback{})
+
+		chunks, err := splitter.Transform(ctx, []*schema.Document{{ID: "doc", Content: content}})
+		require.NoError(t, err)
+		assert.Equal(t, 0, llm.calls)
+		require.Len(t, chunks, 1)
+		assert.Equal(t, "doc_fallback", chunks[0].ID)
+	})
+
+	t.Run("LLM 调用失败回退", func(t *testing.T) {
+		llm := &stubChatModel{err: errors.New("rate limited")}
+		splitter := NewAgenticSplitter(llm).WithMaxLlmCalls(3).WithFallback(stubFallback{})
+
+		chunks, err := splitter.Transform(ctx, []*schema.Document{{ID: "doc", Content: content}})
+		require.NoError(t, err)
+		assert.Equal(t, 1, llm.calls)
+		require.Len(t, chunks, 1)
+		assert.Equal(t, "doc_fallback", chunks[0].ID)
+	})
+}
+
+// 预算按知识文档计算: 同一次切片的多个章节及共享计数的多次切片 (大文档子任务) 合计不超过上限
+func TestAgenticSplitter_BudgetPerDocument(t *testing.T) {
+	// 每个章节 40 个句子, 需要 3 次 LLM 调用
+	section := strings.Repeat("这是一个句子。", 40)
+
+	t.Run("章节共享预算", func(t *testing.T) {
+		llm := &stubChatModel{}
+		splitter := NewAgenticSplitter(llm).WithMaxLlmCalls(5).WithFallback(stubFallback{})
+
+		chunks, err := splitter.Transform(context.Background(), []*schema.Document{{ID: "s1", Content: section}, {ID: "s2", Content: section}})
+		require.NoError(t, err)
+		assert.Equal(t, 3, llm.calls)
+		assert.Equal(t, "s2_fallback", chunks[len(chunks)-1].ID)
+	})
+
+	t.Run("子任务共享预算", func(t *testing.T) {
+		llm := &stubChatModel{}
+		splitter := NewAgenticSplitter(llm).WithMaxLlmCalls(5).WithFallback(stubFallback{})
+		ctx := context.WithValue(context.Background(), constant.CtxKeyIndexConfig, types.ProcessConfig{AgenticLlmBudget: &localBudget{}})
+
+		_, err := splitter.Transform(ctx, []*schema.Document{{ID: "s1", Content: section}})
+		require.NoError(t, err)
+		chunks, err := splitter.Transform(ctx, []*schema.Document{{ID: "s2", Content: section}})
+		require.NoError(t, err)
+		assert.Equal(t, 3, llm.calls)
+		require.Len(t, chunks, 1)
+		assert.Equal(t, "s2_fallback", chunks[0].ID)
+	})
+}
+
+func TestEstimateLlmCalls(t *testing.T) {
+	assert.Equal(t, 0, estimateLlmCalls(1))
+	assert.Equal(t, 1, estimateLlmCalls(20))
+	assert.Equal(t, 2, estimateLlmCalls(21))
+	assert.Equal(t, 3, estimateLlmCalls(40))
+}
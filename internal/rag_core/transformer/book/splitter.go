@@ -0,0 +1,150 @@
+package book
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gozero-rag/internal/rag_core/constant"
+
+	"github.com/cloudwego/eino-ext/components/document/transformer/splitter/recursive"
+	"github.com/cloudwego/eino/components/document"
+	"github.com/cloudwego/eino/schema"
+	"github.com/google/uuid"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// 默认章节标题: "第一章 xxx" / "第十回" / "第二卷" / "Chapter 3" / markdown 一二级标题
+const defaultChapterPattern = `^(第[一二三四五六七八九十百千零〇两0-9]+[章回卷部篇]|(?i:chapter)\s+[0-9IVXLC]+|#{1,2}\s)`
+
+// 章节标题的最大长度, 超过的行视为正文
+const maxChapterTitleLen = 50
+
+type Config struct {
+	ChapterPattern string // 自定义章节标题正则, 为空时使用默认规则
+	MaxChunkSize   int    // chunk 最大字符数, 超长章节递归切分
+	OverlapSize    int
+	Separators     []string
+}
+
+// BookSplitter 书籍分片器
+// 按章节切分, 章节超长时递归切分, 所有子块都在 Metadata 中记录所属章节
+type BookSplitter struct {
+	config            *Config
+	chapterRe         *regexp.Regexp
+	recursiveSplitter document.Transformer
+}
+
+func NewBookSplitter(ctx context.Context, config *Config) (*BookSplitter, error) {
+	pattern := config.ChapterPattern
+	if pattern == "" {
+		pattern = defaultChapterPattern
+	}
+	chapterRe, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("章节标题正则无效: %w", err)
+	}
+
+	recSplitter, err := recursive.NewSplitter(ctx, &recursive.Config{
+		ChunkSize:   config.MaxChunkSize,
+		OverlapSize: config.OverlapSize,
+		Separators:  config.Separators,
+		KeepType:    recursive.KeepTypeNone,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &BookSplitter{
+		config:            config,
+		chapterRe:         chapterRe,
+		recursiveSplitter: recSplitter,
+	}, nil
+}
+
+// chapter 一个章节, 第一个章节标题之前的内容 title 为 "前言"
+type chapter struct {
+	title string
+	lines []string
+}
+
+func (s *BookSplitter) Transform(ctx context.Context, src []*schema.Document, opts ...document.TransformerOption) ([]*schema.Document, error) {
+	var result []*schema.Document
+
+	for _, doc := range src {
+		chapters := s.splitChapters(doc.Content)
+		logx.Infof("[BookSplitter] 文档 [%s] 识别到 %d 个章节", doc.ID, len(chapters))
+
+		for i, ch := range chapters {
+			content := strings.TrimSpace(strings.Join(ch.lines, "\n"))
+			if content == "" {
+				continue
+			}
+
+			pieces := []string{content}
+			if s.config.MaxChunkSize > 0 && len([]rune(content)) > s.config.MaxChunkSize {
+				subDocs, err := s.recursiveSplitter.Transform(ctx, []*schema.Document{{Content: content}})
+				if err != nil {
+					return nil, err
+				}
+				pieces = pieces[:0]
+				for _, sub := range subDocs {
+					pieces = append(pieces, sub.Content)
+				}
+			}
+
+			for _, piece := range pieces {
+				chunk, err := s.createChunk(doc, piece, ch.title, i)
+				if err != nil {
+					return nil, err
+				}
+				result = append(result, chunk)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// splitChapters 按章节标题切分, 标题行保留在章节正文中
+func (s *BookSplitter) splitChapters(content string) []*chapter {
+	current := &chapter{title: "前言"}
+	chapters := []*chapter{current}
+
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed != "" && len([]rune(trimmed)) <= maxChapterTitleLen && s.chapterRe.MatchString(trimmed) {
+			current = &chapter{title: strings.TrimSpace(strings.TrimLeft(trimmed, "#"))}
+			chapters = append(chapters, current)
+		}
+		current.lines = append(current.lines, line)
+	}
+
+	return chapters
+}
+
+func (s *BookSplitter) createChunk(originDoc *schema.Document, content string, title string, chapterIndex int) (*schema.Document, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, err
+	}
+
+	chunk := &schema.Document{
+		ID:       id.String(),
+		Content:  content,
+		MetaData: make(map[string]any),
+	}
+
+	// 复制原始 Metadata
+	for k, v := range originDoc.MetaData {
+		chunk.MetaData[k] = v
+	}
+
+	chunk.MetaData[constant.MetaHeaderContext] = title
+	chunk.MetaData[constant.MetaHeaderLevel] = 1
+	chunk.MetaData[constant.MetaHeaderType] = "book"
+	chunk.MetaData[constant.MetaChapterIndex] = chapterIndex
+
+	return chunk, nil
+}
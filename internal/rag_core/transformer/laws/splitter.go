@@ -0,0 +1,251 @@
+package laws
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"gozero-rag/internal/rag_core/constant"
+
+	"github.com/cloudwego/eino-ext/components/document/transformer/splitter/recursive"
+	"github.com/cloudwego/eino/components/document"
+	"github.com/cloudwego/eino/schema"
+	"github.com/google/uuid"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const chineseNum = `[一二三四五六七八九十百千零〇两0-9]+`
+
+var (
+	partRe    = regexp.MustCompile(`^第` + chineseNum + `编`)
+	chapterRe = regexp.MustCompile(`^第` + chineseNum + `章`)
+	sectionRe = regexp.MustCompile(`^第` + chineseNum + `节`)
+	articleRe = regexp.MustCompile(`^(第` + chineseNum + `条)`)
+)
+
+type Config struct {
+	ArticlesPerChunk int // 每个 chunk 包含的条数, <=0 时每条单独成块
+	MaxChunkSize     int // chunk 最大字符数, 超长条款递归切分, <=0 表示不限制
+	OverlapSize      int
+	Separators       []string
+}
+
+// LawsSplitter 法律法规分片器
+// 以 "第X条" 为最小单位切分, 并记录条款所属的 编/章/节, 保证每个 chunk 都能定位到具体条款
+type LawsSplitter struct {
+	config            *Config
+	recursiveSplitter document.Transformer
+}
+
+func NewLawsSplitter(ctx context.Context, config *Config) (*LawsSplitter, error) {
+	recSplitter, err := recursive.NewSplitter(ctx, &recursive.Config{
+		ChunkSize:   config.MaxChunkSize,
+		OverlapSize: config.OverlapSize,
+		Separators:  config.Separators,
+		KeepType:    recursive.KeepTypeNone,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &LawsSplitter{
+		config:            config,
+		recursiveSplitter: recSplitter,
+	}, nil
+}
+
+// article 一个条款 (或第一条之前的序言部分)
+type article struct {
+	id      string // 条款编号, 如 "第十条", 序言为空
+	context string // 所属 编/章/节, 如 "第一章 总则 / 第一节 一般规定"
+	lines   []string
+}
+
+func (a *article) content() string {
+	return strings.TrimSpace(strings.Join(a.lines, "\n"))
+}
+
+func (s *LawsSplitter) Transform(ctx context.Context, src []*schema.Document, opts ...document.TransformerOption) ([]*schema.Document, error) {
+	var result []*schema.Document
+
+	for _, doc := range src {
+		articles := s.parseArticles(doc.Content)
+		logx.Infof("[LawsSplitter] 文档 [%s] 识别到 %d 个条款", doc.ID, len(articles))
+
+		chunks, err := s.buildChunks(ctx, doc, articles)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, chunks...)
+	}
+
+	return result, nil
+}
+
+// parseArticles 逐行扫描, 遇到 编/章/节 更新上下文, 遇到 "第X条" 开始新条款
+func (s *LawsSplitter) parseArticles(content string) []*article {
+	var (
+		articles            []*article
+		part, chapter, sect string
+		current             = &article{}
+	)
+
+	flush := func() {
+		if current.content() != "" {
+			articles = append(articles, current)
+		}
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if matches := articleRe.FindStringSubmatch(trimmed); len(matches) > 0 {
+			flush()
+			current = &article{
+				id:      matches[1],
+				context: joinContext(part, chapter, sect),
+				lines:   []string{trimmed},
+			}
+			continue
+		}
+
+		switch {
+		case partRe.MatchString(trimmed):
+			part, chapter, sect = trimmed, "", ""
+		case chapterRe.MatchString(trimmed):
+			chapter, sect = trimmed, ""
+		case sectionRe.MatchString(trimmed):
+			sect = trimmed
+		default:
+			current.lines = append(current.lines, line)
+			continue
+		}
+
+		// 编/章/节 标题结束当前条款, 后续无条款编号的正文归入新的上下文
+		flush()
+		current = &article{context: joinContext(part, chapter, sect)}
+	}
+	flush()
+
+	return articles
+}
+
+// buildChunks 同一上下文内的连续条款按 ArticlesPerChunk 和 MaxChunkSize 聚合
+func (s *LawsSplitter) buildChunks(ctx context.Context, doc *schema.Document, articles []*article) ([]*schema.Document, error) {
+	perChunk := s.config.ArticlesPerChunk
+	if perChunk <= 0 {
+		perChunk = 1
+	}
+
+	var (
+		chunks  []*schema.Document
+		pending []*article
+		size    int
+	)
+
+	flush := func() error {
+		if len(pending) == 0 {
+			return nil
+		}
+		built, err := s.createChunks(ctx, doc, pending)
+		if err != nil {
+			return err
+		}
+		chunks = append(chunks, built...)
+		pending, size = nil, 0
+		return nil
+	}
+
+	for _, a := range articles {
+		length := len([]rune(a.content()))
+		sameContext := len(pending) == 0 || pending[0].context == a.context
+		fits := s.config.MaxChunkSize <= 0 || size+length <= s.config.MaxChunkSize
+		// 没有条款编号的正文 (序言、章节说明) 单独成块
+		if len(pending) > 0 && (len(pending) >= perChunk || !sameContext || !fits || a.id == "" || pending[0].id == "") {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+		pending = append(pending, a)
+		size += length + 1
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return chunks, nil
+}
+
+// createChunks 生成 chunk, 单个条款超长时递归切分, 每个子块都保留条款编号
+func (s *LawsSplitter) createChunks(ctx context.Context, doc *schema.Document, articles []*article) ([]*schema.Document, error) {
+	contents := make([]string, 0, len(articles))
+	ids := make([]string, 0, len(articles))
+	for _, a := range articles {
+		contents = append(contents, a.content())
+		if a.id != "" {
+			ids = append(ids, a.id)
+		}
+	}
+	content := strings.Join(contents, "\n")
+
+	pieces := []string{content}
+	if s.config.MaxChunkSize > 0 && len([]rune(content)) > s.config.MaxChunkSize {
+		subDocs, err := s.recursiveSplitter.Transform(ctx, []*schema.Document{{Content: content}})
+		if err != nil {
+			return nil, err
+		}
+		pieces = pieces[:0]
+		for _, sub := range subDocs {
+			pieces = append(pieces, sub.Content)
+		}
+	}
+
+	chunks := make([]*schema.Document, 0, len(pieces))
+	for _, piece := range pieces {
+		chunk, err := s.createChunk(doc, piece, articles[0].context, ids)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks, nil
+}
+
+func (s *LawsSplitter) createChunk(originDoc *schema.Document, content string, header string, articleIds []string) (*schema.Document, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, err
+	}
+
+	chunk := &schema.Document{
+		ID:       id.String(),
+		Content:  content,
+		MetaData: make(map[string]any),
+	}
+
+	// 复制原始 Metadata
+	for k, v := range originDoc.MetaData {
+		chunk.MetaData[k] = v
+	}
+
+	if header == "" {
+		header = "序言"
+	}
+	chunk.MetaData[constant.MetaHeaderContext] = header
+	chunk.MetaData[constant.MetaHeaderType] = "laws"
+	if len(articleIds) > 0 {
+		chunk.MetaData[constant.MetaArticles] = articleIds
+	}
+
+	return chunk, nil
+}
+
+func joinContext(parts ...string) string {
+	var nonEmpty []string
+	for _, p := range parts {
+		if p != "" {
+			nonEmpty = append(nonEmpty, p)
+		}
+	}
+	return strings.Join(nonEmpty, " / ")
+}
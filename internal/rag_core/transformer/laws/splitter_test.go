@@ -0,0 +1,72 @@
+package laws
+
+import (
+	"context"
+	"testing"
+
+	"gozero-rag/internal/rag_core/constant"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLawsSplitter_Transform(t *testing.T) {
+	content := `中华人民共和国示例法
+
+第一章 总则
+第一条 为了规范示例行为，制定本法。
+第二条 本法适用于中华人民共和国境内的示例活动。
+依照本法第一条的规定执行。
+
+第二章 法律责任
+第一节 一般规定
+第三条 违反本法规定的，由有关部门责令改正。
+`
+
+	ctx := context.Background()
+	splitter, err := NewLawsSplitter(ctx, &Config{MaxChunkSize: 500, Separators: []string{"\n"}})
+	require.NoError(t, err)
+
+	chunks, err := splitter.Transform(ctx, []*schema.Document{
+		{ID: "law-1", Content: content, MetaData: map[string]any{"source": "law.txt"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, chunks, 4)
+
+	// 序言
+	assert.Equal(t, "中华人民共和国示例法", chunks[0].Content)
+	assert.Equal(t, "序言", chunks[0].MetaData[constant.MetaHeaderContext])
+
+	// 条款内引用 "第一条" 不应被识别为新条款
+	assert.Equal(t, "第二条 本法适用于中华人民共和国境内的示例活动。\n依照本法第一条的规定执行。", chunks[2].Content)
+	assert.Equal(t, []string{"第二条"}, chunks[2].MetaData[constant.MetaArticles])
+	assert.Equal(t, "第一章 总则", chunks[2].MetaData[constant.MetaHeaderContext])
+
+	assert.Equal(t, "第二章 法律责任 / 第一节 一般规定", chunks[3].MetaData[constant.MetaHeaderContext])
+	for _, chunk := range chunks {
+		assert.Equal(t, "law.txt", chunk.MetaData["source"])
+	}
+}
+
+func TestLawsSplitter_ArticlesPerChunk(t *testing.T) {
+	content := `第一章 总则
+第一条 条款一。
+第二条 条款二。
+第三条 条款三。
+第二章 附则
+第四条 条款四。`
+
+	ctx := context.Background()
+	splitter, err := NewLawsSplitter(ctx, &Config{ArticlesPerChunk: 2, MaxChunkSize: 500})
+	require.NoError(t, err)
+
+	chunks, err := splitter.Transform(ctx, []*schema.Document{{ID: "law-2", Content: content}})
+	require.NoError(t, err)
+
+	// 不同章节的条款不合并
+	require.Len(t, chunks, 3)
+	assert.Equal(t, []string{"第一条", "第二条"}, chunks[0].MetaData[constant.MetaArticles])
+	assert.Equal(t, []string{"第三条"}, chunks[1].MetaData[constant.MetaArticles])
+	assert.Equal(t, []string{"第四条"}, chunks[2].MetaData[constant.MetaArticles])
+}
@@ -0,0 +1,102 @@
+package parentchild
+
+import (
+	"context"
+
+	"gozero-rag/internal/rag_core/constant"
+
+	"github.com/cloudwego/eino-ext/components/document/transformer/splitter/recursive"
+	"github.com/cloudwego/eino/components/document"
+	"github.com/cloudwego/eino/schema"
+	"github.com/google/uuid"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// 默认子块大小
+const defaultChildChunkSize = 128
+
+type Config struct {
+	ChildChunkSize int
+	ChildOverlap   int
+	Separators     []string
+}
+
+// ParentChildSplitter 父子分片器
+// 输入为已切好的分片 (如 StructureSplitter 的章节), 每个分片作为父块再切分为子块,
+// 子块在 MetaData 中记录父块 ID 和父块内容, 入库时子块生成向量, 父块单独存储用于扩展上下文
+type ParentChildSplitter struct {
+	config            *Config
+	recursiveSplitter document.Transformer
+}
+
+func NewParentChildSplitter(ctx context.Context, config *Config) (*ParentChildSplitter, error) {
+	if config.ChildChunkSize <= 0 {
+		config.ChildChunkSize = defaultChildChunkSize
+	}
+
+	recSplitter, err := recursive.NewSplitter(ctx, &recursive.Config{
+		ChunkSize:   config.ChildChunkSize,
+		OverlapSize: config.ChildOverlap,
+		Separators:  config.Separators,
+		KeepType:    recursive.KeepTypeNone,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ParentChildSplitter{
+		config:            config,
+		recursiveSplitter: recSplitter,
+	}, nil
+}
+
+func (s *ParentChildSplitter) Transform(ctx context.Context, src []*schema.Document, opts ...document.TransformerOption) ([]*schema.Document, error) {
+	var result []*schema.Document
+
+	for _, parent := range src {
+		// 问答对本身就是最小单元, 父块不超过子块大小时无需拆分
+		if _, ok := parent.MetaData[constant.MetaQuestion]; ok || len([]rune(parent.Content)) <= s.config.ChildChunkSize {
+			result = append(result, parent)
+			continue
+		}
+
+		subDocs, err := s.recursiveSplitter.Transform(ctx, []*schema.Document{{Content: parent.Content}})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, sub := range subDocs {
+			child, err := s.createChild(parent, sub.Content)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, child)
+		}
+	}
+
+	logx.Infof("[ParentChildSplitter] %d 个父块切分为 %d 个子块", len(src), len(result))
+	return result, nil
+}
+
+func (s *ParentChildSplitter) createChild(parent *schema.Document, content string) (*schema.Document, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, err
+	}
+
+	child := &schema.Document{
+		ID:       id.String(),
+		Content:  content,
+		MetaData: make(map[string]any),
+	}
+
+	// 复制父块 Metadata (标题上下文、来源等)
+	for k, v := range parent.MetaData {
+		child.MetaData[k] = v
+	}
+
+	child.MetaData[constant.MetaParentId] = parent.ID
+	child.MetaData[constant.MetaParentContent] = parent.Content
+
+	return child, nil
+}
@@ -0,0 +1,49 @@
+package parentchild
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gozero-rag/internal/rag_core/constant"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParentChildSplitter_Transform(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewParentChildSplitter(ctx, &Config{ChildChunkSize: 20, Separators: []string{"。"}})
+	require.NoError(t, err)
+
+	long := strings.Repeat("父块内容需要被切分。", 6)
+	src := []*schema.Document{
+		{ID: "p1", Content: long, MetaData: map[string]any{constant.MetaHeaderContext: "第一章"}},
+		{ID: "p2", Content: "短内容", MetaData: map[string]any{}},
+		{ID: "p3", Content: "Question: 很长的问题很长的问题很长的问题\nAnswer: 答案", MetaData: map[string]any{constant.MetaQuestion: "问题"}},
+	}
+
+	result, err := s.Transform(ctx, src)
+	require.NoError(t, err)
+
+	var children []*schema.Document
+	for _, doc := range result {
+		if doc.MetaData[constant.MetaParentId] != nil {
+			children = append(children, doc)
+		}
+	}
+	require.Greater(t, len(children), 1)
+
+	parentId := children[0].MetaData[constant.MetaParentId]
+	for _, child := range children {
+		assert.Equal(t, parentId, child.MetaData[constant.MetaParentId])
+		assert.Equal(t, long, child.MetaData[constant.MetaParentContent])
+		assert.Equal(t, "第一章", child.MetaData[constant.MetaHeaderContext])
+		assert.LessOrEqual(t, len([]rune(child.Content)), 20)
+	}
+
+	// 短分片和问答对保持不变
+	assert.Equal(t, "p2", result[len(result)-2].ID)
+	assert.Equal(t, "p3", result[len(result)-1].ID)
+}
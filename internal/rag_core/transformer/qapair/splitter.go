@@ -0,0 +1,202 @@
+package qapair
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gozero-rag/internal/rag_core/constant"
+
+	"github.com/cloudwego/eino-ext/components/document/transformer/splitter/recursive"
+	"github.com/cloudwego/eino/components/document"
+	"github.com/cloudwego/eino/schema"
+	"github.com/google/uuid"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+var (
+	defaultQuestionPrefixes = []string{"Q:", "Q：", "问题:", "问题：", "问:", "问："}
+	defaultAnswerPrefixes   = []string{"A:", "A：", "答案:", "答案：", "答:", "答："}
+)
+
+type Config struct {
+	QuestionPrefixes []string // 问题行前缀, 为空时使用默认值
+	AnswerPrefixes   []string // 答案行前缀, 为空时使用默认值
+	MaxChunkSize     int      // 无法识别为问答格式的文档回退为递归切分时使用
+	OverlapSize      int
+	Separators       []string
+}
+
+// QaPairSplitter 问答对分片器
+// 支持两种输入:
+//  1. "Q:/A:" 格式的文本, 每个问答对生成一个 chunk
+//  2. TableParser 输出的表格行, 第一列为问题, 第二列为答案
+//
+// 输出的 chunk 在 MetaData 中记录问题 (constant.MetaQuestion), 入库时直接作为 QA chunk, 使用问题生成向量
+type QaPairSplitter struct {
+	config            *Config
+	questionPrefixes  []string
+	answerPrefixes    []string
+	recursiveSplitter document.Transformer
+}
+
+func NewQaPairSplitter(ctx context.Context, config *Config) (*QaPairSplitter, error) {
+	recSplitter, err := recursive.NewSplitter(ctx, &recursive.Config{
+		ChunkSize:   config.MaxChunkSize,
+		OverlapSize: config.OverlapSize,
+		Separators:  config.Separators,
+		KeepType:    recursive.KeepTypeNone,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s := &QaPairSplitter{
+		config:            config,
+		questionPrefixes:  config.QuestionPrefixes,
+		answerPrefixes:    config.AnswerPrefixes,
+		recursiveSplitter: recSplitter,
+	}
+	if len(s.questionPrefixes) == 0 {
+		s.questionPrefixes = defaultQuestionPrefixes
+	}
+	if len(s.answerPrefixes) == 0 {
+		s.answerPrefixes = defaultAnswerPrefixes
+	}
+	return s, nil
+}
+
+type qaPair struct {
+	question string
+	answer   string
+}
+
+func (s *QaPairSplitter) Transform(ctx context.Context, src []*schema.Document, opts ...document.TransformerOption) ([]*schema.Document, error) {
+	var result []*schema.Document
+
+	for _, doc := range src {
+		var pairs []qaPair
+		if cells, ok := doc.MetaData[constant.MetaRowValues].([]string); ok {
+			pairs = s.parseRow(cells)
+		} else {
+			pairs = s.parseText(doc.Content)
+		}
+
+		if len(pairs) == 0 {
+			// 无法识别为问答格式, 按普通文本切分, 避免内容丢失
+			logx.Infof("[QaPairSplitter] 文档 [%s] 未识别到问答对, 回退为递归切分", doc.ID)
+			chunks, err := s.fallback(ctx, doc)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, chunks...)
+			continue
+		}
+
+		for _, pair := range pairs {
+			chunk, err := s.createChunk(doc, pair)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, chunk)
+		}
+	}
+
+	logx.Infof("[QaPairSplitter] %d 个文档生成 %d 个 chunk", len(src), len(result))
+	return result, nil
+}
+
+// parseRow 两列表格: 第一列为问题, 第二列为答案
+func (s *QaPairSplitter) parseRow(cells []string) []qaPair {
+	if len(cells) < 2 || cells[0] == "" || cells[1] == "" {
+		return nil
+	}
+	return []qaPair{{question: cells[0], answer: cells[1]}}
+}
+
+// parseText 解析 "Q:/A:" 格式文本, 问题和答案都支持多行, 遇到下一个问题前缀时结束
+func (s *QaPairSplitter) parseText(content string) []qaPair {
+	var (
+		pairs            []qaPair
+		question, answer []string
+		inAnswer         bool
+	)
+
+	flush := func() {
+		q := strings.TrimSpace(strings.Join(question, "\n"))
+		a := strings.TrimSpace(strings.Join(answer, "\n"))
+		if q != "" && a != "" {
+			pairs = append(pairs, qaPair{question: q, answer: a})
+		}
+		question, answer, inAnswer = nil, nil, false
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if rest, ok := cutPrefix(trimmed, s.questionPrefixes); ok {
+			flush()
+			question = append(question, rest)
+			continue
+		}
+		if rest, ok := cutPrefix(trimmed, s.answerPrefixes); ok && question != nil {
+			inAnswer = true
+			answer = append(answer, rest)
+			continue
+		}
+
+		switch {
+		case inAnswer:
+			answer = append(answer, line)
+		case question != nil:
+			question = append(question, line)
+		}
+	}
+	flush()
+
+	return pairs
+}
+
+func (s *QaPairSplitter) fallback(ctx context.Context, doc *schema.Document) ([]*schema.Document, error) {
+	if s.config.MaxChunkSize <= 0 || len([]rune(doc.Content)) <= s.config.MaxChunkSize {
+		return []*schema.Document{doc}, nil
+	}
+	return s.recursiveSplitter.Transform(ctx, []*schema.Document{doc})
+}
+
+func (s *QaPairSplitter) createChunk(originDoc *schema.Document, pair qaPair) (*schema.Document, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, err
+	}
+
+	chunk := &schema.Document{
+		ID:       id.String(),
+		Content:  fmt.Sprintf("Question: %s\nAnswer: %s", pair.question, pair.answer),
+		MetaData: make(map[string]any),
+	}
+
+	// 复制原始 Metadata
+	for k, v := range originDoc.MetaData {
+		chunk.MetaData[k] = v
+	}
+	delete(chunk.MetaData, constant.MetaRowValues)
+
+	// 表格行号统一为 chunk 级别的 []int
+	if idx, ok := chunk.MetaData[constant.MetaRowIndex].(int); ok {
+		chunk.MetaData[constant.MetaRowIndex] = []int{idx}
+	}
+	chunk.MetaData[constant.MetaQuestion] = pair.question
+
+	return chunk, nil
+}
+
+// cutPrefix 匹配任一前缀 (忽略大小写), 返回去掉前缀后的内容
+func cutPrefix(line string, prefixes []string) (string, bool) {
+	for _, prefix := range prefixes {
+		if len(line) >= len(prefix) && strings.EqualFold(line[:len(prefix)], prefix) {
+			return strings.TrimSpace(line[len(prefix):]), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,70 @@
+package qapair
+
+import (
+	"context"
+	"testing"
+
+	"gozero-rag/internal/rag_core/constant"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQaPairSplitter_Text(t *testing.T) {
+	content := `常见问题
+Q: 如何重置密码？
+A: 在登录页点击"忘记密码"。
+按提示完成邮箱验证。
+
+问：支持哪些文件格式？
+答：pdf、docx、xlsx 等。
+Q: 没有答案的问题`
+
+	ctx := context.Background()
+	splitter, err := NewQaPairSplitter(ctx, &Config{MaxChunkSize: 500})
+	require.NoError(t, err)
+
+	chunks, err := splitter.Transform(ctx, []*schema.Document{{ID: "faq", Content: content}})
+	require.NoError(t, err)
+	require.Len(t, chunks, 2)
+
+	assert.Equal(t, "如何重置密码？", chunks[0].MetaData[constant.MetaQuestion])
+	assert.Equal(t, "Question: 如何重置密码？\nAnswer: 在登录页点击\"忘记密码\"。\n按提示完成邮箱验证。", chunks[0].Content)
+	assert.Equal(t, "支持哪些文件格式？", chunks[1].MetaData[constant.MetaQuestion])
+}
+
+func TestQaPairSplitter_TableRow(t *testing.T) {
+	ctx := context.Background()
+	splitter, err := NewQaPairSplitter(ctx, &Config{MaxChunkSize: 500})
+	require.NoError(t, err)
+
+	src := []*schema.Document{
+		{
+			ID:      "Sheet1_1",
+			Content: "问题: 营业时间？\n答案: 9:00-18:00",
+			MetaData: map[string]any{
+				constant.MetaSheetName: "Sheet1",
+				constant.MetaRowIndex:  1,
+				constant.MetaRowValues: []string{"营业时间？", "9:00-18:00"},
+			},
+		},
+		{
+			ID:       "Sheet1_2",
+			Content:  "问题: 缺少答案",
+			MetaData: map[string]any{constant.MetaRowValues: []string{"缺少答案"}},
+		},
+	}
+
+	chunks, err := splitter.Transform(ctx, src)
+	require.NoError(t, err)
+	require.Len(t, chunks, 2)
+
+	assert.Equal(t, "Question: 营业时间？\nAnswer: 9:00-18:00", chunks[0].Content)
+	assert.Equal(t, []int{1}, chunks[0].MetaData[constant.MetaRowIndex])
+	assert.NotContains(t, chunks[0].MetaData, constant.MetaRowValues)
+
+	// 无法识别的行保留原文
+	assert.Equal(t, "问题: 缺少答案", chunks[1].Content)
+	assert.NotContains(t, chunks[1].MetaData, constant.MetaQuestion)
+}
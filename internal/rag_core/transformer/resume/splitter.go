@@ -0,0 +1,306 @@
+package resume
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gozero-rag/internal/rag_core/constant"
+
+	"github.com/cloudwego/eino-ext/components/document/transformer/splitter/recursive"
+	"github.com/cloudwego/eino/components/document"
+	"github.com/cloudwego/eino/schema"
+	"github.com/google/uuid"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// 结构化字段 key
+const (
+	FieldName        = "name"
+	FieldGender      = "gender"
+	FieldAge         = "age"
+	FieldPhone       = "phone"
+	FieldEmail       = "email"
+	FieldEducation   = "education"
+	FieldYearsOfWork = "years_of_work"
+	FieldIntention   = "intention"
+	FieldLocation    = "location"
+)
+
+// fieldOrder 概要 chunk 中字段的输出顺序及中文名称
+var fieldOrder = []struct {
+	key   string
+	label string
+}{
+	{FieldName, "姓名"},
+	{FieldGender, "性别"},
+	{FieldAge, "年龄"},
+	{FieldPhone, "电话"},
+	{FieldEmail, "邮箱"},
+	{FieldEducation, "学历"},
+	{FieldYearsOfWork, "工作年限"},
+	{FieldIntention, "求职意向"},
+	{FieldLocation, "现居地"},
+}
+
+// labelFields "标签: 值" 形式的字段, 标签 -> 字段 key
+var labelFields = map[string]string{
+	"姓名":    FieldName,
+	"性别":    FieldGender,
+	"年龄":    FieldAge,
+	"电话":    FieldPhone,
+	"手机":    FieldPhone,
+	"联系电话":  FieldPhone,
+	"邮箱":    FieldEmail,
+	"电子邮箱":  FieldEmail,
+	"email": FieldEmail,
+	"学历":    FieldEducation,
+	"最高学历":  FieldEducation,
+	"工作年限":  FieldYearsOfWork,
+	"工作经验":  FieldYearsOfWork,
+	"求职意向":  FieldIntention,
+	"期望职位":  FieldIntention,
+	"现居地":   FieldLocation,
+	"所在城市":  FieldLocation,
+}
+
+// 默认识别的分段标题
+var defaultSections = []string{
+	"基本信息", "个人信息", "求职意向",
+	"教育经历", "教育背景",
+	"工作经历", "工作经验",
+	"项目经历", "项目经验",
+	"专业技能", "技能特长", "技能",
+	"证书", "资格证书",
+	"自我评价", "个人评价",
+}
+
+// 学历按从高到低排列, 取简历中出现的最高学历
+var degrees = []string{"博士", "硕士", "研究生", "本科", "学士", "大专", "专科", "高中", "中专"}
+
+var (
+	labelRe = regexp.MustCompile(`^([\p{Han}A-Za-z]{2,6})\s*[:：]\s*(.+)$`)
+	phoneRe = regexp.MustCompile(`(?:\+?86[- ]?)?1[3-9]\d{9}`)
+	emailRe = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	yearsRe = regexp.MustCompile(`(\d{1,2})\s*年(?:以上)?工作经验`)
+	nameRe  = regexp.MustCompile(`^\p{Han}{2,4}$`)
+)
+
+type Config struct {
+	ExtraSections []string // 额外识别的分段标题
+	MaxChunkSize  int      // 超长分段递归切分
+	OverlapSize   int
+	Separators    []string
+}
+
+// ResumeSplitter 简历分片器
+// 1. 基于规则提取姓名、电话、邮箱、学历等结构化字段, 生成一个 "简历概要" chunk
+// 2. 按教育经历、工作经历等分段生成 chunk, 每个分段都带上候选人姓名, 便于召回后定位到具体候选人
+type ResumeSplitter struct {
+	config            *Config
+	sections          map[string]bool
+	recursiveSplitter document.Transformer
+}
+
+func NewResumeSplitter(ctx context.Context, config *Config) (*ResumeSplitter, error) {
+	recSplitter, err := recursive.NewSplitter(ctx, &recursive.Config{
+		ChunkSize:   config.MaxChunkSize,
+		OverlapSize: config.OverlapSize,
+		Separators:  config.Separators,
+		KeepType:    recursive.KeepTypeNone,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sections := make(map[string]bool, len(defaultSections)+len(config.ExtraSections))
+	for _, name := range defaultSections {
+		sections[name] = true
+	}
+	for _, name := range config.ExtraSections {
+		sections[strings.TrimSpace(name)] = true
+	}
+
+	return &ResumeSplitter{
+		config:            config,
+		sections:          sections,
+		recursiveSplitter: recSplitter,
+	}, nil
+}
+
+type section struct {
+	title string
+	lines []string
+}
+
+func (s *ResumeSplitter) Transform(ctx context.Context, src []*schema.Document, opts ...document.TransformerOption) ([]*schema.Document, error) {
+	var result []*schema.Document
+
+	for _, doc := range src {
+		fields := ExtractFields(doc.Content)
+		sections := s.splitSections(doc.Content)
+		logx.Infof("[ResumeSplitter] 文档 [%s] 提取到 %d 个字段, %d 个分段", doc.ID, len(fields), len(sections))
+
+		if summary := renderSummary(fields); summary != "" {
+			chunk, err := s.createChunk(doc, summary, "简历概要", fields)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, chunk)
+		}
+
+		prefix := ""
+		if name := fields[FieldName]; name != "" {
+			prefix = fmt.Sprintf("候选人: %s\n", name)
+		}
+
+		for _, sec := range sections {
+			content := strings.TrimSpace(strings.Join(sec.lines, "\n"))
+			if content == "" {
+				continue
+			}
+
+			pieces := []string{content}
+			if s.config.MaxChunkSize > 0 && len([]rune(content)) > s.config.MaxChunkSize {
+				subDocs, err := s.recursiveSplitter.Transform(ctx, []*schema.Document{{Content: content}})
+				if err != nil {
+					return nil, err
+				}
+				pieces = pieces[:0]
+				for _, sub := range subDocs {
+					pieces = append(pieces, sub.Content)
+				}
+			}
+
+			for _, piece := range pieces {
+				chunk, err := s.createChunk(doc, prefix+piece, sec.title, fields)
+				if err != nil {
+					return nil, err
+				}
+				result = append(result, chunk)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// splitSections 按分段标题切分, 标题行形如 "工作经历" / "【工作经历】" / "工作经历:"
+func (s *ResumeSplitter) splitSections(content string) []*section {
+	current := &section{title: "基本信息"}
+	sections := []*section{current}
+
+	for _, line := range strings.Split(content, "\n") {
+		title := strings.Trim(strings.TrimSpace(line), "【】[]#:： ")
+		if s.sections[title] {
+			current = &section{title: title}
+			sections = append(sections, current)
+			continue
+		}
+		current.lines = append(current.lines, line)
+	}
+
+	return sections
+}
+
+// ExtractFields 基于规则提取简历中的结构化字段, 未识别的字段不出现在结果中
+func ExtractFields(content string) map[string]string {
+	fields := make(map[string]string)
+	firstLine := ""
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if firstLine == "" {
+			firstLine = line
+		}
+
+		// 一行可能包含多个 "标签: 值", 如 "姓名: 张三 | 性别: 男"
+		for _, part := range strings.FieldsFunc(line, func(r rune) bool { return r == '|' || r == '｜' || r == '\t' }) {
+			matches := labelRe.FindStringSubmatch(strings.TrimSpace(part))
+			if len(matches) == 0 {
+				continue
+			}
+			key, ok := labelFields[strings.ToLower(matches[1])]
+			if ok && fields[key] == "" {
+				fields[key] = strings.TrimSpace(matches[2])
+			}
+		}
+	}
+
+	if fields[FieldPhone] == "" {
+		if phone := phoneRe.FindString(content); phone != "" {
+			fields[FieldPhone] = phone
+		}
+	}
+	if fields[FieldEmail] == "" {
+		if email := emailRe.FindString(content); email != "" {
+			fields[FieldEmail] = email
+		}
+	}
+	if fields[FieldYearsOfWork] == "" {
+		if matches := yearsRe.FindStringSubmatch(content); len(matches) > 0 {
+			fields[FieldYearsOfWork] = matches[1] + "年"
+		}
+	}
+	if fields[FieldEducation] == "" {
+		for _, degree := range degrees {
+			if strings.Contains(content, degree) {
+				fields[FieldEducation] = degree
+				break
+			}
+		}
+	}
+	// 没有 "姓名:" 标签时, 简历首行通常就是姓名
+	if fields[FieldName] == "" && isLikelyName(firstLine) {
+		fields[FieldName] = firstLine
+	}
+
+	return fields
+}
+
+// isLikelyName 2~4 个汉字, 且不是分段标题
+func isLikelyName(line string) bool {
+	return nameRe.MatchString(line) && !slices.Contains(defaultSections, line)
+}
+
+func renderSummary(fields map[string]string) string {
+	var sb strings.Builder
+	for _, f := range fieldOrder {
+		if v := fields[f.key]; v != "" {
+			sb.WriteString(fmt.Sprintf("%s: %s\n", f.label, v))
+		}
+	}
+	if sb.Len() == 0 {
+		return ""
+	}
+	return "简历概要\n" + strings.TrimSuffix(sb.String(), "\n")
+}
+
+func (s *ResumeSplitter) createChunk(originDoc *schema.Document, content string, header string, fields map[string]string) (*schema.Document, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, err
+	}
+
+	chunk := &schema.Document{
+		ID:       id.String(),
+		Content:  content,
+		MetaData: make(map[string]any),
+	}
+
+	// 复制原始 Metadata
+	for k, v := range originDoc.MetaData {
+		chunk.MetaData[k] = v
+	}
+
+	chunk.MetaData[constant.MetaHeaderContext] = header
+	chunk.MetaData[constant.MetaHeaderType] = "resume"
+	chunk.MetaData[constant.MetaResumeFields] = fields
+
+	return chunk, nil
+}
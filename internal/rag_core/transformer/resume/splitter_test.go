@@ -0,0 +1,62 @@
+package resume
+
+import (
+	"context"
+	"testing"
+
+	"gozero-rag/internal/rag_core/constant"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractFields(t *testing.T) {
+	content := `张三
+性别: 男 | 年龄: 28
+联系方式 13812345678 zhangsan@example.com
+5年工作经验
+
+教育经历
+2012-2016 某某大学 计算机科学 本科
+`
+	fields := ExtractFields(content)
+
+	assert.Equal(t, "张三", fields[FieldName])
+	assert.Equal(t, "男", fields[FieldGender])
+	assert.Equal(t, "28", fields[FieldAge])
+	assert.Equal(t, "13812345678", fields[FieldPhone])
+	assert.Equal(t, "zhangsan@example.com", fields[FieldEmail])
+	assert.Equal(t, "5年", fields[FieldYearsOfWork])
+	assert.Equal(t, "本科", fields[FieldEducation])
+}
+
+func TestResumeSplitter_Transform(t *testing.T) {
+	content := `姓名：李四
+邮箱：lisi@example.com
+
+【工作经历】
+2020-至今 某公司 后端工程师
+
+获奖情况
+2019 年度优秀员工`
+
+	ctx := context.Background()
+	splitter, err := NewResumeSplitter(ctx, &Config{ExtraSections: []string{"获奖情况"}, MaxChunkSize: 500})
+	require.NoError(t, err)
+
+	chunks, err := splitter.Transform(ctx, []*schema.Document{{ID: "cv", Content: content}})
+	require.NoError(t, err)
+	require.Len(t, chunks, 4)
+
+	assert.Equal(t, "简历概要\n姓名: 李四\n邮箱: lisi@example.com", chunks[0].Content)
+	assert.Equal(t, "简历概要", chunks[0].MetaData[constant.MetaHeaderContext])
+
+	assert.Equal(t, "工作经历", chunks[2].MetaData[constant.MetaHeaderContext])
+	assert.Equal(t, "候选人: 李四\n2020-至今 某公司 后端工程师", chunks[2].Content)
+	assert.Equal(t, "获奖情况", chunks[3].MetaData[constant.MetaHeaderContext])
+
+	fields, ok := chunks[3].MetaData[constant.MetaResumeFields].(map[string]string)
+	require.True(t, ok)
+	assert.Equal(t, "李四", fields[FieldName])
+}
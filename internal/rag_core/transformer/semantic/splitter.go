@@ -0,0 +1,344 @@
+package semantic
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gozero-rag/internal/rag_core/types"
+
+	"github.com/cloudwego/eino-ext/components/embedding/openai"
+	"github.com/cloudwego/eino/components/document"
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/cloudwego/eino/schema"
+	"github.com/google/uuid"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	// 默认切分百分位: 相邻句子相似度低于所有相似度的第 10 百分位时切分
+	defaultBreakpointPercentile = 10
+	// 每批次生成向量的句子数
+	embedBatchSize = 32
+	// 默认最大 chunk 长度
+	defaultMaxChunkSize = 500
+)
+
+// 句子结束符, 结束符本身保留在句子中
+var sentenceEnds = map[rune]bool{'。': true, '！': true, '？': true, '；': true, '!': true, '?': true, '\n': true}
+
+type Config struct {
+	Embedder             embedding.Embedder
+	MaxChunkSize         int // chunk 最大字符数
+	OverlapSize          int // 因长度切分时与上一个 chunk 重叠的字符数, 话题切分不重叠
+	BreakpointPercentile int // 相邻句子相似度低于该百分位时切分, <=0 使用默认值
+	// Fallback 生成向量失败时使用的回退分片器, 为空时返回错误
+	Fallback document.Transformer
+}
+
+// SemanticSplitter 基于向量相似度的语义分片器
+// 1. 将文档拆分为句子, 使用知识库的 Embedding 模型生成向量
+// 2. 计算相邻句子的余弦相似度, 低于百分位阈值的位置视为话题边界
+// 3. 按边界聚合句子, 同时遵守 MaxChunkSize / OverlapSize
+//
+// 与 AgenticSplitter 相比只需要 Embedding 调用, 成本更低; 与 StructureSplitter 相比不依赖标题格式
+type SemanticSplitter struct {
+	config *Config
+}
+
+func NewSemanticSplitter(ctx context.Context, config *Config) (*SemanticSplitter, error) {
+	if config.Embedder == nil {
+		return nil, fmt.Errorf("SemanticSplitter 需要 Embedder")
+	}
+	if config.MaxChunkSize <= 0 {
+		config.MaxChunkSize = defaultMaxChunkSize
+	}
+	if config.BreakpointPercentile <= 0 || config.BreakpointPercentile >= 100 {
+		config.BreakpointPercentile = defaultBreakpointPercentile
+	}
+	return &SemanticSplitter{config: config}, nil
+}
+
+// NewSemanticSplitterFromConfig 使用 ProcessConfig 中的 Embedding 模型创建 SemanticSplitter
+func NewSemanticSplitterFromConfig(ctx context.Context, cfg types.ProcessConfig, fallback document.Transformer) (*SemanticSplitter, error) {
+	if cfg.LlmConfig.EmbeddingKey == "" {
+		return nil, fmt.Errorf("ApiKey is required for SemanticSplitter")
+	}
+
+	embedder, err := openai.NewEmbedder(ctx, &openai.EmbeddingConfig{
+		APIKey:  cfg.LlmConfig.EmbeddingKey,
+		BaseURL: cfg.LlmConfig.EmbeddingBaseUrl,
+		Model:   cfg.LlmConfig.EmbeddingModelName,
+		Timeout: 30 * time.Second,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("创建 Embedder 失败: %w", err)
+	}
+
+	return NewSemanticSplitter(ctx, &Config{
+		Embedder:             embedder,
+		MaxChunkSize:         cfg.MaxChunkLength,
+		OverlapSize:          cfg.ChunkOverlap,
+		BreakpointPercentile: cfg.SemanticPercentile,
+		Fallback:             fallback,
+	})
+}
+
+func (s *SemanticSplitter) Transform(ctx context.Context, src []*schema.Document, opts ...document.TransformerOption) ([]*schema.Document, error) {
+	var result []*schema.Document
+
+	for _, doc := range src {
+		chunks, err := s.splitDocument(ctx, doc)
+		if err != nil {
+			if s.config.Fallback == nil {
+				return nil, err
+			}
+			logx.Errorf("[SemanticSplitter] 文档 [%s] 语义切分失败, 回退到 %T: %v", doc.ID, s.config.Fallback, err)
+			chunks, err = s.config.Fallback.Transform(ctx, []*schema.Document{doc})
+			if err != nil {
+				return nil, err
+			}
+		}
+		result = append(result, chunks...)
+	}
+
+	return result, nil
+}
+
+func (s *SemanticSplitter) splitDocument(ctx context.Context, doc *schema.Document) ([]*schema.Document, error) {
+	sentences := splitSentences(doc.Content)
+	if len(sentences) == 0 {
+		return nil, nil
+	}
+
+	var boundaries []bool
+	if len(sentences) > 1 {
+		vectors, err := s.embed(ctx, sentences)
+		if err != nil {
+			return nil, err
+		}
+		boundaries = findBoundaries(vectors, s.config.BreakpointPercentile)
+	}
+
+	contents := aggregate(sentences, boundaries, s.config.MaxChunkSize, s.config.OverlapSize)
+	logx.Infof("[SemanticSplitter] 文档 [%s] %d 个句子聚合为 %d 个 chunk", doc.ID, len(sentences), len(contents))
+
+	chunks := make([]*schema.Document, 0, len(contents))
+	for _, content := range contents {
+		chunk, err := s.createChunk(doc, content)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks, nil
+}
+
+// embed 分批生成句子向量
+func (s *SemanticSplitter) embed(ctx context.Context, sentences []string) ([][]float64, error) {
+	vectors := make([][]float64, 0, len(sentences))
+	for start := 0; start < len(sentences); start += embedBatchSize {
+		end := min(start+embedBatchSize, len(sentences))
+		batch, err := s.config.Embedder.EmbedStrings(ctx, sentences[start:end])
+		if err != nil {
+			return nil, fmt.Errorf("生成句子向量失败: %w", err)
+		}
+		if len(batch) != end-start {
+			return nil, fmt.Errorf("句子向量数量(%d)与句子数量(%d)不一致", len(batch), end-start)
+		}
+		vectors = append(vectors, batch...)
+	}
+	return vectors, nil
+}
+
+func (s *SemanticSplitter) createChunk(originDoc *schema.Document, content string) (*schema.Document, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, err
+	}
+
+	chunk := &schema.Document{
+		ID:       id.String(),
+		Content:  content,
+		MetaData: make(map[string]any),
+	}
+
+	// 复制原始 Metadata
+	for k, v := range originDoc.MetaData {
+		chunk.MetaData[k] = v
+	}
+
+	return chunk, nil
+}
+
+// splitSentences 按中英文句末标点和换行拆分句子, 标点保留在句尾
+func splitSentences(content string) []string {
+	var (
+		sentences []string
+		current   strings.Builder
+	)
+
+	flush := func() {
+		if sentence := strings.TrimSpace(current.String()); sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+		current.Reset()
+	}
+
+	runes := []rune(content)
+	for i, r := range runes {
+		current.WriteRune(r)
+		if sentenceEnds[r] {
+			flush()
+			continue
+		}
+		// 英文句号后跟空白才视为句末, 避免切断小数和缩写
+		if r == '.' && (i+1 == len(runes) || runes[i+1] == ' ' || runes[i+1] == '\n') {
+			flush()
+		}
+	}
+	flush()
+
+	return sentences
+}
+
+// findBoundaries 计算相邻句子的余弦相似度, 低于百分位阈值的位置为边界
+// 返回长度为 len(vectors)-1, boundaries[i] 表示句子 i 和 i+1 之间是否切分
+func findBoundaries(vectors [][]float64, percentile int) []bool {
+	similarities := make([]float64, len(vectors)-1)
+	for i := range similarities {
+		similarities[i] = cosineSimilarity(vectors[i], vectors[i+1])
+	}
+
+	threshold := percentileOf(similarities, percentile)
+	boundaries := make([]bool, len(similarities))
+	for i, sim := range similarities {
+		boundaries[i] = sim < threshold
+	}
+	return boundaries
+}
+
+// percentileOf 线性插值计算百分位数
+func percentileOf(values []float64, percentile int) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	pos := float64(percentile) / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+	if lower == upper {
+		return sorted[lower]
+	}
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(pos-float64(lower))
+}
+
+func cosineSimilarity(a, b []float64) float64 {
+	var dot, normA, normB float64
+	for i := range a {
+		if i >= len(b) {
+			break
+		}
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// aggregate 按话题边界聚合句子, 超过 maxChunkSize 时强制切分并保留 overlapSize 的重叠
+func aggregate(sentences []string, boundaries []bool, maxChunkSize, overlapSize int) []string {
+	var (
+		chunks  []string
+		current []string
+		length  int
+	)
+
+	flush := func() {
+		if len(current) > 0 {
+			chunks = append(chunks, joinSentences(current))
+		}
+		current, length = nil, 0
+	}
+
+	for i, sentence := range sentences {
+		if i > 0 && boundaries[i-1] {
+			flush()
+		}
+
+		// 单个句子超长时按字符硬切
+		for _, piece := range splitLong(sentence, maxChunkSize) {
+			pieceLen := len([]rune(piece))
+
+			if len(current) > 0 && length+pieceLen > maxChunkSize {
+				overlap := tailOverlap(current, overlapSize, maxChunkSize-pieceLen)
+				flush()
+				for _, o := range overlap {
+					current = append(current, o)
+					length += len([]rune(o))
+				}
+			}
+
+			current = append(current, piece)
+			length += pieceLen
+		}
+	}
+	flush()
+
+	return chunks
+}
+
+// tailOverlap 取当前 chunk 末尾若干完整句子作为重叠, 总长度不超过 overlapSize 且为下一句留出空间
+func tailOverlap(current []string, overlapSize, room int) []string {
+	limit := min(overlapSize, room)
+	if limit <= 0 {
+		return nil
+	}
+
+	length := 0
+	start := len(current)
+	for start > 0 {
+		l := len([]rune(current[start-1]))
+		if length+l > limit {
+			break
+		}
+		length += l
+		start--
+	}
+	return append([]string(nil), current[start:]...)
+}
+
+func splitLong(sentence string, maxChunkSize int) []string {
+	runes := []rune(sentence)
+	if len(runes) <= maxChunkSize {
+		return []string{sentence}
+	}
+
+	var pieces []string
+	for start := 0; start < len(runes); start += maxChunkSize {
+		end := min(start+maxChunkSize, len(runes))
+		pieces = append(pieces, string(runes[start:end]))
+	}
+	return pieces
+}
+
+// joinSentences 拼接句子, 英文句子之间补充空格
+func joinSentences(sentences []string) string {
+	var sb strings.Builder
+	for i, sentence := range sentences {
+		if i > 0 {
+			prev := []rune(sentences[i-1])
+			if prev[len(prev)-1] < utf8.RuneSelf {
+				sb.WriteString(" ")
+			}
+		}
+		sb.WriteString(sentence)
+	}
+	return sb.String()
+}
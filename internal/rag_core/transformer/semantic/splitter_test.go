@@ -0,0 +1,107 @@
+package semantic
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/components/document"
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/cloudwego/eino/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// topicEmbedder 按关键词生成向量: 包含 "猫" 的句子和包含 "股票" 的句子正交
+type topicEmbedder struct {
+	err error
+}
+
+func (e *topicEmbedder) EmbedStrings(ctx context.Context, texts []string, opts ...embedding.Option) ([][]float64, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	vectors := make([][]float64, 0, len(texts))
+	for _, text := range texts {
+		switch {
+		case strings.Contains(text, "猫"):
+			vectors = append(vectors, []float64{1, 0.1, 0})
+		case strings.Contains(text, "股票"):
+			vectors = append(vectors, []float64{0, 0.1, 1})
+		default:
+			vectors = append(vectors, []float64{0.5, 1, 0.5})
+		}
+	}
+	return vectors, nil
+}
+
+type passthrough struct{}
+
+func (passthrough) Transform(ctx context.Context, src []*schema.Document, opts ...document.TransformerOption) ([]*schema.Document, error) {
+	return src, nil
+}
+
+func TestSemanticSplitter_Transform(t *testing.T) {
+	content := "猫喜欢晒太阳。猫每天睡很久。猫爱吃鱼。股票今天上涨。股票成交量放大。股票后市看好。"
+
+	ctx := context.Background()
+	splitter, err := NewSemanticSplitter(ctx, &Config{
+		Embedder:             &topicEmbedder{},
+		MaxChunkSize:         100,
+		BreakpointPercentile: 20,
+	})
+	require.NoError(t, err)
+
+	chunks, err := splitter.Transform(ctx, []*schema.Document{
+		{ID: "doc", Content: content, MetaData: map[string]any{"source": "a.txt"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, chunks, 2)
+
+	assert.Equal(t, "猫喜欢晒太阳。猫每天睡很久。猫爱吃鱼。", chunks[0].Content)
+	assert.Equal(t, "股票今天上涨。股票成交量放大。股票后市看好。", chunks[1].Content)
+	assert.Equal(t, "a.txt", chunks[1].MetaData["source"])
+}
+
+func TestSemanticSplitter_MaxChunkSize(t *testing.T) {
+	// 同一话题, 仅按长度切分并保留一句重叠
+	content := "猫一。猫二。猫三。猫四。"
+
+	ctx := context.Background()
+	splitter, err := NewSemanticSplitter(ctx, &Config{
+		Embedder:     &topicEmbedder{},
+		MaxChunkSize: 6,
+		OverlapSize:  3,
+	})
+	require.NoError(t, err)
+
+	chunks, err := splitter.Transform(ctx, []*schema.Document{{ID: "doc", Content: content}})
+	require.NoError(t, err)
+
+	var contents []string
+	for _, c := range chunks {
+		contents = append(contents, c.Content)
+	}
+	assert.Equal(t, []string{"猫一。猫二。", "猫二。猫三。", "猫三。猫四。"}, contents)
+}
+
+func TestSemanticSplitter_Fallback(t *testing.T) {
+	ctx := context.Background()
+	splitter, err := NewSemanticSplitter(ctx, &Config{
+		Embedder: &topicEmbedder{err: errors.New("quota exceeded")},
+		Fallback: passthrough{},
+	})
+	require.NoError(t, err)
+
+	src := []*schema.Document{{ID: "doc", Content: "第一句。第二句。"}}
+	chunks, err := splitter.Transform(ctx, src)
+	require.NoError(t, err)
+	assert.Equal(t, src, chunks)
+}
+
+func TestSplitSentences(t *testing.T) {
+	sentences := splitSentences("价格是3.5元。Hello world. It works!\n最后一行")
+	assert.Equal(t, []string{"价格是3.5元。", "Hello world.", "It works!", "最后一行"}, sentences)
+	assert.Equal(t, "Hello world. It works!", joinSentences(sentences[1:3]))
+}
@@ -0,0 +1,163 @@
+package table
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gozero-rag/internal/rag_core/constant"
+
+	"github.com/cloudwego/eino/components/document"
+	"github.com/cloudwego/eino/schema"
+	"github.com/google/uuid"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type Config struct {
+	RowsPerChunk int // 每个 chunk 最多包含的行数, <=0 表示不限制
+	MaxChunkSize int // chunk 最大字符数 (包含工作表和表头), <=0 表示不限制
+}
+
+// TableSplitter 表格分片器
+// 将 TableParser 输出的行数据按工作表聚合为 chunk, 每个 chunk 都重复工作表名称和表头,
+// 保证单独召回时依然能理解各列含义
+type TableSplitter struct {
+	config *Config
+}
+
+func NewTableSplitter(ctx context.Context, config *Config) (*TableSplitter, error) {
+	return &TableSplitter{config: config}, nil
+}
+
+// rowGroup 同一来源同一工作表的连续行
+type rowGroup struct {
+	sheet   string
+	headers []string
+	rows    []*schema.Document
+}
+
+func (s *TableSplitter) Transform(ctx context.Context, src []*schema.Document, opts ...document.TransformerOption) ([]*schema.Document, error) {
+	var result []*schema.Document
+
+	for _, group := range s.groupRows(src) {
+		chunks, err := s.splitGroup(group)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, chunks...)
+	}
+
+	logx.Infof("[TableSplitter] %d 行数据聚合为 %d 个 chunk", len(src), len(result))
+	return result, nil
+}
+
+// groupRows 按 (来源, 工作表) 对连续的行分组, 保持原始顺序
+func (s *TableSplitter) groupRows(src []*schema.Document) []*rowGroup {
+	var groups []*rowGroup
+	var current *rowGroup
+	currentKey := ""
+
+	for _, doc := range src {
+		sheet, _ := doc.MetaData[constant.MetaSheetName].(string)
+		source, _ := doc.MetaData["_source"].(string)
+		key := source + "\x00" + sheet
+
+		if current == nil || key != currentKey {
+			headers, _ := doc.MetaData[constant.MetaTableHeaders].([]string)
+			current = &rowGroup{sheet: sheet, headers: headers}
+			currentKey = key
+			groups = append(groups, current)
+		}
+		current.rows = append(current.rows, doc)
+	}
+	return groups
+}
+
+func (s *TableSplitter) splitGroup(group *rowGroup) ([]*schema.Document, error) {
+	prefix := s.renderPrefix(group)
+	prefixLen := len([]rune(prefix))
+
+	var chunks []*schema.Document
+	var pending []*schema.Document
+	pendingLen := 0
+
+	flush := func() error {
+		if len(pending) == 0 {
+			return nil
+		}
+		chunk, err := s.createChunk(group, prefix, pending)
+		if err != nil {
+			return err
+		}
+		chunks = append(chunks, chunk)
+		pending = nil
+		pendingLen = 0
+		return nil
+	}
+
+	for _, row := range group.rows {
+		rowLen := len([]rune(row.Content)) + 2 // 行之间使用空行分隔
+
+		exceedRows := s.config.RowsPerChunk > 0 && len(pending) >= s.config.RowsPerChunk
+		exceedSize := s.config.MaxChunkSize > 0 && len(pending) > 0 && prefixLen+pendingLen+rowLen > s.config.MaxChunkSize
+		if exceedRows || exceedSize {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+
+		pending = append(pending, row)
+		pendingLen += rowLen
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return chunks, nil
+}
+
+// renderPrefix 每个 chunk 头部重复的工作表和表头信息
+func (s *TableSplitter) renderPrefix(group *rowGroup) string {
+	var sb strings.Builder
+	if group.sheet != "" {
+		sb.WriteString(fmt.Sprintf("工作表: %s\n", group.sheet))
+	}
+	if len(group.headers) > 0 {
+		sb.WriteString(fmt.Sprintf("表头: %s\n", strings.Join(group.headers, " | ")))
+	}
+	return sb.String()
+}
+
+func (s *TableSplitter) createChunk(group *rowGroup, prefix string, rows []*schema.Document) (*schema.Document, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, err
+	}
+
+	contents := make([]string, 0, len(rows))
+	rowIndexes := make([]int, 0, len(rows))
+	for _, row := range rows {
+		contents = append(contents, row.Content)
+		if idx, ok := row.MetaData[constant.MetaRowIndex].(int); ok {
+			rowIndexes = append(rowIndexes, idx)
+		}
+	}
+
+	chunk := &schema.Document{
+		ID:       id.String(),
+		Content:  prefix + "\n" + strings.Join(contents, "\n\n"),
+		MetaData: make(map[string]any),
+	}
+
+	// 复制第一行的 Metadata (来源信息等)
+	for k, v := range rows[0].MetaData {
+		chunk.MetaData[k] = v
+	}
+
+	chunk.MetaData[constant.MetaSheetName] = group.sheet
+	chunk.MetaData[constant.MetaTableHeaders] = group.headers
+	chunk.MetaData[constant.MetaRowIndex] = rowIndexes
+	chunk.MetaData[constant.MetaHeaderContext] = group.sheet
+
+	return chunk, nil
+}
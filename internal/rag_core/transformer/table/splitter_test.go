@@ -0,0 +1,67 @@
+package table
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"gozero-rag/internal/rag_core/constant"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRow(sheet string, idx int) *schema.Document {
+	return &schema.Document{
+		ID:      fmt.Sprintf("%s_%d", sheet, idx),
+		Content: fmt.Sprintf("姓名: 员工%d\n部门: 研发", idx),
+		MetaData: map[string]any{
+			"_source":                 "/tmp/员工.xlsx",
+			constant.MetaSheetName:    sheet,
+			constant.MetaTableHeaders: []string{"姓名", "部门"},
+			constant.MetaRowIndex:     idx,
+		},
+	}
+}
+
+func TestTableSplitter_Transform(t *testing.T) {
+	ctx := context.Background()
+	splitter, err := NewTableSplitter(ctx, &Config{RowsPerChunk: 2})
+	require.NoError(t, err)
+
+	src := []*schema.Document{
+		newRow("Sheet1", 1), newRow("Sheet1", 2), newRow("Sheet1", 3),
+		newRow("Sheet2", 1),
+	}
+
+	chunks, err := splitter.Transform(ctx, src)
+	require.NoError(t, err)
+
+	// Sheet1: [1,2] [3], Sheet2: [1], 不同工作表不会合并
+	require.Len(t, chunks, 3)
+	assert.Equal(t, []int{1, 2}, chunks[0].MetaData[constant.MetaRowIndex])
+	assert.Equal(t, []int{3}, chunks[1].MetaData[constant.MetaRowIndex])
+	assert.Equal(t, "Sheet2", chunks[2].MetaData[constant.MetaSheetName])
+
+	// 每个 chunk 都重复工作表和表头
+	for _, chunk := range chunks {
+		assert.True(t, strings.HasPrefix(chunk.Content, "工作表: "+chunk.MetaData[constant.MetaSheetName].(string)+"\n表头: 姓名 | 部门\n"))
+	}
+}
+
+func TestTableSplitter_MaxChunkSize(t *testing.T) {
+	ctx := context.Background()
+	splitter, err := NewTableSplitter(ctx, &Config{MaxChunkSize: 60})
+	require.NoError(t, err)
+
+	chunks, err := splitter.Transform(ctx, []*schema.Document{newRow("Sheet1", 1), newRow("Sheet1", 2), newRow("Sheet1", 3)})
+	require.NoError(t, err)
+
+	// 超过长度限制时拆分, 但每个 chunk 至少保留一行
+	assert.Greater(t, len(chunks), 1)
+	for _, chunk := range chunks {
+		assert.NotEmpty(t, chunk.MetaData[constant.MetaRowIndex])
+	}
+}
@@ -0,0 +1,117 @@
+package transformer
+
+import (
+	"context"
+
+	"gozero-rag/internal/rag_core/parser"
+	"gozero-rag/internal/rag_core/tools/docx"
+	"gozero-rag/internal/rag_core/transformer/book"
+	"gozero-rag/internal/rag_core/transformer/laws"
+	"gozero-rag/internal/rag_core/transformer/qapair"
+	"gozero-rag/internal/rag_core/transformer/resume"
+	"gozero-rag/internal/rag_core/transformer/table"
+	"gozero-rag/internal/rag_core/types"
+
+	"github.com/cloudwego/eino/components/document"
+	"github.com/cloudwego/eino/schema"
+)
+
+// template 解析模板对应的分片策略
+type template struct {
+	// accept 判断文档是否由该模板处理, 为 nil 时处理全部文档, 其余文档走通用分片
+	accept func(doc *schema.Document) bool
+	// newTransformer 根据配置创建分片器, conf.Template 为对应的 parser.ParserConfigXxx
+	newTransformer func(ctx context.Context, conf types.ProcessConfig) (document.Transformer, error)
+}
+
+// templateRegistry parser_id -> 分片策略, 未注册的 parser_id (general) 按文档类型选择 markdown/structure 分片
+var templateRegistry = map[parser.ParserId]*template{
+	parser.ParserIdTable: {
+		accept:         docx.IsTableRow,
+		newTransformer: newTableTransformer,
+	},
+	parser.ParserIdQa: {
+		newTransformer: newQaTransformer,
+	},
+	parser.ParserIdLaws: {
+		newTransformer: newLawsTransformer,
+	},
+	parser.ParserIdBook: {
+		newTransformer: newBookTransformer,
+	},
+	parser.ParserIdResume: {
+		newTransformer: newResumeTransformer,
+	},
+}
+
+// templateConfig 取出模板专属配置, 未设置时返回零值配置
+func templateConfig[T any](conf types.ProcessConfig) *T {
+	if c, ok := conf.Template.(*T); ok && c != nil {
+		return c
+	}
+	return new(T)
+}
+
+func newTableTransformer(ctx context.Context, conf types.ProcessConfig) (document.Transformer, error) {
+	c := templateConfig[parser.ParserConfigTable](conf)
+	return table.NewTableSplitter(ctx, &table.Config{
+		RowsPerChunk: c.RowsPerChunk,
+		MaxChunkSize: conf.MaxChunkLength,
+	})
+}
+
+func newQaTransformer(ctx context.Context, conf types.ProcessConfig) (document.Transformer, error) {
+	c := templateConfig[parser.ParserConfigQa](conf)
+	return qapair.NewQaPairSplitter(ctx, &qapair.Config{
+		QuestionPrefixes: c.QuestionPrefixes,
+		AnswerPrefixes:   c.AnswerPrefixes,
+		MaxChunkSize:     conf.MaxChunkLength,
+		OverlapSize:      conf.ChunkOverlap,
+		Separators:       conf.Separators,
+	})
+}
+
+func newLawsTransformer(ctx context.Context, conf types.ProcessConfig) (document.Transformer, error) {
+	c := templateConfig[parser.ParserConfigLaws](conf)
+	return laws.NewLawsSplitter(ctx, &laws.Config{
+		ArticlesPerChunk: c.ArticlesPerChunk,
+		MaxChunkSize:     conf.MaxChunkLength,
+		OverlapSize:      conf.ChunkOverlap,
+		Separators:       conf.Separators,
+	})
+}
+
+func newBookTransformer(ctx context.Context, conf types.ProcessConfig) (document.Transformer, error) {
+	c := templateConfig[parser.ParserConfigBook](conf)
+	return book.NewBookSplitter(ctx, &book.Config{
+		ChapterPattern: c.ChapterPattern,
+		MaxChunkSize:   conf.MaxChunkLength,
+		OverlapSize:    conf.ChunkOverlap,
+		Separators:     conf.Separators,
+	})
+}
+
+func newResumeTransformer(ctx context.Context, conf types.ProcessConfig) (document.Transformer, error) {
+	c := templateConfig[parser.ParserConfigResume](conf)
+	return resume.NewResumeSplitter(ctx, &resume.Config{
+		ExtraSections: c.ExtraSections,
+		MaxChunkSize:  conf.MaxChunkLength,
+		OverlapSize:   conf.ChunkOverlap,
+		Separators:    conf.Separators,
+	})
+}
+
+// splitTemplateDocs 拆分出由模板处理的文档, 其余文档仍走通用分片
+func splitTemplateDocs(tpl *template, src []*schema.Document) (accepted []*schema.Document, others []*schema.Document) {
+	if tpl.accept == nil {
+		return src, nil
+	}
+	for _, doc := range src {
+		if tpl.accept(doc) {
+			accepted = append(accepted, doc)
+		} else {
+			others = append(others, doc)
+		}
+	}
+	return accepted, others
+}
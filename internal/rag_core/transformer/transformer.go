@@ -3,12 +3,17 @@ package transformer
 import (
 	"context"
 	"gozero-rag/internal/rag_core/constant"
+	"gozero-rag/internal/rag_core/parser"
 	"gozero-rag/internal/rag_core/tools/docx"
+	"gozero-rag/internal/rag_core/transformer/agentic"
+	"gozero-rag/internal/rag_core/transformer/parentchild"
+	"gozero-rag/internal/rag_core/transformer/semantic"
 	"gozero-rag/internal/rag_core/transformer/structure"
 	"gozero-rag/internal/rag_core/types"
 	"gozero-rag/internal/xerr"
 
 	"github.com/cloudwego/eino-ext/components/document/transformer/splitter/markdown"
+	"github.com/cloudwego/eino-ext/components/document/transformer/splitter/recursive"
 	"github.com/cloudwego/eino/components/document"
 	"github.com/cloudwego/eino/schema"
 	"github.com/zeromicro/go-zero/core/logx"
@@ -23,36 +28,91 @@ func NewTransformer(ctx context.Context) (document.Transformer, error) {
 }
 
 func (t *Transformer) getTransformer(ctx context.Context, conf types.ProcessConfig, doc *schema.Document) (document.Transformer, error) {
-	if docx.IsMarkdown(doc) {
-		// 使用markdown transformer
-		mdSplitter, err := markdown.NewHeaderSplitter(ctx, &markdown.HeaderConfig{
-			Headers: map[string]string{
-				"#":    "h1", // 一级标题
-				"##":   "h2", // 二级标题
-				"###":  "h3", // 三级标题
-				"####": "h4", // 四级标题
-
-			},
-			TrimHeaders: false, // 是否在输出中保留标题行
+	switch conf.ChunkMethod {
+	case parser.ChunkMethodRecursive:
+		logx.Infof("使用 recursive transformer, doc: %s", doc.ID)
+		return recursive.NewSplitter(ctx, &recursive.Config{
+			ChunkSize:   conf.MaxChunkLength,
+			OverlapSize: conf.ChunkOverlap,
+			Separators:  conf.Separators,
+			KeepType:    recursive.KeepTypeNone,
 		})
-		if err != nil {
-			return nil, err
-		}
+	case parser.ChunkMethodStructure:
+		logx.Infof("使用 structure transformer (heuristic), doc: %s", doc.ID)
+		return newStructureSplitter(ctx, conf)
+	case parser.ChunkMethodMarkdown:
 		logx.Infof("使用 markdown transformer, doc: %s", doc.ID)
-		return mdSplitter, nil
+		return newMarkdownSplitter(ctx)
+	case parser.ChunkMethodAgentic:
+		return t.getAgenticTransformer(ctx, conf, doc)
+	case parser.ChunkMethodSemantic:
+		return t.getSemanticTransformer(ctx, conf, doc)
+	}
+
+	// 未指定分片策略时按文档类型自动选择
+	if docx.IsMarkdown(doc) {
+		logx.Infof("使用 markdown transformer, doc: %s", doc.ID)
+		return newMarkdownSplitter(ctx)
 	}
 
-	// 判断使用哪种类型的transform, recursive 或 agentic
-	// 如果要使用 agentic 要先加个配置，先不加了
 	// 使用 StructureSplitter (支持启发式标题检测)
-	structureSplitter, err := structure.NewStructureSplitter(ctx, &structure.Config{
+	logx.Infof("使用 structure transformer (heuristic), doc: %s", doc.ID)
+	return newStructureSplitter(ctx, conf)
+}
+
+// getAgenticTransformer LLM 分片, 模型不可用、预算不足或调用失败时回退到 structure 分片
+func (t *Transformer) getAgenticTransformer(ctx context.Context, conf types.ProcessConfig, doc *schema.Document) (document.Transformer, error) {
+	structureSplitter, err := newStructureSplitter(ctx, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	agenticSplitter, err := agentic.NewAgenticSplitterFromConfig(ctx, conf)
+	if err != nil {
+		logx.Errorf("创建 agentic transformer 失败, 回退到 structure transformer, doc: %s, err: %v", doc.ID, err)
+		return structureSplitter, nil
+	}
+
+	logx.Infof("使用 agentic transformer, doc: %s", doc.ID)
+	return agenticSplitter.WithFallback(structureSplitter), nil
+}
+
+// getSemanticTransformer 语义分片, Embedding 模型不可用或调用失败时回退到 structure 分片
+func (t *Transformer) getSemanticTransformer(ctx context.Context, conf types.ProcessConfig, doc *schema.Document) (document.Transformer, error) {
+	structureSplitter, err := newStructureSplitter(ctx, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	semanticSplitter, err := semantic.NewSemanticSplitterFromConfig(ctx, conf, structureSplitter)
+	if err != nil {
+		logx.Errorf("创建 semantic transformer 失败, 回退到 structure transformer, doc: %s, err: %v", doc.ID, err)
+		return structureSplitter, nil
+	}
+
+	logx.Infof("使用 semantic transformer, doc: %s", doc.ID)
+	return semanticSplitter, nil
+}
+
+func newStructureSplitter(ctx context.Context, conf types.ProcessConfig) (document.Transformer, error) {
+	return structure.NewStructureSplitter(ctx, &structure.Config{
 		MaxChunkSize: conf.MaxChunkLength,
 		OverlapSize:  conf.ChunkOverlap,
 		Separators:   conf.Separators,
 	})
+}
 
-	logx.Infof("使用 structure transformer (heuristic), doc: %s", doc.ID)
-	return structureSplitter, err
+func newMarkdownSplitter(ctx context.Context) (document.Transformer, error) {
+	return markdown.NewHeaderSplitter(ctx, &markdown.HeaderConfig{
+		Headers: map[string]string{
+			"#":    "h1", // 一级标题
+			"##":   "h2", // 二级标题
+			"###":  "h3", // 三级标题
+			"####": "h4", // 四级标题
+
+		},
+		TrimHeaders: false, // 是否在输出中保留标题行
+	})
 }
 
 func (t *Transformer) Transform(ctx context.Context, src []*schema.Document, opts ...document.TransformerOption) ([]*schema.Document, error) {
@@ -67,6 +127,24 @@ func (t *Transformer) Transform(ctx context.Context, src []*schema.Document, opt
 
 	var result []*schema.Document
 
+	// 解析模板: 按 parser_id 选择分片策略, 模板需要整体处理一批文档 (如表格行聚合), 不能逐个文档切分
+	if tpl, ok := templateRegistry[conf.ParserId]; ok {
+		var docs []*schema.Document
+		docs, src = splitTemplateDocs(tpl, src)
+		if len(docs) > 0 {
+			splitter, err := tpl.newTransformer(ctx, conf)
+			if err != nil {
+				return nil, err
+			}
+			logx.Infof("使用 %s 模板 transformer, 文档数: %d", conf.ParserId, len(docs))
+			chunks, err := splitter.Transform(ctx, docs)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, chunks...)
+		}
+	}
+
 	// 2. 遍历文档处理 (因为不同文档可能有不同类型，though通常一批是一样的)
 	for _, doc := range src {
 		// 识别文档类型，这里简单通过 ContentType 或 扩展名判断，或者默认 Recursive
@@ -93,5 +171,18 @@ func (t *Transformer) Transform(ctx context.Context, src []*schema.Document, opt
 		result = append(result, chunks...)
 	}
 
+	// 3. 父子分片: 以上结果作为父块, 继续切分为子块
+	if conf.ParentChild.Enable {
+		pcSplitter, err := parentchild.NewParentChildSplitter(ctx, &parentchild.Config{
+			ChildChunkSize: conf.ParentChild.ChildChunkSize,
+			ChildOverlap:   conf.ParentChild.ChildOverlap,
+			Separators:     conf.Separators,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return pcSplitter.Transform(ctx, result)
+	}
+
 	return result, nil
 }
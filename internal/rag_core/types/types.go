@@ -1,12 +1,31 @@
 package types
 
+import "context"
+
 type ProcessRequest struct {
 	URI         string        // 可以是文件路径，也可以是web url
 	IndexConfig ProcessConfig // 索引相关的配置
 }
+
+// ParentChildConfig 父子分片配置
+type ParentChildConfig struct {
+	Enable         bool
+	ChildChunkSize int // 子块最大字符数
+	ChildOverlap   int
+}
+
+// IndexConfigPreCleanRule 文本预清洗规则, 字段含义见 parser.PreCleanConfig
 type IndexConfigPreCleanRule struct {
-	CleanWhitespace  bool
-	RemoveUrlsEmails bool
+	CleanWhitespace   bool
+	RemoveUrlsEmails  bool
+	NormalizeWidth    bool
+	StripControlChars bool
+	RemovePatterns    []string
+	MaskPhone         bool
+	MaskIdCard        bool
+	MaskEmail         bool
+	RemoveBoilerplate bool
+	BoilerplateLines  []string
 }
 
 type ProcessLlmConfig struct {
@@ -23,15 +42,31 @@ type ProcessLlmConfig struct {
 	ChatModelName string
 }
 
+// LlmBudget 按知识文档计数的 LLM 调用预算, 大文档拆分的多个子任务共享同一文档的计数
+type LlmBudget interface {
+	// Reserve 已用次数加 n 不超过 limit 时预占 n 次并返回 true, 否则不扣减返回 false
+	Reserve(ctx context.Context, n, limit int) (bool, error)
+}
+
 type ProcessConfig struct {
 	KnowledgeName string // 知识库名称
+	ParserId      string // 解析器ID, 为空时按 general 处理
 	EnableQACheck bool   // 是否启用 QA 检查
 	QaNum         int
+	QaProgress    func(done, total int) // QA 生成进度回调, 可为空
 
 	Separators     []string
 	ChunkOverlap   int
 	MaxChunkLength int
 	PreCleanRule   IndexConfigPreCleanRule
+	Template       any // 解析模板专属配置 (parser.ParserConfigXxx), 由对应模板的 transformer 读取
+
+	ChunkMethod        string    // 分片策略, 见 parser.ChunkMethodXxx, 为空时按文档类型自动选择
+	AgenticMaxLlmCalls int       // agentic 分片单个文档的 LLM 调用上限, <=0 使用默认值
+	AgenticLlmBudget   LlmBudget // agentic 分片按文档共享的调用计数, 为空时只在单次切片内共享
+	SemanticPercentile int       // semantic 分片的相似度百分位阈值, <=0 使用默认值
+
+	ParentChild ParentChildConfig
 
 	LlmConfig ProcessLlmConfig
 }
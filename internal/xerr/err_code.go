@@ -46,12 +46,14 @@ const (
 	UserApiAddError              uint32 = 400004 // 添加API配置失败
 
 	// KnowledgeError 知识库相关错误码 (5xx)
-	KnowledgeBaseNotFoundError uint32 = 500001 // 知识库不存在
-	KnowledgeDocUploadError    uint32 = 500002 // 文档上传失败
-	KnowledgeDocTypeNotSupport uint32 = 500003 // 不支持的文档类型
-	KnowledgeDocTooLargeError  uint32 = 500004 // 文档大小超过限制
-	KnowledgeDocNotFoundError  uint32 = 500005 // 文档不存在
-	KnowledgeDocSaveError      uint32 = 500006 // 文档保存失败
+	KnowledgeBaseNotFoundError   uint32 = 500001 // 知识库不存在
+	KnowledgeDocUploadError      uint32 = 500002 // 文档上传失败
+	KnowledgeDocTypeNotSupport   uint32 = 500003 // 不支持的文档类型
+	KnowledgeDocTooLargeError    uint32 = 500004 // 文档大小超过限制
+	KnowledgeDocNotFoundError    uint32 = 500005 // 文档不存在
+	KnowledgeDocSaveError        uint32 = 500006 // 文档保存失败
+	KnowledgeChunkNotFoundError  uint32 = 500007 // 切片不存在
+	CommunityReportNotFoundError uint32 = 500008 // 社区报告不存在
 
 	// VectorStoreError 向量存储相关错误码 (6xx)
 	VectorStoreError           uint32 = 600001 // 内部错误
@@ -105,6 +107,8 @@ func init() {
 	message[KnowledgeDocTooLargeError] = "文档大小超过限制,请上传小于50MB的文件"
 	message[KnowledgeDocNotFoundError] = "文档不存在"
 	message[KnowledgeDocSaveError] = "文档保存失败"
+	message[KnowledgeChunkNotFoundError] = "切片不存在"
+	message[CommunityReportNotFoundError] = "社区报告不存在"
 
 	// 向量存储相关错误消息 (6xx)
 	message[VectorStoreError] = "向量存储内部错误"
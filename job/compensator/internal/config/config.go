@@ -3,6 +3,8 @@ package config
 import (
 	"github.com/zeromicro/go-queue/kq"
 	"github.com/zeromicro/go-zero/core/stores/cache"
+
+	commonconf "gozero-rag/internal/config"
 )
 
 type Config struct {
@@ -11,6 +13,9 @@ type Config struct {
 	}
 	Cache cache.CacheConf
 
+	// 对象存储, 用于清理过期的分片上传
+	Oss commonconf.OssConf
+
 	// Kafka 配置
 	KqPusherConf kq.KqConf
 
@@ -0,0 +1,60 @@
+package logic
+
+import (
+	"context"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"gozero-rag/internal/oss"
+	"gozero-rag/job/compensator/internal/svc"
+)
+
+// UploadGC 清理过期的直传分片上传
+// 直传会话过期后客户端无法再完成上传, 存储端已上传的分片会一直占用空间, 需要主动中止
+type UploadGC struct {
+	svcCtx *svc.ServiceContext
+}
+
+// NewUploadGC 创建分片上传清理任务
+func NewUploadGC(svcCtx *svc.ServiceContext) *UploadGC {
+	return &UploadGC{svcCtx: svcCtx}
+}
+
+// Run 中止已过期的分片上传
+func (g *UploadGC) Run(ctx context.Context) {
+	batchSize := g.svcCtx.Config.Compensator.BatchSize
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+
+	pairs, err := g.svcCtx.RedisClient.ZrangebyscoreWithScoresAndLimitCtx(ctx, oss.PendingUploadsKey, 0, time.Now().UnixMilli(), 0, batchSize)
+	if err != nil {
+		logx.Errorf("[UploadGC] 查询过期分片上传失败: %v", err)
+		return
+	}
+
+	for _, pair := range pairs {
+		upload, err := oss.ParsePendingUpload(pair.Key)
+		if err != nil {
+			logx.Errorf("[UploadGC] 登记信息损坏, 直接移除: member=%s, err=%v", pair.Key, err)
+			g.remove(ctx, pair.Key)
+			continue
+		}
+
+		// 上传任务已完成或已中止时返回 ErrObjectNotFound, 同样视为清理完成
+		err = g.svcCtx.OssClient.AbortMultipartUpload(ctx, upload.Bucket, upload.Key, upload.UploadId)
+		if err != nil && err != oss.ErrObjectNotFound {
+			logx.Errorf("[UploadGC] 中止分片上传失败: key=%s, upload_id=%s, err=%v", upload.Key, upload.UploadId, err)
+			continue
+		}
+		g.remove(ctx, pair.Key)
+		logx.Infof("[UploadGC] 已中止过期分片上传: key=%s, upload_id=%s", upload.Key, upload.UploadId)
+	}
+}
+
+func (g *UploadGC) remove(ctx context.Context, member string) {
+	if _, err := g.svcCtx.RedisClient.ZremCtx(ctx, oss.PendingUploadsKey, member); err != nil {
+		logx.Errorf("[UploadGC] 移除登记信息失败: member=%s, err=%v", member, err)
+	}
+}
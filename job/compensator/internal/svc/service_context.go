@@ -1,22 +1,40 @@
 package svc
 
 import (
+	"github.com/zeromicro/go-zero/core/stores/redis"
 	"github.com/zeromicro/go-zero/core/stores/sqlx"
 
 	"gozero-rag/internal/model/local_message"
+	"gozero-rag/internal/oss"
 	"gozero-rag/job/compensator/internal/config"
 )
 
 type ServiceContext struct {
 	Config            config.Config
 	LocalMessageModel local_message.LocalMessageModel
+	RedisClient       *redis.Redis
+	OssClient         oss.Client
 }
 
 func NewServiceContext(c config.Config) *ServiceContext {
 	sqlConn := sqlx.NewMysql(c.Mysql.DataSource)
 
+	rdb := redis.MustNewRedis(redis.RedisConf{
+		Host: c.Cache[0].Host,
+		Type: c.Cache[0].Type,
+		User: c.Cache[0].User,
+		Pass: c.Cache[0].Pass,
+	})
+
+	ossClient, err := oss.NewClient(c.Oss)
+	if err != nil {
+		panic(err)
+	}
+
 	return &ServiceContext{
 		Config:            c,
 		LocalMessageModel: local_message.NewLocalMessageModel(sqlConn),
+		RedisClient:       rdb,
+		OssClient:         ossClient,
 	}
 }
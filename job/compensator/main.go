@@ -33,6 +33,7 @@ func main() {
 
 	svcCtx := svc.NewServiceContext(c)
 	compensator := logic.NewCompensator(svcCtx)
+	uploadGC := logic.NewUploadGC(svcCtx)
 
 	// 计算扫描间隔
 	interval := c.Compensator.Interval
@@ -61,6 +62,7 @@ func main() {
 		select {
 		case <-ticker.C:
 			compensator.Run(ctx)
+			uploadGC.Run(ctx)
 		case <-ctx.Done():
 			logx.Info("[Compensator] 已停止")
 			return
@@ -0,0 +1,32 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package graph
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+	"gozero-rag/restful/rag/internal/logic/graph"
+	"gozero-rag/restful/rag/internal/svc"
+	"gozero-rag/restful/rag/internal/types"
+)
+
+// 获取社区报告列表
+func ListCommunityReportsHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.ListCommunityReportsReq
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := graph.NewListCommunityReportsLogic(r.Context(), svcCtx)
+		resp, err := l.ListCommunityReports(&req)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package knowledge_document
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+	"gozero-rag/restful/rag/internal/logic/knowledge_document"
+	"gozero-rag/restful/rag/internal/svc"
+	"gozero-rag/restful/rag/internal/types"
+)
+
+// 取消直传会话
+func AbortUploadSessionHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AbortUploadSessionReq
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := knowledge_document.NewAbortUploadSessionLogic(r.Context(), svcCtx)
+		resp, err := l.AbortUploadSession(&req)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package knowledge_document
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"gozero-rag/internal/response"
+	"gozero-rag/restful/rag/internal/logic/knowledge_document"
+	"gozero-rag/restful/rag/internal/svc"
+	"gozero-rag/restful/rag/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logc"
+	"github.com/zeromicro/go-zero/core/threading"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// 心跳间隔, 避免空闲连接被代理断开
+const indexProgressHeartbeat = 30 * time.Second
+
+// 订阅知识库文档索引进度
+func IndexProgressHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.IndexProgressReq
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		// 权限校验和订阅在推送开始前同步完成, 失败时按普通请求返回错误
+		l := knowledge_document.NewIndexProgressLogic(r.Context(), svcCtx)
+		if err := l.Subscribe(&req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		client := make(chan *types.IndexProgressEvent, 16)
+		var streamErr error
+		threading.GoSafeCtx(r.Context(), func() {
+			defer close(client)
+			streamErr = l.IndexProgress(&req, client)
+		})
+
+		ticker := time.NewTicker(indexProgressHeartbeat)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case data, ok := <-client:
+				if !ok {
+					// 推送开始后出错, 以 error 事件通知前端
+					if streamErr != nil {
+						_, body := response.ErrHandler(svcCtx.Config.Name)(r.Context(), streamErr)
+						writeIndexProgressError(w, body)
+					}
+					return
+				}
+				output, err := json.Marshal(data)
+				if err != nil {
+					logc.Errorw(r.Context(), "IndexProgressHandler", logc.Field("error", err))
+					continue
+				}
+
+				if _, err := fmt.Fprintf(w, "event: progress\ndata: %s\n\n", string(output)); err != nil {
+					logc.Errorw(r.Context(), "IndexProgressHandler", logc.Field("error", err))
+					return
+				}
+				if flusher, ok := w.(http.Flusher); ok {
+					flusher.Flush()
+				}
+			case <-ticker.C:
+				if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+					return
+				}
+				if flusher, ok := w.(http.Flusher); ok {
+					flusher.Flush()
+				}
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}
+}
+
+// writeIndexProgressError 错误事件与普通接口的错误响应格式一致
+func writeIndexProgressError(w http.ResponseWriter, body any) {
+	output, err := json.Marshal(body)
+	if err != nil {
+		return
+	}
+	if _, err := fmt.Fprintf(w, "event: error\ndata: %s\n\n", string(output)); err != nil {
+		return
+	}
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,46 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package knowledge_document
+
+import (
+	"net/http"
+
+	"gozero-rag/internal/xerr"
+	"gozero-rag/restful/rag/internal/logic/knowledge_document"
+	"gozero-rag/restful/rag/internal/svc"
+	"gozero-rag/restful/rag/internal/types"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// 上传文档新版本
+func UploadDocumentVersionHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.UploadDocumentVersionReq
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		err := r.ParseMultipartForm(100 << 20) // 100MB 最大内存
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		files := r.MultipartForm.File["file"]
+		if len(files) != 1 {
+			httpx.ErrorCtx(r.Context(), w, xerr.NewErrCodeMsg(xerr.BadRequest, "新版本只能上传一个文件"))
+			return
+		}
+
+		l := knowledge_document.NewUploadDocumentVersionLogic(r.Context(), svcCtx)
+		resp, err := l.UploadDocumentVersion(&req, files[0])
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
@@ -81,6 +81,18 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/:kb_id/graph",
 				Handler: graph.GetKnowledgeGraphHandler(serverCtx),
 			},
+			{
+				// 获取社区报告列表
+				Method:  http.MethodGet,
+				Path:    "/:kb_id/graph/communities",
+				Handler: graph.ListCommunityReportsHandler(serverCtx),
+			},
+			{
+				// 获取社区报告详情
+				Method:  http.MethodGet,
+				Path:    "/:kb_id/graph/communities/:id",
+				Handler: graph.GetCommunityReportHandler(serverCtx),
+			},
 			{
 				// 搜索图谱节点
 				Method:  http.MethodGet,
@@ -161,6 +173,12 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/knowledge_document/:id",
 				Handler: knowledge_document.DeleteKnowledgeDocumentHandler(serverCtx),
 			},
+			{
+				// 取消文档索引
+				Method:  http.MethodPost,
+				Path:    "/knowledge_document/:id/cancel",
+				Handler: knowledge_document.CancelDocumentIndexHandler(serverCtx),
+			},
 			{
 				// 获取文档切片列表
 				Method:  http.MethodGet,
@@ -173,27 +191,149 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/knowledge_document/:id/parser_config",
 				Handler: knowledge_document.UpdateDocumentParserConfigHandler(serverCtx),
 			},
+			{
+				// 暂停文档索引
+				Method:  http.MethodPost,
+				Path:    "/knowledge_document/:id/pause",
+				Handler: knowledge_document.PauseDocumentIndexHandler(serverCtx),
+			},
+			{
+				// 预览文档分片
+				Method:  http.MethodPost,
+				Path:    "/knowledge_document/:id/preview",
+				Handler: knowledge_document.PreviewKnowledgeDocumentHandler(serverCtx),
+			},
+			{
+				// 获取文档 QA 列表
+				Method:  http.MethodGet,
+				Path:    "/knowledge_document/:id/qa",
+				Handler: knowledge_document.ListDocumentQaHandler(serverCtx),
+			},
+			{
+				// 获取文档质量报告
+				Method:  http.MethodGet,
+				Path:    "/knowledge_document/:id/quality_report",
+				Handler: knowledge_document.DocumentQualityReportHandler(serverCtx),
+			},
+			{
+				// 恢复文档索引
+				Method:  http.MethodPost,
+				Path:    "/knowledge_document/:id/resume",
+				Handler: knowledge_document.ResumeDocumentIndexHandler(serverCtx),
+			},
 			{
 				// 重试/重新解析文档
 				Method:  http.MethodPost,
 				Path:    "/knowledge_document/:id/retry",
 				Handler: knowledge_document.RetryDocumentHandler(serverCtx),
 			},
+			{
+				// 获取文档版本历史
+				Method:  http.MethodGet,
+				Path:    "/knowledge_document/:id/versions",
+				Handler: knowledge_document.ListDocumentVersionsHandler(serverCtx),
+			},
+			{
+				// 上传文档新版本
+				Method:  http.MethodPost,
+				Path:    "/knowledge_document/:id/versions",
+				Handler: knowledge_document.UploadDocumentVersionHandler(serverCtx),
+			},
+			{
+				// 回滚到指定版本
+				Method:  http.MethodPost,
+				Path:    "/knowledge_document/:id/versions/:version/rollback",
+				Handler: knowledge_document.RollbackDocumentVersionHandler(serverCtx),
+			},
 			{
 				// 批量解析文档
 				Method:  http.MethodPost,
 				Path:    "/knowledge_document/batch_parse",
 				Handler: knowledge_document.BatchParseDocumentHandler(serverCtx),
 			},
+			{
+				// 重新生成切片 QA
+				Method:  http.MethodPost,
+				Path:    "/knowledge_document/chunks/:id/qa/regenerate",
+				Handler: knowledge_document.RegenerateChunkQaHandler(serverCtx),
+			},
+			{
+				// 查询租户下内容重复的文档
+				Method:  http.MethodGet,
+				Path:    "/knowledge_document/duplicates",
+				Handler: knowledge_document.ListDuplicateDocumentsHandler(serverCtx),
+			},
+			{
+				// 编辑 QA
+				Method:  http.MethodPut,
+				Path:    "/knowledge_document/qa/:id",
+				Handler: knowledge_document.UpdateDocumentQaHandler(serverCtx),
+			},
+			{
+				// 删除 QA
+				Method:  http.MethodDelete,
+				Path:    "/knowledge_document/qa/:id",
+				Handler: knowledge_document.DeleteDocumentQaHandler(serverCtx),
+			},
+			{
+				// 审核通过 QA
+				Method:  http.MethodPost,
+				Path:    "/knowledge_document/qa/:id/approve",
+				Handler: knowledge_document.ApproveDocumentQaHandler(serverCtx),
+			},
 			{
 				// 上传文档
 				Method:  http.MethodPost,
 				Path:    "/knowledge_document/upload",
 				Handler: knowledge_document.UploadDocumentHandler(serverCtx),
 			},
+			{
+				// 创建直传会话
+				Method:  http.MethodPost,
+				Path:    "/knowledge_document/upload_session",
+				Handler: knowledge_document.CreateUploadSessionHandler(serverCtx),
+			},
+			{
+				// 查询直传会话
+				Method:  http.MethodGet,
+				Path:    "/knowledge_document/upload_session/:session_id",
+				Handler: knowledge_document.GetUploadSessionHandler(serverCtx),
+			},
+			{
+				// 取消直传会话
+				Method:  http.MethodDelete,
+				Path:    "/knowledge_document/upload_session/:session_id",
+				Handler: knowledge_document.AbortUploadSessionHandler(serverCtx),
+			},
+			{
+				// 完成直传并创建文档
+				Method:  http.MethodPost,
+				Path:    "/knowledge_document/upload_session/:session_id/complete",
+				Handler: knowledge_document.CompleteUploadSessionHandler(serverCtx),
+			},
+			{
+				// 获取分片上传地址
+				Method:  http.MethodPost,
+				Path:    "/knowledge_document/upload_session/:session_id/part_urls",
+				Handler: knowledge_document.GetUploadPartUrlsHandler(serverCtx),
+			},
+		},
+		rest.WithJwt(serverCtx.Config.Auth.AccessSecret),
+		rest.WithPrefix("/v1"),
+	)
+
+	server.AddRoutes(
+		[]rest.Route{
+			{
+				// 订阅知识库文档索引进度
+				Method:  http.MethodGet,
+				Path:    "/knowledge_document/index_progress",
+				Handler: knowledge_document.IndexProgressHandler(serverCtx),
+			},
 		},
 		rest.WithJwt(serverCtx.Config.Auth.AccessSecret),
 		rest.WithPrefix("/v1"),
+		rest.WithSSE(),
 	)
 
 	server.AddRoutes(
@@ -0,0 +1,118 @@
+package chat
+
+import (
+	"sync"
+
+	"gozero-rag/internal/graphrag/query"
+	graphtypes "gozero-rag/internal/graphrag/types"
+	"gozero-rag/internal/rag_core/retriever"
+	"gozero-rag/internal/xerr"
+	"gozero-rag/restful/rag/internal/types"
+
+	"github.com/cloudwego/eino-ext/components/model/openai"
+	"github.com/cloudwego/eino/schema"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// globalSearch 对每个知识库的社区报告做 map-reduce, 使用对话模型完成 map 阶段
+// 单个知识库失败 (如尚未生成社区报告) 时跳过
+func (l *ChatLogic) globalSearch(req *types.ChatReq) ([]types.ChatGraphContext, error) {
+	chatApi, err := l.svcCtx.UserApiModel.FindOne(l.ctx, req.ChatModelId)
+	if err != nil {
+		return nil, xerr.NewInternalErrMsg("获取对话模型失败")
+	}
+	llm, err := openai.NewChatModel(l.ctx, &openai.ChatModelConfig{
+		APIKey:  chatApi.ApiKey,
+		BaseURL: chatApi.BaseUrl,
+		Model:   chatApi.ModelName,
+	})
+	if err != nil {
+		logx.Errorf("init chat model failed: %v", err)
+		return nil, xerr.NewInternalErrMsg("初始化对话模型失败")
+	}
+	searcher := query.NewGlobalSearcher(l.svcCtx.CommunityModel, llm)
+
+	var wg sync.WaitGroup
+	results := make([]*types.ChatGraphContext, len(req.KnowledgeBaseIds))
+	for i, kbId := range req.KnowledgeBaseIds {
+		wg.Add(1)
+		go func(i int, kbId string) {
+			defer wg.Done()
+			result, err := searcher.Search(l.ctx, kbId, req.Message)
+			if err != nil {
+				logx.Errorf("global search失败, kb:%s, err:%v", kbId, err)
+				return
+			}
+			if len(result.Points) == 0 {
+				return
+			}
+			results[i] = toGlobalGraphContext(kbId, result)
+		}(i, kbId)
+	}
+	wg.Wait()
+
+	contexts := make([]types.ChatGraphContext, 0, len(results))
+	for _, c := range results {
+		if c != nil {
+			contexts = append(contexts, *c)
+		}
+	}
+	return contexts, nil
+}
+
+func toGlobalGraphContext(kbId string, result *query.GlobalResult) *types.ChatGraphContext {
+	reports := make([]types.ChatGraphReport, 0, len(result.Reports))
+	for _, r := range result.Reports {
+		reports = append(reports, types.ChatGraphReport{
+			Id:          r.Id,
+			CommunityId: r.CommunityId,
+			Title:       r.Title,
+			Rating:      r.Rating,
+		})
+	}
+	points := make([]types.ChatGraphKeyPoint, 0, len(result.Points))
+	for _, p := range result.Points {
+		points = append(points, types.ChatGraphKeyPoint{
+			Description: p.Description,
+			Score:       p.Score,
+		})
+	}
+	return &types.ChatGraphContext{
+		KnowledgeBaseId: kbId,
+		Mode:            query.ModeGlobal,
+		Content:         result.Context,
+		Reports:         reports,
+		KeyPoints:       points,
+	}
+}
+
+// toLocalGraphContext 将 retriever 返回的图谱上下文文档转换为 SSE 结构
+func toLocalGraphContext(doc *schema.Document) types.ChatGraphContext {
+	kbId, _ := doc.MetaData[retriever.MetaKnowledgeBaseID].(string)
+	entities, _ := doc.MetaData[retriever.MetaGraphEntities].([]graphtypes.Entity)
+	relations, _ := doc.MetaData[retriever.MetaGraphRelations].([]graphtypes.Relation)
+
+	c := types.ChatGraphContext{
+		KnowledgeBaseId: kbId,
+		Mode:            query.ModeLocal,
+		Content:         doc.Content,
+		Entities:        make([]types.ChatGraphEntity, 0, len(entities)),
+		Relations:       make([]types.ChatGraphRelation, 0, len(relations)),
+	}
+	for _, e := range entities {
+		c.Entities = append(c.Entities, types.ChatGraphEntity{
+			Name:        e.Name,
+			Type:        e.Type,
+			Description: e.Description,
+		})
+	}
+	for _, r := range relations {
+		c.Relations = append(c.Relations, types.ChatGraphRelation{
+			Source:      r.SrcId,
+			Target:      r.DstId,
+			Description: r.Description,
+			Weight:      r.Weight,
+		})
+	}
+	return c
+}
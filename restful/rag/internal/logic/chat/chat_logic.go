@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"gozero-rag/internal/graphrag/query"
 	"gozero-rag/internal/model/chat_conversation"
 	"gozero-rag/internal/model/chat_message"
 	"gozero-rag/internal/rag_core/retriever"
@@ -131,11 +132,16 @@ func (l *ChatLogic) Chat(req *types.ChatReq, client chan<- *types.ChatResp) (err
 	// For simplicity, we skip updating message_count in conversation strictly here or do it later.
 
 	// 3. Retrieval
-	docs, err := l.retrieve(req)
+	docs, graphContexts, err := l.retrieve(req)
 	if err != nil {
 		return failTask("检索失败")
 	}
 
+	// 图谱上下文单独推送, 便于前端高亮图谱节点
+	if len(graphContexts) > 0 {
+		sse.SendGraphContext(graphContexts)
+	}
+
 	// 4. Stream Retrieval Results (Citations)
 	// In the new design, we might want to send "citation" event.
 	// But legacy logic sent text. Let's send Citation event if possible, or Text as before?
@@ -254,19 +260,31 @@ func (l *ChatLogic) incrementMessageCount(convId string) error {
 	return l.svcCtx.ChatConversationModel.Update(l.ctx, conv)
 }
 
-func (l *ChatLogic) retrieve(req *types.ChatReq) (docs []*schema.Document, err error) {
+// retrieve 检索知识库, 返回分片及图谱上下文 (local / global / graph 模式)
+func (l *ChatLogic) retrieve(req *types.ChatReq) (docs []*schema.Document, graphContexts []types.ChatGraphContext, err error) {
 	if len(req.KnowledgeBaseIds) == 0 {
-		return []*schema.Document{}, nil
+		return []*schema.Document{}, nil, nil
+	}
+
+	if req.ChatRetrieveConfig.Mode == query.ModeGlobal {
+		graphContexts, err = l.globalSearch(req)
+		return []*schema.Document{}, graphContexts, err
 	}
 
 	embMap, err := l.findEmbeddingConfigByIds(req.KnowledgeBaseIds)
 	if err != nil {
-		return nil, xerr.NewInternalErrMsg("获取emb模型失败")
+		return nil, nil, xerr.NewInternalErrMsg("获取emb模型失败")
 	}
 
 	rerankConfig, err := l.svcCtx.UserApiModel.FindOne(l.ctx, req.ChatRetrieveConfig.RerankModelId)
 	if err != nil {
-		return nil, xerr.NewInternalErrMsg("获取rerank模型失败")
+		return nil, nil, xerr.NewInternalErrMsg("获取rerank模型失败")
+	}
+
+	// local 检索由 retriever 的 graph 模式实现: 分片混合检索 + 实体邻域
+	mode := req.ChatRetrieveConfig.Mode
+	if mode == query.ModeLocal {
+		mode = retriever.RetrieveModeGraph
 	}
 
 	rerankModelConfig := retriever.ModelConfig{
@@ -296,7 +314,7 @@ func (l *ChatLogic) retrieve(req *types.ChatReq) (docs []*schema.Document, err e
 				TopK:                 req.ChatRetrieveConfig.TopK,
 				EmbeddingModelConfig: embConfig,
 				RerankModelConfig:    rerankModelConfig,
-				Mode:                 req.ChatRetrieveConfig.Mode,
+				Mode:                 mode,
 				ScoreThreshold:       req.ChatRetrieveConfig.Score,
 				HybridRankType:       req.ChatRetrieveConfig.RerankMode,
 				VectorWeight:         req.ChatRetrieveConfig.RerankVectorWeight,
@@ -316,5 +334,15 @@ func (l *ChatLogic) retrieve(req *types.ChatReq) (docs []*schema.Document, err e
 	}
 
 	wg.Wait()
-	return docs, nil
+
+	// 图谱上下文不作为引用分片返回
+	chunkDocs := make([]*schema.Document, 0, len(docs))
+	for _, doc := range docs {
+		if retriever.IsGraphContext(doc) {
+			graphContexts = append(graphContexts, toLocalGraphContext(doc))
+			continue
+		}
+		chunkDocs = append(chunkDocs, doc)
+	}
+	return chunkDocs, graphContexts, nil
 }
@@ -0,0 +1,49 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package graph
+
+import (
+	"context"
+	"errors"
+
+	"gozero-rag/internal/model/graph"
+	"gozero-rag/internal/xerr"
+	"gozero-rag/restful/rag/internal/svc"
+	"gozero-rag/restful/rag/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetCommunityReportLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 获取社区报告详情
+func NewGetCommunityReportLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetCommunityReportLogic {
+	return &GetCommunityReportLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetCommunityReportLogic) GetCommunityReport(req *types.GetCommunityReportReq) (resp *types.CommunityReportInfo, err error) {
+	report, err := l.svcCtx.CommunityModel.FindOne(l.ctx, req.Id)
+	if err != nil {
+		if errors.Is(err, graph.ErrCommunityNotFound) {
+			return nil, xerr.NewErrCode(xerr.CommunityReportNotFoundError)
+		}
+		l.Errorf("GetCommunityReport failed: id=%s, err=%v", req.Id, err)
+		return nil, xerr.NewInternalErrMsg("查询社区报告失败")
+	}
+	// 报告不属于路径中的知识库时视为不存在
+	if report.KbId != req.KbId {
+		return nil, xerr.NewErrCode(xerr.CommunityReportNotFoundError)
+	}
+
+	info := toCommunityReportInfo(report)
+	return &info, nil
+}
@@ -0,0 +1,69 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package graph
+
+import (
+	"context"
+
+	"gozero-rag/internal/model/graph"
+	"gozero-rag/internal/xerr"
+	"gozero-rag/restful/rag/internal/svc"
+	"gozero-rag/restful/rag/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListCommunityReportsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 获取社区报告列表
+func NewListCommunityReportsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListCommunityReportsLogic {
+	return &ListCommunityReportsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ListCommunityReports 按评分从高到低分页查询知识库的社区报告
+func (l *ListCommunityReportsLogic) ListCommunityReports(req *types.ListCommunityReportsReq) (resp *types.ListCommunityReportsResp, err error) {
+	reports, total, err := l.svcCtx.CommunityModel.List(l.ctx, req.KbId, req.Page, req.PageSize)
+	if err != nil {
+		l.Errorf("ListCommunityReports failed: kb=%s, err=%v", req.KbId, err)
+		return nil, xerr.NewInternalErrMsg("查询社区报告失败")
+	}
+
+	list := make([]types.CommunityReportInfo, 0, len(reports))
+	for _, r := range reports {
+		list = append(list, toCommunityReportInfo(r))
+	}
+	return &types.ListCommunityReportsResp{
+		Total: total,
+		List:  list,
+	}, nil
+}
+
+func toCommunityReportInfo(r *graph.CommunityReport) types.CommunityReportInfo {
+	findings := make([]types.CommunityFindingInfo, 0, len(r.Findings))
+	for _, f := range r.Findings {
+		findings = append(findings, types.CommunityFindingInfo{
+			Summary:     f.Summary,
+			Explanation: f.Explanation,
+		})
+	}
+	return types.CommunityReportInfo{
+		Id:                r.Id,
+		CommunityId:       r.CommunityId,
+		Title:             r.Title,
+		Summary:           r.Summary,
+		Rating:            r.Rating,
+		RatingExplanation: r.RatingExplanation,
+		Findings:          findings,
+		Entities:          r.Entities,
+		UpdatedAt:         r.UpdatedAt,
+	}
+}
@@ -0,0 +1,9 @@
+package knowledge_base
+
+// boolToInt 布尔开关转换为数据库中的 tinyint
+func boolToInt(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -100,6 +100,8 @@ func (l *CreateKnowledgeBaseLogic) CreateKnowledgeBase(req *types.CreateKnowledg
 		ChunkNum:               0,
 		SimilarityThreshold:    req.SimilarityThreshold,
 		VectorSimilarityWeight: req.VectorSimilarityWeight,
+		DedupPolicy:            req.DedupPolicy,
+		AutoParse:              boolToInt(req.AutoParse),
 
 		Language:    req.Language,
 		CreatedTime: nowUnix,
@@ -139,6 +141,8 @@ func (l *CreateKnowledgeBaseLogic) CreateKnowledgeBase(req *types.CreateKnowledg
 			SimilarityThreshold:    kb.SimilarityThreshold,
 			VectorSimilarityWeight: kb.VectorSimilarityWeight,
 			Status:                 kb.Status,
+			DedupPolicy:            kb.DedupPolicy,
+			AutoParse:              kb.AutoParse == 1,
 			CreatedTime:            kb.CreatedTime,
 			UpdatedTime:            kb.UpdatedTime,
 		},
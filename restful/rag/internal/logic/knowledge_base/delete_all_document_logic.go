@@ -67,15 +67,34 @@ func (l *DeleteAllDocumentLogic) DeleteAllDocument(req *types.DeleteAllDocumentR
 	// 由于数量可能较多，且错误不影响 DB 删除 (脏数据)，我们可以遍历删除
 	// TODO: 如果数量非常大，应该放入后台任务。此处假设数量在合理范围内 (<1000)
 	var docIds []string
+	paths := make(map[string]bool)
 	for _, doc := range docs {
 		docIds = append(docIds, doc.Id)
 		if doc.StoragePath.Valid && doc.StoragePath.String != "" && doc.SourceType == "local" {
-			// 尝试删除 OSS 文件
-			// 忽略错误，只记录日志
-			err := l.svcCtx.OssClient.RemoveObject(l.ctx, l.svcCtx.Config.Oss.BucketName, doc.StoragePath.String)
-			if err != nil {
-				l.Errorf("Failed to delete oss object %s: %v", doc.StoragePath.String, err)
-			}
+			paths[doc.StoragePath.String] = true
+		}
+	}
+
+	// 经本地消息表异步扣减文档对知识图谱的贡献, 只由这些文档贡献的实体和关系被删除
+	// 先于文件、分片等删除写入, 写入失败时文档数据保持完整
+	if err := l.svcCtx.EnqueueGraphRemoval(l.ctx, kb.TenantId, req.Id, docIds); err != nil {
+		l.Errorf("Failed to enqueue graph removal for kb %s: %v", req.Id, err)
+		return nil, xerr.NewInternalErrMsg("清空文档图谱失败")
+	}
+	// 历史版本文件与当前文件一并删除
+	versions, err := l.svcCtx.DocumentVersionModel.FindListByDocumentIds(l.ctx, docIds)
+	if err != nil {
+		l.Errorf("Failed to find document versions for kb %s: %v", req.Id, err)
+	}
+	for _, v := range versions {
+		if v.StoragePath != "" {
+			paths[v.StoragePath] = true
+		}
+	}
+	for path := range paths {
+		// 忽略错误，只记录日志
+		if err := l.svcCtx.OssClient.RemoveObject(l.ctx, l.svcCtx.Config.Oss.BucketName, path); err != nil {
+			l.Errorf("Failed to delete oss object %s: %v", path, err)
 		}
 	}
 
@@ -89,6 +108,9 @@ func (l *DeleteAllDocumentLogic) DeleteAllDocument(req *types.DeleteAllDocumentR
 	}
 
 	// 6. 数据库删除
+	if err := l.svcCtx.DocumentVersionModel.DeleteByDocumentIds(l.ctx, docIds); err != nil {
+		l.Errorf("Failed to delete document versions for kb %s: %v", req.Id, err)
+	}
 	err = l.svcCtx.KnowledgeDocumentModel.DeleteAllNonIndexingByKbId(l.ctx, req.Id)
 	if err != nil {
 		l.Errorf("Failed to delete docs from db: %v", err)
@@ -2,6 +2,7 @@ package knowledge_base
 
 import (
 	"context"
+	"fmt"
 	"gozero-rag/internal/model/knowledge_base"
 	"gozero-rag/internal/xerr"
 	"gozero-rag/restful/rag/internal/common"
@@ -56,6 +57,23 @@ func (l *DeleteKnowledgeBaseLogic) DeleteKnowledgeBase(req *types.DeleteKnowledg
 		return nil, xerr.NewInternalErrMsg("删除向量数据失败")
 	}
 
+	// 删除知识图谱 (Nebula Space kg_<kbId>、kg_graph 实体、文档贡献) 及社区报告, 失败只记录日志
+	if err := l.svcCtx.GraphStore.DropKnowledgeBase(l.ctx, req.Id); err != nil {
+		logx.Errorf("DropKnowledgeBase GraphStore error: %v", err)
+	}
+	if err := l.svcCtx.CommunityModel.Replace(l.ctx, req.Id, nil); err != nil {
+		logx.Errorf("Replace CommunityModel error: %v", err)
+	}
+
+	// 删除知识库目录下的全部文件 (含历史版本、子任务暂存文件) 及版本记录, 失败只记录日志
+	prefix := fmt.Sprintf("tenant_%s/kb_%s/", kb.TenantId, kb.Id)
+	if err := l.svcCtx.OssClient.RemoveObjectsWithPrefix(l.ctx, l.svcCtx.Config.Oss.BucketName, prefix); err != nil {
+		logx.Errorf("RemoveObjectsWithPrefix OssClient error: %v", err)
+	}
+	if err := l.svcCtx.DocumentVersionModel.DeleteByKnowledgeBaseId(l.ctx, req.Id); err != nil {
+		logx.Errorf("DeleteByKnowledgeBaseId DocumentVersionModel error: %v", err)
+	}
+
 	// 4. Delete Knowledge Documents
 	err = l.svcCtx.KnowledgeDocumentModel.DeleteByKbId(l.ctx, req.Id)
 	if err != nil {
@@ -65,6 +65,8 @@ func (l *GetKnowledgeBaseLogic) GetKnowledgeBase(req *types.GetKnowledgeBaseReq)
 			Status:                 kb.Status,
 			ParserId:               kb.ParserId,
 			ParserConfig:           kb.ParserConfig.String,
+			DedupPolicy:            kb.DedupPolicy,
+			AutoParse:              kb.AutoParse == 1,
 			CreatedTime:            kb.CreatedTime,
 			UpdatedTime:            kb.UpdatedTime,
 		},
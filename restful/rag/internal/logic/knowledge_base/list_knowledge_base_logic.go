@@ -87,6 +87,8 @@ func (l *ListKnowledgeBaseLogic) ListKnowledgeBase(req *types.ListKnowledgeBaseR
 			SimilarityThreshold:    kb.SimilarityThreshold,
 			VectorSimilarityWeight: kb.VectorSimilarityWeight,
 			Status:                 kb.Status,
+			DedupPolicy:            kb.DedupPolicy,
+			AutoParse:              kb.AutoParse == 1,
 			CreatedTime:            kb.CreatedTime,
 			UpdatedTime:            kb.UpdatedTime,
 		}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"gozero-rag/internal/model/knowledge_base"
+	"gozero-rag/internal/rag_core/parser"
 	"gozero-rag/internal/xerr"
 	"gozero-rag/restful/rag/internal/common"
 
@@ -85,6 +86,23 @@ func (l *UpdateKnowledgeBaseLogic) UpdateKnowledgeBase(req *types.UpdateKnowledg
 		kb.ParserConfig = sql.NullString{String: req.ParserConfig, Valid: true}
 		isUpdated = true
 	}
+	if req.DedupPolicy != "" {
+		if !knowledge_base.IsValidDedupPolicy(req.DedupPolicy) {
+			return nil, xerr.NewBadRequestErrMsg("重复文档策略无效: " + req.DedupPolicy)
+		}
+		kb.DedupPolicy = req.DedupPolicy
+		isUpdated = true
+	}
+	if req.AutoParse != nil {
+		kb.AutoParse = boolToInt(*req.AutoParse)
+		isUpdated = true
+	}
+	// 按 parser_id 对应的模板校验配置, 避免索引时才发现配置无效
+	if req.ParserId != "" || req.ParserConfig != "" {
+		if _, err := parser.ParseParserConfig(kb.ParserId, kb.ParserConfig.String); err != nil {
+			return nil, xerr.NewBadRequestErrMsg(err.Error())
+		}
+	}
 
 	if isUpdated {
 		err = l.svcCtx.KnowledgeBaseModel.Update(l.ctx, kb)
@@ -0,0 +1,51 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package knowledge_document
+
+import (
+	"context"
+
+	"gozero-rag/internal/oss"
+
+	"gozero-rag/restful/rag/internal/svc"
+	"gozero-rag/restful/rag/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type AbortUploadSessionLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 取消直传会话
+func NewAbortUploadSessionLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AbortUploadSessionLogic {
+	return &AbortUploadSessionLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// AbortUploadSession 取消直传, 清理已上传的分片或对象
+func (l *AbortUploadSessionLogic) AbortUploadSession(req *types.AbortUploadSessionReq) (resp *types.AbortUploadSessionResp, err error) {
+	session, err := loadUploadSession(l.ctx, l.svcCtx, req.SessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	bucket := l.svcCtx.Config.Oss.BucketName
+	if session.Mode == UploadModeMultipart {
+		err = l.svcCtx.OssClient.AbortMultipartUpload(l.ctx, bucket, session.ObjectKey, session.UploadId)
+	} else {
+		err = l.svcCtx.OssClient.RemoveObject(l.ctx, bucket, session.ObjectKey)
+	}
+	if err != nil && err != oss.ErrObjectNotFound {
+		l.Errorf("AbortUploadSession cleanup failed: session=%s, err=%v", session.SessionId, err)
+	}
+	deleteUploadSession(l.ctx, l.svcCtx, session)
+
+	return &types.AbortUploadSessionResp{}, nil
+}
@@ -0,0 +1,47 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package knowledge_document
+
+import (
+	"context"
+
+	"gozero-rag/internal/model/chunk"
+	"gozero-rag/internal/xerr"
+	"gozero-rag/restful/rag/internal/svc"
+	"gozero-rag/restful/rag/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ApproveDocumentQaLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 审核通过 QA
+func NewApproveDocumentQaLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ApproveDocumentQaLogic {
+	return &ApproveDocumentQaLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ApproveDocumentQa 审核通过的 QA 在重新索引和重新生成时保留
+func (l *ApproveDocumentQaLogic) ApproveDocumentQa(req *types.ApproveDocumentQaReq) (resp *types.ApproveDocumentQaResp, err error) {
+	qa, _, err := findQaWithPermission(l.ctx, l.svcCtx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if qa.QaStatus != chunk.QaStatusApproved {
+		if err := l.svcCtx.ChunkModel.Update(l.ctx, qa.Id, map[string]interface{}{"qa_status": chunk.QaStatusApproved}); err != nil {
+			l.Errorf("ApproveDocumentQa failed: qaId=%s, err=%v", qa.Id, err)
+			return nil, xerr.NewInternalErrMsg("更新 QA 失败")
+		}
+	}
+
+	return &types.ApproveDocumentQaResp{QaStatus: chunk.QaStatusApproved}, nil
+}
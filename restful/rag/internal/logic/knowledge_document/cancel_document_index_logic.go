@@ -0,0 +1,88 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package knowledge_document
+
+import (
+	"context"
+	"time"
+
+	"gozero-rag/internal/indexctl"
+	"gozero-rag/internal/model/knowledge_document"
+	"gozero-rag/internal/xerr"
+
+	"gozero-rag/restful/rag/internal/svc"
+	"gozero-rag/restful/rag/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CancelDocumentIndexLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 取消文档索引
+func NewCancelDocumentIndexLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CancelDocumentIndexLogic {
+	return &CancelDocumentIndexLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// CancelDocumentIndex 取消索引
+// 正在索引的文档只写入取消信号, 由消费者清理本次写入的分片后置为 canceled
+// 进度超过 indexctl.RunningStaleTimeout 未更新时消费者可能已退出, 直接置为 canceled, 残留分片在下次索引时清理
+func (l *CancelDocumentIndexLogic) CancelDocumentIndex(req *types.CancelDocumentIndexReq) (resp *types.CancelDocumentIndexResp, err error) {
+	doc, _, err := findDocumentWithPermission(l.ctx, l.svcCtx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch doc.RunStatus {
+	case knowledge_document.RunStateRunning:
+		if err := l.svcCtx.IndexSignal.Send(l.ctx, doc.Id, indexctl.SignalCancel); err != nil {
+			return nil, xerr.NewInternalErrMsg("发送取消信号失败")
+		}
+		if time.Since(time.UnixMilli(doc.UpdatedTime)) < indexctl.RunningStaleTimeout {
+			return &types.CancelDocumentIndexResp{RunStatus: doc.RunStatus}, nil
+		}
+		ok, err := l.svcCtx.KnowledgeDocumentModel.CompareAndSetRunStatus(l.ctx, doc.Id, knowledge_document.RunStateRunning, knowledge_document.RunStateCanceled, "索引长时间未响应, 已取消")
+		if err != nil {
+			return nil, xerr.NewInternalErrMsg("更新文档状态失败")
+		}
+		if !ok {
+			// 状态已被消费者更新, 以最新状态为准
+			return &types.CancelDocumentIndexResp{RunStatus: doc.RunStatus}, nil
+		}
+		if err := l.svcCtx.IndexCheckpoint.Clear(l.ctx, doc.Id); err != nil {
+			l.Errorf("CancelDocumentIndex clear checkpoint failed: docId=%s, err=%v", doc.Id, err)
+		}
+		return &types.CancelDocumentIndexResp{RunStatus: knowledge_document.RunStateCanceled}, nil
+
+	case knowledge_document.RunStatePending:
+		// 消费者可能已读取到 pending 状态, 同时写入信号; 等待失败重试的文档还需清理检查点
+		if err := l.svcCtx.IndexSignal.Send(l.ctx, doc.Id, indexctl.SignalCancel); err != nil {
+			return nil, xerr.NewInternalErrMsg("发送取消信号失败")
+		}
+		if err := l.svcCtx.IndexCheckpoint.Clear(l.ctx, doc.Id); err != nil {
+			l.Errorf("CancelDocumentIndex clear checkpoint failed: docId=%s, err=%v", doc.Id, err)
+		}
+
+	case knowledge_document.RunStatePaused:
+		// 暂停时尚未写入分片, 只需清理检查点
+		if err := l.svcCtx.IndexCheckpoint.Clear(l.ctx, doc.Id); err != nil {
+			l.Errorf("CancelDocumentIndex clear checkpoint failed: docId=%s, err=%v", doc.Id, err)
+		}
+
+	default:
+		return nil, xerr.NewBadRequestErrMsg("文档当前状态不可取消")
+	}
+
+	if err := l.svcCtx.KnowledgeDocumentModel.UpdateRunStatus(l.ctx, doc.Id, knowledge_document.RunStateCanceled, "已取消"); err != nil {
+		return nil, xerr.NewInternalErrMsg("更新文档状态失败")
+	}
+	return &types.CancelDocumentIndexResp{RunStatus: knowledge_document.RunStateCanceled}, nil
+}
@@ -0,0 +1,46 @@
+package knowledge_document
+
+import (
+	"testing"
+	"time"
+
+	"gozero-rag/internal/indexctl"
+	"gozero-rag/internal/model/knowledge_base"
+	"gozero-rag/internal/model/knowledge_document"
+	"gozero-rag/restful/rag/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCancelDocumentIndex_Running(t *testing.T) {
+	env := newTestEnv(t)
+	env.addKnowledgeBase("kb1", knowledge_base.DedupPolicyAllow)
+	doc := addDocument(t, env, "kb1", "doc1", []byte("content"))
+	doc.RunStatus = knowledge_document.RunStateRunning
+	doc.UpdatedTime = time.Now().UnixMilli()
+
+	// 消费者仍在上报进度, 只写入信号, 由消费者置为 canceled
+	resp, err := NewCancelDocumentIndexLogic(userCtx(testUserId), env.svcCtx).CancelDocumentIndex(&types.CancelDocumentIndexReq{Id: "doc1"})
+	require.NoError(t, err)
+	assert.Equal(t, knowledge_document.RunStateRunning, resp.RunStatus)
+	assert.Equal(t, knowledge_document.RunStateRunning, doc.RunStatus)
+}
+
+func TestCancelDocumentIndex_StaleRunning(t *testing.T) {
+	env := newTestEnv(t)
+	env.addKnowledgeBase("kb1", knowledge_base.DedupPolicyAllow)
+	doc := addDocument(t, env, "kb1", "doc1", []byte("content"))
+	doc.RunStatus = knowledge_document.RunStateRunning
+	doc.UpdatedTime = time.Now().Add(-indexctl.RunningStaleTimeout - time.Minute).UnixMilli()
+
+	// 进度长时间未更新, 消费者可能已退出, 直接置为 canceled, 之后可以删除文档
+	resp, err := NewCancelDocumentIndexLogic(userCtx(testUserId), env.svcCtx).CancelDocumentIndex(&types.CancelDocumentIndexReq{Id: "doc1"})
+	require.NoError(t, err)
+	assert.Equal(t, knowledge_document.RunStateCanceled, resp.RunStatus)
+	assert.Equal(t, knowledge_document.RunStateCanceled, doc.RunStatus)
+
+	_, err = NewDeleteKnowledgeDocumentLogic(userCtx(testUserId), env.svcCtx).DeleteKnowledgeDocument(&types.DeleteKnowledgeDocumentReq{Id: "doc1"})
+	require.NoError(t, err)
+	assert.NotContains(t, env.docs.docs, "doc1")
+}
@@ -0,0 +1,330 @@
+package knowledge_document
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	"gozero-rag/internal/model/knowledge_base"
+	"gozero-rag/internal/model/knowledge_document"
+	"gozero-rag/internal/model/knowledge_document_version"
+	"gozero-rag/internal/model/local_message"
+	"gozero-rag/internal/mq"
+	"gozero-rag/internal/xerr"
+	"gozero-rag/restful/rag/internal/common"
+	"gozero-rag/restful/rag/internal/svc"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+)
+
+const (
+	dedupLockExpireSec     = 30                     // 去重锁过期时间
+	dedupLockWaitTimeout   = 5 * time.Second        // 等待去重锁的最长时间
+	dedupLockRetryInterval = 100 * time.Millisecond // 重试获取去重锁的间隔
+)
+
+// dedupLockKey 同一知识库内相同内容的文件串行执行去重检查与写入
+func dedupLockKey(kbId, fileHash string) string {
+	return fmt.Sprintf("rag:document:dedup:lock:%s:%s", kbId, fileHash)
+}
+
+// findDocumentWithPermission 查询文档及所属知识库, 并校验当前租户的操作权限
+func findDocumentWithPermission(ctx context.Context, svcCtx *svc.ServiceContext, docId string) (*knowledge_document.KnowledgeDocument, *knowledge_base.KnowledgeBase, error) {
+	tenantId, err := common.GetTenantIdFromCtx(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	doc, err := svcCtx.KnowledgeDocumentModel.FindOne(ctx, docId)
+	if err != nil {
+		if err == knowledge_document.ErrNotFound {
+			return nil, nil, xerr.NewErrCode(xerr.KnowledgeDocNotFoundError)
+		}
+		return nil, nil, xerr.NewInternalErrMsg(err.Error())
+	}
+
+	kb, err := svcCtx.KnowledgeBaseModel.FindOne(ctx, doc.KnowledgeBaseId)
+	if err != nil {
+		if err == knowledge_base.ErrNotFound {
+			return nil, nil, xerr.NewErrCode(xerr.KnowledgeBaseNotFoundError)
+		}
+		return nil, nil, xerr.NewInternalErrMsg(err.Error())
+	}
+	if kb.TenantId != tenantId {
+		return nil, nil, xerr.NewErrCodeMsg(xerr.ForbiddenError, "无权操作此知识库")
+	}
+
+	return doc, kb, nil
+}
+
+// reindexDocument 将文档重置为待解析状态, 与索引任务的本地消息同事务写入后投递
+// 投递失败时消息停留在 init 状态, 由 compensator 补偿投递
+func reindexDocument(ctx context.Context, svcCtx *svc.ServiceContext, doc *knowledge_document.KnowledgeDocument, kb *knowledge_base.KnowledgeBase, userId string) error {
+	// 清除上一次索引残留的取消/暂停信号, 暂停或失败前的 embedding 检查点保留供本次复用, 重试次数重新计算
+	if err := svcCtx.IndexSignal.Clear(ctx, doc.Id); err != nil {
+		logx.WithContext(ctx).Errorf("reindexDocument clear signal failed: docId=%s, err=%v", doc.Id, err)
+	}
+	if err := svcCtx.IndexCheckpoint.ResetAttempts(ctx, doc.Id); err != nil {
+		logx.WithContext(ctx).Errorf("reindexDocument reset attempts failed: docId=%s, err=%v", doc.Id, err)
+	}
+
+	doc.RunStatus = knowledge_document.RunStatePending
+	doc.Status = 1
+	doc.Progress = 0
+	doc.ProgressMsg = sql.NullString{String: "", Valid: true}
+
+	msg := &mq.KnowledgeDocumentIndexMsg{
+		UserId:          userId,
+		TenantId:        kb.TenantId,
+		KnowledgeBaseId: kb.Id,
+		DocumentId:      doc.Id,
+	}
+	snapshot, err := json.Marshal(msg)
+	if err != nil {
+		return xerr.NewInternalErrMsg(err.Error())
+	}
+
+	var msgId uint64
+	err = svcCtx.KnowledgeDocumentModel.TransactCtx(ctx, func(ctx context.Context, session sqlx.Session) error {
+		if err := svcCtx.KnowledgeDocumentModel.UpdateWithSession(ctx, session, doc); err != nil {
+			return err
+		}
+		msgId, err = svcCtx.LocalMessageModel.InsertWithSession(ctx, session, local_message.NewLocalMessage(local_message.TaskTypeDocumentIndex, string(snapshot)))
+		return err
+	})
+	if err != nil {
+		logx.WithContext(ctx).Errorf("reindexDocument update doc failed: docId=%s, err=%v", doc.Id, err)
+		return xerr.NewInternalErrMsg("更新文档状态失败")
+	}
+
+	publishIndexTask(ctx, svcCtx, msg, msgId)
+	return nil
+}
+
+// putObjectWithHash 上传文件到 OSS, 上传过程中同时计算文件 SHA-256
+func putObjectWithHash(ctx context.Context, svcCtx *svc.ServiceContext, objectKey string, file io.Reader, size int64, contentType string) (string, error) {
+	h := sha256.New()
+	if _, err := svcCtx.OssClient.PutObject(ctx, svcCtx.Config.Oss.BucketName, objectKey, io.TeeReader(file, h), size, contentType); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// newDocumentVersion 以文档当前的文件信息构造版本记录
+func newDocumentVersion(doc *knowledge_document.KnowledgeDocument, version int64, fileHash, userId string) *knowledge_document_version.KnowledgeDocumentVersion {
+	return &knowledge_document_version.KnowledgeDocumentVersion{
+		DocumentId:  doc.Id,
+		Version:     version,
+		DocName:     doc.DocName.String,
+		DocType:     doc.DocType,
+		DocSize:     doc.DocSize,
+		FileHash:    fileHash,
+		StoragePath: doc.StoragePath.String,
+		CreatedBy:   userId,
+		CreatedTime: time.Now().UnixMilli(),
+	}
+}
+
+// removeDocumentFiles 删除文档当前文件及全部历史版本文件, 失败只记录日志
+func removeDocumentFiles(ctx context.Context, svcCtx *svc.ServiceContext, doc *knowledge_document.KnowledgeDocument) {
+	paths := make(map[string]bool)
+	if doc.StoragePath.Valid && doc.StoragePath.String != "" && doc.SourceType == "local" {
+		paths[doc.StoragePath.String] = true
+	}
+	versions, err := svcCtx.DocumentVersionModel.FindListByDocumentId(ctx, doc.Id)
+	if err != nil {
+		logx.WithContext(ctx).Errorf("removeDocumentFiles find versions failed: docId=%s, err=%v", doc.Id, err)
+	}
+	for _, v := range versions {
+		if v.StoragePath != "" {
+			paths[v.StoragePath] = true
+		}
+	}
+	for path := range paths {
+		if err := svcCtx.OssClient.RemoveObject(ctx, svcCtx.Config.Oss.BucketName, path); err != nil {
+			logx.WithContext(ctx).Errorf("removeDocumentFiles delete oss object failed: path=%s, err=%v", path, err)
+		}
+	}
+}
+
+// ensureInitialVersion 版本功能上线前上传的文档没有版本记录, 以当前文件补齐版本 1, 返回最新版本号
+func ensureInitialVersion(ctx context.Context, svcCtx *svc.ServiceContext, doc *knowledge_document.KnowledgeDocument) (int64, error) {
+	latest, err := svcCtx.DocumentVersionModel.FindLatestVersion(ctx, doc.Id)
+	if err != nil {
+		return 0, xerr.NewInternalErrMsg(err.Error())
+	}
+	if latest > 0 {
+		return latest, nil
+	}
+
+	v := newDocumentVersion(doc, 1, doc.FileHash, doc.CreatedBy)
+	v.CreatedTime = doc.CreatedTime
+	if _, err := svcCtx.DocumentVersionModel.Insert(ctx, v); err != nil {
+		return 0, xerr.NewInternalErrMsg("补齐初始版本失败")
+	}
+	return 1, nil
+}
+
+// dedupEnabled 知识库按 skip / reject 策略处理重复文档, 且文件哈希已知
+func dedupEnabled(kb *knowledge_base.KnowledgeBase, fileHash string) bool {
+	return fileHash != "" && (kb.DedupPolicy == knowledge_base.DedupPolicySkip || kb.DedupPolicy == knowledge_base.DedupPolicyReject)
+}
+
+// lockDuplicateCheck 获取知识库内该文件哈希的去重锁, 持有期间执行 findDuplicateDocument 及文档写入,
+// 避免并发上传相同文件时同时通过检查; allow 策略不加锁. 返回的 unlock 必须调用
+func lockDuplicateCheck(ctx context.Context, svcCtx *svc.ServiceContext, kb *knowledge_base.KnowledgeBase, fileHash string) (unlock func(), err error) {
+	if !dedupEnabled(kb, fileHash) {
+		return func() {}, nil
+	}
+
+	lock := redis.NewRedisLock(svcCtx.RedisClient, dedupLockKey(kb.Id, fileHash))
+	lock.SetExpire(dedupLockExpireSec)
+	deadline := time.Now().Add(dedupLockWaitTimeout)
+	for {
+		ok, err := lock.AcquireCtx(ctx)
+		if err != nil {
+			logx.WithContext(ctx).Errorf("acquire dedup lock failed: kbId=%s, hash=%s, err=%v", kb.Id, fileHash, err)
+			return nil, xerr.NewInternalErrMsg("重复文档检查失败")
+		}
+		if ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			return nil, xerr.NewErrCodeMsg(xerr.KnowledgeDocUploadError, "相同内容的文件正在上传, 请稍后重试")
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(dedupLockRetryInterval):
+		}
+	}
+	return func() {
+		if _, err := lock.ReleaseCtx(context.Background()); err != nil {
+			logx.WithContext(ctx).Errorf("release dedup lock failed: kbId=%s, hash=%s, err=%v", kb.Id, fileHash, err)
+		}
+	}, nil
+}
+
+// findDuplicateDocument 按知识库的重复文档策略检查内容相同的文档
+// skip 策略返回已有文档, reject 策略返回错误, 其余情况返回 nil
+// 仅检查时可直接调用 (如创建上传会话的预检查); 检查后要写入文档的须先持有 lockDuplicateCheck 返回的锁
+func findDuplicateDocument(ctx context.Context, svcCtx *svc.ServiceContext, kb *knowledge_base.KnowledgeBase, fileHash string) (*knowledge_document.KnowledgeDocument, error) {
+	if !dedupEnabled(kb, fileHash) {
+		return nil, nil
+	}
+
+	existing, err := svcCtx.KnowledgeDocumentModel.FindOneByKbIdFileHash(ctx, kb.Id, fileHash)
+	if err != nil {
+		if err == knowledge_document.ErrNotFound {
+			return nil, nil
+		}
+		return nil, xerr.NewInternalErrMsg(err.Error())
+	}
+	if kb.DedupPolicy == knowledge_base.DedupPolicyReject {
+		return nil, xerr.NewErrCodeMsg(xerr.KnowledgeDocUploadError, fmt.Sprintf("与已有文档内容重复: %s", existing.DocName.String))
+	}
+	return existing, nil
+}
+
+// createUploadedDocument 为已上传到 OSS 的文件创建文档记录及初始版本, 解析配置继承自知识库
+func createUploadedDocument(ctx context.Context, svcCtx *svc.ServiceContext, kb *knowledge_base.KnowledgeBase, docId, userId, fileName string, fileSize int64, objectKey, fileHash string) (*knowledge_document.KnowledgeDocument, error) {
+	now := time.Now()
+	ext := filepath.Ext(fileName)
+	docType := "unknown"
+	if len(ext) > 1 {
+		docType = ext[1:] // 去掉点号
+	}
+
+	// 文档级解析配置只保存用户的覆盖项, 索引时叠加在知识库配置之上, 上传时不复制知识库配置
+	parserConfig := "{}"
+
+	doc := &knowledge_document.KnowledgeDocument{
+		Id:              docId,
+		KnowledgeBaseId: kb.Id,
+		DocName:         sql.NullString{String: fileName, Valid: true},
+		DocType:         docType,
+		DocSize:         fileSize,
+		StoragePath:     sql.NullString{String: objectKey, Valid: true},
+		Status:          1,
+		RunStatus:       knowledge_document.RunStatePending,
+		CreatedBy:       userId,
+		CreatedTime:     now.UnixMilli(),
+		UpdatedTime:     now.UnixMilli(),
+		CreatedDate:     now,
+		UpdatedDate:     now,
+		ParserId:        kb.ParserId,
+		ParserConfig:    parserConfig,
+		FileHash:        fileHash,
+		SourceType:      "local",
+	}
+
+	var err error
+	if kb.AutoParse == 1 {
+		err = insertDocumentWithIndexTask(ctx, svcCtx, doc, kb, userId)
+	} else {
+		_, err = svcCtx.KnowledgeDocumentModel.Insert(ctx, doc)
+	}
+	if err != nil {
+		logx.WithContext(ctx).Errorf("createUploadedDocument insert failed: docId=%s, err=%v", docId, err)
+		return nil, xerr.NewInternalErrMsg("保存文档信息失败")
+	}
+
+	// 记录初始版本, 失败时由版本接口按当前文件补齐
+	if _, err := svcCtx.DocumentVersionModel.Insert(ctx, newDocumentVersion(doc, 1, fileHash, userId)); err != nil {
+		logx.WithContext(ctx).Errorf("createUploadedDocument insert initial version failed: docId=%s, err=%v", docId, err)
+	}
+
+	return doc, nil
+}
+
+// insertDocumentWithIndexTask 文档与索引任务的本地消息同事务写入, 提交后立即投递
+// 投递失败或提交后进程崩溃时, 消息停留在 init 状态, 由 compensator 补偿投递
+func insertDocumentWithIndexTask(ctx context.Context, svcCtx *svc.ServiceContext, doc *knowledge_document.KnowledgeDocument, kb *knowledge_base.KnowledgeBase, userId string) error {
+	msg := &mq.KnowledgeDocumentIndexMsg{
+		UserId:          userId,
+		TenantId:        kb.TenantId,
+		KnowledgeBaseId: kb.Id,
+		DocumentId:      doc.Id,
+	}
+	snapshot, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	var msgId uint64
+	err = svcCtx.KnowledgeDocumentModel.TransactCtx(ctx, func(ctx context.Context, session sqlx.Session) error {
+		if err := svcCtx.KnowledgeDocumentModel.InsertWithSession(ctx, session, doc); err != nil {
+			return err
+		}
+		msgId, err = svcCtx.LocalMessageModel.InsertWithSession(ctx, session, local_message.NewLocalMessage(local_message.TaskTypeDocumentIndex, string(snapshot)))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	publishIndexTask(ctx, svcCtx, msg, msgId)
+	return nil
+}
+
+// publishIndexTask 携带本地消息ID投递索引任务, 消费端据此更新同一条消息的状态
+// 投递失败只记录日志, 由 compensator 补偿投递
+func publishIndexTask(ctx context.Context, svcCtx *svc.ServiceContext, msg *mq.KnowledgeDocumentIndexMsg, msgId uint64) {
+	msg.SetLocalMessageId(msgId)
+	if err := svcCtx.MqPusherClient.PublishDocumentIndex(ctx, msg); err != nil {
+		logx.WithContext(ctx).Errorf("push index task failed, waiting for compensator: docId=%s, msgId=%d, err=%v", msg.DocumentId, msgId, err)
+		return
+	}
+	// 投递成功, 标记为 retrying 防止 compensator 重复投递
+	if err := svcCtx.LocalMessageModel.UpdateRetrying(ctx, msgId); err != nil {
+		logx.WithContext(ctx).Errorf("update local message failed: msgId=%d, err=%v", msgId, err)
+	}
+}
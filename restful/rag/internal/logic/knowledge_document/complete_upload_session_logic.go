@@ -0,0 +1,181 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package knowledge_document
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"gozero-rag/internal/model/knowledge_base"
+	"gozero-rag/internal/oss"
+	"gozero-rag/internal/xerr"
+
+	"gozero-rag/restful/rag/internal/svc"
+	"gozero-rag/restful/rag/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CompleteUploadSessionLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 完成直传并创建文档
+func NewCompleteUploadSessionLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CompleteUploadSessionLogic {
+	return &CompleteUploadSessionLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// CompleteUploadSession 合并分片并校验对象大小及内容哈希, 按实际哈希去重后创建文档记录
+func (l *CompleteUploadSessionLogic) CompleteUploadSession(req *types.CompleteUploadSessionReq) (resp *types.CompleteUploadSessionResp, err error) {
+	session, err := loadUploadSession(l.ctx, l.svcCtx, req.SessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	kb, err := l.svcCtx.KnowledgeBaseModel.FindOne(l.ctx, session.KnowledgeBaseId)
+	if err != nil {
+		if err == knowledge_base.ErrNotFound {
+			return nil, xerr.NewErrCodeMsg(xerr.KnowledgeBaseNotFoundError, "知识库不存在")
+		}
+		return nil, xerr.NewInternalErrMsg(err.Error())
+	}
+
+	bucket := l.svcCtx.Config.Oss.BucketName
+	if session.Mode == UploadModeMultipart {
+		if err := l.completeMultipart(session, req.Parts); err != nil {
+			return nil, err
+		}
+	}
+
+	info, err := l.svcCtx.OssClient.StatObject(l.ctx, bucket, session.ObjectKey)
+	if err != nil {
+		if err == oss.ErrObjectNotFound {
+			return nil, xerr.NewBadRequestErrMsg("文件尚未上传完成")
+		}
+		return nil, xerr.NewErrCodeMsg(xerr.KnowledgeDocUploadError, "校验上传文件失败")
+	}
+	if info.Size != session.FileSize {
+		l.discardUpload(session)
+		return nil, xerr.NewErrCodeMsg(xerr.KnowledgeDocUploadError,
+			fmt.Sprintf("文件大小不一致, 声明 %d 字节, 实际 %d 字节", session.FileSize, info.Size))
+	}
+
+	// 客户端声明的哈希不可信, 以对象实际内容为准
+	fileHash, err := l.hashObject(session.ObjectKey)
+	if err != nil {
+		l.Errorf("CompleteUploadSession hash object failed: session=%s, err=%v", session.SessionId, err)
+		return nil, xerr.NewErrCodeMsg(xerr.KnowledgeDocUploadError, "校验上传文件失败")
+	}
+	if session.FileHash != "" && !strings.EqualFold(session.FileHash, fileHash) {
+		l.discardUpload(session)
+		return nil, xerr.NewErrCodeMsg(xerr.KnowledgeDocUploadError, "文件内容与声明的哈希不一致")
+	}
+
+	resp = &types.CompleteUploadSessionResp{
+		UploadedFileInfo: types.UploadedFileInfo{
+			Id:      session.DocumentId,
+			DocName: session.FileName,
+		},
+	}
+
+	// 创建会话时的去重基于声明的哈希, 此处按实际哈希重新执行; 持有去重锁直到文档写入
+	// 获取锁失败时保留对象和会话, 客户端可重新完成
+	unlock, err := lockDuplicateCheck(l.ctx, l.svcCtx, kb, fileHash)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	existing, err := findDuplicateDocument(l.ctx, l.svcCtx, kb, fileHash)
+	if err != nil {
+		l.discardUpload(session)
+		return nil, err
+	}
+	if existing != nil {
+		// 上次完成时已创建文档但会话未删除, 直接返回
+		if existing.Id == session.DocumentId {
+			deleteUploadSession(l.ctx, l.svcCtx, session)
+			return resp, nil
+		}
+		l.discardUpload(session)
+		resp.Id, resp.DocName, resp.Duplicate = existing.Id, existing.DocName.String, true
+		return resp, nil
+	}
+
+	if _, err := createUploadedDocument(l.ctx, l.svcCtx, kb, session.DocumentId, session.UserId, session.FileName, info.Size, session.ObjectKey, fileHash); err != nil {
+		return nil, err
+	}
+	deleteUploadSession(l.ctx, l.svcCtx, session)
+
+	return resp, nil
+}
+
+// hashObject 流式读取对象计算 SHA-256
+func (l *CompleteUploadSessionLogic) hashObject(key string) (string, error) {
+	reader, err := l.svcCtx.OssClient.GetObject(l.ctx, l.svcCtx.Config.Oss.BucketName, key)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, reader); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// discardUpload 删除未通过校验的对象及会话
+func (l *CompleteUploadSessionLogic) discardUpload(session *uploadSession) {
+	if err := l.svcCtx.OssClient.RemoveObject(l.ctx, l.svcCtx.Config.Oss.BucketName, session.ObjectKey); err != nil {
+		l.Errorf("CompleteUploadSession remove object failed: key=%s, err=%v", session.ObjectKey, err)
+	}
+	deleteUploadSession(l.ctx, l.svcCtx, session)
+}
+
+// completeMultipart 合并分片, 未指定分片时以存储端已上传的分片为准
+func (l *CompleteUploadSessionLogic) completeMultipart(session *uploadSession, reqParts []types.UploadPartInfo) error {
+	bucket := l.svcCtx.Config.Oss.BucketName
+
+	var parts []oss.Part
+	if len(reqParts) > 0 {
+		for _, p := range reqParts {
+			parts = append(parts, oss.Part{PartNumber: int(p.PartNumber), ETag: p.ETag})
+		}
+		sort.Slice(parts, func(i, j int) bool {
+			return parts[i].PartNumber < parts[j].PartNumber
+		})
+	} else {
+		uploaded, err := l.svcCtx.OssClient.ListParts(l.ctx, bucket, session.ObjectKey, session.UploadId)
+		if err != nil {
+			// 上传任务已不存在: 可能是上次合并成功但创建文档失败, 交由后续对象校验判断
+			if err == oss.ErrObjectNotFound {
+				return nil
+			}
+			return xerr.NewErrCodeMsg(xerr.KnowledgeDocUploadError, "查询已上传分片失败")
+		}
+		parts = uploaded
+	}
+
+	if int64(len(parts)) != session.PartCount {
+		return xerr.NewBadRequestErrMsg(fmt.Sprintf("分片未上传完成, 已上传 %d/%d", len(parts), session.PartCount))
+	}
+
+	err := l.svcCtx.OssClient.CompleteMultipartUpload(l.ctx, bucket, session.ObjectKey, session.UploadId, parts)
+	if err != nil && err != oss.ErrObjectNotFound {
+		l.Errorf("CompleteUploadSession complete multipart failed: session=%s, err=%v", session.SessionId, err)
+		return xerr.NewErrCodeMsg(xerr.KnowledgeDocUploadError, "合并分片失败")
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package knowledge_document
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"gozero-rag/internal/model/knowledge_base"
+	"gozero-rag/internal/xerr"
+	"gozero-rag/restful/rag/internal/common"
+
+	"github.com/google/uuid"
+
+	"gozero-rag/restful/rag/internal/svc"
+	"gozero-rag/restful/rag/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CreateUploadSessionLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 创建直传会话
+func NewCreateUploadSessionLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateUploadSessionLogic {
+	return &CreateUploadSessionLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// CreateUploadSession 创建直传会话, 客户端凭预签名 URL 直接上传到对象存储, 不经过 API 服务
+func (l *CreateUploadSessionLogic) CreateUploadSession(req *types.CreateUploadSessionReq) (resp *types.CreateUploadSessionResp, err error) {
+	userId, err := common.GetUidFromCtx(l.ctx)
+	if err != nil {
+		return nil, err
+	}
+	tenantId, err := common.GetTenantIdFromCtx(l.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.FileName == "" {
+		return nil, xerr.NewBadRequestErrMsg("文件名不能为空")
+	}
+	if req.FileSize <= 0 || req.FileSize > uploadMaxFileSize {
+		return nil, xerr.NewBadRequestErrMsg(fmt.Sprintf("文件大小必须在 1 到 %d 字节之间", int64(uploadMaxFileSize)))
+	}
+
+	kb, err := l.svcCtx.KnowledgeBaseModel.FindOne(l.ctx, req.KnowledgeBaseId)
+	if err != nil {
+		if err == knowledge_base.ErrNotFound {
+			return nil, xerr.NewErrCodeMsg(xerr.KnowledgeBaseNotFoundError, "知识库不存在")
+		}
+		return nil, xerr.NewInternalErrMsg(err.Error())
+	}
+	if kb.TenantId != tenantId {
+		return nil, xerr.NewErrCodeMsg(xerr.ForbiddenError, "无权操作此知识库")
+	}
+
+	// 客户端提供哈希时提前按重复文档策略处理, 命中 skip 无需上传; 完成时按实际内容重新校验
+	existing, err := findDuplicateDocument(l.ctx, l.svcCtx, kb, req.FileHash)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return &types.CreateUploadSessionResp{Duplicate: true, DocumentId: existing.Id}, nil
+	}
+
+	docUuid, err := uuid.NewV7()
+	if err != nil {
+		return nil, xerr.NewInternalErrMsg("UUID generation failed")
+	}
+	contentType := req.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	mode, partSize, partCount := planUploadParts(req.FileSize)
+	session := &uploadSession{
+		SessionId:       uuid.NewString(),
+		TenantId:        tenantId,
+		UserId:          userId,
+		KnowledgeBaseId: kb.Id,
+		DocumentId:      docUuid.String(),
+		FileName:        req.FileName,
+		FileSize:        req.FileSize,
+		FileHash:        req.FileHash,
+		ContentType:     contentType,
+		Mode:            mode,
+		PartSize:        partSize,
+		PartCount:       partCount,
+		ExpireTime:      time.Now().Add(uploadSessionExpire).UnixMilli(),
+	}
+	session.ObjectKey = fmt.Sprintf("tenant_%s/kb_%s/%s%s", tenantId, kb.Id, session.DocumentId, filepath.Ext(req.FileName))
+
+	bucket := l.svcCtx.Config.Oss.BucketName
+	resp = &types.CreateUploadSessionResp{
+		SessionId:  session.SessionId,
+		Mode:       mode,
+		PartSize:   partSize,
+		PartCount:  partCount,
+		ExpireTime: session.ExpireTime,
+	}
+	if mode == UploadModeMultipart {
+		session.UploadId, err = l.svcCtx.OssClient.NewMultipartUpload(l.ctx, bucket, session.ObjectKey, contentType)
+		if err != nil {
+			l.Errorf("CreateUploadSession init multipart failed: key=%s, err=%v", session.ObjectKey, err)
+			return nil, xerr.NewErrCodeMsg(xerr.KnowledgeDocUploadError, "初始化分片上传失败")
+		}
+	} else {
+		resp.UploadUrl, err = l.svcCtx.OssClient.PresignedPutObject(l.ctx, bucket, session.ObjectKey, uploadPresignExpire)
+		if err != nil {
+			l.Errorf("CreateUploadSession presign failed: key=%s, err=%v", session.ObjectKey, err)
+			return nil, xerr.NewErrCodeMsg(xerr.KnowledgeDocUploadError, "生成上传地址失败")
+		}
+	}
+
+	if err := saveUploadSession(l.ctx, l.svcCtx, session); err != nil {
+		l.Errorf("CreateUploadSession save session failed: %v", err)
+		if session.UploadId != "" {
+			_ = l.svcCtx.OssClient.AbortMultipartUpload(l.ctx, bucket, session.ObjectKey, session.UploadId)
+		}
+		return nil, xerr.NewInternalErrMsg("创建上传会话失败")
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,44 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package knowledge_document
+
+import (
+	"context"
+
+	"gozero-rag/internal/xerr"
+	"gozero-rag/restful/rag/internal/svc"
+	"gozero-rag/restful/rag/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DeleteDocumentQaLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 删除 QA
+func NewDeleteDocumentQaLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteDocumentQaLogic {
+	return &DeleteDocumentQaLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// DeleteDocumentQa 删除 QA, 重新索引时可能再次生成
+func (l *DeleteDocumentQaLogic) DeleteDocumentQa(req *types.DeleteDocumentQaReq) (resp *types.DeleteDocumentQaResp, err error) {
+	qa, _, err := findQaWithPermission(l.ctx, l.svcCtx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := l.svcCtx.ChunkModel.DeleteByIds(l.ctx, []string{qa.Id}); err != nil {
+		l.Errorf("DeleteDocumentQa failed: qaId=%s, err=%v", qa.Id, err)
+		return nil, xerr.NewInternalErrMsg("删除 QA 失败")
+	}
+
+	return &types.DeleteDocumentQaResp{}, nil
+}
@@ -6,6 +6,8 @@ package knowledge_document
 import (
 	"context"
 
+	"gozero-rag/internal/model/knowledge_document"
+	"gozero-rag/internal/xerr"
 	"gozero-rag/restful/rag/internal/svc"
 	"gozero-rag/restful/rag/internal/types"
 
@@ -28,7 +30,41 @@ func NewDeleteKnowledgeDocumentLogic(ctx context.Context, svcCtx *svc.ServiceCon
 }
 
 func (l *DeleteKnowledgeDocumentLogic) DeleteKnowledgeDocument(req *types.DeleteKnowledgeDocumentReq) (resp *types.DeleteKnowledgeDocumentResp, err error) {
-	// todo: add your logic here and delete this line
+	doc, kb, err := findDocumentWithPermission(l.ctx, l.svcCtx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if doc.RunStatus == knowledge_document.RunStateRunning {
+		return nil, xerr.NewBadRequestErrMsg("文档正在索引中, 请先取消索引")
+	}
+
+	// 1. 经本地消息表异步扣减文档对知识图谱的贡献, 失败时由 compensator 重试
+	// 先于分片、文件等删除写入, 写入失败时文档数据保持完整
+	if err := l.svcCtx.EnqueueGraphRemoval(l.ctx, kb.TenantId, kb.Id, []string{doc.Id}); err != nil {
+		l.Errorf("DeleteKnowledgeDocument enqueue graph removal failed: docId=%s, err=%v", doc.Id, err)
+		return nil, xerr.NewInternalErrMsg("删除文档图谱失败")
+	}
+
+	// 2. 删除分片
+	if err := l.svcCtx.ChunkModel.DeleteByDocId(l.ctx, kb.Id, doc.Id); err != nil {
+		l.Errorf("DeleteKnowledgeDocument delete chunks failed: docId=%s, err=%v", doc.Id, err)
+		return nil, xerr.NewInternalErrMsg("删除文档分片失败")
+	}
+
+	// 3. 删除 OSS 文件 (含历史版本) 及版本记录, 失败只记录日志
+	removeDocumentFiles(l.ctx, l.svcCtx, doc)
+	if err := l.svcCtx.DocumentVersionModel.DeleteByDocumentId(l.ctx, doc.Id); err != nil {
+		l.Errorf("DeleteKnowledgeDocument delete versions failed: docId=%s, err=%v", doc.Id, err)
+	}
+	if err := l.svcCtx.IndexCheckpoint.Clear(l.ctx, doc.Id); err != nil {
+		l.Errorf("DeleteKnowledgeDocument clear checkpoint failed: docId=%s, err=%v", doc.Id, err)
+	}
+
+	// 4. 删除文档记录
+	if err := l.svcCtx.KnowledgeDocumentModel.Delete(l.ctx, doc.Id); err != nil {
+		l.Errorf("DeleteKnowledgeDocument delete doc failed: docId=%s, err=%v", doc.Id, err)
+		return nil, xerr.NewInternalErrMsg("删除文档失败")
+	}
 
-	return
+	return &types.DeleteKnowledgeDocumentResp{}, nil
 }
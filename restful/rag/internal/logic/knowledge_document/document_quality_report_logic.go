@@ -0,0 +1,73 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package knowledge_document
+
+import (
+	"context"
+
+	"gozero-rag/internal/model/chunk"
+	"gozero-rag/internal/xerr"
+	"gozero-rag/restful/rag/internal/svc"
+	"gozero-rag/restful/rag/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// maxQualityReportLimit 最低分切片最多返回条数
+const maxQualityReportLimit = 100
+
+type DocumentQualityReportLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 获取文档质量报告
+func NewDocumentQualityReportLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DocumentQualityReportLogic {
+	return &DocumentQualityReportLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// DocumentQualityReport 汇总文档切片的质量评分: 分数分布、常见问题与建议、最低分切片
+func (l *DocumentQualityReportLogic) DocumentQualityReport(req *types.DocumentQualityReportReq) (resp *types.DocumentQualityReportResp, err error) {
+	if _, _, err := findDocumentWithPermission(l.ctx, l.svcCtx, req.Id); err != nil {
+		return nil, err
+	}
+
+	limit := min(max(req.Limit, 0), maxQualityReportLimit)
+
+	report, err := l.svcCtx.ChunkModel.QualityReport(l.ctx, req.Id, limit)
+	if err != nil {
+		l.Errorf("DocumentQualityReport failed: docId=%s, err=%v", req.Id, err)
+		return nil, xerr.NewInternalErrMsg("查询质量报告失败")
+	}
+
+	resp = &types.DocumentQualityReportResp{
+		Total:             report.Total,
+		Disabled:          report.Disabled,
+		AvgScore:          report.AvgScore,
+		Distribution:      make([]types.QualityBucketInfo, 0, len(report.Distribution)),
+		CommonIssues:      toQualityTermCounts(report.Issues),
+		CommonSuggestions: toQualityTermCounts(report.Suggestions),
+		WorstChunks:       make([]types.ChunkInfo, 0, len(report.Worst)),
+	}
+	for _, b := range report.Distribution {
+		resp.Distribution = append(resp.Distribution, types.QualityBucketInfo{From: b.From, To: b.To, Count: b.Count})
+	}
+	for _, c := range report.Worst {
+		resp.WorstChunks = append(resp.WorstChunks, toChunkInfo(c))
+	}
+	return resp, nil
+}
+
+func toQualityTermCounts(counts []chunk.TermCount) []types.QualityTermCount {
+	list := make([]types.QualityTermCount, 0, len(counts))
+	for _, c := range counts {
+		list = append(list, types.QualityTermCount{Term: c.Term, Count: c.Count})
+	}
+	return list
+}
@@ -0,0 +1,253 @@
+package knowledge_document
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"testing"
+
+	"gozero-rag/internal/model/knowledge_base"
+	"gozero-rag/internal/model/knowledge_document"
+	"gozero-rag/internal/xerr"
+	"gozero-rag/restful/rag/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// addDocument 添加已索引完成的文档, 文件写入内存 OSS
+func addDocument(t *testing.T, env *testEnv, kbId, docId string, data []byte) *knowledge_document.KnowledgeDocument {
+	t.Helper()
+	objectKey := "tenant_" + testTenantId + "/kb_" + kbId + "/" + docId + ".txt"
+	_, err := env.oss.PutObject(context.Background(), testBucket, objectKey, bytes.NewReader(data), int64(len(data)), "text/plain")
+	require.NoError(t, err)
+
+	doc := &knowledge_document.KnowledgeDocument{
+		Id:              docId,
+		KnowledgeBaseId: kbId,
+		DocName:         sql.NullString{String: docId + ".txt", Valid: true},
+		DocType:         "txt",
+		DocSize:         int64(len(data)),
+		StoragePath:     sql.NullString{String: objectKey, Valid: true},
+		FileHash:        sha256Hex(data),
+		SourceType:      "local",
+		Status:          1,
+		RunStatus:       knowledge_document.RunStateSuccess,
+		CreatedBy:       testUserId,
+	}
+	env.docs.docs[docId] = doc
+	return doc
+}
+
+// newFileHeader 构造 multipart 表单中的上传文件
+func newFileHeader(t *testing.T, fileName string, data []byte) *multipart.FileHeader {
+	t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("file", fileName)
+	require.NoError(t, err)
+	_, err = part.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	req, err := http.NewRequest(http.MethodPost, "/", &body)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	require.NoError(t, req.ParseMultipartForm(1<<20))
+	return req.MultipartForm.File["file"][0]
+}
+
+func readObject(t *testing.T, env *testEnv, key string) []byte {
+	t.Helper()
+	r, err := env.oss.GetObject(context.Background(), testBucket, key)
+	require.NoError(t, err)
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return data
+}
+
+func TestUploadDocumentVersion_CreatesVersionAndReindexes(t *testing.T) {
+	env := newTestEnv(t)
+	env.addKnowledgeBase("kb1", knowledge_base.DedupPolicyAllow)
+	doc := addDocument(t, env, "kb1", "doc1", []byte("v1 content"))
+	v1Path := doc.StoragePath.String
+
+	ctx := userCtx(testUserId)
+	data := []byte("v2 content")
+	resp, err := NewUploadDocumentVersionLogic(ctx, env.svcCtx).UploadDocumentVersion(
+		&types.UploadDocumentVersionReq{Id: "doc1"}, newFileHeader(t, "manual.md", data))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), resp.Version)
+	assert.False(t, resp.Duplicate)
+
+	// 补齐初始版本, 新版本单独存储, 旧版本文件保留
+	require.Len(t, env.versions.versions, 2)
+	assert.Equal(t, v1Path, env.versions.versions[0].StoragePath)
+	v2 := env.versions.versions[1]
+	assert.Equal(t, "tenant_"+testTenantId+"/kb_kb1/doc1_v2.md", v2.StoragePath)
+	assert.Equal(t, sha256Hex(data), v2.FileHash)
+	assert.Equal(t, data, readObject(t, env, v2.StoragePath))
+	assert.Equal(t, []byte("v1 content"), readObject(t, env, v1Path))
+
+	updated := env.docs.docs["doc1"]
+	assert.Equal(t, v2.StoragePath, updated.StoragePath.String)
+	assert.Equal(t, "md", updated.DocType)
+	assert.Equal(t, knowledge_document.RunStatePending, updated.RunStatus)
+
+	// 重新索引经本地消息表投递
+	require.Len(t, env.messages.messages, 1)
+	require.Len(t, env.mq.indexMsgs, 1)
+	assert.Equal(t, "doc1", env.mq.indexMsgs[0].DocumentId)
+	assert.Equal(t, []uint64{1}, env.messages.retrying)
+}
+
+func TestUploadDocumentVersion_Running(t *testing.T) {
+	env := newTestEnv(t)
+	env.addKnowledgeBase("kb1", knowledge_base.DedupPolicyAllow)
+	doc := addDocument(t, env, "kb1", "doc1", []byte("v1 content"))
+	doc.RunStatus = knowledge_document.RunStateRunning
+
+	_, err := NewUploadDocumentVersionLogic(userCtx(testUserId), env.svcCtx).UploadDocumentVersion(
+		&types.UploadDocumentVersionReq{Id: "doc1"}, newFileHeader(t, "manual.txt", []byte("v2")))
+	require.Error(t, err)
+	assert.Empty(t, env.versions.versions)
+	assert.Empty(t, env.mq.indexMsgs)
+}
+
+func TestUploadDocumentVersion_DedupPolicy(t *testing.T) {
+	data := []byte("same as other")
+
+	t.Run("skip", func(t *testing.T) {
+		env := newTestEnv(t)
+		env.addKnowledgeBase("kb1", knowledge_base.DedupPolicySkip)
+		addDocument(t, env, "kb1", "doc1", []byte("v1 content"))
+		addDocument(t, env, "kb1", "other", data)
+
+		resp, err := NewUploadDocumentVersionLogic(userCtx(testUserId), env.svcCtx).UploadDocumentVersion(
+			&types.UploadDocumentVersionReq{Id: "doc1"}, newFileHeader(t, "manual.txt", data))
+		require.NoError(t, err)
+		assert.True(t, resp.Duplicate)
+		assert.Equal(t, "other", resp.DuplicateId)
+		assert.Equal(t, int64(1), resp.Version)
+
+		// 未创建新版本, 也未写入文件或触发索引
+		require.Len(t, env.versions.versions, 1)
+		_, err = env.oss.GetObject(context.Background(), testBucket, "tenant_"+testTenantId+"/kb_kb1/doc1_v2.txt")
+		assert.Error(t, err)
+		assert.Empty(t, env.mq.indexMsgs)
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		env := newTestEnv(t)
+		env.addKnowledgeBase("kb1", knowledge_base.DedupPolicyReject)
+		addDocument(t, env, "kb1", "doc1", []byte("v1 content"))
+		addDocument(t, env, "kb1", "other", data)
+
+		_, err := NewUploadDocumentVersionLogic(userCtx(testUserId), env.svcCtx).UploadDocumentVersion(
+			&types.UploadDocumentVersionReq{Id: "doc1"}, newFileHeader(t, "manual.txt", data))
+		assert.Equal(t, uint32(xerr.KnowledgeDocUploadError), errCode(t, err))
+		require.Len(t, env.versions.versions, 1)
+		assert.Empty(t, env.mq.indexMsgs)
+	})
+}
+
+func TestListDocumentVersions_MarksCurrent(t *testing.T) {
+	env := newTestEnv(t)
+	env.addKnowledgeBase("kb1", knowledge_base.DedupPolicyAllow)
+	addDocument(t, env, "kb1", "doc1", []byte("v1 content"))
+
+	ctx := userCtx(testUserId)
+	_, err := NewUploadDocumentVersionLogic(ctx, env.svcCtx).UploadDocumentVersion(
+		&types.UploadDocumentVersionReq{Id: "doc1"}, newFileHeader(t, "manual.txt", []byte("v2 content")))
+	require.NoError(t, err)
+
+	resp, err := NewListDocumentVersionsLogic(ctx, env.svcCtx).ListDocumentVersions(&types.ListDocumentVersionsReq{Id: "doc1"})
+	require.NoError(t, err)
+	require.Len(t, resp.List, 2)
+	assert.Equal(t, int64(2), resp.List[0].Version)
+	assert.True(t, resp.List[0].IsCurrent)
+	assert.False(t, resp.List[1].IsCurrent)
+}
+
+func TestRollbackDocumentVersion(t *testing.T) {
+	env := newTestEnv(t)
+	env.addKnowledgeBase("kb1", knowledge_base.DedupPolicyAllow)
+	doc := addDocument(t, env, "kb1", "doc1", []byte("v1 content"))
+	v1Path, v1Hash := doc.StoragePath.String, doc.FileHash
+
+	ctx := userCtx(testUserId)
+	_, err := NewUploadDocumentVersionLogic(ctx, env.svcCtx).UploadDocumentVersion(
+		&types.UploadDocumentVersionReq{Id: "doc1"}, newFileHeader(t, "manual.txt", []byte("v2 content")))
+	require.NoError(t, err)
+
+	_, err = NewRollbackDocumentVersionLogic(ctx, env.svcCtx).RollbackDocumentVersion(&types.RollbackDocumentVersionReq{Id: "doc1", Version: 1})
+	require.NoError(t, err)
+
+	// 切换回 v1 的文件并重新索引, 版本记录不变
+	updated := env.docs.docs["doc1"]
+	assert.Equal(t, v1Path, updated.StoragePath.String)
+	assert.Equal(t, v1Hash, updated.FileHash)
+	assert.Len(t, env.versions.versions, 2)
+	assert.Len(t, env.mq.indexMsgs, 2)
+
+	// 已是当前版本或版本不存在
+	_, err = NewRollbackDocumentVersionLogic(ctx, env.svcCtx).RollbackDocumentVersion(&types.RollbackDocumentVersionReq{Id: "doc1", Version: 1})
+	assert.Error(t, err)
+	_, err = NewRollbackDocumentVersionLogic(ctx, env.svcCtx).RollbackDocumentVersion(&types.RollbackDocumentVersionReq{Id: "doc1", Version: 9})
+	assert.Error(t, err)
+	assert.Len(t, env.mq.indexMsgs, 2)
+}
+
+func TestDeleteKnowledgeDocument_RemovesVersionsAndEnqueuesGraphRemoval(t *testing.T) {
+	env := newTestEnv(t)
+	env.addKnowledgeBase("kb1", knowledge_base.DedupPolicyAllow)
+	doc := addDocument(t, env, "kb1", "doc1", []byte("v1 content"))
+	v1Path := doc.StoragePath.String
+
+	ctx := userCtx(testUserId)
+	_, err := NewUploadDocumentVersionLogic(ctx, env.svcCtx).UploadDocumentVersion(
+		&types.UploadDocumentVersionReq{Id: "doc1"}, newFileHeader(t, "manual.txt", []byte("v2 content")))
+	require.NoError(t, err)
+	v2Path := env.docs.docs["doc1"].StoragePath.String
+	env.docs.docs["doc1"].RunStatus = knowledge_document.RunStateSuccess
+
+	_, err = NewDeleteKnowledgeDocumentLogic(ctx, env.svcCtx).DeleteKnowledgeDocument(&types.DeleteKnowledgeDocumentReq{Id: "doc1"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"doc1"}, env.chunks.deletedDocIds)
+	assert.NotContains(t, env.docs.docs, "doc1")
+	assert.Empty(t, env.versions.versions)
+	for _, key := range []string{v1Path, v2Path} {
+		_, err := env.oss.GetObject(context.Background(), testBucket, key)
+		assert.Error(t, err, key)
+	}
+
+	// 图谱贡献经本地消息表交给 graph_extract 消费者移除
+	require.Len(t, env.mq.graphMsgs, 1)
+	msg := env.mq.graphMsgs[0]
+	assert.Equal(t, []string{"doc1"}, msg.RemoveDocumentIds)
+	assert.Equal(t, "kb1", msg.KnowledgeBaseId)
+	assert.NotZero(t, msg.LocalMessageId)
+	assert.Contains(t, env.messages.retrying, msg.LocalMessageId)
+}
+
+func TestDeleteKnowledgeDocument_EnqueueFailureKeepsData(t *testing.T) {
+	env := newTestEnv(t)
+	env.addKnowledgeBase("kb1", knowledge_base.DedupPolicyAllow)
+	doc := addDocument(t, env, "kb1", "doc1", []byte("v1 content"))
+	env.messages.insertErr = errors.New("db down")
+
+	_, err := NewDeleteKnowledgeDocumentLogic(userCtx(testUserId), env.svcCtx).DeleteKnowledgeDocument(&types.DeleteKnowledgeDocumentReq{Id: "doc1"})
+	require.Error(t, err)
+
+	// 本地消息写入失败时不做任何删除, 文档可重新删除
+	assert.Empty(t, env.chunks.deletedDocIds)
+	assert.Contains(t, env.docs.docs, "doc1")
+	assert.Equal(t, []byte("v1 content"), readObject(t, env, doc.StoragePath.String))
+	assert.Empty(t, env.mq.graphMsgs)
+}
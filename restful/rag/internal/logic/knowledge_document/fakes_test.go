@@ -0,0 +1,303 @@
+package knowledge_document
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+
+	"gozero-rag/internal/indexctl"
+	"gozero-rag/internal/model/chunk"
+	"gozero-rag/internal/model/knowledge_base"
+	"gozero-rag/internal/model/knowledge_document"
+	"gozero-rag/internal/model/knowledge_document_version"
+	"gozero-rag/internal/model/local_message"
+	"gozero-rag/internal/mq"
+	"gozero-rag/internal/oss/osstest"
+	"gozero-rag/restful/rag/internal/svc"
+
+	"github.com/zeromicro/go-zero/core/stores/redis/redistest"
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+)
+
+const (
+	testBucket   = "test-bucket"
+	testTenantId = "tenant-1"
+	testUserId   = "user-1"
+)
+
+type fakeKnowledgeBaseModel struct {
+	knowledge_base.KnowledgeBaseModel
+	kbs map[string]*knowledge_base.KnowledgeBase
+}
+
+func (f *fakeKnowledgeBaseModel) FindOne(_ context.Context, id string) (*knowledge_base.KnowledgeBase, error) {
+	if kb, ok := f.kbs[id]; ok {
+		return kb, nil
+	}
+	return nil, knowledge_base.ErrNotFound
+}
+
+type fakeKnowledgeDocumentModel struct {
+	knowledge_document.KnowledgeDocumentModel
+	mu   sync.Mutex
+	docs map[string]*knowledge_document.KnowledgeDocument
+}
+
+func (f *fakeKnowledgeDocumentModel) FindOne(_ context.Context, id string) (*knowledge_document.KnowledgeDocument, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if doc, ok := f.docs[id]; ok {
+		return doc, nil
+	}
+	return nil, knowledge_document.ErrNotFound
+}
+
+func (f *fakeKnowledgeDocumentModel) Insert(_ context.Context, data *knowledge_document.KnowledgeDocument) (sql.Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.docs[data.Id] = data
+	return nil, nil
+}
+
+func (f *fakeKnowledgeDocumentModel) InsertWithSession(ctx context.Context, _ sqlx.Session, data *knowledge_document.KnowledgeDocument) error {
+	_, err := f.Insert(ctx, data)
+	return err
+}
+
+func (f *fakeKnowledgeDocumentModel) UpdateWithSession(_ context.Context, _ sqlx.Session, data *knowledge_document.KnowledgeDocument) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.docs[data.Id] = data
+	return nil
+}
+
+func (f *fakeKnowledgeDocumentModel) Delete(_ context.Context, id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.docs, id)
+	return nil
+}
+
+func (f *fakeKnowledgeDocumentModel) UpdateRunStatus(_ context.Context, id, status, msg string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if doc, ok := f.docs[id]; ok {
+		doc.RunStatus = status
+		doc.ProgressMsg = sql.NullString{String: msg, Valid: true}
+	}
+	return nil
+}
+
+func (f *fakeKnowledgeDocumentModel) CompareAndSetRunStatus(_ context.Context, id, expected, status, msg string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	doc, ok := f.docs[id]
+	if !ok || doc.RunStatus != expected {
+		return false, nil
+	}
+	doc.RunStatus = status
+	doc.ProgressMsg = sql.NullString{String: msg, Valid: true}
+	return true, nil
+}
+
+func (f *fakeKnowledgeDocumentModel) TransactCtx(ctx context.Context, fn func(context.Context, sqlx.Session) error) error {
+	return fn(ctx, nil)
+}
+
+func (f *fakeKnowledgeDocumentModel) FindOneByKbIdFileHash(_ context.Context, kbId, fileHash string) (*knowledge_document.KnowledgeDocument, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, doc := range f.docs {
+		if doc.KnowledgeBaseId == kbId && doc.FileHash == fileHash {
+			return doc, nil
+		}
+	}
+	return nil, knowledge_document.ErrNotFound
+}
+
+type fakeChunkModel struct {
+	chunk.ChunkModel
+	deletedDocIds []string
+}
+
+func (f *fakeChunkModel) DeleteByDocId(_ context.Context, _, docId string) error {
+	f.deletedDocIds = append(f.deletedDocIds, docId)
+	return nil
+}
+
+type fakeDocumentVersionModel struct {
+	knowledge_document_version.KnowledgeDocumentVersionModel
+	versions []*knowledge_document_version.KnowledgeDocumentVersion
+}
+
+func (f *fakeDocumentVersionModel) Insert(_ context.Context, data *knowledge_document_version.KnowledgeDocumentVersion) (sql.Result, error) {
+	f.versions = append(f.versions, data)
+	return nil, nil
+}
+
+func (f *fakeDocumentVersionModel) FindListByDocumentId(_ context.Context, documentId string) ([]*knowledge_document_version.KnowledgeDocumentVersion, error) {
+	var list []*knowledge_document_version.KnowledgeDocumentVersion
+	for i := len(f.versions) - 1; i >= 0; i-- {
+		if f.versions[i].DocumentId == documentId {
+			list = append(list, f.versions[i])
+		}
+	}
+	return list, nil
+}
+
+func (f *fakeDocumentVersionModel) FindOneByDocumentIdVersion(_ context.Context, documentId string, version int64) (*knowledge_document_version.KnowledgeDocumentVersion, error) {
+	for _, v := range f.versions {
+		if v.DocumentId == documentId && v.Version == version {
+			return v, nil
+		}
+	}
+	return nil, knowledge_document_version.ErrNotFound
+}
+
+func (f *fakeDocumentVersionModel) FindLatestVersion(_ context.Context, documentId string) (int64, error) {
+	var latest int64
+	for _, v := range f.versions {
+		if v.DocumentId == documentId && v.Version > latest {
+			latest = v.Version
+		}
+	}
+	return latest, nil
+}
+
+func (f *fakeDocumentVersionModel) DeleteByDocumentId(_ context.Context, documentId string) error {
+	kept := f.versions[:0]
+	for _, v := range f.versions {
+		if v.DocumentId != documentId {
+			kept = append(kept, v)
+		}
+	}
+	f.versions = kept
+	return nil
+}
+
+type fakeLocalMessageModel struct {
+	local_message.LocalMessageModel
+	messages  []*local_message.LocalMessage
+	retrying  []uint64
+	insertErr error
+}
+
+func (f *fakeLocalMessageModel) InsertWithSession(_ context.Context, _ sqlx.Session, data *local_message.LocalMessage) (uint64, error) {
+	if f.insertErr != nil {
+		return 0, f.insertErr
+	}
+	f.messages = append(f.messages, data)
+	return uint64(len(f.messages)), nil
+}
+
+func (f *fakeLocalMessageModel) InsertAndGetId(ctx context.Context, data *local_message.LocalMessage) (uint64, error) {
+	return f.InsertWithSession(ctx, nil, data)
+}
+
+func (f *fakeLocalMessageModel) UpdateRetrying(_ context.Context, id uint64) error {
+	f.retrying = append(f.retrying, id)
+	return nil
+}
+
+type fakeMq struct {
+	indexMsgs []*mq.KnowledgeDocumentIndexMsg
+	graphMsgs []*mq.GraphGenerateMsg
+}
+
+func (f *fakeMq) PublishGraphGenerateMsg(_ context.Context, msg *mq.GraphGenerateMsg) error {
+	f.graphMsgs = append(f.graphMsgs, msg)
+	return nil
+}
+
+func (f *fakeMq) PublishDocumentIndex(_ context.Context, msg *mq.KnowledgeDocumentIndexMsg) error {
+	f.indexMsgs = append(f.indexMsgs, msg)
+	return nil
+}
+
+type memoryUploadSessionStore struct {
+	sessions map[string]*uploadSession
+}
+
+func (m *memoryUploadSessionStore) Save(_ context.Context, s *uploadSession) error {
+	cp := *s
+	m.sessions[s.SessionId] = &cp
+	return nil
+}
+
+func (m *memoryUploadSessionStore) Load(_ context.Context, sessionId string) (*uploadSession, error) {
+	s, ok := m.sessions[sessionId]
+	if !ok {
+		return nil, nil
+	}
+	cp := *s
+	return &cp, nil
+}
+
+func (m *memoryUploadSessionStore) Delete(_ context.Context, s *uploadSession) {
+	delete(m.sessions, s.SessionId)
+}
+
+// testEnv 知识文档逻辑测试的依赖, 存储均为内存实现
+type testEnv struct {
+	svcCtx   *svc.ServiceContext
+	oss      *osstest.MemoryClient
+	kbs      *fakeKnowledgeBaseModel
+	docs     *fakeKnowledgeDocumentModel
+	chunks   *fakeChunkModel
+	versions *fakeDocumentVersionModel
+	messages *fakeLocalMessageModel
+	mq       *fakeMq
+	sessions *memoryUploadSessionStore
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	env := &testEnv{
+		oss:      osstest.NewMemoryClient(),
+		kbs:      &fakeKnowledgeBaseModel{kbs: make(map[string]*knowledge_base.KnowledgeBase)},
+		docs:     &fakeKnowledgeDocumentModel{docs: make(map[string]*knowledge_document.KnowledgeDocument)},
+		chunks:   &fakeChunkModel{},
+		versions: &fakeDocumentVersionModel{},
+		messages: &fakeLocalMessageModel{},
+		mq:       &fakeMq{},
+		sessions: &memoryUploadSessionStore{sessions: make(map[string]*uploadSession)},
+	}
+	rds := redistest.CreateRedis(t)
+	env.svcCtx = &svc.ServiceContext{
+		MqPusherClient:         env.mq,
+		OssClient:              env.oss,
+		KnowledgeBaseModel:     env.kbs,
+		KnowledgeDocumentModel: env.docs,
+		ChunkModel:             env.chunks,
+		DocumentVersionModel:   env.versions,
+		LocalMessageModel:      env.messages,
+		RedisClient:            rds,
+		// 进程内 Redis, 存储索引信号及检查点
+		IndexSignal:     indexctl.NewSignalStore(rds),
+		IndexCheckpoint: indexctl.NewCheckpointStore(rds),
+	}
+	env.svcCtx.Config.Oss.BucketName = testBucket
+
+	origin := newUploadSessionStore
+	newUploadSessionStore = func(*svc.ServiceContext) uploadSessionStore { return env.sessions }
+	t.Cleanup(func() { newUploadSessionStore = origin })
+	return env
+}
+
+// addKnowledgeBase 添加属于测试租户的知识库
+func (e *testEnv) addKnowledgeBase(id, dedupPolicy string) *knowledge_base.KnowledgeBase {
+	kb := &knowledge_base.KnowledgeBase{
+		Id:          id,
+		TenantId:    testTenantId,
+		ParserId:    "naive",
+		DedupPolicy: dedupPolicy,
+		AutoParse:   1,
+	}
+	e.kbs.kbs[id] = kb
+	return kb
+}
+
+func userCtx(userId string) context.Context {
+	ctx := context.WithValue(context.Background(), "uid", userId)
+	return context.WithValue(ctx, "tenant_id", testTenantId)
+}
@@ -53,7 +53,12 @@ func (l *GetKnowledgeDocumentLogic) GetKnowledgeDocument(req *types.GetKnowledge
 			RunStatus:       doc.RunStatus,
 			ChunkNum:        doc.ChunkNum,
 			TokenNum:        doc.TokenNum,
+			ChunkAdded:      doc.ChunkAdded,
+			ChunkRemoved:    doc.ChunkRemoved,
+			ChunkReused:     doc.ChunkReused,
 			ParserConfig:    doc.ParserConfig,
+			EffectiveConfig: doc.EffectiveConfig.String,
+			FileHash:        doc.FileHash,
 			Progress:        doc.Progress,
 			ProgressMsg:     doc.ProgressMsg.String,
 			CreatedBy:       doc.CreatedBy,
@@ -0,0 +1,60 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package knowledge_document
+
+import (
+	"context"
+	"fmt"
+
+	"gozero-rag/internal/xerr"
+
+	"gozero-rag/restful/rag/internal/svc"
+	"gozero-rag/restful/rag/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetUploadPartUrlsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 获取分片上传地址
+func NewGetUploadPartUrlsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetUploadPartUrlsLogic {
+	return &GetUploadPartUrlsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetUploadPartUrls 为指定分片签发预签名上传地址, 地址过期后可重新获取
+func (l *GetUploadPartUrlsLogic) GetUploadPartUrls(req *types.GetUploadPartUrlsReq) (resp *types.GetUploadPartUrlsResp, err error) {
+	session, err := loadUploadSession(l.ctx, l.svcCtx, req.SessionId)
+	if err != nil {
+		return nil, err
+	}
+	if session.Mode != UploadModeMultipart {
+		return nil, xerr.NewBadRequestErrMsg("该会话不是分片上传")
+	}
+	if len(req.PartNumbers) == 0 {
+		return nil, xerr.NewBadRequestErrMsg("分片序号不能为空")
+	}
+
+	list := make([]types.UploadPartUrl, 0, len(req.PartNumbers))
+	for _, num := range req.PartNumbers {
+		if num < 1 || num > session.PartCount {
+			return nil, xerr.NewBadRequestErrMsg(fmt.Sprintf("分片序号超出范围: %d", num))
+		}
+		u, err := l.svcCtx.OssClient.PresignedUploadPart(l.ctx, l.svcCtx.Config.Oss.BucketName, session.ObjectKey, session.UploadId, int(num), uploadPresignExpire)
+		if err != nil {
+			l.Errorf("GetUploadPartUrls presign failed: session=%s, part=%d, err=%v", session.SessionId, num, err)
+			return nil, xerr.NewErrCodeMsg(xerr.KnowledgeDocUploadError, "生成上传地址失败")
+		}
+		list = append(list, types.UploadPartUrl{PartNumber: num, Url: u})
+	}
+
+	return &types.GetUploadPartUrlsResp{List: list}, nil
+}
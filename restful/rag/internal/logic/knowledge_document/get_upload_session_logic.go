@@ -0,0 +1,72 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package knowledge_document
+
+import (
+	"context"
+
+	"gozero-rag/internal/xerr"
+
+	"gozero-rag/restful/rag/internal/svc"
+	"gozero-rag/restful/rag/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetUploadSessionLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 查询直传会话
+func NewGetUploadSessionLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetUploadSessionLogic {
+	return &GetUploadSessionLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetUploadSession 返回会话信息及存储端已上传的分片, 客户端据此续传缺失分片
+func (l *GetUploadSessionLogic) GetUploadSession(req *types.GetUploadSessionReq) (resp *types.GetUploadSessionResp, err error) {
+	session, err := loadUploadSession(l.ctx, l.svcCtx, req.SessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	resp = &types.GetUploadSessionResp{
+		SessionId:     session.SessionId,
+		Mode:          session.Mode,
+		FileName:      session.FileName,
+		FileSize:      session.FileSize,
+		PartSize:      session.PartSize,
+		PartCount:     session.PartCount,
+		UploadedParts: []types.UploadPartInfo{},
+		ExpireTime:    session.ExpireTime,
+	}
+
+	bucket := l.svcCtx.Config.Oss.BucketName
+	if session.Mode == UploadModeMultipart {
+		parts, err := l.svcCtx.OssClient.ListParts(l.ctx, bucket, session.ObjectKey, session.UploadId)
+		if err != nil {
+			l.Errorf("GetUploadSession list parts failed: session=%s, err=%v", session.SessionId, err)
+			return nil, xerr.NewErrCodeMsg(xerr.KnowledgeDocUploadError, "查询已上传分片失败")
+		}
+		for _, p := range parts {
+			resp.UploadedParts = append(resp.UploadedParts, types.UploadPartInfo{
+				PartNumber: int64(p.PartNumber),
+				ETag:       p.ETag,
+				Size:       p.Size,
+			})
+		}
+		return resp, nil
+	}
+
+	resp.UploadUrl, err = l.svcCtx.OssClient.PresignedPutObject(l.ctx, bucket, session.ObjectKey, uploadPresignExpire)
+	if err != nil {
+		return nil, xerr.NewErrCodeMsg(xerr.KnowledgeDocUploadError, "生成上传地址失败")
+	}
+	return resp, nil
+}
@@ -0,0 +1,116 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package knowledge_document
+
+import (
+	"context"
+
+	"gozero-rag/internal/indexctl"
+	"gozero-rag/internal/model/knowledge_base"
+	"gozero-rag/internal/xerr"
+	"gozero-rag/restful/rag/internal/common"
+
+	"gozero-rag/restful/rag/internal/svc"
+	"gozero-rag/restful/rag/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type IndexProgressLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	kb     *knowledge_base.KnowledgeBase
+	events <-chan *indexctl.ProgressEvent
+}
+
+// 订阅知识库文档索引进度
+func NewIndexProgressLogic(ctx context.Context, svcCtx *svc.ServiceContext) *IndexProgressLogic {
+	return &IndexProgressLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Subscribe 校验知识库权限并订阅进度, 在开始推送 SSE 之前调用, 失败时可按普通请求返回错误
+func (l *IndexProgressLogic) Subscribe(req *types.IndexProgressReq) error {
+	tenantId, err := common.GetTenantIdFromCtx(l.ctx)
+	if err != nil {
+		return err
+	}
+
+	kb, err := l.svcCtx.KnowledgeBaseModel.FindOne(l.ctx, req.KnowledgeBaseId)
+	if err != nil {
+		if err == knowledge_base.ErrNotFound {
+			return xerr.NewErrCodeMsg(xerr.KnowledgeBaseNotFoundError, "知识库不存在")
+		}
+		return xerr.NewInternalErrMsg(err.Error())
+	}
+	if kb.TenantId != tenantId {
+		return xerr.NewErrCodeMsg(xerr.ForbiddenError, "无权操作此知识库")
+	}
+
+	// 先订阅再查询快照, 避免两者之间的事件丢失
+	events, err := l.svcCtx.IndexProgress.Subscribe(l.ctx, kb.Id)
+	if err != nil {
+		l.Errorf("IndexProgress subscribe failed: kbId=%s, err=%v", kb.Id, err)
+		return xerr.NewInternalErrMsg("订阅索引进度失败")
+	}
+	l.kb, l.events = kb, events
+	return nil
+}
+
+// IndexProgress 先推送未完成文档的当前进度快照, 再持续转发消费者发布的进度事件, 直到连接断开
+// 未调用 Subscribe 时先完成校验和订阅
+func (l *IndexProgressLogic) IndexProgress(req *types.IndexProgressReq, client chan<- *types.IndexProgressEvent) error {
+	if l.events == nil {
+		if err := l.Subscribe(req); err != nil {
+			return err
+		}
+	}
+	kb, events := l.kb, l.events
+
+	docs, err := l.svcCtx.KnowledgeDocumentModel.FindUnfinishedByKbId(l.ctx, kb.Id)
+	if err != nil {
+		return xerr.NewInternalErrMsg(err.Error())
+	}
+	for _, doc := range docs {
+		l.send(client, &types.IndexProgressEvent{
+			DocumentId:      doc.Id,
+			KnowledgeBaseId: doc.KnowledgeBaseId,
+			Progress:        doc.Progress,
+			Message:         doc.ProgressMsg.String,
+			RunStatus:       doc.RunStatus,
+			Time:            doc.UpdatedTime,
+		})
+	}
+
+	for ev := range events {
+		l.send(client, toIndexProgressEvent(ev))
+	}
+	return nil
+}
+
+// send 连接断开后不再阻塞写入
+func (l *IndexProgressLogic) send(client chan<- *types.IndexProgressEvent, ev *types.IndexProgressEvent) {
+	select {
+	case client <- ev:
+	case <-l.ctx.Done():
+	}
+}
+
+func toIndexProgressEvent(ev *indexctl.ProgressEvent) *types.IndexProgressEvent {
+	return &types.IndexProgressEvent{
+		DocumentId:      ev.DocumentId,
+		KnowledgeBaseId: ev.KnowledgeBaseId,
+		Step:            ev.Step,
+		Progress:        ev.Progress,
+		Current:         ev.Current,
+		Total:           ev.Total,
+		Message:         ev.Message,
+		RunStatus:       ev.RunStatus,
+		Time:            ev.Time,
+	}
+}
@@ -0,0 +1,30 @@
+package knowledge_document
+
+import (
+	"testing"
+
+	"gozero-rag/internal/model/knowledge_base"
+	"gozero-rag/internal/xerr"
+	"gozero-rag/restful/rag/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 权限校验在推送开始前完成, 失败时返回可直接响应的业务错误
+func TestIndexProgress_SubscribeChecksPermission(t *testing.T) {
+	env := newTestEnv(t)
+	kb := env.addKnowledgeBase("kb1", knowledge_base.DedupPolicyAllow)
+	kb.TenantId = "other-tenant"
+
+	l := NewIndexProgressLogic(userCtx(testUserId), env.svcCtx)
+	err := l.Subscribe(&types.IndexProgressReq{KnowledgeBaseId: "kb1"})
+	require.Error(t, err)
+	var codeErr *xerr.CodeError
+	require.ErrorAs(t, err, &codeErr)
+	assert.Equal(t, uint32(xerr.ForbiddenError), codeErr.GetErrCode())
+
+	err = l.Subscribe(&types.IndexProgressReq{KnowledgeBaseId: "missing"})
+	require.ErrorAs(t, err, &codeErr)
+	assert.Equal(t, uint32(xerr.KnowledgeBaseNotFoundError), codeErr.GetErrCode())
+}
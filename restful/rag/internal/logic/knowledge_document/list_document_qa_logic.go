@@ -0,0 +1,56 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package knowledge_document
+
+import (
+	"context"
+
+	"gozero-rag/internal/model/chunk"
+	"gozero-rag/internal/xerr"
+	"gozero-rag/restful/rag/internal/svc"
+	"gozero-rag/restful/rag/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListDocumentQaLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 获取文档 QA 列表
+func NewListDocumentQaLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListDocumentQaLogic {
+	return &ListDocumentQaLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ListDocumentQa 分页查询文档生成的 QA, 可按来源切片和审核状态过滤
+func (l *ListDocumentQaLogic) ListDocumentQa(req *types.ListDocumentQaReq) (resp *types.ListDocumentQaResp, err error) {
+	if _, _, err := findDocumentWithPermission(l.ctx, l.svcCtx, req.Id); err != nil {
+		return nil, err
+	}
+
+	result, err := l.svcCtx.ChunkModel.ListQaByDocId(l.ctx, req.Id, &chunk.QaFilter{
+		SourceId: req.SourceId,
+		Status:   req.QaStatus,
+	}, req.Page, req.PageSize)
+	if err != nil {
+		l.Errorf("ListDocumentQa failed: docId=%s, err=%v", req.Id, err)
+		return nil, xerr.NewInternalErrMsg("查询 QA 列表失败")
+	}
+
+	list := make([]types.DocumentQaInfo, 0, len(result.Chunks))
+	for _, c := range result.Chunks {
+		list = append(list, toDocumentQaInfo(c))
+	}
+
+	return &types.ListDocumentQaResp{
+		Total: result.Total,
+		List:  list,
+	}, nil
+}
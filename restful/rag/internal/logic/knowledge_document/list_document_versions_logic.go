@@ -0,0 +1,63 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package knowledge_document
+
+import (
+	"context"
+
+	"gozero-rag/internal/xerr"
+
+	"gozero-rag/restful/rag/internal/svc"
+	"gozero-rag/restful/rag/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListDocumentVersionsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 获取文档版本历史
+func NewListDocumentVersionsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListDocumentVersionsLogic {
+	return &ListDocumentVersionsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ListDocumentVersions 按版本号倒序返回版本历史, 存储路径与文档当前文件一致的为当前版本
+func (l *ListDocumentVersionsLogic) ListDocumentVersions(req *types.ListDocumentVersionsReq) (resp *types.ListDocumentVersionsResp, err error) {
+	doc, _, err := findDocumentWithPermission(l.ctx, l.svcCtx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := ensureInitialVersion(l.ctx, l.svcCtx, doc); err != nil {
+		return nil, err
+	}
+
+	versions, err := l.svcCtx.DocumentVersionModel.FindListByDocumentId(l.ctx, doc.Id)
+	if err != nil {
+		return nil, xerr.NewInternalErrMsg(err.Error())
+	}
+
+	list := make([]types.DocumentVersionInfo, 0, len(versions))
+	for _, v := range versions {
+		list = append(list, types.DocumentVersionInfo{
+			Version:     v.Version,
+			DocName:     v.DocName,
+			DocType:     v.DocType,
+			DocSize:     v.DocSize,
+			FileHash:    v.FileHash,
+			CreatedBy:   v.CreatedBy,
+			CreatedTime: v.CreatedTime,
+			IsCurrent:   v.StoragePath == doc.StoragePath.String,
+		})
+	}
+
+	return &types.ListDocumentVersionsResp{List: list}, nil
+}
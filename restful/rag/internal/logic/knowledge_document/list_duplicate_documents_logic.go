@@ -0,0 +1,74 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package knowledge_document
+
+import (
+	"context"
+
+	"gozero-rag/internal/xerr"
+	"gozero-rag/restful/rag/internal/common"
+
+	"gozero-rag/restful/rag/internal/svc"
+	"gozero-rag/restful/rag/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListDuplicateDocumentsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 查询租户下内容重复的文档
+func NewListDuplicateDocumentsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListDuplicateDocumentsLogic {
+	return &ListDuplicateDocumentsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ListDuplicateDocuments 按文件哈希分组返回租户下所有知识库中内容重复的文档
+func (l *ListDuplicateDocumentsLogic) ListDuplicateDocuments() (resp *types.ListDuplicateDocumentsResp, err error) {
+	tenantId, err := common.GetTenantIdFromCtx(l.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	docs, err := l.svcCtx.KnowledgeDocumentModel.FindDuplicatesByTenantId(l.ctx, tenantId)
+	if err != nil {
+		l.Errorf("查询重复文档失败: tenantId=%s, err=%v", tenantId, err)
+		return nil, xerr.NewInternalErrMsg("查询重复文档失败")
+	}
+
+	// 结果已按 file_hash 排序, 相邻的同哈希文档归为一组
+	kbNames := make(map[string]string)
+	list := make([]types.DuplicateDocumentGroup, 0)
+	for _, doc := range docs {
+		name, ok := kbNames[doc.KnowledgeBaseId]
+		if !ok {
+			if kb, err := l.svcCtx.KnowledgeBaseModel.FindOne(l.ctx, doc.KnowledgeBaseId); err == nil {
+				name = kb.Name
+			}
+			kbNames[doc.KnowledgeBaseId] = name
+		}
+
+		if len(list) == 0 || list[len(list)-1].FileHash != doc.FileHash {
+			list = append(list, types.DuplicateDocumentGroup{FileHash: doc.FileHash})
+		}
+		group := &list[len(list)-1]
+		group.Documents = append(group.Documents, types.DuplicateDocumentInfo{
+			Id:                doc.Id,
+			KnowledgeBaseId:   doc.KnowledgeBaseId,
+			KnowledgeBaseName: name,
+			DocName:           doc.DocName.String,
+			DocSize:           doc.DocSize,
+			RunStatus:         doc.RunStatus,
+			CreatedTime:       doc.CreatedTime,
+		})
+	}
+
+	return &types.ListDuplicateDocumentsResp{List: list}, nil
+}
@@ -6,6 +6,7 @@ package knowledge_document
 import (
 	"context"
 
+	"gozero-rag/internal/model/chunk"
 	"gozero-rag/restful/rag/internal/svc"
 	"gozero-rag/restful/rag/internal/types"
 
@@ -43,16 +44,8 @@ func (l *ListKnowledgeDocumentChunksLogic) ListKnowledgeDocumentChunks(req *type
 
 	// 组装响应
 	list := make([]types.ChunkInfo, 0, len(result.Chunks))
-	for _, chunk := range result.Chunks {
-		list = append(list, types.ChunkInfo{
-			Id:          chunk.Id,
-			Content:     chunk.Content,
-			DocId:       chunk.DocId,
-			DocName:     chunk.DocName,
-			ImportantKw: chunk.ImportantKw,
-			CreatedAt:   chunk.CreateTime,
-			Status:      chunk.Available,
-		})
+	for _, c := range result.Chunks {
+		list = append(list, toChunkInfo(c))
 	}
 
 	resp = &types.ListKnowledgeDocumentChunksResp{
@@ -62,3 +55,21 @@ func (l *ListKnowledgeDocumentChunksLogic) ListKnowledgeDocumentChunks(req *type
 
 	return resp, nil
 }
+
+func toChunkInfo(c *chunk.Chunk) types.ChunkInfo {
+	var qualityScore float64
+	if c.QualityScore != nil {
+		qualityScore = *c.QualityScore
+	}
+	return types.ChunkInfo{
+		Id:            c.Id,
+		Content:       c.Content,
+		DocId:         c.DocId,
+		DocName:       c.DocName,
+		ImportantKw:   c.ImportantKw,
+		CreatedAt:     c.CreateTime,
+		Status:        c.Available,
+		QualityScore:  qualityScore,
+		QualityIssues: c.QualityIssues,
+	}
+}
@@ -104,7 +104,12 @@ func (l *ListKnowledgeDocumentLogic) ListKnowledgeDocument(req *types.ListKnowle
 			RunStatus:       doc.RunStatus,
 			ChunkNum:        doc.ChunkNum,
 			TokenNum:        doc.TokenNum,
+			ChunkAdded:      doc.ChunkAdded,
+			ChunkRemoved:    doc.ChunkRemoved,
+			ChunkReused:     doc.ChunkReused,
 			ParserConfig:    doc.ParserConfig,
+			EffectiveConfig: doc.EffectiveConfig.String,
+			FileHash:        doc.FileHash,
 			Progress:        doc.Progress,
 			ProgressMsg:     doc.ProgressMsg.String,
 			CreatedBy:       doc.CreatedBy,
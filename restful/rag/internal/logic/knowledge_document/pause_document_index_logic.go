@@ -0,0 +1,57 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package knowledge_document
+
+import (
+	"context"
+
+	"gozero-rag/internal/indexctl"
+	"gozero-rag/internal/model/knowledge_document"
+	"gozero-rag/internal/xerr"
+
+	"gozero-rag/restful/rag/internal/svc"
+	"gozero-rag/restful/rag/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type PauseDocumentIndexLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 暂停文档索引
+func NewPauseDocumentIndexLogic(ctx context.Context, svcCtx *svc.ServiceContext) *PauseDocumentIndexLogic {
+	return &PauseDocumentIndexLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PauseDocumentIndex 暂停索引
+// 正在索引的文档在当前 embedding 批次完成后由消费者置为 paused, 已完成的批次记录在检查点中
+func (l *PauseDocumentIndexLogic) PauseDocumentIndex(req *types.PauseDocumentIndexReq) (resp *types.PauseDocumentIndexResp, err error) {
+	doc, _, err := findDocumentWithPermission(l.ctx, l.svcCtx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if doc.RunStatus != knowledge_document.RunStateRunning && doc.RunStatus != knowledge_document.RunStatePending {
+		return nil, xerr.NewBadRequestErrMsg("文档当前状态不可暂停")
+	}
+
+	if err := l.svcCtx.IndexSignal.Send(l.ctx, doc.Id, indexctl.SignalPause); err != nil {
+		return nil, xerr.NewInternalErrMsg("发送暂停信号失败")
+	}
+	if doc.RunStatus == knowledge_document.RunStateRunning {
+		return &types.PauseDocumentIndexResp{RunStatus: doc.RunStatus}, nil
+	}
+
+	// 尚未开始索引, 直接置为暂停, 消费者读取到非 pending 状态会跳过该消息
+	if err := l.svcCtx.KnowledgeDocumentModel.UpdateRunStatus(l.ctx, doc.Id, knowledge_document.RunStatePaused, "已暂停"); err != nil {
+		return nil, xerr.NewInternalErrMsg("更新文档状态失败")
+	}
+	return &types.PauseDocumentIndexResp{RunStatus: knowledge_document.RunStatePaused}, nil
+}
@@ -0,0 +1,185 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package knowledge_document
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gozero-rag/internal/model/knowledge_base"
+	"gozero-rag/internal/model/knowledge_document"
+	"gozero-rag/internal/rag_core/parser"
+	ragtypes "gozero-rag/internal/rag_core/types"
+	"gozero-rag/internal/tools/llmx"
+	"gozero-rag/internal/xerr"
+	"gozero-rag/restful/rag/internal/metric"
+	"gozero-rag/restful/rag/internal/svc"
+	"gozero-rag/restful/rag/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type PreviewKnowledgeDocumentLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 预览文档分片
+func NewPreviewKnowledgeDocumentLogic(ctx context.Context, svcCtx *svc.ServiceContext) *PreviewKnowledgeDocumentLogic {
+	return &PreviewKnowledgeDocumentLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PreviewKnowledgeDocument 使用候选解析配置切分文档, 返回前 N 个切片及质量评分, 不写入索引
+func (l *PreviewKnowledgeDocumentLogic) PreviewKnowledgeDocument(req *types.PreviewKnowledgeDocumentReq) (resp *types.PreviewKnowledgeDocumentResp, err error) {
+	start := time.Now()
+
+	// 1. 查询文档与知识库, 校验权限
+	doc, kb, err := findDocumentWithPermission(l.ctx, l.svcCtx, req.Id)
+	if err != nil {
+		metric.RecordPreviewFail("", previewFailReason(err))
+		return nil, err
+	}
+
+	// 2. 解析候选配置: 候选配置 > 文档配置 > 知识库配置, 与索引时的叠加规则一致
+	parserId := req.ParserId
+	if parserId == "" {
+		parserId = doc.ParserId
+	}
+	if parserId == "" {
+		parserId = kb.ParserId
+	}
+	template, err := parser.ResolveParserConfig(parserId, kb.ParserConfig.String, doc.ParserConfig, req.ParserConfig)
+	if err != nil {
+		metric.RecordPreviewFail(doc.DocType, metric.PreviewFailReasonInvalidParam)
+		return nil, xerr.NewBadRequestErrMsg(err.Error())
+	}
+
+	// 3. 下载文件
+	filePath, cleanup, err := l.downloadToTemp(doc)
+	if err != nil {
+		l.Errorf("[Preview] 下载文档 %s 失败: %v", doc.Id, err)
+		metric.RecordPreviewFail(doc.DocType, metric.PreviewFailReasonLoadError)
+		return nil, xerr.NewErrCodeMsg(xerr.FileNotFoundError, "文档文件读取失败")
+	}
+	defer cleanup()
+
+	// 4. 切分并评分
+	indexConfig := parser.NewProcessConfig(parserId, template)
+	indexConfig.KnowledgeName = doc.DocName.String
+	indexConfig.LlmConfig = l.buildLlmConfig(kb, template.General())
+
+	result, err := l.svcCtx.DocPreviewer.Preview(l.ctx, &ragtypes.ProcessRequest{
+		URI:         filePath,
+		IndexConfig: indexConfig,
+	}, req.Limit)
+	if err != nil {
+		l.Errorf("[Preview] 文档 %s 切分失败: %v", doc.Id, err)
+		metric.RecordPreviewFail(doc.DocType, metric.PreviewFailReasonSplitError)
+		return nil, xerr.NewInternalErrMsg(err.Error())
+	}
+
+	metric.RecordPreviewSuccess(doc.DocType, float64(time.Since(start).Milliseconds()), result.ContentLength, result.TotalChunks, result.Truncated)
+
+	// 5. 组装响应
+	list := make([]types.PreviewChunkInfo, 0, len(result.Chunks))
+	for _, c := range result.Chunks {
+		list = append(list, types.PreviewChunkInfo{
+			Index:      c.Index,
+			Content:    c.Content,
+			Length:     c.Length,
+			Header:     c.Header,
+			HeaderType: c.HeaderType,
+			Quality: types.ChunkQualityScore{
+				TotalScore:     c.Quality.TotalScore,
+				LengthScore:    c.Quality.LengthScore,
+				StructureScore: c.Quality.StructureScore,
+				ContentScore:   c.Quality.ContentScore,
+				SemanticScore:  c.Quality.SemanticScore,
+				QAScore:        c.Quality.QAScore,
+				Issues:         c.Quality.Issues,
+				Suggestions:    c.Quality.Suggestions,
+			},
+		})
+	}
+
+	return &types.PreviewKnowledgeDocumentResp{
+		ContentLength: result.ContentLength,
+		Truncated:     result.Truncated,
+		TotalChunks:   result.TotalChunks,
+		TotalLength:   result.TotalLength,
+		List:          list,
+	}, nil
+}
+
+// previewFailReason 文档或知识库不存在记为 doc_not_found, 无权访问记为 forbidden, 数据库等错误记为 server_error
+func previewFailReason(err error) string {
+	var codeErr *xerr.CodeError
+	if errors.As(err, &codeErr) {
+		switch codeErr.GetErrCode() {
+		case xerr.KnowledgeDocNotFoundError, xerr.KnowledgeBaseNotFoundError:
+			return metric.PreviewFailReasonDocNotFound
+		case xerr.ForbiddenError:
+			return metric.PreviewFailReasonForbidden
+		}
+	}
+	return metric.PreviewFailReasonServerError
+}
+
+func (l *PreviewKnowledgeDocumentLogic) downloadToTemp(doc *knowledge_document.KnowledgeDocument) (string, func(), error) {
+	tempFile, err := os.CreateTemp("", fmt.Sprintf("rag_preview_%s_*%s", doc.Id, filepath.Ext(doc.DocName.String)))
+	if err != nil {
+		return "", nil, fmt.Errorf("创建临时文件失败: %w", err)
+	}
+
+	cleanup := func() {
+		tempFile.Close()
+		os.Remove(tempFile.Name())
+	}
+
+	err = l.svcCtx.OssClient.FGetObject(l.ctx, l.svcCtx.Config.Oss.BucketName, doc.StoragePath.String, tempFile.Name())
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return tempFile.Name(), cleanup, nil
+}
+
+// buildLlmConfig semantic 分片需要 Embedding 模型, agentic 分片需要对话模型; 获取失败时由 transformer 回退到 structure
+func (l *PreviewKnowledgeDocumentLogic) buildLlmConfig(kb *knowledge_base.KnowledgeBase, general *parser.ParserConfigGeneral) ragtypes.ProcessLlmConfig {
+	var config ragtypes.ProcessLlmConfig
+
+	switch general.ChunkMethod {
+	case parser.ChunkMethodSemantic:
+		modelName, factory := llmx.GetModelNameFactory(kb.EmbdId)
+		embdModel, err := l.svcCtx.TenantLlmModel.FindOneByTenantIdLlmFactoryLlmName(l.ctx, kb.TenantId, factory, modelName)
+		if err != nil {
+			l.Infof("[Preview] Embedding 模型 %s 获取失败: %v", kb.EmbdId, err)
+			return config
+		}
+		config.EmbeddingKey = embdModel.ApiKey.String
+		config.EmbeddingBaseUrl = embdModel.ApiBase.String
+		config.EmbeddingModelName = embdModel.LlmName
+	case parser.ChunkMethodAgentic:
+		modelName, factory := llmx.GetModelNameFactory(general.ChunkLlmId)
+		chatModel, err := l.svcCtx.TenantLlmModel.FindOneByTenantIdLlmFactoryLlmName(l.ctx, kb.TenantId, factory, modelName)
+		if err != nil {
+			l.Infof("[Preview] 分片模型 %s 获取失败: %v", general.ChunkLlmId, err)
+			return config
+		}
+		config.ChatKey = chatModel.ApiKey.String
+		config.ChatBaseUrl = chatModel.ApiBase.String
+		config.ChatModelName = modelName
+	}
+
+	return config
+}
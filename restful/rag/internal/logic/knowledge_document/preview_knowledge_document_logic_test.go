@@ -0,0 +1,19 @@
+package knowledge_document
+
+import (
+	"errors"
+	"testing"
+
+	"gozero-rag/internal/xerr"
+	"gozero-rag/restful/rag/internal/metric"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreviewFailReason(t *testing.T) {
+	assert.Equal(t, metric.PreviewFailReasonDocNotFound, previewFailReason(xerr.NewErrCode(xerr.KnowledgeDocNotFoundError)))
+	assert.Equal(t, metric.PreviewFailReasonDocNotFound, previewFailReason(xerr.NewErrCode(xerr.KnowledgeBaseNotFoundError)))
+	assert.Equal(t, metric.PreviewFailReasonForbidden, previewFailReason(xerr.NewErrCodeMsg(xerr.ForbiddenError, "无权操作此知识库")))
+	assert.Equal(t, metric.PreviewFailReasonServerError, previewFailReason(xerr.NewInternalErrMsg("db down")))
+	assert.Equal(t, metric.PreviewFailReasonServerError, previewFailReason(errors.New("unknown")))
+}
@@ -0,0 +1,89 @@
+package knowledge_document
+
+import (
+	"context"
+
+	"gozero-rag/internal/model/chunk"
+	"gozero-rag/internal/model/knowledge_base"
+	"gozero-rag/internal/model/knowledge_document"
+	"gozero-rag/internal/tools/llmx"
+	"gozero-rag/internal/xerr"
+	"gozero-rag/restful/rag/internal/svc"
+	"gozero-rag/restful/rag/internal/types"
+
+	"github.com/cloudwego/eino-ext/components/embedding/openai"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// findChunkWithPermission 查询切片及所属文档, 并校验当前租户的操作权限
+func findChunkWithPermission(ctx context.Context, svcCtx *svc.ServiceContext, chunkId string) (*chunk.Chunk, *knowledge_document.KnowledgeDocument, *knowledge_base.KnowledgeBase, error) {
+	chunks, err := svcCtx.ChunkModel.GetByIds(ctx, []string{chunkId})
+	if err != nil {
+		return nil, nil, nil, xerr.NewInternalErrMsg(err.Error())
+	}
+	if len(chunks) == 0 {
+		return nil, nil, nil, xerr.NewErrCode(xerr.KnowledgeChunkNotFoundError)
+	}
+
+	doc, kb, err := findDocumentWithPermission(ctx, svcCtx, chunks[0].DocId)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return chunks[0], doc, kb, nil
+}
+
+// findQaWithPermission 查询 QA 分片并校验权限
+func findQaWithPermission(ctx context.Context, svcCtx *svc.ServiceContext, qaId string) (*chunk.Chunk, *knowledge_base.KnowledgeBase, error) {
+	qa, _, kb, err := findChunkWithPermission(ctx, svcCtx, qaId)
+	if err != nil {
+		return nil, nil, err
+	}
+	if qa.ChunkType != chunk.ChunkTypeQa {
+		return nil, nil, xerr.NewErrCodeMsg(xerr.KnowledgeChunkNotFoundError, "QA 不存在")
+	}
+	return qa, kb, nil
+}
+
+// embedQuestions 使用知识库的 Embedding 模型为问题生成向量
+func embedQuestions(ctx context.Context, svcCtx *svc.ServiceContext, kb *knowledge_base.KnowledgeBase, questions []string) ([][]float64, error) {
+	modelName, factory := llmx.GetModelNameFactory(kb.EmbdId)
+	embLlm, err := svcCtx.TenantLlmModel.FindOneByTenantIdLlmFactoryLlmName(ctx, kb.TenantId, factory, modelName)
+	if err != nil {
+		logx.WithContext(ctx).Errorf("embedQuestions get embedding model failed: kb=%s, model=%s, err=%v", kb.Id, kb.EmbdId, err)
+		return nil, xerr.NewInternalErrMsg("Embedding 模型配置不存在: " + kb.EmbdId)
+	}
+
+	dim := chunk.DefaultEmbeddingDims
+	embedder, err := openai.NewEmbedder(ctx, &openai.EmbeddingConfig{
+		APIKey:     embLlm.ApiKey.String,
+		BaseURL:    embLlm.ApiBase.String,
+		Model:      embLlm.LlmName,
+		Dimensions: &dim,
+	})
+	if err != nil {
+		return nil, xerr.NewInternalErrMsg(err.Error())
+	}
+
+	vectors, err := embedder.EmbedStrings(ctx, questions)
+	if err != nil {
+		logx.WithContext(ctx).Errorf("embedQuestions embed failed: kb=%s, err=%v", kb.Id, err)
+		return nil, xerr.NewInternalErrMsg("生成问题向量失败")
+	}
+	if len(vectors) != len(questions) {
+		return nil, xerr.NewInternalErrMsg("问题向量数量不一致")
+	}
+	return vectors, nil
+}
+
+func toDocumentQaInfo(c *chunk.Chunk) types.DocumentQaInfo {
+	return types.DocumentQaInfo{
+		Id:        c.Id,
+		DocId:     c.DocId,
+		SourceId:  c.SourceId,
+		Question:  c.Question,
+		Answer:    c.Answer,
+		QaStatus:  c.QaStatus,
+		CreatedAt: c.CreateTime,
+		Status:    c.Available,
+	}
+}
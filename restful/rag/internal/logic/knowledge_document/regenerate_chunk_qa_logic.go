@@ -0,0 +1,167 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package knowledge_document
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"gozero-rag/internal/model/chunk"
+	"gozero-rag/internal/rag_core/parser"
+	"gozero-rag/internal/rag_core/qa"
+	ragtypes "gozero-rag/internal/rag_core/types"
+	"gozero-rag/internal/tools/llmx"
+	"gozero-rag/internal/xerr"
+	"gozero-rag/restful/rag/internal/svc"
+	"gozero-rag/restful/rag/internal/types"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type RegenerateChunkQaLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 重新生成切片 QA
+func NewRegenerateChunkQaLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RegenerateChunkQaLogic {
+	return &RegenerateChunkQaLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// regenerateQaPageSize 单个切片的 QA 数量上限
+const regenerateQaPageSize = 100
+
+// RegenerateChunkQa 使用文档生效的 QA 配置为单个切片重新生成 QA
+// 新 QA 写入后删除该切片未审核的旧 QA, 已审核的保留
+func (l *RegenerateChunkQaLogic) RegenerateChunkQa(req *types.RegenerateChunkQaReq) (resp *types.RegenerateChunkQaResp, err error) {
+	source, doc, kb, err := findChunkWithPermission(l.ctx, l.svcCtx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if source.ChunkType != "" {
+		return nil, xerr.NewBadRequestErrMsg("仅支持为内容切片生成 QA")
+	}
+
+	// 1. 解析生效配置: 文档配置 > 知识库配置, 与索引时一致
+	parserId := doc.ParserId
+	if parserId == "" {
+		parserId = kb.ParserId
+	}
+	template, err := parser.ResolveParserConfig(parserId, kb.ParserConfig.String, doc.ParserConfig)
+	if err != nil {
+		return nil, xerr.NewBadRequestErrMsg(err.Error())
+	}
+	general := template.General()
+	if general.QaLlmId == "" {
+		return nil, xerr.NewBadRequestErrMsg("未配置 QA 生成模型")
+	}
+	modelName, factory := llmx.GetModelNameFactory(general.QaLlmId)
+	qaLlm, err := l.svcCtx.TenantLlmModel.FindOneByTenantIdLlmFactoryLlmName(l.ctx, kb.TenantId, factory, modelName)
+	if err != nil {
+		l.Errorf("RegenerateChunkQa get qa model failed: model=%s, err=%v", general.QaLlmId, err)
+		return nil, xerr.NewInternalErrMsg("QA 模型配置不存在: " + general.QaLlmId)
+	}
+	qaNum := general.QaNum
+	if qaNum <= 0 {
+		qaNum = ragtypes.QAMinCount
+	}
+
+	// 2. 生成 QA 并生成问题向量
+	generator := qa.NewGenerator(ragtypes.ProcessConfig{
+		LlmConfig: ragtypes.ProcessLlmConfig{
+			QaKey:       qaLlm.ApiKey.String,
+			QaBaseUrl:   qaLlm.ApiBase.String,
+			QaModelName: modelName,
+		},
+	}, doc.DocName.String)
+	items, err := generator.Generate(l.ctx, &schema.Document{Content: source.Content}, qaNum)
+	if err != nil {
+		l.Errorf("RegenerateChunkQa generate failed: chunkId=%s, err=%v", source.Id, err)
+		return nil, xerr.NewInternalErrMsg("生成 QA 失败")
+	}
+	var questions []string
+	var pairs []ragtypes.QAItem
+	for _, item := range items {
+		item.Question = strings.TrimSpace(item.Question)
+		item.Answer = strings.TrimSpace(item.Answer)
+		if item.Question == "" || item.Answer == "" {
+			continue
+		}
+		questions = append(questions, item.Question)
+		pairs = append(pairs, item)
+	}
+	if len(pairs) == 0 {
+		return nil, xerr.NewInternalErrMsg("未生成有效的 QA")
+	}
+	vectors, err := embedQuestions(l.ctx, l.svcCtx, kb, questions)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 写入新 QA, 删除未审核的旧 QA
+	old, err := l.svcCtx.ChunkModel.ListQaByDocId(l.ctx, doc.Id, &chunk.QaFilter{SourceId: source.Id}, 1, regenerateQaPageSize)
+	if err != nil {
+		return nil, xerr.NewInternalErrMsg("查询 QA 失败")
+	}
+	approved := make(map[string]bool)
+	for _, c := range old.Chunks {
+		if c.QaStatus == chunk.QaStatusApproved {
+			approved[c.Id] = true
+		}
+	}
+
+	now := float64(time.Now().Unix())
+	saveChunks := make([]*chunk.Chunk, 0, len(pairs))
+	kept := make(map[string]bool, len(pairs))
+	for i, pair := range pairs {
+		id := chunk.GenerateId("qa", pair.Question+pair.Answer, doc.Id)
+		if approved[id] || kept[id] {
+			continue
+		}
+		kept[id] = true
+		saveChunks = append(saveChunks, &chunk.Chunk{
+			Id:            id,
+			DocId:         doc.Id,
+			KbIds:         source.KbIds,
+			Content:       chunk.QaContent(pair.Question, pair.Answer),
+			ContentVector: vectors[i],
+			DocName:       source.DocName,
+			CreateTime:    now,
+			Available:     1,
+			SheetName:     source.SheetName,
+			ChunkType:     chunk.ChunkTypeQa,
+			SourceId:      source.Id,
+			Question:      pair.Question,
+			Answer:        pair.Answer,
+			QaStatus:      chunk.QaStatusPending,
+		})
+	}
+	if err := l.svcCtx.ChunkModel.Put(l.ctx, saveChunks); err != nil {
+		l.Errorf("RegenerateChunkQa put failed: chunkId=%s, err=%v", source.Id, err)
+		return nil, xerr.NewInternalErrMsg("写入 QA 失败")
+	}
+
+	var staleIds []string
+	for _, c := range old.Chunks {
+		if !approved[c.Id] && !kept[c.Id] {
+			staleIds = append(staleIds, c.Id)
+		}
+	}
+	if err := l.svcCtx.ChunkModel.DeleteByIds(l.ctx, staleIds); err != nil {
+		l.Errorf("RegenerateChunkQa delete stale qa failed: chunkId=%s, err=%v", source.Id, err)
+	}
+
+	list := make([]types.DocumentQaInfo, 0, len(saveChunks))
+	for _, c := range saveChunks {
+		list = append(list, toDocumentQaInfo(c))
+	}
+	return &types.RegenerateChunkQaResp{List: list}, nil
+}
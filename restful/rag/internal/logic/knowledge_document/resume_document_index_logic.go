@@ -0,0 +1,53 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package knowledge_document
+
+import (
+	"context"
+
+	"gozero-rag/internal/model/knowledge_document"
+	"gozero-rag/internal/xerr"
+	"gozero-rag/restful/rag/internal/common"
+
+	"gozero-rag/restful/rag/internal/svc"
+	"gozero-rag/restful/rag/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ResumeDocumentIndexLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 恢复文档索引
+func NewResumeDocumentIndexLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ResumeDocumentIndexLogic {
+	return &ResumeDocumentIndexLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ResumeDocumentIndex 恢复已暂停的索引, 重新投递索引任务, 消费者从检查点跳过已完成的 embedding 批次
+func (l *ResumeDocumentIndexLogic) ResumeDocumentIndex(req *types.ResumeDocumentIndexReq) (resp *types.ResumeDocumentIndexResp, err error) {
+	userId, err := common.GetUidFromCtx(l.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, kb, err := findDocumentWithPermission(l.ctx, l.svcCtx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if doc.RunStatus != knowledge_document.RunStatePaused {
+		return nil, xerr.NewBadRequestErrMsg("只能恢复已暂停的文档")
+	}
+
+	if err := reindexDocument(l.ctx, l.svcCtx, doc, kb, userId); err != nil {
+		return nil, err
+	}
+	return &types.ResumeDocumentIndexResp{RunStatus: knowledge_document.RunStatePending}, nil
+}
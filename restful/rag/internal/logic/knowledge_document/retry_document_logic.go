@@ -6,6 +6,10 @@ package knowledge_document
 import (
 	"context"
 
+	"gozero-rag/internal/model/knowledge_document"
+	"gozero-rag/internal/xerr"
+	"gozero-rag/restful/rag/internal/common"
+
 	"gozero-rag/restful/rag/internal/svc"
 	"gozero-rag/restful/rag/internal/types"
 
@@ -27,8 +31,24 @@ func NewRetryDocumentLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Ret
 	}
 }
 
+// RetryDocument 使用文档当前的解析配置重新索引
 func (l *RetryDocumentLogic) RetryDocument(req *types.RetryDocumentReq) (resp *types.RetryDocumentResp, err error) {
-	// todo: add your logic here and delete this line
+	userId, err := common.GetUidFromCtx(l.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, kb, err := findDocumentWithPermission(l.ctx, l.svcCtx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if doc.RunStatus == knowledge_document.RunStateRunning {
+		return nil, xerr.NewBadRequestErrMsg("文档正在索引中")
+	}
+
+	if err := reindexDocument(l.ctx, l.svcCtx, doc, kb, userId); err != nil {
+		return nil, err
+	}
 
-	return
+	return &types.RetryDocumentResp{}, nil
 }
@@ -0,0 +1,73 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package knowledge_document
+
+import (
+	"context"
+	"database/sql"
+
+	"gozero-rag/internal/model/knowledge_document"
+	"gozero-rag/internal/model/knowledge_document_version"
+	"gozero-rag/internal/xerr"
+	"gozero-rag/restful/rag/internal/common"
+
+	"gozero-rag/restful/rag/internal/svc"
+	"gozero-rag/restful/rag/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type RollbackDocumentVersionLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 回滚到指定版本
+func NewRollbackDocumentVersionLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RollbackDocumentVersionLogic {
+	return &RollbackDocumentVersionLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// RollbackDocumentVersion 将文档切换回历史版本的文件并重新索引, 版本记录本身保持不变
+func (l *RollbackDocumentVersionLogic) RollbackDocumentVersion(req *types.RollbackDocumentVersionReq) (resp *types.RollbackDocumentVersionResp, err error) {
+	userId, err := common.GetUidFromCtx(l.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, kb, err := findDocumentWithPermission(l.ctx, l.svcCtx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if doc.RunStatus == knowledge_document.RunStateRunning {
+		return nil, xerr.NewBadRequestErrMsg("文档正在索引中")
+	}
+
+	target, err := l.svcCtx.DocumentVersionModel.FindOneByDocumentIdVersion(l.ctx, doc.Id, req.Version)
+	if err != nil {
+		if err == knowledge_document_version.ErrNotFound {
+			return nil, xerr.NewBadRequestErrMsg("版本不存在")
+		}
+		return nil, xerr.NewInternalErrMsg(err.Error())
+	}
+	if target.StoragePath == doc.StoragePath.String {
+		return nil, xerr.NewBadRequestErrMsg("已是当前版本")
+	}
+
+	doc.DocName = sql.NullString{String: target.DocName, Valid: true}
+	doc.DocType = target.DocType
+	doc.DocSize = target.DocSize
+	doc.StoragePath = sql.NullString{String: target.StoragePath, Valid: true}
+	doc.FileHash = target.FileHash
+
+	if err := reindexDocument(l.ctx, l.svcCtx, doc, kb, userId); err != nil {
+		return nil, err
+	}
+
+	return &types.RollbackDocumentVersionResp{}, nil
+}
@@ -6,6 +6,10 @@ package knowledge_document
 import (
 	"context"
 
+	"gozero-rag/internal/model/knowledge_document"
+	"gozero-rag/internal/rag_core/parser"
+	"gozero-rag/internal/xerr"
+
 	"gozero-rag/restful/rag/internal/svc"
 	"gozero-rag/restful/rag/internal/types"
 
@@ -27,8 +31,34 @@ func NewUpdateDocumentParserConfigLogic(ctx context.Context, svcCtx *svc.Service
 	}
 }
 
+// UpdateDocumentParserConfig 更新文档级解析配置, 索引时叠加在知识库配置之上, 需重新解析后生效
 func (l *UpdateDocumentParserConfigLogic) UpdateDocumentParserConfig(req *types.UpdateDocumentParserConfigReq) (resp *types.UpdateDocumentParserConfigResp, err error) {
-	// todo: add your logic here and delete this line
+	doc, kb, err := findDocumentWithPermission(l.ctx, l.svcCtx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if doc.RunStatus == knowledge_document.RunStateRunning {
+		return nil, xerr.NewBadRequestErrMsg("文档正在索引中, 请稍后再修改配置")
+	}
+
+	parserConfig := req.ParserConfig
+	if parserConfig == "" {
+		parserConfig = "{}"
+	}
+
+	parserId := doc.ParserId
+	if parserId == "" {
+		parserId = kb.ParserId
+	}
+	// 校验叠加后的生效配置
+	if _, err := parser.ResolveParserConfig(parserId, kb.ParserConfig.String, parserConfig); err != nil {
+		return nil, xerr.NewBadRequestErrMsg(err.Error())
+	}
+
+	if err := l.svcCtx.KnowledgeDocumentModel.UpdateParserConfig(l.ctx, doc.Id, parserId, parserConfig); err != nil {
+		l.Errorf("更新文档解析配置失败: docId=%s, err=%v", doc.Id, err)
+		return nil, xerr.NewInternalErrMsg("更新文档解析配置失败")
+	}
 
-	return
+	return &types.UpdateDocumentParserConfigResp{}, nil
 }
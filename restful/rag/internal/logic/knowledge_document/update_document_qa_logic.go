@@ -0,0 +1,67 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package knowledge_document
+
+import (
+	"context"
+	"strings"
+
+	"gozero-rag/internal/model/chunk"
+	"gozero-rag/internal/xerr"
+	"gozero-rag/restful/rag/internal/svc"
+	"gozero-rag/restful/rag/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UpdateDocumentQaLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 编辑 QA
+func NewUpdateDocumentQaLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpdateDocumentQaLogic {
+	return &UpdateDocumentQaLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// UpdateDocumentQa 编辑问题和答案, 重新生成问题向量, 人工编辑的 QA 视为已审核
+func (l *UpdateDocumentQaLogic) UpdateDocumentQa(req *types.UpdateDocumentQaReq) (resp *types.UpdateDocumentQaResp, err error) {
+	question := strings.TrimSpace(req.Question)
+	answer := strings.TrimSpace(req.Answer)
+	if question == "" || answer == "" {
+		return nil, xerr.NewBadRequestErrMsg("问题和答案不能为空")
+	}
+
+	qa, kb, err := findQaWithPermission(l.ctx, l.svcCtx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	vectors, err := embedQuestions(l.ctx, l.svcCtx, kb, []string{question})
+	if err != nil {
+		return nil, err
+	}
+
+	err = l.svcCtx.ChunkModel.Update(l.ctx, qa.Id, map[string]interface{}{
+		"question":       question,
+		"answer":         answer,
+		"content":        chunk.QaContent(question, answer),
+		"content_vector": vectors[0],
+		"qa_status":      chunk.QaStatusApproved,
+	})
+	if err != nil {
+		l.Errorf("UpdateDocumentQa failed: qaId=%s, err=%v", qa.Id, err)
+		return nil, xerr.NewInternalErrMsg("更新 QA 失败")
+	}
+
+	qa.Question = question
+	qa.Answer = answer
+	qa.QaStatus = chunk.QaStatusApproved
+	return &types.UpdateDocumentQaResp{Qa: toDocumentQaInfo(qa)}, nil
+}
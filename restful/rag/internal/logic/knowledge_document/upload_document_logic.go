@@ -2,14 +2,11 @@ package knowledge_document
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 	"mime/multipart"
 	"path/filepath"
-	"time"
 
 	"gozero-rag/internal/model/knowledge_base"
-	"gozero-rag/internal/model/knowledge_document"
 	"gozero-rag/internal/xerr"
 	"gozero-rag/restful/rag/internal/common"
 
@@ -76,8 +73,8 @@ func (l *UploadDocumentLogic) UploadDocuments(
 		}
 
 		// 上传单个文件
-		docId, docName, err := l.uploadSingleFile(
-			req.KnowledgeBaseId,
+		docId, docName, duplicate, err := l.uploadSingleFile(
+			kb,
 			tenantId,
 			userId,
 			file,
@@ -93,8 +90,9 @@ func (l *UploadDocumentLogic) UploadDocuments(
 
 		fileIds = append(fileIds, docId)
 		files = append(files, types.UploadedFileInfo{
-			Id:      docId,
-			DocName: docName,
+			Id:        docId,
+			DocName:   docName,
+			Duplicate: duplicate,
 		})
 	}
 
@@ -112,17 +110,19 @@ func (l *UploadDocumentLogic) UploadDocuments(
 	}, nil
 }
 
-// uploadSingleFile 上传单个文件 (提取出来的辅助函数)
+// uploadSingleFile 上传单个文件 (提取出来的辅助函数), duplicate 表示按 skip 策略返回了已有文档
 func (l *UploadDocumentLogic) uploadSingleFile(
-	knowledgeBaseId, tenantId, userId string,
+	kb *knowledge_base.KnowledgeBase,
+	tenantId, userId string,
 	file multipart.File,
 	header *multipart.FileHeader,
-) (docId, docName string, err error) {
+) (docId, docName string, duplicate bool, err error) {
+	knowledgeBaseId := kb.Id
 
 	// UUID 生成
 	docUuid, err := uuid.NewV7()
 	if err != nil {
-		return "", "", xerr.NewInternalErrMsg("UUID generation failed")
+		return "", "", false, xerr.NewInternalErrMsg("UUID generation failed")
 	}
 	docId = docUuid.String()
 
@@ -132,70 +132,38 @@ func (l *UploadDocumentLogic) uploadSingleFile(
 		contentType = "application/octet-stream"
 	}
 
-	// OSS 上传
+	// OSS 上传, 同时计算文件哈希
 	objectKey := fmt.Sprintf("tenant_%s/kb_%s/%s%s", tenantId, knowledgeBaseId, docId, ext)
-	_, err = l.svcCtx.OssClient.PutObject(
-		l.ctx,
-		l.svcCtx.Config.Oss.BucketName,
-		objectKey,
-		file,
-		header.Size,
-		contentType,
-	)
+	fileHash, err := putObjectWithHash(l.ctx, l.svcCtx, objectKey, file, header.Size, contentType)
 	if err != nil {
-		return "", "", xerr.NewErrCodeMsg(xerr.KnowledgeDocUploadError, "OSS上传失败")
+		return "", "", false, xerr.NewErrCodeMsg(xerr.KnowledgeDocUploadError, "OSS上传失败")
 	}
 
-	// 获取知识库配置以继承解析规则
-	kb, err := l.svcCtx.KnowledgeBaseModel.FindOne(l.ctx, knowledgeBaseId)
+	// 按知识库的重复文档策略处理内容相同的文件, 重复时删除刚上传的文件
+	// 持有去重锁直到文档写入, 并发上传相同文件时只有一个能通过检查
+	unlock, err := lockDuplicateCheck(l.ctx, l.svcCtx, kb, fileHash)
 	if err != nil {
-		logx.Errorf("UploadSingleFile: failed to find knowledge base %s: %v", knowledgeBaseId, err)
-		return "", "", xerr.NewErrCodeMsg(xerr.KnowledgeBaseNotFoundError, "获取知识库信息失败")
+		_ = l.svcCtx.OssClient.RemoveObject(l.ctx, l.svcCtx.Config.Oss.BucketName, objectKey)
+		return "", "", false, err
 	}
-
-	// 数据库保存
-	now := time.Now()
-	docType := "unknown"
-	if len(ext) > 1 {
-		docType = ext[1:] // 去掉点号
+	defer unlock()
+	existing, err := findDuplicateDocument(l.ctx, l.svcCtx, kb, fileHash)
+	if err != nil || existing != nil {
+		_ = l.svcCtx.OssClient.RemoveObject(l.ctx, l.svcCtx.Config.Oss.BucketName, objectKey)
 	}
-
-	// 处理 ParserConfig
-	parserConfig := "{}"
-	if kb.ParserConfig.Valid && kb.ParserConfig.String != "" {
-		parserConfig = kb.ParserConfig.String
+	if err != nil {
+		return "", "", false, err
 	}
-
-	doc := &knowledge_document.KnowledgeDocument{
-		Id:              docId,
-		KnowledgeBaseId: knowledgeBaseId,
-		DocName:         sql.NullString{String: header.Filename, Valid: true},
-		DocType:         docType,
-		DocSize:         int64(header.Size),
-		StoragePath:     sql.NullString{String: objectKey, Valid: true},
-		Status:          1,
-		RunStatus:       "pending",
-		CreatedBy:       userId,
-		CreatedTime:     now.UnixMilli(),
-		UpdatedTime:     now.UnixMilli(),
-		CreatedDate:     now,
-		UpdatedDate:     now,
-		ParserId:        kb.ParserId,
-		ParserConfig:    parserConfig,
-		SourceType:      "local",
-		Progress:        0,
-		TokenNum:        0,
-		ChunkNum:        0,
+	if existing != nil {
+		return existing.Id, existing.DocName.String, true, nil
 	}
 
-	_, err = l.svcCtx.KnowledgeDocumentModel.Insert(l.ctx, doc)
-	if err != nil {
+	// 数据库保存
+	if _, err := createUploadedDocument(l.ctx, l.svcCtx, kb, docId, userId, header.Filename, header.Size, objectKey, fileHash); err != nil {
 		// 回滚 OSS
 		_ = l.svcCtx.OssClient.RemoveObject(l.ctx, l.svcCtx.Config.Oss.BucketName, objectKey)
-		return "", "", xerr.NewInternalErrMsg("保存文档信息失败")
+		return "", "", false, err
 	}
 
-	// TODO: 发送异步解析任务到 Kafka/Asynq
-
-	return docId, header.Filename, nil
+	return docId, header.Filename, false, nil
 }
@@ -0,0 +1,126 @@
+package knowledge_document
+
+import (
+	"context"
+	"mime/multipart"
+	"strings"
+	"testing"
+
+	"gozero-rag/internal/model/knowledge_base"
+	"gozero-rag/internal/oss/osstest"
+	"gozero-rag/restful/rag/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingOss 记录被删除的对象
+type recordingOss struct {
+	*osstest.MemoryClient
+	removed []string
+}
+
+func (r *recordingOss) RemoveObject(ctx context.Context, bucket, key string) error {
+	r.removed = append(r.removed, key)
+	return r.MemoryClient.RemoveObject(ctx, bucket, key)
+}
+
+func uploadDocuments(t *testing.T, env *testEnv, kbId string, headers ...*multipart.FileHeader) (*types.UploadDocumentResp, error) {
+	t.Helper()
+	return NewUploadDocumentLogic(userCtx(testUserId), env.svcCtx).UploadDocuments(&types.UploadDocumentReq{KnowledgeBaseId: kbId}, headers)
+}
+
+func TestUploadDocuments_DedupPolicy(t *testing.T) {
+	data := []byte("same content")
+
+	t.Run("allow", func(t *testing.T) {
+		env := newTestEnv(t)
+		env.addKnowledgeBase("kb1", knowledge_base.DedupPolicyAllow)
+		addDocument(t, env, "kb1", "existing", data)
+
+		resp, err := uploadDocuments(t, env, "kb1", newFileHeader(t, "a.txt", data))
+		require.NoError(t, err)
+		require.Len(t, resp.Files, 1)
+		assert.False(t, resp.Files[0].Duplicate)
+		assert.NotEqual(t, "existing", resp.Files[0].Id)
+
+		// 上传时计算的哈希与文件内容一致, 文件写入 OSS 并触发索引
+		doc := env.docs.docs[resp.Files[0].Id]
+		require.NotNil(t, doc)
+		assert.Equal(t, sha256Hex(data), doc.FileHash)
+		assert.Equal(t, data, readObject(t, env, doc.StoragePath.String))
+		assert.Len(t, env.mq.indexMsgs, 1)
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		env := newTestEnv(t)
+		env.addKnowledgeBase("kb1", knowledge_base.DedupPolicySkip)
+		addDocument(t, env, "kb1", "existing", data)
+		oss := &recordingOss{MemoryClient: env.oss}
+		env.svcCtx.OssClient = oss
+
+		resp, err := uploadDocuments(t, env, "kb1", newFileHeader(t, "a.txt", data), newFileHeader(t, "b.txt", []byte("other")))
+		require.NoError(t, err)
+		require.Len(t, resp.Files, 2)
+		assert.True(t, resp.Files[0].Duplicate)
+		assert.Equal(t, "existing", resp.Files[0].Id)
+		assert.False(t, resp.Files[1].Duplicate)
+
+		// 重复文件已上传的对象被删除, 不创建文档
+		require.Len(t, oss.removed, 1)
+		assert.True(t, strings.HasPrefix(oss.removed[0], "tenant_"+testTenantId+"/kb_kb1/"))
+		_, err = env.oss.GetObject(context.Background(), testBucket, oss.removed[0])
+		assert.Error(t, err)
+		assert.Len(t, env.docs.docs, 2)
+		assert.Len(t, env.mq.indexMsgs, 1)
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		env := newTestEnv(t)
+		env.addKnowledgeBase("kb1", knowledge_base.DedupPolicyReject)
+		addDocument(t, env, "kb1", "existing", data)
+		oss := &recordingOss{MemoryClient: env.oss}
+		env.svcCtx.OssClient = oss
+
+		_, err := uploadDocuments(t, env, "kb1", newFileHeader(t, "a.txt", data))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "与已有文档内容重复")
+
+		require.Len(t, oss.removed, 1)
+		assert.Len(t, env.docs.docs, 1)
+		assert.Empty(t, env.mq.indexMsgs)
+	})
+}
+
+// 并发上传相同文件时去重检查与写入串行执行, 等待锁超时的上传不创建文档
+func TestUploadDocuments_DedupLock(t *testing.T) {
+	data := []byte("same content")
+	env := newTestEnv(t)
+	kb := env.addKnowledgeBase("kb1", knowledge_base.DedupPolicyReject)
+	oss := &recordingOss{MemoryClient: env.oss}
+	env.svcCtx.OssClient = oss
+
+	unlock, err := lockDuplicateCheck(context.Background(), env.svcCtx, kb, sha256Hex(data))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(userCtx(testUserId), 3*dedupLockRetryInterval)
+	defer cancel()
+	_, err = NewUploadDocumentLogic(ctx, env.svcCtx).UploadDocuments(&types.UploadDocumentReq{KnowledgeBaseId: "kb1"}, []*multipart.FileHeader{newFileHeader(t, "a.txt", data)})
+	require.Error(t, err)
+	require.Len(t, oss.removed, 1)
+	assert.Empty(t, env.docs.docs)
+
+	unlock()
+	resp, err := uploadDocuments(t, env, "kb1", newFileHeader(t, "a.txt", data))
+	require.NoError(t, err)
+	assert.False(t, resp.Files[0].Duplicate)
+	assert.Len(t, env.docs.docs, 1)
+
+	// allow 策略不加锁
+	kb.DedupPolicy = knowledge_base.DedupPolicyAllow
+	unlock, err = lockDuplicateCheck(context.Background(), env.svcCtx, kb, sha256Hex(data))
+	require.NoError(t, err)
+	defer unlock()
+	_, err = uploadDocuments(t, env, "kb1", newFileHeader(t, "b.txt", data))
+	require.NoError(t, err)
+}
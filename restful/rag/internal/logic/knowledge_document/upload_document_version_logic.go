@@ -0,0 +1,122 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package knowledge_document
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"mime/multipart"
+	"path/filepath"
+
+	"gozero-rag/internal/model/knowledge_document"
+	"gozero-rag/internal/xerr"
+	"gozero-rag/restful/rag/internal/common"
+
+	"gozero-rag/restful/rag/internal/svc"
+	"gozero-rag/restful/rag/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UploadDocumentVersionLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 上传文档新版本
+func NewUploadDocumentVersionLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UploadDocumentVersionLogic {
+	return &UploadDocumentVersionLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// UploadDocumentVersion 在同一文档下上传新版本文件, 旧版本文件保留在 OSS, 新版本上传后重新索引
+func (l *UploadDocumentVersionLogic) UploadDocumentVersion(req *types.UploadDocumentVersionReq, header *multipart.FileHeader) (resp *types.UploadDocumentVersionResp, err error) {
+	userId, err := common.GetUidFromCtx(l.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, kb, err := findDocumentWithPermission(l.ctx, l.svcCtx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if doc.RunStatus == knowledge_document.RunStateRunning {
+		return nil, xerr.NewBadRequestErrMsg("文档正在索引中")
+	}
+
+	latest, err := ensureInitialVersion(l.ctx, l.svcCtx, doc)
+	if err != nil {
+		return nil, err
+	}
+	version := latest + 1
+
+	file, err := header.Open()
+	if err != nil {
+		return nil, xerr.NewErrCodeMsg(xerr.KnowledgeDocUploadError, "打开文件失败")
+	}
+	defer file.Close()
+
+	ext := filepath.Ext(header.Filename)
+	contentType := header.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	// 版本文件单独存储, 不覆盖旧版本; 上传时同时计算文件哈希
+	objectKey := fmt.Sprintf("tenant_%s/kb_%s/%s_v%d%s", kb.TenantId, kb.Id, doc.Id, version, ext)
+	fileHash, err := putObjectWithHash(l.ctx, l.svcCtx, objectKey, file, header.Size, contentType)
+	if err != nil {
+		return nil, xerr.NewErrCodeMsg(xerr.KnowledgeDocUploadError, "OSS上传失败")
+	}
+
+	// 新版本同样遵循知识库的重复文档策略, 重复时删除刚上传的文件; 持有去重锁直到文档更新
+	unlock, err := lockDuplicateCheck(l.ctx, l.svcCtx, kb, fileHash)
+	if err != nil {
+		_ = l.svcCtx.OssClient.RemoveObject(l.ctx, l.svcCtx.Config.Oss.BucketName, objectKey)
+		return nil, err
+	}
+	defer unlock()
+	existing, err := findDuplicateDocument(l.ctx, l.svcCtx, kb, fileHash)
+	if err != nil || existing != nil {
+		_ = l.svcCtx.OssClient.RemoveObject(l.ctx, l.svcCtx.Config.Oss.BucketName, objectKey)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return &types.UploadDocumentVersionResp{
+			Version:       latest,
+			Duplicate:     true,
+			DuplicateId:   existing.Id,
+			DuplicateName: existing.DocName.String,
+		}, nil
+	}
+
+	docType := "unknown"
+	if len(ext) > 1 {
+		docType = ext[1:]
+	}
+	doc.DocName = sql.NullString{String: header.Filename, Valid: true}
+	doc.DocType = docType
+	doc.DocSize = header.Size
+	doc.StoragePath = sql.NullString{String: objectKey, Valid: true}
+	doc.FileHash = fileHash
+
+	if _, err := l.svcCtx.DocumentVersionModel.Insert(l.ctx, newDocumentVersion(doc, version, fileHash, userId)); err != nil {
+		l.Errorf("UploadDocumentVersion insert version failed: docId=%s, version=%d, err=%v", doc.Id, version, err)
+		_ = l.svcCtx.OssClient.RemoveObject(l.ctx, l.svcCtx.Config.Oss.BucketName, objectKey)
+		return nil, xerr.NewInternalErrMsg("保存版本信息失败")
+	}
+
+	if err := reindexDocument(l.ctx, l.svcCtx, doc, kb, userId); err != nil {
+		return nil, err
+	}
+
+	return &types.UploadDocumentVersionResp{Version: version}, nil
+}
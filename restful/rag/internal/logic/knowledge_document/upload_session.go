@@ -0,0 +1,157 @@
+package knowledge_document
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gozero-rag/internal/oss"
+	"gozero-rag/internal/xerr"
+	"gozero-rag/restful/rag/internal/common"
+	"gozero-rag/restful/rag/internal/svc"
+
+	"github.com/zeromicro/go-zero/core/stores/redis"
+)
+
+// 直传模式
+const (
+	UploadModeSingle    = "single"    // 预签名 URL 单次 PUT
+	UploadModeMultipart = "multipart" // 分片上传, 支持断点续传
+)
+
+const (
+	uploadSessionExpire     = 24 * time.Hour          // 直传会话有效期
+	uploadPresignExpire     = time.Hour               // 预签名 URL 有效期, 过期后可重新获取
+	uploadMultipartSize     = 64 << 20                // 超过该大小使用分片上传
+	uploadPartSize          = 16 << 20                // 分片大小
+	uploadMaxFileSize       = 5 << 30                 // 直传文件大小上限
+	uploadMaxParts          = 10000                   // 对象存储单个上传任务的分片数上限
+	uploadSessionKeyPattern = "rag:upload:session:%s" // Redis key
+)
+
+// uploadSession 直传会话, 存储在 Redis 中, 完成或取消后删除
+type uploadSession struct {
+	SessionId       string `json:"session_id"`
+	TenantId        string `json:"tenant_id"`
+	UserId          string `json:"user_id"`
+	KnowledgeBaseId string `json:"knowledge_base_id"`
+	DocumentId      string `json:"document_id"` // 预先生成的文档ID, 对象 key 与普通上传保持一致
+	FileName        string `json:"file_name"`
+	FileSize        int64  `json:"file_size"`
+	FileHash        string `json:"file_hash"`
+	ContentType     string `json:"content_type"`
+	ObjectKey       string `json:"object_key"`
+	Mode            string `json:"mode"`
+	UploadId        string `json:"upload_id"` // multipart 模式的上传任务ID
+	PartSize        int64  `json:"part_size"`
+	PartCount       int64  `json:"part_count"`
+	ExpireTime      int64  `json:"expire_time"`
+}
+
+func uploadSessionKey(sessionId string) string {
+	return fmt.Sprintf(uploadSessionKeyPattern, sessionId)
+}
+
+// pendingUpload 返回会话对应的未完成分片上传登记信息
+func (s *uploadSession) pendingUpload(bucket string) oss.PendingUpload {
+	return oss.PendingUpload{Bucket: bucket, Key: s.ObjectKey, UploadId: s.UploadId}
+}
+
+// uploadSessionStore 直传会话存储
+type uploadSessionStore interface {
+	// Save 保存会话, 分片上传同时登记到未完成上传表, 会话过期后由 compensator 中止
+	Save(ctx context.Context, s *uploadSession) error
+	// Load 读取会话, 不存在时返回 nil
+	Load(ctx context.Context, sessionId string) (*uploadSession, error)
+	// Delete 删除会话及其未完成上传登记
+	Delete(ctx context.Context, s *uploadSession)
+}
+
+// newUploadSessionStore 创建会话存储, 测试中替换为内存实现
+var newUploadSessionStore = func(svcCtx *svc.ServiceContext) uploadSessionStore {
+	return &redisUploadSessionStore{rds: svcCtx.RedisClient, bucket: svcCtx.Config.Oss.BucketName}
+}
+
+type redisUploadSessionStore struct {
+	rds    *redis.Redis
+	bucket string
+}
+
+func (r *redisUploadSessionStore) Save(ctx context.Context, s *uploadSession) error {
+	data, _ := json.Marshal(s)
+	if err := r.rds.SetexCtx(ctx, uploadSessionKey(s.SessionId), string(data), int(uploadSessionExpire.Seconds())); err != nil {
+		return err
+	}
+	if s.UploadId == "" {
+		return nil
+	}
+	_, err := r.rds.ZaddCtx(ctx, oss.PendingUploadsKey, s.ExpireTime, s.pendingUpload(r.bucket).Member())
+	return err
+}
+
+func (r *redisUploadSessionStore) Load(ctx context.Context, sessionId string) (*uploadSession, error) {
+	data, err := r.rds.GetCtx(ctx, uploadSessionKey(sessionId))
+	if err != nil || data == "" {
+		return nil, err
+	}
+	var s uploadSession
+	if err := json.Unmarshal([]byte(data), &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *redisUploadSessionStore) Delete(ctx context.Context, s *uploadSession) {
+	_, _ = r.rds.DelCtx(ctx, uploadSessionKey(s.SessionId))
+	if s.UploadId != "" {
+		_, _ = r.rds.ZremCtx(ctx, oss.PendingUploadsKey, s.pendingUpload(r.bucket).Member())
+	}
+}
+
+func saveUploadSession(ctx context.Context, svcCtx *svc.ServiceContext, s *uploadSession) error {
+	return newUploadSessionStore(svcCtx).Save(ctx, s)
+}
+
+// loadUploadSession 读取直传会话, 并校验会话属于当前用户
+func loadUploadSession(ctx context.Context, svcCtx *svc.ServiceContext, sessionId string) (*uploadSession, error) {
+	userId, err := common.GetUidFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tenantId, err := common.GetTenantIdFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := newUploadSessionStore(svcCtx).Load(ctx, sessionId)
+	if err != nil {
+		return nil, xerr.NewInternalErrMsg(err.Error())
+	}
+	if s == nil {
+		return nil, xerr.NewBadRequestErrMsg("上传会话不存在或已过期")
+	}
+	if s.TenantId != tenantId || s.UserId != userId {
+		return nil, xerr.NewErrCodeMsg(xerr.ForbiddenError, "无权操作此上传会话")
+	}
+	return s, nil
+}
+
+func deleteUploadSession(ctx context.Context, svcCtx *svc.ServiceContext, s *uploadSession) {
+	newUploadSessionStore(svcCtx).Delete(ctx, s)
+}
+
+// planUploadParts 根据文件大小选择直传模式, 返回分片大小及分片数
+func planUploadParts(fileSize int64) (mode string, partSize, partCount int64) {
+	if fileSize <= uploadMultipartSize {
+		return UploadModeSingle, 0, 0
+	}
+
+	partSize = uploadPartSize
+	// 超大文件放大分片, 保证分片数不超过上限
+	if fileSize > partSize*uploadMaxParts {
+		partSize = (fileSize + uploadMaxParts - 1) / uploadMaxParts
+	}
+	partCount = (fileSize + partSize - 1) / partSize
+	return UploadModeMultipart, partSize, partCount
+}
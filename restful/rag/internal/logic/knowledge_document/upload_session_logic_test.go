@@ -0,0 +1,183 @@
+package knowledge_document
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"gozero-rag/internal/model/knowledge_base"
+	"gozero-rag/internal/model/knowledge_document"
+	"gozero-rag/internal/oss"
+	"gozero-rag/internal/xerr"
+	"gozero-rag/restful/rag/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func errCode(t *testing.T, err error) uint32 {
+	t.Helper()
+	var codeErr *xerr.CodeError
+	require.ErrorAs(t, err, &codeErr)
+	return codeErr.GetErrCode()
+}
+
+// createSingleUpload 创建单次直传会话并模拟客户端上传对象
+func createSingleUpload(t *testing.T, env *testEnv, kbId string, data []byte, declaredHash string) *types.CreateUploadSessionResp {
+	t.Helper()
+	ctx := userCtx(testUserId)
+	resp, err := NewCreateUploadSessionLogic(ctx, env.svcCtx).CreateUploadSession(&types.CreateUploadSessionReq{
+		KnowledgeBaseId: kbId,
+		FileName:        "manual.txt",
+		FileSize:        int64(len(data)),
+		FileHash:        declaredHash,
+	})
+	require.NoError(t, err)
+	require.Equal(t, UploadModeSingle, resp.Mode)
+
+	session := env.sessions.sessions[resp.SessionId]
+	require.NotNil(t, session)
+	_, err = env.oss.PutObject(ctx, testBucket, session.ObjectKey, bytes.NewReader(data), int64(len(data)), "text/plain")
+	require.NoError(t, err)
+	return resp
+}
+
+func TestUploadSession_MultipartFlow(t *testing.T) {
+	env := newTestEnv(t)
+	env.addKnowledgeBase("kb-1", knowledge_base.DedupPolicyAllow)
+	ctx := userCtx(testUserId)
+
+	data := bytes.Repeat([]byte("0123456789abcdef"), (uploadMultipartSize+uploadPartSize/2)/16)
+	created, err := NewCreateUploadSessionLogic(ctx, env.svcCtx).CreateUploadSession(&types.CreateUploadSessionReq{
+		KnowledgeBaseId: "kb-1",
+		FileName:        "big.pdf",
+		FileSize:        int64(len(data)),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, UploadModeMultipart, created.Mode)
+	assert.Equal(t, int64(5), created.PartCount)
+
+	session := env.sessions.sessions[created.SessionId]
+	require.NotNil(t, session)
+	require.NotEmpty(t, session.UploadId)
+
+	var partNumbers []int64
+	for i := int64(1); i <= created.PartCount; i++ {
+		partNumbers = append(partNumbers, i)
+	}
+	urls, err := NewGetUploadPartUrlsLogic(ctx, env.svcCtx).GetUploadPartUrls(&types.GetUploadPartUrlsReq{
+		SessionId:   created.SessionId,
+		PartNumbers: partNumbers,
+	})
+	require.NoError(t, err)
+	assert.Len(t, urls.List, int(created.PartCount))
+
+	_, err = NewGetUploadPartUrlsLogic(ctx, env.svcCtx).GetUploadPartUrls(&types.GetUploadPartUrlsReq{
+		SessionId:   created.SessionId,
+		PartNumbers: []int64{created.PartCount + 1},
+	})
+	assert.Error(t, err)
+
+	// 分片未上传完时不能完成
+	_, err = env.oss.UploadPart(session.UploadId, 1, data[:created.PartSize])
+	require.NoError(t, err)
+	_, err = NewCompleteUploadSessionLogic(ctx, env.svcCtx).CompleteUploadSession(&types.CompleteUploadSessionReq{SessionId: created.SessionId})
+	assert.Error(t, err)
+
+	for i := int64(1); i < created.PartCount; i++ {
+		end := min((i+1)*created.PartSize, int64(len(data)))
+		_, err = env.oss.UploadPart(session.UploadId, int(i+1), data[i*created.PartSize:end])
+		require.NoError(t, err)
+	}
+
+	completed, err := NewCompleteUploadSessionLogic(ctx, env.svcCtx).CompleteUploadSession(&types.CompleteUploadSessionReq{SessionId: created.SessionId})
+	require.NoError(t, err)
+	assert.False(t, completed.Duplicate)
+	assert.Equal(t, session.DocumentId, completed.Id)
+
+	doc := env.docs.docs[completed.Id]
+	require.NotNil(t, doc)
+	assert.Equal(t, sha256Hex(data), doc.FileHash)
+	assert.Equal(t, int64(len(data)), doc.DocSize)
+	assert.Equal(t, session.ObjectKey, doc.StoragePath.String)
+	assert.Equal(t, testUserId, doc.CreatedBy)
+	require.Len(t, env.mq.indexMsgs, 1)
+	assert.Equal(t, completed.Id, env.mq.indexMsgs[0].DocumentId)
+	assert.Len(t, env.versions.versions, 1)
+	assert.Empty(t, env.sessions.sessions)
+}
+
+func TestCompleteUploadSession_HashMismatch(t *testing.T) {
+	env := newTestEnv(t)
+	env.addKnowledgeBase("kb-1", knowledge_base.DedupPolicyAllow)
+	ctx := userCtx(testUserId)
+
+	data := []byte("actual content")
+	created := createSingleUpload(t, env, "kb-1", data, sha256Hex([]byte("declared content")))
+	objectKey := env.sessions.sessions[created.SessionId].ObjectKey
+
+	_, err := NewCompleteUploadSessionLogic(ctx, env.svcCtx).CompleteUploadSession(&types.CompleteUploadSessionReq{SessionId: created.SessionId})
+	assert.Equal(t, xerr.KnowledgeDocUploadError, errCode(t, err))
+
+	_, err = env.oss.StatObject(ctx, testBucket, objectKey)
+	assert.ErrorIs(t, err, oss.ErrObjectNotFound)
+	assert.Empty(t, env.docs.docs)
+	assert.Empty(t, env.sessions.sessions)
+}
+
+func TestCompleteUploadSession_DedupByActualHash(t *testing.T) {
+	data := []byte("same content")
+	existing := &knowledge_document.KnowledgeDocument{Id: "doc-existing", KnowledgeBaseId: "kb-1", FileHash: sha256Hex(data)}
+
+	t.Run("skip", func(t *testing.T) {
+		env := newTestEnv(t)
+		env.addKnowledgeBase("kb-1", knowledge_base.DedupPolicySkip)
+		env.docs.docs[existing.Id] = existing
+
+		// 未声明哈希时创建会话无法提前去重, 完成时按实际内容命中
+		created := createSingleUpload(t, env, "kb-1", data, "")
+		objectKey := env.sessions.sessions[created.SessionId].ObjectKey
+
+		completed, err := NewCompleteUploadSessionLogic(userCtx(testUserId), env.svcCtx).CompleteUploadSession(&types.CompleteUploadSessionReq{SessionId: created.SessionId})
+		require.NoError(t, err)
+		assert.True(t, completed.Duplicate)
+		assert.Equal(t, existing.Id, completed.Id)
+		assert.Len(t, env.docs.docs, 1)
+		_, err = env.oss.StatObject(context.Background(), testBucket, objectKey)
+		assert.ErrorIs(t, err, oss.ErrObjectNotFound)
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		env := newTestEnv(t)
+		env.addKnowledgeBase("kb-1", knowledge_base.DedupPolicyReject)
+		env.docs.docs[existing.Id] = existing
+
+		// 创建时未能提前去重, 完成时按实际内容拒绝
+		created := createSingleUpload(t, env, "kb-1", data, "")
+		_, err := NewCompleteUploadSessionLogic(userCtx(testUserId), env.svcCtx).CompleteUploadSession(&types.CompleteUploadSessionReq{SessionId: created.SessionId})
+		assert.Equal(t, xerr.KnowledgeDocUploadError, errCode(t, err))
+		assert.Len(t, env.docs.docs, 1)
+		assert.Empty(t, env.sessions.sessions)
+	})
+}
+
+func TestUploadSession_OtherUserForbidden(t *testing.T) {
+	env := newTestEnv(t)
+	env.addKnowledgeBase("kb-1", knowledge_base.DedupPolicyAllow)
+	created := createSingleUpload(t, env, "kb-1", []byte("content"), "")
+
+	// 同租户的其他用户不能操作该会话
+	other := userCtx("user-2")
+	_, err := NewCompleteUploadSessionLogic(other, env.svcCtx).CompleteUploadSession(&types.CompleteUploadSessionReq{SessionId: created.SessionId})
+	assert.Equal(t, xerr.ForbiddenError, errCode(t, err))
+	_, err = NewAbortUploadSessionLogic(other, env.svcCtx).AbortUploadSession(&types.AbortUploadSessionReq{SessionId: created.SessionId})
+	assert.Equal(t, xerr.ForbiddenError, errCode(t, err))
+	assert.Contains(t, env.sessions.sessions, created.SessionId)
+}
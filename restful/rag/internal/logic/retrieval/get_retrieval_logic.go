@@ -72,8 +72,9 @@ func (l *GetRetrievalLogic) getRetrieveRequestFromReq(req *types.RetrieveReq) (*
 		mode = retriever.RetrieveModeFulltext
 	case retriever.RetrieveModeVector:
 		mode = retriever.RetrieveModeVector
-	case retriever.RetrieveModeHybrid:
-		mode = retriever.RetrieveModeHybrid
+	case retriever.RetrieveModeHybrid, retriever.RetrieveModeGraph:
+		// graph 模式的分片检索部分同 hybrid
+		mode = req.RetrievalMode
 
 		if req.RetrievalConfig.HybridStrategy.Type == retriever.HybridRankTypeWeighted {
 			hybridType = retriever.HybridRankTypeWeighted
@@ -104,6 +105,8 @@ func (l *GetRetrievalLogic) getRetrieveRequestFromReq(req *types.RetrieveReq) (*
 		HybridRankType:    hybridType,
 		VectorWeight:      req.RetrievalConfig.HybridStrategy.Weights.Vector,
 		KeywordWeight:     req.RetrievalConfig.HybridStrategy.Weights.Keyword,
+		SheetNames:        req.SheetNames,
+		GraphHops:         req.RetrievalConfig.GraphHops,
 	}
 
 	return ret, nil
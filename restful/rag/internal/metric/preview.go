@@ -11,6 +11,7 @@ const (
 // Preview 失败原因常量
 const (
 	PreviewFailReasonDocNotFound  = "doc_not_found" // 文档不存在
+	PreviewFailReasonForbidden    = "forbidden"     // 无权访问文档
 	PreviewFailReasonLoadError    = "load_error"    // 加载文件失败
 	PreviewFailReasonSplitError   = "split_error"   // 分片失败
 	PreviewFailReasonInvalidParam = "invalid_param" // 参数无效
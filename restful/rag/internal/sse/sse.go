@@ -9,6 +9,7 @@ const (
 	SSETypeReasoning = "reasoning"
 	SSETypeToolUse   = "tool_use"
 	SSETypeCitation  = "citation"
+	SSETypeGraph     = "graph_context"
 	SSETypeFinish    = "finish"
 	SSETypeError     = "error"
 )
@@ -63,3 +64,11 @@ func (s *SseClient) SendCitation(chunks []types.ChatRetrievalChunk) {
 		RetrievalDocs: chunks,
 	}
 }
+
+func (s *SseClient) SendGraphContext(contexts []types.ChatGraphContext) {
+	s.client <- &types.ChatResp{
+		Type:          SSETypeGraph,
+		MsgId:         s.msgId,
+		GraphContexts: contexts,
+	}
+}
@@ -0,0 +1,36 @@
+package svc
+
+import (
+	"context"
+	"encoding/json"
+
+	"gozero-rag/internal/model/local_message"
+	"gozero-rag/internal/mq"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// EnqueueGraphRemoval 经本地消息表投递移除文档图谱贡献的任务, 由 graph_extract 消费者执行并登记社区报告重建
+// 消息写入失败返回错误; 投递失败只记录日志, 由 compensator 补偿
+// 调用方应在删除分片、文件及文档记录之前调用, 保证失败时文档数据完整
+func (svc *ServiceContext) EnqueueGraphRemoval(ctx context.Context, tenantId, kbId string, docIds []string) error {
+	msg := mq.NewGraphRemoveMsg(tenantId, kbId, docIds)
+	snapshot, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	msgId, err := svc.LocalMessageModel.InsertAndGetId(ctx, local_message.NewLocalMessage(local_message.TaskTypeGraphExtract, string(snapshot)))
+	if err != nil {
+		return err
+	}
+
+	msg.SetLocalMessageId(msgId)
+	if err := svc.MqPusherClient.PublishGraphGenerateMsg(ctx, msg); err != nil {
+		logx.WithContext(ctx).Errorf("push graph remove task failed, waiting for compensator: kbId=%s, msgId=%d, err=%v", kbId, msgId, err)
+		return nil
+	}
+	if err := svc.LocalMessageModel.UpdateRetrying(ctx, msgId); err != nil {
+		logx.WithContext(ctx).Errorf("update local message failed: msgId=%d, err=%v", msgId, err)
+	}
+	return nil
+}
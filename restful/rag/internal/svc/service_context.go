@@ -5,14 +5,18 @@ package svc
 
 import (
 	"context"
+	"gozero-rag/internal/graphrag/store"
+	"gozero-rag/internal/indexctl"
 	"gozero-rag/internal/model/chat_conversation"
 	"gozero-rag/internal/model/chat_message"
 	"gozero-rag/internal/model/chunk"
 	"gozero-rag/internal/model/graph"
 	"gozero-rag/internal/model/knowledge_base"
 	"gozero-rag/internal/model/knowledge_document"
+	"gozero-rag/internal/model/knowledge_document_version"
 	"gozero-rag/internal/model/knowledge_retrieval_log"
 	"gozero-rag/internal/model/llm_factories"
+	"gozero-rag/internal/model/local_message"
 	"gozero-rag/internal/model/tenant"
 	"gozero-rag/internal/model/tenant_llm"
 	"gozero-rag/internal/model/user"
@@ -21,6 +25,7 @@ import (
 	"gozero-rag/internal/mq"
 
 	"gozero-rag/internal/oss"
+	"gozero-rag/internal/rag_core/previewer"
 	"gozero-rag/internal/rag_core/retriever"
 	"gozero-rag/restful/rag/internal/config"
 
@@ -43,12 +48,19 @@ type ServiceContext struct {
 
 	KnowledgeBaseModel     knowledge_base.KnowledgeBaseModel
 	KnowledgeDocumentModel knowledge_document.KnowledgeDocumentModel
+	DocumentVersionModel   knowledge_document_version.KnowledgeDocumentVersionModel
+	LocalMessageModel      local_message.LocalMessageModel // 本地消息表, 保证文档写入与索引任务投递的一致性
 	ChunkModel             chunk.ChunkModel
+	IndexSignal            *indexctl.SignalStore        // 索引取消/暂停信号
+	IndexCheckpoint        *indexctl.CheckpointStore    // 索引 embedding 检查点
+	IndexProgress          *indexctl.ProgressSubscriber // 索引进度订阅
 
 	KnowledgeRetrievalLogModel knowledge_retrieval_log.KnowledgeRetrievalLogModel
 
 	RetrieveSvc *retriever.RetrieverService
 
+	DocPreviewer *previewer.Previewer // 文档分片预览
+
 	ChatConversationModel chat_conversation.ChatConversationModel
 	ChatMessageModel      chat_message.ChatMessageModel
 
@@ -59,6 +71,8 @@ type ServiceContext struct {
 
 	// Nebula Graph
 	NebulaGraphModel graph.NebulaGraphModel
+	CommunityModel   graph.CommunityModel // 社区报告
+	GraphStore       *store.GraphStore    // 删除知识库时清理图谱数据
 }
 
 func (svc *ServiceContext) ensureKnowledgeBaseHasGraphSpace() {
@@ -70,12 +84,13 @@ func NewServiceContext(c config.Config) *ServiceContext {
 
 	sqlConn := sqlx.NewMysql(c.Mysql.DataSource)
 
-	rdb := redis.MustNewRedis(redis.RedisConf{
+	redisConf := redis.RedisConf{
 		Host: c.Cache[0].Host,
 		Type: c.Cache[0].Type,
 		User: c.Cache[0].User,
 		Pass: c.Cache[0].Pass,
-	})
+	}
+	rdb := redis.MustNewRedis(redisConf)
 
 	// Init OSS Client
 	ossClient, err := oss.NewClient(c.Oss)
@@ -96,16 +111,39 @@ func NewServiceContext(c config.Config) *ServiceContext {
 		panic(err) // ES is mandatory
 	}
 
+	// Init Nebula Graph Model
+	nebulaGraphModel, err := graph.NewNebulaGraphModel(c.Nebula.Addresses, c.Nebula.Username, c.Nebula.Password)
+	if err != nil {
+		logx.Errorf("NewNebulaGraphModel failed: %v", err)
+		panic(err)
+	}
+
+	graphModel, err := graph.NewEsGraphModel(c.ElasticSearch.Addresses, c.ElasticSearch.Username, c.ElasticSearch.Password)
+	if err != nil {
+		logx.Errorf("NewEsGraphModel failed: %v", err)
+		panic(err)
+	}
+
 	ctx := context.Background()
-	retrieverSvc, err := retriever.NewRetrieverService(ctx, esModel)
+	retrieverSvc, err := retriever.NewRetrieverService(ctx, esModel, graphModel, nebulaGraphModel)
 	if err != nil {
 		panic(err)
 	}
 
-	// Init Nebula Graph Model
-	nebulaGraphModel, err := graph.NewNebulaGraphModel(c.Nebula.Addresses, c.Nebula.Username, c.Nebula.Password)
+	docPreviewer, err := previewer.NewPreviewer(ctx)
 	if err != nil {
-		logx.Errorf("NewNebulaGraphModel failed: %v", err)
+		panic(err)
+	}
+
+	communityModel, err := graph.NewEsCommunityModel(c.ElasticSearch.Addresses, c.ElasticSearch.Username, c.ElasticSearch.Password)
+	if err != nil {
+		logx.Errorf("NewEsCommunityModel failed: %v", err)
+		panic(err)
+	}
+
+	contributionModel, err := graph.NewEsContributionModel(c.ElasticSearch.Addresses, c.ElasticSearch.Username, c.ElasticSearch.Password)
+	if err != nil {
+		logx.Errorf("NewEsContributionModel failed: %v", err)
 		panic(err)
 	}
 
@@ -122,12 +160,19 @@ func NewServiceContext(c config.Config) *ServiceContext {
 
 		KnowledgeBaseModel:     knowledge_base.NewKnowledgeBaseModel(sqlConn, c.Cache),
 		KnowledgeDocumentModel: knowledge_document.NewKnowledgeDocumentModel(sqlConn, c.Cache),
+		DocumentVersionModel:   knowledge_document_version.NewKnowledgeDocumentVersionModel(sqlConn),
+		LocalMessageModel:      local_message.NewLocalMessageModel(sqlConn),
 		ChunkModel:             esModel,
+		IndexSignal:            indexctl.NewSignalStore(rdb),
+		IndexCheckpoint:        indexctl.NewCheckpointStore(rdb),
+		IndexProgress:          indexctl.NewProgressSubscriber(redisConf),
 
 		KnowledgeRetrievalLogModel: knowledge_retrieval_log.NewKnowledgeRetrievalLogModel(sqlConn),
 
 		RetrieveSvc: retrieverSvc,
 
+		DocPreviewer: docPreviewer,
+
 		ChatConversationModel: chat_conversation.NewChatConversationModel(sqlConn, c.Cache),
 		ChatMessageModel:      chat_message.NewChatMessageModel(sqlConn),
 
@@ -136,6 +181,8 @@ func NewServiceContext(c config.Config) *ServiceContext {
 		TenantLlmModel:    tenant_llm.NewTenantLlmModel(sqlConn, c.Cache),
 
 		NebulaGraphModel: nebulaGraphModel,
+		CommunityModel:   communityModel,
+		GraphStore:       store.NewGraphStore(rdb, contributionModel, graphModel, nebulaGraphModel),
 	}
 	return svc
 }
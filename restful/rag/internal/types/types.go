@@ -3,6 +3,13 @@
 
 package types
 
+type AbortUploadSessionReq struct {
+	SessionId string `path:"session_id"`
+}
+
+type AbortUploadSessionResp struct {
+}
+
 type AddTenantLlmReq struct {
 	LlmFactory string        `json:"llm_factory"` // 厂商名称
 	ApiKey     string        `json:"api_key"`     // 共享的API密钥
@@ -35,6 +42,14 @@ type AddUserApiResp struct {
 	Id int64 `json:"id"` // 新增配置ID
 }
 
+type ApproveDocumentQaReq struct {
+	Id string `path:"id"` // QA ID
+}
+
+type ApproveDocumentQaResp struct {
+	QaStatus string `json:"qa_status"`
+}
+
 type BatchParseDocumentReq struct {
 	KnowledgeBaseId string   `json:"knowledge_base_id"` // 知识库ID
 	DocumentIds     []string `json:"document_ids"`      // 文档ID列表
@@ -43,6 +58,49 @@ type BatchParseDocumentReq struct {
 type BatchParseDocumentResp struct {
 }
 
+type CancelDocumentIndexReq struct {
+	Id string `path:"id"`
+}
+
+type CancelDocumentIndexResp struct {
+	RunStatus string `json:"run_status"` // 正在索引的文档由消费者异步取消, 返回时仍为 indexing
+}
+
+type ChatGraphContext struct {
+	KnowledgeBaseId string              `json:"knowledge_base_id"`
+	Mode            string              `json:"mode"`                // local | global
+	Content         string              `json:"content"`             // 交给 LLM 的图谱上下文
+	Entities        []ChatGraphEntity   `json:"entities,optional"`   // local: 命中及扩展的实体, 用于高亮图谱节点
+	Relations       []ChatGraphRelation `json:"relations,optional"`  // local: 实体之间的关系
+	Reports         []ChatGraphReport   `json:"reports,optional"`    // global: 参与检索的社区报告
+	KeyPoints       []ChatGraphKeyPoint `json:"key_points,optional"` // global: map-reduce 得到的关键要点
+}
+
+type ChatGraphEntity struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+}
+
+type ChatGraphKeyPoint struct {
+	Description string `json:"description"`
+	Score       int    `json:"score"` // 重要性评分 (0-100)
+}
+
+type ChatGraphRelation struct {
+	Source      string  `json:"source"`
+	Target      string  `json:"target"`
+	Description string  `json:"description"`
+	Weight      float64 `json:"weight"`
+}
+
+type ChatGraphReport struct {
+	Id          string  `json:"id"`
+	CommunityId int     `json:"community_id"`
+	Title       string  `json:"title"`
+	Rating      float64 `json:"rating"`
+}
+
 type ChatReq struct {
 	ConversationId     string             `json:"conversation_id"`    // 对应 chat_conversation表的id
 	Message            string             `json:"message"`            // 用户输入
@@ -59,6 +117,7 @@ type ChatResp struct {
 	Content          string               `json:"content,optional"`
 	ReasoningContent string               `json:"reasoning_content,optional"`
 	RetrievalDocs    []ChatRetrievalChunk `json:"retrieval_docs,optional"`
+	GraphContexts    []ChatGraphContext   `json:"graph_contexts,optional"`
 	TokenUsage       int                  `json:"token_usage,optional"`
 	FinishReason     string               `json:"finish_reason,optional"`
 	ErrorMsg         string               `json:"error_msg,optional"`
@@ -70,11 +129,11 @@ type ChatRetrievalChunk struct {
 	DocName string  `json:"doc_name"` // 文档名称
 	Content string  `json:"content"`  // 片段内容
 	Score   float64 `json:"score"`    // 匹配分数
-	Source  string  `json:"source"`   // 来源: vector, keyword
+	Source  string  `json:"source"`   // 来源: vector, keyword, graph
 }
 
 type ChatRetrieveConfig struct {
-	Mode                string  `json:"mode"` // 检索模式：vetcor,fulltext,hybrid
+	Mode                string  `json:"mode"` // 检索模式：vetcor,fulltext,hybrid,graph; 图谱模式: local (以实体为中心), global (基于社区报告)
 	TopK                int     `json:"top_k"`
 	Score               float64 `json:"score"`                // 阈值
 	RerankMode          string  `json:"rerank_mode"`          // hybrid模式下的rerank模式: weighted, rerank
@@ -84,13 +143,52 @@ type ChatRetrieveConfig struct {
 }
 
 type ChunkInfo struct {
-	Id          string   `json:"id"`
-	Content     string   `json:"content"`
-	DocId       string   `json:"doc_id"`
-	DocName     string   `json:"doc_name"`
-	ImportantKw []string `json:"important_keywords"` // 重要关键词
-	CreatedAt   float64  `json:"created_at"`         // 创建时间戳
-	Status      int      `json:"status"`             // 状态 1-启用 0-禁用
+	Id            string   `json:"id"`
+	Content       string   `json:"content"`
+	DocId         string   `json:"doc_id"`
+	DocName       string   `json:"doc_name"`
+	ImportantKw   []string `json:"important_keywords"`       // 重要关键词
+	CreatedAt     float64  `json:"created_at"`               // 创建时间戳
+	Status        int      `json:"status"`                   // 状态 1-启用 0-禁用
+	QualityScore  float64  `json:"quality_score"`            // 质量评分 (0-100)
+	QualityIssues []string `json:"quality_issues,omitempty"` // 质量问题
+}
+
+type ChunkQualityScore struct {
+	TotalScore     float64  `json:"total_score"`     // 总分 (0-100)
+	LengthScore    float64  `json:"length_score"`    // 长度合理性 (0-20)
+	StructureScore float64  `json:"structure_score"` // 结构完整性 (0-20)
+	ContentScore   float64  `json:"content_score"`   // 内容质量 (0-20)
+	SemanticScore  float64  `json:"semantic_score"`  // 语义完整性 (0-20)
+	QAScore        float64  `json:"qa_score"`        // QA 质量分 (0-20), 预览不生成 QA
+	Issues         []string `json:"issues"`          // 问题列表
+	Suggestions    []string `json:"suggestions"`     // 建议列表
+}
+
+type CommunityFindingInfo struct {
+	Summary     string `json:"summary"`
+	Explanation string `json:"explanation"`
+}
+
+type CommunityReportInfo struct {
+	Id                string                 `json:"id"`
+	CommunityId       int                    `json:"community_id"`
+	Title             string                 `json:"title"`
+	Summary           string                 `json:"summary"`
+	Rating            float64                `json:"rating"`             // 影响严重程度评分 (0-10)
+	RatingExplanation string                 `json:"rating_explanation"` // 评分解释
+	Findings          []CommunityFindingInfo `json:"findings"`
+	Entities          []string               `json:"entities"` // 社区包含的实体
+	UpdatedAt         string                 `json:"updated_at"`
+}
+
+type CompleteUploadSessionReq struct {
+	SessionId string           `path:"session_id"`
+	Parts     []UploadPartInfo `json:"parts,optional"` // multipart 模式的分片列表, 为空时以存储端已上传分片为准
+}
+
+type CompleteUploadSessionResp struct {
+	UploadedFileInfo
 }
 
 type Conversation struct {
@@ -129,12 +227,33 @@ type CreateKnowledgeBaseReq struct {
 	Permission             string  `json:"permission,optional,default=me"`
 	SimilarityThreshold    float64 `json:"similarity_threshold,optional,default=0.3"`
 	VectorSimilarityWeight float64 `json:"vector_similarity_weight,optional,default=0.3"`
+	DedupPolicy            string  `json:"dedup_policy,optional,default=allow,options=allow|skip|reject"` // 重复文档策略
+	AutoParse              bool    `json:"auto_parse,optional"`                                           // 上传后自动解析
 }
 
 type CreateKnowledgeBaseResp struct {
 	KnowledgeBaseInfo
 }
 
+type CreateUploadSessionReq struct {
+	KnowledgeBaseId string `json:"knowledge_base_id"`
+	FileName        string `json:"file_name"`
+	FileSize        int64  `json:"file_size"`
+	ContentType     string `json:"content_type,optional"`
+	FileHash        string `json:"file_hash,optional"` // 客户端计算的 SHA-256, 用于重复文档检测
+}
+
+type CreateUploadSessionResp struct {
+	SessionId  string `json:"session_id"`
+	Mode       string `json:"mode"`        // single: 单次直传 | multipart: 分片直传
+	UploadUrl  string `json:"upload_url"`  // single 模式的预签名 PUT 地址
+	PartSize   int64  `json:"part_size"`   // multipart 模式的分片大小(字节)
+	PartCount  int64  `json:"part_count"`  // multipart 模式的分片数
+	ExpireTime int64  `json:"expire_time"` // 会话过期时间戳(ms)
+	Duplicate  bool   `json:"duplicate"`   // 命中 skip 策略, 无需上传
+	DocumentId string `json:"document_id"` // Duplicate 时为已有文档ID
+}
+
 type DeleteAllDocumentReq struct {
 	Id string `path:"id"`
 }
@@ -149,6 +268,13 @@ type DeleteConversationReq struct {
 type DeleteConversationResp struct {
 }
 
+type DeleteDocumentQaReq struct {
+	Id string `path:"id"` // QA ID
+}
+
+type DeleteDocumentQaResp struct {
+}
+
 type DeleteKnowledgeBaseReq struct {
 	Id string `path:"id"`
 }
@@ -174,6 +300,63 @@ type DeleteUserApiReq struct {
 type DeleteUserApiResp struct {
 }
 
+type DocumentQaInfo struct {
+	Id        string  `json:"id"`
+	DocId     string  `json:"doc_id"`
+	SourceId  string  `json:"source_id"` // 来源切片ID
+	Question  string  `json:"question"`
+	Answer    string  `json:"answer"`
+	QaStatus  string  `json:"qa_status"`  // pending-待审核 approved-已审核
+	CreatedAt float64 `json:"created_at"` // 创建时间戳
+	Status    int     `json:"status"`     // 状态 1-启用 0-禁用
+}
+
+type DocumentQualityReportReq struct {
+	Id    string `path:"id"`                        // 文档ID
+	Limit int    `form:"limit,optional,default=10"` // 最低分切片数量
+}
+
+type DocumentQualityReportResp struct {
+	Total             int64               `json:"total"`              // 已评分切片数
+	Disabled          int64               `json:"disabled"`           // 禁用切片数
+	AvgScore          float64             `json:"avg_score"`          // 平均分
+	Distribution      []QualityBucketInfo `json:"distribution"`       // 分数分布
+	CommonIssues      []QualityTermCount  `json:"common_issues"`      // 常见问题
+	CommonSuggestions []QualityTermCount  `json:"common_suggestions"` // 常见建议
+	WorstChunks       []ChunkInfo         `json:"worst_chunks"`       // 最低分切片
+}
+
+type DocumentVersionInfo struct {
+	Version     int64  `json:"version"`      // 版本号
+	DocName     string `json:"doc_name"`     // 文件名
+	DocType     string `json:"doc_type"`     // 文件类型
+	DocSize     int64  `json:"doc_size"`     // 大小(字节)
+	FileHash    string `json:"file_hash"`    // 文件 SHA-256
+	CreatedBy   string `json:"created_by"`   // 上传者ID
+	CreatedTime int64  `json:"created_time"` // 上传时间戳(ms)
+	IsCurrent   bool   `json:"is_current"`   // 是否为当前版本
+}
+
+type DuplicateDocumentGroup struct {
+	FileHash  string                  `json:"file_hash"` // 文件 SHA-256
+	Documents []DuplicateDocumentInfo `json:"documents"`
+}
+
+type DuplicateDocumentInfo struct {
+	Id                string `json:"id"`
+	KnowledgeBaseId   string `json:"knowledge_base_id"`
+	KnowledgeBaseName string `json:"knowledge_base_name"`
+	DocName           string `json:"doc_name"`
+	DocSize           int64  `json:"doc_size"`
+	RunStatus         string `json:"run_status"`
+	CreatedTime       int64  `json:"created_time"`
+}
+
+type GetCommunityReportReq struct {
+	KbId string `path:"kb_id"`
+	Id   string `path:"id"`
+}
+
 type GetConversationHistoryReq struct {
 	ConversationId string `path:"conversation_id"`
 }
@@ -219,6 +402,31 @@ type GetRetrieveLogResp struct {
 	Logs  []RetrieveLog `json:"logs"`
 }
 
+type GetUploadPartUrlsReq struct {
+	SessionId   string  `path:"session_id"`
+	PartNumbers []int64 `json:"part_numbers"` // 分片序号, 从 1 开始
+}
+
+type GetUploadPartUrlsResp struct {
+	List []UploadPartUrl `json:"list"`
+}
+
+type GetUploadSessionReq struct {
+	SessionId string `path:"session_id"`
+}
+
+type GetUploadSessionResp struct {
+	SessionId     string           `json:"session_id"`
+	Mode          string           `json:"mode"`
+	FileName      string           `json:"file_name"`
+	FileSize      int64            `json:"file_size"`
+	UploadUrl     string           `json:"upload_url"` // single 模式重新签发的上传地址
+	PartSize      int64            `json:"part_size"`
+	PartCount     int64            `json:"part_count"`
+	UploadedParts []UploadPartInfo `json:"uploaded_parts"`
+	ExpireTime    int64            `json:"expire_time"`
+}
+
 type GetUserApiInfoReq struct {
 	Id uint64 `path:"id"` // 对应 user_api.id
 }
@@ -291,6 +499,7 @@ type HistoryMessage struct {
 	TokenCount       int                  `json:"token_count"`
 	CreatedAt        string               `json:"created_at"`
 	RetrievalDocs    []ChatRetrievalChunk `json:"retrieval_docs,optional"`
+	GraphContexts    []ChatGraphContext   `json:"graph_contexts,optional"`
 }
 
 type HybridStrategy struct {
@@ -304,6 +513,22 @@ type HybridWeights struct {
 	Keyword float64 `json:"keyword,optional"` // 关键词检索权重，默认 0.3
 }
 
+type IndexProgressEvent struct {
+	DocumentId      string  `json:"document_id"`
+	KnowledgeBaseId string  `json:"knowledge_base_id"`
+	Step            string  `json:"step"`     // download | parse | split | qa | embedding | write | graph | finish, 连接建立时的快照为空
+	Progress        float64 `json:"progress"` // 0-1
+	Current         int     `json:"current"`  // 当前步骤已完成数量, 如 embedding 批次
+	Total           int     `json:"total"`    // 当前步骤总数量
+	Message         string  `json:"message"`
+	RunStatus       string  `json:"run_status"`
+	Time            int64   `json:"time"` // 毫秒时间戳
+}
+
+type IndexProgressReq struct {
+	KnowledgeBaseId string `form:"knowledge_base_id"`
+}
+
 type JoinTeamReq struct {
 	InviteCode string `json:"invite_code"` // 邀请码
 }
@@ -344,6 +569,8 @@ type KnowledgeBaseInfo struct {
 	Status                 int64   `json:"status"`
 	ParserId               string  `json:"parser_id"`     // 解析器ID
 	ParserConfig           string  `json:"parser_config"` // 解析配置 JSON
+	DedupPolicy            string  `json:"dedup_policy"`  // 重复文档策略: allow|skip|reject
+	AutoParse              bool    `json:"auto_parse"`    // 上传后自动解析
 	CreatedTime            int64   `json:"created_time"`
 	UpdatedTime            int64   `json:"updated_time"`
 }
@@ -360,7 +587,12 @@ type KnowledgeDocumentInfo struct {
 	RunStatus       string  `json:"run_status"`
 	ChunkNum        int64   `json:"chunk_num"`
 	TokenNum        int64   `json:"token_num"`
-	ParserConfig    string  `json:"parser_config"` // JSON string
+	ChunkAdded      int64   `json:"chunk_added"`      // 最近一次索引新增的分片数
+	ChunkRemoved    int64   `json:"chunk_removed"`    // 最近一次索引删除的分片数
+	ChunkReused     int64   `json:"chunk_reused"`     // 最近一次索引复用的分片数
+	ParserConfig    string  `json:"parser_config"`    // 文档级覆盖配置 JSON, 索引时叠加在知识库配置之上
+	EffectiveConfig string  `json:"effective_config"` // 最近一次索引实际生效的解析配置 JSON
+	FileHash        string  `json:"file_hash"`        // 文件 SHA-256
 	Progress        float64 `json:"progress"`
 	ProgressMsg     string  `json:"progress_msg"`
 	CreatedBy       string  `json:"created_by"`
@@ -368,6 +600,42 @@ type KnowledgeDocumentInfo struct {
 	UpdatedTime     int64   `json:"updated_time"`
 }
 
+type ListCommunityReportsReq struct {
+	KbId     string `path:"kb_id"`
+	Page     int64  `form:"page,optional,default=1"`
+	PageSize int64  `form:"page_size,optional,default=20"`
+}
+
+type ListCommunityReportsResp struct {
+	Total int64                 `json:"total"`
+	List  []CommunityReportInfo `json:"list"`
+}
+
+type ListDocumentQaReq struct {
+	Id       string `path:"id"`                                          // 文档ID
+	SourceId string `form:"source_id,optional"`                          // 按来源切片过滤
+	QaStatus string `form:"qa_status,optional,options=pending|approved"` // 按审核状态过滤
+	Page     int64  `form:"page,optional,default=1"`                     // 页码
+	PageSize int64  `form:"page_size,optional,default=20"`               // 每页条数
+}
+
+type ListDocumentQaResp struct {
+	Total int64            `json:"total"`
+	List  []DocumentQaInfo `json:"list"`
+}
+
+type ListDocumentVersionsReq struct {
+	Id string `path:"id"` // 文档ID
+}
+
+type ListDocumentVersionsResp struct {
+	List []DocumentVersionInfo `json:"list"`
+}
+
+type ListDuplicateDocumentsResp struct {
+	List []DuplicateDocumentGroup `json:"list"`
+}
+
 type ListJoinedTeamsResp struct {
 	List []JoinedTeam `json:"list"`
 }
@@ -463,6 +731,57 @@ type ModelConfig struct {
 	MaxTokens int64  `json:"max_tokens,optional,default=8192"`
 }
 
+type PauseDocumentIndexReq struct {
+	Id string `path:"id"`
+}
+
+type PauseDocumentIndexResp struct {
+	RunStatus string `json:"run_status"` // 正在索引的文档在当前 embedding 批次完成后暂停, 返回时仍为 indexing
+}
+
+type PreviewChunkInfo struct {
+	Index      int               `json:"index"`       // 切片序号
+	Content    string            `json:"content"`     // 切片内容
+	Length     int               `json:"length"`      // 字符数
+	Header     string            `json:"header"`      // 识别到的标题上下文
+	HeaderType string            `json:"header_type"` // 标题类型
+	Quality    ChunkQualityScore `json:"quality"`     // 质量评分
+}
+
+type PreviewKnowledgeDocumentReq struct {
+	Id           string `path:"id"`                        // 文档ID
+	ParserId     string `json:"parser_id,optional"`        // 候选解析模板, 为空时使用文档/知识库的 parser_id
+	ParserConfig string `json:"parser_config,optional"`    // 候选解析配置 JSON, 叠加在文档/知识库配置之上
+	Limit        int    `json:"limit,optional,default=10"` // 返回前 N 个切片, 最大 50
+}
+
+type PreviewKnowledgeDocumentResp struct {
+	ContentLength int                `json:"content_length"` // 文档字符总数
+	Truncated     bool               `json:"truncated"`      // 文档过长, 只切分了前 50000 个字符
+	TotalChunks   int                `json:"total_chunks"`   // 预览内容的切片总数
+	TotalLength   int                `json:"total_length"`   // 预览内容所有切片的字符总数
+	List          []PreviewChunkInfo `json:"list"`           // 前 N 个切片
+}
+
+type QualityBucketInfo struct {
+	From  float64 `json:"from"`
+	To    float64 `json:"to"`
+	Count int64   `json:"count"`
+}
+
+type QualityTermCount struct {
+	Term  string `json:"term"`
+	Count int64  `json:"count"`
+}
+
+type RegenerateChunkQaReq struct {
+	Id string `path:"id"` // 切片ID
+}
+
+type RegenerateChunkQaResp struct {
+	List []DocumentQaInfo `json:"list"`
+}
+
 type RegisterRequest struct {
 	Nickname        string `json:"nickname"`
 	Email           string `json:"email"`
@@ -470,19 +789,28 @@ type RegisterRequest struct {
 	ConfirmPassword string `json:"confirm_password"`
 }
 
+type ResumeDocumentIndexReq struct {
+	Id string `path:"id"`
+}
+
+type ResumeDocumentIndexResp struct {
+	RunStatus string `json:"run_status"`
+}
+
 type RetrievalChunk struct {
 	ChunkID string  `json:"chunk_id"` // 片段唯一ID
 	DocID   string  `json:"doc_id"`   // 所属文档ID
 	DocName string  `json:"doc_name"` // 文档名称
 	Content string  `json:"content"`  // 片段内容
 	Score   float64 `json:"score"`    // 匹配分数
-	Source  string  `json:"source"`   // 来源: vector, keyword
+	Source  string  `json:"source"`   // 来源: vector, keyword, graph
 }
 
 type RetrievalConfig struct {
 	TopK           int            `json:"top_k,optional,default=10"`            // 返回结果数量
 	ScoreThreshold float64        `json:"score_threshold,optional,default=0.6"` // 相似度阈值
 	HybridStrategy HybridStrategy `json:"hybrid_strategy,optional"`             // 混合检索策略配置
+	GraphHops      int            `json:"graph_hops,optional,default=1"`        // graph 模式: 实体邻居扩展跳数 (1-3)
 }
 
 type RetrieveLog struct {
@@ -499,9 +827,10 @@ type RetrieveLog struct {
 type RetrieveReq struct {
 	KnowledgeBaseId string          `path:"knowledge_base_id"`
 	Query           string          `json:"query"`                                  // 用户查询语句
-	RetrievalMode   string          `json:"retrieval_mode,optional,default=hybrid"` // 检索模式: vector, fulltext, hybrid
+	RetrievalMode   string          `json:"retrieval_mode,optional,default=hybrid"` // 检索模式: vector, fulltext, hybrid, graph
 	RetrievalConfig RetrievalConfig `json:"retrieval_config,optional"`              // 详细检索配置
 	DocIDs          []string        `json:"doc_ids,optional"`                       // 限定检索的文档ID列表
+	SheetNames      []string        `json:"sheet_names,optional"`                   // 表格知识库: 限定检索的工作表
 }
 
 type RetrieveResp struct {
@@ -518,6 +847,14 @@ type RetryDocumentReq struct {
 type RetryDocumentResp struct {
 }
 
+type RollbackDocumentVersionReq struct {
+	Id      string `path:"id"`      // 文档ID
+	Version int64  `path:"version"` // 目标版本号
+}
+
+type RollbackDocumentVersionResp struct {
+}
+
 type SetDefaultModelReq struct {
 	UserId    string `json:"user_id"`  // 用户ID (UUID)
 	ModelId   int64  `json:"model_id"` // 对应user_api表的id
@@ -595,6 +932,16 @@ type UpdateDocumentParserConfigReq struct {
 type UpdateDocumentParserConfigResp struct {
 }
 
+type UpdateDocumentQaReq struct {
+	Id       string `path:"id"` // QA ID
+	Question string `json:"question"`
+	Answer   string `json:"answer"`
+}
+
+type UpdateDocumentQaResp struct {
+	Qa DocumentQaInfo `json:"qa"`
+}
+
 type UpdateKnowledgeBasePermissionReq struct {
 	Id         string `path:"id"`
 	Permission string `json:"permission"` // me | team
@@ -613,8 +960,10 @@ type UpdateKnowledgeBaseReq struct {
 	SimilarityThreshold    float64 `json:"similarity_threshold,optional"`
 	VectorSimilarityWeight float64 `json:"vector_similarity_weight,optional"`
 	Status                 int64   `json:"status,optional"`
-	ParserId               string  `json:"parser_id,optional"`     // 解析器ID: general | resume
-	ParserConfig           string  `json:"parser_config,optional"` // 解析配置 JSON
+	ParserId               string  `json:"parser_id,optional"`                              // 解析器ID: general | table | qa | laws | book | resume
+	ParserConfig           string  `json:"parser_config,optional"`                          // 解析配置 JSON
+	DedupPolicy            string  `json:"dedup_policy,optional,options=allow|skip|reject"` // 重复文档策略
+	AutoParse              *bool   `json:"auto_parse,optional"`                             // 上传后自动解析, 不传则不修改
 }
 
 type UpdateKnowledgeBaseResp struct {
@@ -637,9 +986,32 @@ type UploadDocumentResp struct {
 	Files   []UploadedFileInfo `json:"files"`    // 文件详细信息列表
 }
 
+type UploadDocumentVersionReq struct {
+	Id string `path:"id"` // 文档ID
+}
+
+type UploadDocumentVersionResp struct {
+	Version       int64  `json:"version"`                  // 新版本号, 命中 skip 策略时为当前最新版本号
+	Duplicate     bool   `json:"duplicate"`                // 命中 skip 策略, 未创建新版本
+	DuplicateId   string `json:"duplicate_id,omitempty"`   // 内容相同的已有文档ID
+	DuplicateName string `json:"duplicate_name,omitempty"` // 内容相同的已有文档名称
+}
+
+type UploadPartInfo struct {
+	PartNumber int64  `json:"part_number"`
+	ETag       string `json:"etag"`
+	Size       int64  `json:"size,optional"`
+}
+
+type UploadPartUrl struct {
+	PartNumber int64  `json:"part_number"`
+	Url        string `json:"url"`
+}
+
 type UploadedFileInfo struct {
-	Id      string `json:"id"`
-	DocName string `json:"doc_name"`
+	Id        string `json:"id"`
+	DocName   string `json:"doc_name"`
+	Duplicate bool   `json:"duplicate"` // 命中 skip 策略, 返回的是已有文档
 }
 
 type UserApiInfo struct {
@@ -20,8 +20,10 @@ CREATE TABLE `knowledge_base`
     `vector_similarity_weight` float NOT NULL DEFAULT 0.3 COMMENT '混合检索向量权重 (1-keyword)',
     `status` tinyint NOT NULL DEFAULT 1 COMMENT '状态: 1-启用, 0-禁用',
 
-    `parser_id` varchar(36) NOT NULL DEFAULT 'general' COMMENT '解析器ID,目前仅支持 general | resume',
+    `parser_id` varchar(36) NOT NULL DEFAULT 'general' COMMENT '解析器ID: general | table | qa | laws | book | resume',
     `parser_config` longtext COMMENT '解析器配置, 默认是 {}',
+    `dedup_policy` varchar(16) NOT NULL DEFAULT 'allow' COMMENT '重复文档策略: allow|skip|reject',
+    `auto_parse` tinyint NOT NULL DEFAULT 0 COMMENT '上传后自动解析: 1-开启, 0-关闭',
 
     `created_time` bigint NOT NULL COMMENT '创建时间戳(ms)',
     `updated_time` bigint NOT NULL COMMENT '更新时间戳(ms)',
@@ -21,8 +21,13 @@ CREATE TABLE `knowledge_document` (
   `run_status` varchar(32) NOT NULL DEFAULT 'pending' COMMENT '状态: pending/running/success/fail/paused',
   `status` tinyint DEFAULT 1 COMMENT '状态: 1-有效, 0-删除/禁用',
 
-  `parser_id` varchar(36) NOT NULL DEFAULT 'general' COMMENT '解析器ID,目前仅支持 general | resume',
+  `parser_id` varchar(36) NOT NULL DEFAULT 'general' COMMENT '解析器ID: general | table | qa | laws | book | resume',
   `parser_config` longtext NOT NULL COMMENT '解析配置(JSON)',
+  `file_hash` varchar(64) NOT NULL DEFAULT '' COMMENT '文件 SHA-256, 用于重复文档检测',
+  `effective_config` longtext COMMENT '最近一次索引实际生效的解析配置(JSON)',
+  `chunk_added` int(11) NOT NULL DEFAULT 0 COMMENT '最近一次索引新增的分片数',
+  `chunk_removed` int(11) NOT NULL DEFAULT 0 COMMENT '最近一次索引删除的分片数',
+  `chunk_reused` int(11) NOT NULL DEFAULT 0 COMMENT '最近一次索引复用的分片数',
 
 
   `created_time` bigint NOT NULL COMMENT '创建时间戳(ms)',
@@ -32,5 +37,6 @@ CREATE TABLE `knowledge_document` (
   `meta_fields` longtext COMMENT '元数据',
   PRIMARY KEY (`id`),
   KEY `idx_doc_kb_id` (`knowledge_base_id`),
-  KEY `idx_doc_name` (`doc_name`)
+  KEY `idx_doc_name` (`doc_name`),
+  KEY `idx_doc_kb_file_hash` (`knowledge_base_id`, `file_hash`)
 ) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci COMMENT ='文档表';
\ No newline at end of file
@@ -0,0 +1,19 @@
+use gozero_rag;
+
+DROP TABLE IF EXISTS `knowledge_document_version`;
+CREATE TABLE `knowledge_document_version`
+(
+    `id`           bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+    `document_id`  char(36)     NOT NULL COMMENT '文档ID',
+    `version`      int          NOT NULL COMMENT '版本号, 从1开始递增',
+    `doc_name`     varchar(255) NOT NULL COMMENT '该版本的文件名',
+    `doc_type`     varchar(32)  NOT NULL COMMENT '类型: pdf/word/txt/md',
+    `doc_size`     bigint       NOT NULL DEFAULT 0 COMMENT '大小(字节)',
+    `file_hash`    varchar(64)  NOT NULL DEFAULT '' COMMENT '文件 SHA-256, 历史数据可能为空',
+    `storage_path` varchar(255) NOT NULL COMMENT '存储路径(MinIO), 旧版本文件保留',
+    `created_by`   varchar(36)  NOT NULL COMMENT '上传者ID',
+    `created_time` bigint       NOT NULL COMMENT '上传时间戳(ms)',
+    `created_at`   datetime     NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_document_version` (`document_id`, `version`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci COMMENT ='文档版本表';